@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maai.solutions/gengo/internal/extractors/asr"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/output"
+)
+
+var (
+	audioOutputDir string
+	audioModel     string
+	audioLanguage  string
+	audioPerSeg    bool
+	audioSkipSil   bool
+	audioSilenceDB float64
+	audioFFmpeg    string
+	audioAFilter   string
+	audioDenoise   bool
+	audioNormalize bool
+	audioFormat    string
+	audioSettle    time.Duration
+	audioExisting  bool
+)
+
+// audioCmd represents the audio command
+var audioCmd = &cobra.Command{
+	Use:   "audio",
+	Short: "Transcribe local audio files",
+	Long:  `Transcribe audio files on disk with whisper.`,
+}
+
+// audioWatchCmd represents the audio watch subcommand
+var audioWatchCmd = &cobra.Command{
+	Use:   "watch [directory]",
+	Short: "Transcribe audio files as they appear in a directory",
+	Long: `Watch a directory and transcribe each audio file added to it, writing the
+transcript to --output under the file's name, e.g. meeting.mp3 becomes
+meeting.md. The whisper model is loaded once and kept between files.
+
+Files are transcribed once completely written: recordings that are copied in
+or still being recorded are picked up when their size has not changed for
+--settle. Audio and video files ffmpeg can read are recognized by extension:
+` + strings.Join(asr.AudioExtensions, " ") + `
+
+Files already in the directory are left alone unless --existing is given.
+Watching stops with Ctrl-C, after the file being transcribed is done.
+
+Examples:
+  gengo audio watch ./incoming --output ./transcripts
+  gengo audio watch ./incoming -o ./transcripts --model small --language de
+  gengo audio watch ./recorder -o ./notes --settle 10s --existing`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := args[0]
+
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: Not a directory: %s\n", dir)
+			os.Exit(1)
+		}
+		format, err := output.ParseFormat(audioFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		language, err := asr.ValidateLanguage(audioLanguage)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if audioPerSeg && language != "" {
+			fmt.Fprintln(os.Stderr, "Error: --per-segment-language detects the language, it cannot be combined with --language "+audioLanguage)
+			os.Exit(1)
+		}
+		if audioSilenceDB >= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --silence-threshold must be below 0 dBFS, got %g\n", audioSilenceDB)
+			os.Exit(1)
+		}
+		filter, err := buildAudioFilter(cmd.Context(), audioFFmpeg, audioAFilter, audioDenoise, audioNormalize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		modelPath := ytaudio.FindWhisperModel(audioModel)
+		if modelPath == "" {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", asr.ModelNotFound(audioModel))
+			fmt.Fprintf(os.Stderr, "Available models: %s\n", strings.Join(ytaudio.WhisperModels, ", "))
+			os.Exit(1)
+		}
+
+		opts := output.OutputOptions{
+			OutputDir:  audioOutputDir,
+			Format:     format,
+			SourceType: output.SourceTranscript,
+		}
+
+		if dryRun {
+			details := []string{"Model: " + modelPath}
+			if filter != "" {
+				details = append(details, "Audio filter: "+filter)
+			}
+			printPlan(output.Plan{
+				Action:  "watch directory and transcribe new audio files",
+				Source:  dir,
+				Details: details,
+			}, opts)
+			return
+		}
+
+		service := asr.NewService(&asr.Config{
+			WhisperModel: modelPath,
+			Language:     language,
+			FFmpegPath:   audioFFmpeg,
+			AudioFilter:  filter,
+
+			PerSegmentLanguage: audioPerSeg,
+			SkipSilence:        audioSkipSil,
+			SilenceThreshold:   audioSilenceDB,
+		})
+		if err := service.LoadModel(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer service.Close()
+
+		ctx := cmd.Context()
+		statusf("Watching %s for audio files (Ctrl-C to stop)\n", dir)
+		err = asr.Watch(ctx, dir, asr.WatchOptions{Settle: audioSettle, Existing: audioExisting}, func(path string) {
+			path, err := transcribeWatched(ctx, service, path, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error %v\n", err)
+				return
+			}
+			statusf("Transcript saved to: %s\n", path)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		statusln("Stopped watching")
+	},
+}
+
+// transcribeWatched transcribes an audio file and writes the transcript,
+// named after the file, returning the path written
+func transcribeWatched(ctx context.Context, service *asr.Service, path string, opts output.OutputOptions) (string, error) {
+	statusf("Transcribing %s\n", path)
+
+	// Each file converts in a directory of its own, so transcriptions of
+	// this and other runs never share a work file
+	workDir, err := os.MkdirTemp("", "gengo-audio-*")
+	if err != nil {
+		return "", fmt.Errorf("creating a work directory for %s: %w", path, err)
+	}
+	defer os.RemoveAll(workDir)
+
+	transcribed, err := service.TranscribeAudio(ctx, path, workDir)
+	if err != nil {
+		return "", fmt.Errorf("transcribing %s: %w", path, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	result := output.NewResult(output.SourceTranscript, path, name, transcribed.Text)
+	result.Metadata = map[string]string{"Transcribed": result.ExtractedAt.Format("2006-01-02 15:04:05")}
+	if transcribed.Language != "" {
+		result.Metadata["Language"] = transcribed.Language
+	}
+	if languages := asr.SegmentLanguages(transcribed.Segments); len(languages) > 1 {
+		result.Metadata["Languages"] = strings.Join(languages, ", ")
+	}
+	if transcribed.Partial {
+		result.Metadata["Partial"] = "transcription stopped early"
+	}
+
+	written, err := output.Write(result, opts)
+	if err != nil {
+		return "", fmt.Errorf("saving the transcript of %s: %w", path, err)
+	}
+	return written, nil
+}
+
+// buildAudioFilter chains --denoise, --audio-filter and --normalize into the
+// ffmpeg filter graph applied before transcription, normalizing last so the
+// removed noise is not amplified, and checks that ffmpeg accepts it
+func buildAudioFilter(ctx context.Context, ffmpegPath, filter string, denoise, normalize bool) (string, error) {
+	var denoiseFilter, normalizeFilter string
+	if denoise {
+		denoiseFilter = asr.DenoiseFilter
+	}
+	if normalize {
+		normalizeFilter = asr.NormalizeFilter
+	}
+
+	chain := asr.JoinFilters(denoiseFilter, filter, normalizeFilter)
+	if chain == "" {
+		return "", nil
+	}
+	if err := asr.CheckAudioFilter(ctx, ffmpegPath, chain); err != nil {
+		return "", err
+	}
+	return chain, nil
+}
+
+func init() {
+	rootCmd.AddCommand(audioCmd)
+	audioCmd.AddCommand(audioWatchCmd)
+
+	audioWatchCmd.Flags().StringVarP(&audioOutputDir, "output", "o", "transcripts", "Directory transcripts are written to")
+	audioWatchCmd.Flags().StringVarP(&audioModel, "model", "m", "base", "Whisper model to use (tiny, base, small, medium, large)")
+	audioWatchCmd.Flags().StringVarP(&audioLanguage, "language", "l", asr.AutoLanguage, "Spoken language code (e.g. en, de, es), or auto to detect it")
+	audioWatchCmd.Flags().BoolVar(&audioPerSeg, "per-segment-language", false, "Detect the language of every 30 seconds of audio separately, for recordings that switch languages")
+	audioWatchCmd.Flags().BoolVar(&audioSkipSil, "skip-silence", false, "Transcribe only the audio louder than --silence-threshold, skipping pauses of a second or more")
+	audioWatchCmd.Flags().Float64Var(&audioSilenceDB, "silence-threshold", asr.DefaultSilenceThreshold, "Level in dBFS below which audio counts as silence with --skip-silence")
+	audioWatchCmd.Flags().StringVar(&audioFFmpeg, "ffmpeg", "", "Path to the ffmpeg binary (default: ffmpeg on PATH)")
+	audioWatchCmd.Flags().StringVar(&audioAFilter, "audio-filter", "", "ffmpeg audio filters applied before transcription, e.g. highpass=f=200,afftdn")
+	audioWatchCmd.Flags().BoolVar(&audioDenoise, "denoise", false, "Filter out rumble, hiss and background noise before transcription")
+	audioWatchCmd.Flags().BoolVar(&audioNormalize, "normalize", false, "Even out the loudness of quiet or distant speakers before transcription")
+	audioWatchCmd.Flags().StringVarP(&audioFormat, "format", "f", "markdown", "Transcript format (text, markdown, json)")
+	audioWatchCmd.Flags().DurationVar(&audioSettle, "settle", asr.DefaultSettle, "How long a file's size must stay unchanged before it is transcribed")
+	audioWatchCmd.Flags().BoolVar(&audioExisting, "existing", false, "Also transcribe the audio files already in the directory")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/project"
+)
+
+// batchState records the outcome of every source of a batch in the state
+// file of its destination, so an interrupted run can be picked up again with
+// --resume or --retry-failed-only
+type batchState struct {
+	batch *project.Batch
+}
+
+// startBatch loads the batch state kept with the destination of opts and
+// returns the sources to extract: all of them, or with resume those not done
+// yet, or with failedOnly only those that failed. State is kept when several
+// sources are saved into a folder, and whenever resuming.
+func startBatch(opts output.OutputOptions, sources []string, resume, failedOnly bool) (*batchState, []string, error) {
+	dir := output.BatchDir(opts)
+	if dir == "" {
+		if resume || failedOnly {
+			return nil, nil, errors.New("--resume and --retry-failed-only need --project or --dir to keep the batch state in")
+		}
+		return nil, sources, nil
+	}
+	if len(sources) < 2 && !resume && !failedOnly {
+		return nil, sources, nil
+	}
+
+	if err := output.EnsureDir(dir); err != nil {
+		return nil, nil, err
+	}
+	batch, err := project.LoadBatch(dir, output.FileMode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := batch.Add(sources); err != nil {
+		return nil, nil, err
+	}
+	if !resume && !failedOnly {
+		return &batchState{batch}, sources, nil
+	}
+
+	remaining := batch.Remaining(sources, failedOnly)
+	noticef("Resuming batch: %d of %d sources done, %d failed before, %d to extract\n",
+		len(sources)-len(batch.Remaining(sources, false)), len(sources), len(batch.Remaining(sources, true)), len(remaining))
+	return &batchState{batch}, remaining, nil
+}
+
+// record saves the outcome of a source. Failing to save it is reported but
+// does not stop the batch.
+func (s *batchState) record(source string, err error) {
+	if s == nil {
+		return
+	}
+	if err := s.batch.Mark(source, err); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/project"
+)
+
+func TestStartBatch(t *testing.T) {
+	dir := t.TempDir()
+	opts := output.OutputOptions{OutputDir: dir}
+	sources := []string{"a.pdf", "b.pdf", "c.pdf"}
+
+	batch, got, err := startBatch(opts, sources, false, false)
+	if err != nil {
+		t.Fatalf("startBatch failed: %v", err)
+	}
+	if !slices.Equal(got, sources) {
+		t.Errorf("Expected every source on a first run, got %v", got)
+	}
+	batch.record("a.pdf", nil)
+	batch.record("b.pdf", errors.New("broken"))
+
+	if _, got, _ = startBatch(opts, sources, true, false); !slices.Equal(got, []string{"b.pdf", "c.pdf"}) {
+		t.Errorf("Expected --resume to skip the done source, got %v", got)
+	}
+	if _, got, _ = startBatch(opts, sources, false, true); !slices.Equal(got, []string{"b.pdf"}) {
+		t.Errorf("Expected --retry-failed-only to pick the failed source, got %v", got)
+	}
+
+	state, err := project.LoadBatch(dir, output.FileMode)
+	if err != nil || state.Count(project.BatchDone) != 1 || state.Count(project.BatchFailed) != 1 {
+		t.Errorf("Expected the outcomes to be saved next to the output, got %v", err)
+	}
+}
+
+func TestStartBatchNeedsFolder(t *testing.T) {
+	if _, _, err := startBatch(output.OutputOptions{}, []string{"a.pdf", "b.pdf"}, true, false); err == nil {
+		t.Error("Expected --resume without a folder to fail")
+	}
+
+	// Without resuming, stdout batches and single sources keep no state
+	batch, got, err := startBatch(output.OutputOptions{}, []string{"a.pdf", "b.pdf"}, false, false)
+	if err != nil || batch != nil || len(got) != 2 {
+		t.Errorf("Expected no state for stdout, got %v, %v", batch, err)
+	}
+	if batch, _, _ := startBatch(output.OutputOptions{OutputDir: t.TempDir()}, []string{"a.pdf"}, false, false); batch != nil {
+		t.Error("Expected no state for a single source")
+	}
+}
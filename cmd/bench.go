@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maai.solutions/gengo/internal/extractors/registry"
+	webextractors "maai.solutions/gengo/internal/extractors/web"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/metrics"
+	"maai.solutions/gengo/internal/output"
+)
+
+var (
+	benchIterations  int
+	benchFormat      string
+	benchModel       string
+	benchContentTags []string
+	benchSkipTags    []string
+	benchCPUProfile  string
+	benchMemProfile  string
+	benchTimeout     time.Duration
+)
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench [source]",
+	Short: "Measure how fast a source is extracted",
+	Long: `Extract a source repeatedly, the way "gengo extract" does, and report the
+latency of each run (min, avg and max), the throughput and the peak memory
+use. Each run includes downloading remote sources and rendering the result
+in --format, nothing is written.
+
+Use it to compare whisper models, content selectors and output formats on
+your own sources, and write a CPU or heap profile to find out where the time
+goes:
+
+  go tool pprof -top cpu.pprof
+
+Memory is measured on the Go heap, so memory held by whisper.cpp during
+transcription is not included.
+
+Examples:
+  gengo bench report.pdf                                   # 5 runs
+  gengo bench https://example.com/article -n 20 -f json    # 20 runs, rendered as JSON
+  gengo bench https://example.com/article --content-tags article
+  gengo bench https://youtu.be/abc123 -n 1 --model tiny    # Time a transcription
+  gengo bench report.pdf --cpuprofile cpu.pprof --memprofile mem.pprof`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		source := args[0]
+
+		format, err := output.ParseFormat(benchFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if benchIterations < 1 {
+			fmt.Fprintf(os.Stderr, "Error: invalid iterations %d: must be 1 or more\n", benchIterations)
+			os.Exit(1)
+		}
+
+		e := benchExtractors().Lookup(strings.TrimSpace(source))
+		if e == nil {
+			fmt.Fprintf(os.Stderr, "Error: Unsupported source: %s\n", source)
+			fmt.Fprintln(os.Stderr, "Supported sources: YouTube URLs, http(s) URLs, .pdf, .docx, .epub and .md files")
+			os.Exit(1)
+		}
+
+		if dryRun {
+			plan := output.Plan{
+				Action:  fmt.Sprintf("extract %s source %d times and report timings", e.Name(), benchIterations),
+				Source:  source,
+				Details: []string{"Format: " + string(format)},
+			}
+			for _, profile := range []string{benchCPUProfile, benchMemProfile} {
+				if profile != "" {
+					plan.Details = append(plan.Details, "Profile: "+profile)
+				}
+			}
+			printPlan(plan, output.OutputOptions{})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), benchTimeout)
+		defer cancel()
+
+		if benchCPUProfile != "" {
+			f, err := os.Create(benchCPUProfile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			if err := pprof.StartCPUProfile(f); err != nil {
+				fmt.Fprintf(os.Stderr, "Error starting CPU profile: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		statusf("Extracting %s %d times...\n", source, benchIterations)
+		result, err := benchmark(ctx, e, source, benchIterations, format)
+		if benchCPUProfile != "" {
+			pprof.StopCPUProfile()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error extracting %s source: %v\n", e.Name(), err)
+			os.Exit(1)
+		}
+
+		if benchMemProfile != "" {
+			if err := writeHeapProfile(benchMemProfile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		fmt.Print(result.String())
+		if benchCPUProfile != "" {
+			statusf("CPU profile written to: %s\n", benchCPUProfile)
+		}
+		if benchMemProfile != "" {
+			statusf("Heap profile written to: %s\n", benchMemProfile)
+		}
+	},
+}
+
+// benchExtractors returns the extractors of "gengo extract", with the web
+// and YouTube extractors configured from the bench flags
+func benchExtractors() *registry.Registry {
+	r := sourceExtractors()
+	r.Register(&ytaudio.Extractor{Model: benchModel})
+	r.Register(&webextractors.PageExtractor{Options: webextractors.Options{
+		ContentTags: benchContentTags,
+		SkipTags:    benchSkipTags,
+	}})
+	return r
+}
+
+// benchResult summarizes the runs of a benchmark
+type benchResult struct {
+	Source    string
+	Extractor string
+	Durations []time.Duration // time of each run
+	Bytes     int             // size of the rendered result
+	PeakHeap  uint64          // largest Go heap seen during the runs
+	Allocated uint64          // bytes allocated over all runs
+}
+
+// benchmark extracts source iterations times with e, rendering each result
+// in format, and measures the runs
+func benchmark(ctx context.Context, e registry.Extractor, source string, iterations int, format output.Format) (benchResult, error) {
+	result := benchResult{Source: source, Extractor: e.Name()}
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	stop := sampleHeap(&result.PeakHeap)
+	for i := 0; i < iterations; i++ {
+		start := time.Now()
+		extracted, err := e.Extract(ctx, source)
+		if err != nil {
+			stop()
+			return result, err
+		}
+		data, err := output.Render(extracted, format)
+		if err != nil {
+			stop()
+			return result, err
+		}
+		result.Durations = append(result.Durations, time.Since(start))
+		result.Bytes = len(data)
+	}
+	stop()
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	result.Allocated = after.TotalAlloc - before.TotalAlloc
+	return result, nil
+}
+
+// heapSampleInterval is how often sampleHeap reads the heap size
+const heapSampleInterval = 10 * time.Millisecond
+
+// sampleHeap records the largest Go heap size in peak until the returned
+// function is called. Short peaks between samples can be missed, but the
+// heap is also read when sampling stops.
+func sampleHeap(peak *uint64) (stop func()) {
+	var mu sync.Mutex
+	read := func() {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		mu.Lock()
+		*peak = max(*peak, stats.HeapAlloc)
+		mu.Unlock()
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(heapSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				read()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+		read()
+	}
+}
+
+// Min returns the fastest run
+func (r benchResult) Min() time.Duration {
+	return slices.Min(r.Durations)
+}
+
+// Max returns the slowest run
+func (r benchResult) Max() time.Duration {
+	return slices.Max(r.Durations)
+}
+
+// Avg returns the mean time of a run
+func (r benchResult) Avg() time.Duration {
+	var total time.Duration
+	for _, d := range r.Durations {
+		total += d
+	}
+	return total / time.Duration(len(r.Durations))
+}
+
+// String formats the result as the report printed by the bench command
+func (r benchResult) String() string {
+	avg := r.Avg()
+	perSecond := 0.0
+	if avg > 0 {
+		perSecond = float64(time.Second) / float64(avg)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Source:      %s\n", r.Source)
+	fmt.Fprintf(&b, "Extractor:   %s\n", r.Extractor)
+	fmt.Fprintf(&b, "Iterations:  %d\n", len(r.Durations))
+	fmt.Fprintf(&b, "Latency:     min %s, avg %s, max %s\n", roundDuration(r.Min()), roundDuration(avg), roundDuration(r.Max()))
+	fmt.Fprintf(&b, "Throughput:  %.2f extractions/s, %s/s\n", perSecond, metrics.FormatBytes(int64(float64(r.Bytes)*perSecond)))
+	fmt.Fprintf(&b, "Output:      %s per extraction\n", metrics.FormatBytes(int64(r.Bytes)))
+	fmt.Fprintf(&b, "Peak heap:   %s\n", metrics.FormatBytes(int64(r.PeakHeap)))
+	fmt.Fprintf(&b, "Allocated:   %s per extraction\n", metrics.FormatBytes(int64(r.Allocated/uint64(len(r.Durations)))))
+	return b.String()
+}
+
+// roundDuration rounds a duration to a precision that suits its size
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+// writeHeapProfile writes a heap profile of the memory still in use
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	runtime.GC() // report up-to-date statistics
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write heap profile: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().IntVarP(&benchIterations, "iterations", "n", 5, "Number of extractions to run")
+	benchCmd.Flags().StringVarP(&benchFormat, "format", "f", "markdown", "Output format each result is rendered in (text, markdown, json)")
+	benchCmd.Flags().StringVarP(&benchModel, "model", "m", "base", "Whisper model to use for YouTube sources (tiny, base, small, medium, large)")
+	benchCmd.Flags().StringSliceVar(&benchContentTags, "content-tags", webextractors.DefaultContentTags, "HTML elements whose text is extracted as content (web sources)")
+	benchCmd.Flags().StringSliceVar(&benchSkipTags, "skip-tags", webextractors.DefaultSkipTags, "HTML elements whose text is skipped (web sources)")
+	benchCmd.Flags().StringVar(&benchCPUProfile, "cpuprofile", "", "Write a CPU profile of the runs to this file")
+	benchCmd.Flags().StringVar(&benchMemProfile, "memprofile", "", "Write a heap profile after the runs to this file")
+	benchCmd.Flags().DurationVarP(&benchTimeout, "timeout", "t", 30*time.Minute, "Timeout for all runs together")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"maai.solutions/gengo/internal/output"
+)
+
+// fakeExtractor returns a fixed result, failing after a number of runs
+type fakeExtractor struct {
+	runs      int
+	failAfter int // 0 never fails
+}
+
+func (f *fakeExtractor) Name() string                 { return "fake" }
+func (f *fakeExtractor) CanHandle(source string) bool { return true }
+
+func (f *fakeExtractor) Extract(ctx context.Context, source string) (output.Result, error) {
+	f.runs++
+	if f.failAfter > 0 && f.runs > f.failAfter {
+		return output.Result{}, errors.New("gone")
+	}
+	time.Sleep(time.Millisecond)
+	return output.Result{Title: "Doc", Source: source, Content: strings.Repeat("word ", 100)}, nil
+}
+
+func TestBenchmark(t *testing.T) {
+	e := &fakeExtractor{}
+	result, err := benchmark(t.Context(), e, "doc.md", 3, output.FormatText)
+	if err != nil {
+		t.Fatalf("benchmark failed: %v", err)
+	}
+	if e.runs != 3 || len(result.Durations) != 3 {
+		t.Fatalf("Expected 3 runs, got %d extractions and %d durations", e.runs, len(result.Durations))
+	}
+	if result.Min() < time.Millisecond || result.Min() > result.Avg() || result.Avg() > result.Max() {
+		t.Errorf("Inconsistent latencies: min %s, avg %s, max %s", result.Min(), result.Avg(), result.Max())
+	}
+	if result.Bytes != 501 {
+		t.Errorf("Expected the 501 byte text rendering, with its final newline, to be measured, got %d", result.Bytes)
+	}
+	if result.PeakHeap == 0 || result.Allocated == 0 {
+		t.Errorf("Expected memory to be measured, got peak %d, allocated %d", result.PeakHeap, result.Allocated)
+	}
+
+	report := result.String()
+	for _, want := range []string{"Source:      doc.md", "Extractor:   fake", "Iterations:  3", "Latency:     min ", "extractions/s", "Output:      501 B per extraction"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected %q in report:\n%s", want, report)
+		}
+	}
+
+	// A failing run ends the benchmark
+	if _, err := benchmark(t.Context(), &fakeExtractor{failAfter: 1}, "doc.md", 3, output.FormatText); err == nil {
+		t.Error("Expected an error from the failing run")
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultBrowserHeight is how many entries the file browser lists when the
+// terminal size is not known yet
+const defaultBrowserHeight = 15
+
+// pdfBrowser lets "pdf browse" pick a PDF with a file picker instead of
+// typing its path, then runs pdf info or pdf extract on the selection.
+// Browsing starts in the current directory, or the one given with --from,
+// and cannot leave it.
+type pdfBrowser struct {
+	picker filepicker.Model
+	root   string   // absolute directory browsing is scoped to
+	action string   // "info" or "extract"
+	args   []string // extract options applied to the selection
+	notice string   // why the last selection was refused
+}
+
+// newPDFBrowser parses "pdf browse [info|extract] [--from dir] [options]",
+// listing height entries at a time
+func newPDFBrowser(args []string, height int) (*pdfBrowser, error) {
+	b := &pdfBrowser{action: "info"}
+	if len(args) > 0 && (args[0] == "info" || args[0] == "extract") {
+		b.action, args = args[0], args[1:]
+	}
+
+	dir := "."
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--from" {
+			b.args = append(b.args, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf("--from needs a directory")
+		}
+		dir = args[i+1]
+		i++
+	}
+	if b.action == "info" && len(b.args) > 0 {
+		return nil, fmt.Errorf("pdf info takes no options, got %s", strings.Join(b.args, " "))
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", dir)
+	}
+	b.root = root
+
+	if height <= 0 {
+		height = defaultBrowserHeight
+	}
+	b.picker = filepicker.New()
+	b.picker.CurrentDirectory = root
+	b.picker.AllowedTypes = []string{".pdf", ".PDF"}
+	b.picker.AutoHeight = false
+	b.picker.Height = height
+	// Esc cancels browsing rather than going up a directory
+	b.picker.KeyMap.Back = key.NewBinding(key.WithKeys("h", "backspace", "left"), key.WithHelp("←", "back"))
+	return b, nil
+}
+
+// init reads the starting directory
+func (b *pdfBrowser) init() tea.Cmd {
+	return b.picker.Init()
+}
+
+// update passes msg to the file picker and returns the path of the PDF
+// selected with it, or "" while the user is still browsing
+func (b *pdfBrowser) update(msg tea.Msg) (string, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		// Stay inside the directory browsing started in
+		if key.Matches(keyMsg, b.picker.KeyMap.Back) && filepath.Clean(b.picker.CurrentDirectory) == b.root {
+			return "", nil
+		}
+		b.notice = ""
+	}
+
+	var cmd tea.Cmd
+	b.picker, cmd = b.picker.Update(msg)
+	if ok, path := b.picker.DidSelectFile(msg); ok {
+		return path, nil
+	}
+	if ok, path := b.picker.DidSelectDisabledFile(msg); ok {
+		b.notice = fmt.Sprintf("%s is not a PDF", filepath.Base(path))
+	}
+	return "", cmd
+}
+
+// command returns the pdf subcommand and arguments to run on path, with
+// path relative to the working directory when it is inside it
+func (b *pdfBrowser) command(path string) []string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil && filepath.IsLocal(rel) {
+			path = rel
+		}
+	}
+	return append([]string{b.action, path}, b.args...)
+}
+
+func (b *pdfBrowser) view() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Select a PDF to %s (↑/↓ move, enter open, ← back, esc cancel)\n", b.action)
+	dir := b.picker.CurrentDirectory
+	if rel, err := filepath.Rel(b.root, dir); err == nil {
+		dir = filepath.Join(filepath.Base(b.root), rel)
+	}
+	s.WriteString(dir + string(filepath.Separator) + "\n\n")
+	s.WriteString(b.picker.View())
+	if b.notice != "" {
+		s.WriteString(b.notice + "\n")
+	}
+	return s.String()
+}
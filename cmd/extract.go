@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	docxextractors "maai.solutions/gengo/internal/extractors/docx"
+	epubextractors "maai.solutions/gengo/internal/extractors/epub"
+	mdextractors "maai.solutions/gengo/internal/extractors/markdown"
+	extractors "maai.solutions/gengo/internal/extractors/pdf"
+	"maai.solutions/gengo/internal/extractors/registry"
+	webextractors "maai.solutions/gengo/internal/extractors/web"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/metrics"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/ratelimit"
+)
+
+var (
+	srcOutputFile  string
+	srcOutputDir   string
+	srcProjectName string
+	srcFormat      string
+	srcModel       string
+	srcTimeout     time.Duration
+	srcVerbose     bool
+	srcForce       bool
+	srcTOC         bool
+	srcHeadingOff  int
+	srcLimit       int
+	srcLimitUnit   string
+	srcAutoTitle   bool
+	srcAutoProject bool
+	srcRate        float64
+	srcDelay       time.Duration
+	srcResume      bool
+	srcRetryFailed bool
+)
+
+// hostLimiter spaces out requests to the same host during batch extractions.
+// It is nil, and so unlimited, until a command sets it from its flags.
+var hostLimiter *ratelimit.HostLimiter
+
+// sourceKind identifies which extractor handles a given source. Its values
+// are the names the extractors register under.
+type sourceKind string
+
+const (
+	sourceUnknown  sourceKind = "unknown"
+	sourceYouTube  sourceKind = "youtube"
+	sourceWeb      sourceKind = "web"
+	sourcePDF      sourceKind = "pdf"
+	sourceDocx     sourceKind = "docx"
+	sourceEpub     sourceKind = "epub"
+	sourceMarkdown sourceKind = "markdown"
+)
+
+// String returns a human readable name for the source kind
+func (k sourceKind) String() string {
+	return string(k)
+}
+
+// folder names the project subfolder for the source kind when projects are
+// laid out by source
+func (k sourceKind) folder() string {
+	if k == sourceYouTube {
+		return output.SourceTranscript
+	}
+	return k.String()
+}
+
+// sourceExtractCmd represents the unified extract command
+var sourceExtractCmd = &cobra.Command{
+	Use:   "extract [source...]",
+	Short: "Extract content from any supported source",
+	Long: `Extract content from a source, automatically choosing the right extractor.
+
+The source type is detected from the argument:
+- YouTube URL          → audio transcription
+- Other http(s) URL    → web page extraction
+- .pdf file            → PDF text extraction
+- .docx file           → Word document extraction
+- .epub file           → EPUB book extraction
+- .md file             → Markdown document
+
+Examples:
+  gengo extract https://example.com/article               # Extract web page to stdout
+  gengo extract https://youtube.com/watch?v=abc123 -p talks # Transcribe into a project
+  gengo extract report.pdf --output report.md             # Extract PDF to file
+  gengo extract book.epub --dir ./library                 # Extract EPUB to directory
+  gengo extract report.docx --toc -o report.md            # Add a table of contents
+  gengo extract notes.docx --heading-offset 2             # Demote headings to embed them
+  gengo extract a.pdf b.pdf https://example.com -d out -f jsonl # Stream a status line per source
+
+Several sources can be given at once. With --format jsonl each source is
+reported on stdout as one JSON object (source, status, output, error) as soon
+as it finishes; results are saved as markdown when --dir or --project is set
+and embedded in the line otherwise.
+
+With --auto-project, each source is saved to a project named after its site
+or, for YouTube, its channel, unless --project names one. Local files keep
+their destination.
+
+With --auto-title, sources without a title and YouTube transcripts are named
+after the first heading or sentence of their content, so batches do not end
+up as a pile of Untitled.md files.
+
+Requests to the same host are limited to --rate per second, with at least
+--delay between them; sources on different hosts do not wait for each other.
+
+Batches saved with --project or --dir record the outcome of every source in
+batch.jsonl next to the results. If a run is interrupted, run the same
+command again with --resume to skip the sources already done, or with
+--retry-failed-only to retry just the ones that failed.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, err := parseExtractFormat(srcFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := checkHeadingOffset(srcHeadingOff); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		limitUnit, err := parseLimit(srcLimit, srcLimitUnit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(args) > 1 && srcOutputFile != "" {
+			fmt.Fprintln(os.Stderr, "Error: --output cannot be used with several sources; use --dir or --project")
+			os.Exit(1)
+		}
+
+		// In a JSON-lines stream saved files are markdown and stdout carries
+		// only the stream itself
+		stream := format == output.FormatJSONL
+		if stream {
+			format = output.FormatMarkdown
+		}
+
+		opts := output.OutputOptions{
+			OutputFile:  srcOutputFile,
+			OutputDir:   srcOutputDir,
+			ProjectName: srcProjectName,
+			Format:      format,
+			Force:       srcForce,
+			Extraction:  extractionOptions(srcTOC, srcHeadingOff, srcLimit, limitUnit),
+		}
+
+		if !stream {
+			for _, source := range args {
+				if detectSourceKind(source) == sourceUnknown {
+					fmt.Fprintf(os.Stderr, "Error: Unsupported source: %s\n", source)
+					fmt.Fprintln(os.Stderr, "Supported sources: YouTube URLs, http(s) URLs, .pdf, .docx, .epub and .md files")
+					os.Exit(1)
+				}
+			}
+		}
+
+		if dryRun {
+			for _, source := range args {
+				kind := detectSourceKind(source)
+				if kind != sourceYouTube && kind != sourceWeb {
+					if _, err := os.Stat(source); os.IsNotExist(err) {
+						fmt.Fprintf(os.Stderr, "Error: File does not exist: %s\n", source)
+						os.Exit(1)
+					}
+				}
+				plan := output.Plan{Action: fmt.Sprintf("extract %s source", kind), Source: source}
+				planOpts := withAutoProject(opts, srcAutoProject && kind != sourceYouTube, output.Result{Source: source})
+				if srcAutoProject && opts.ProjectName == "" && kind == sourceYouTube {
+					// The channel is only known once the video is fetched
+					plan.Details = append(plan.Details, "Project: named after the video's channel")
+				}
+				printPlan(plan, planOpts)
+			}
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), srcTimeout)
+		defer cancel()
+
+		batch, sources, err := startBatch(opts, args, srcResume, srcRetryFailed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		hostLimiter = ratelimit.New(srcRate, srcDelay)
+		failed := 0
+		if stream {
+			failed = streamSources(ctx, sources, opts, output.NewStreamWriter(os.Stdout), batch)
+		} else {
+			for _, source := range sources {
+				err := extractAndWrite(ctx, source, opts)
+				batch.record(source, err)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error %v\n", err)
+					failed++
+				}
+			}
+		}
+
+		// Summarize batches on stderr so stdout stays usable for results
+		if len(args) > 1 && !quiet {
+			fmt.Fprintf(os.Stderr, "\nSummary:\n%s", metrics.Default.Summary())
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// parseExtractFormat accepts the shared output formats plus jsonl, which
+// streams a status line per source
+func parseExtractFormat(name string) (output.Format, error) {
+	if strings.EqualFold(strings.TrimSpace(name), string(output.FormatJSONL)) {
+		return output.FormatJSONL, nil
+	}
+	return output.ParseFormat(name)
+}
+
+// extractAndWrite extracts one source and writes it to the selected destination
+func extractAndWrite(ctx context.Context, source string, opts output.OutputOptions) error {
+	kind := detectSourceKind(source)
+	if srcVerbose {
+		fmt.Fprintf(os.Stderr, "Detected %s source: %s\n", kind, source)
+	}
+
+	result, err := extractSource(ctx, source, kind)
+	if err != nil {
+		return fmt.Errorf("extracting %s source: %w", kind, err)
+	}
+	*result = withHeadingOffset(withAutoTitle(srcLimited(*result), srcAutoTitle, kind == sourceYouTube), opts.Format, srcHeadingOff)
+	*result = withTOC(*result, opts.Format, srcTOC)
+	opts.SourceType = kind.folder()
+	opts = withAutoProject(opts, srcAutoProject, *result)
+
+	if srcVerbose {
+		fmt.Fprintf(os.Stderr, "Title: %s\n", result.Title)
+		fmt.Fprintf(os.Stderr, "Content length: %d characters\n", len(result.Content))
+	}
+
+	paths, err := output.WriteAll(*result, opts)
+	for _, path := range paths {
+		statusf("✅ Content extracted and saved to: %s\n", path)
+	}
+	if err != nil {
+		if reportDuplicate(err) {
+			return nil
+		}
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
+
+// srcLimited applies --limit to an extracted source; the unit was validated
+// when the command started
+func srcLimited(result output.Result) output.Result {
+	unit, _ := parseLimit(srcLimit, srcLimitUnit)
+	return withLimit(result, srcLimit, unit)
+}
+
+// streamSources extracts each source and reports it as a JSON line as soon as
+// it completes. Results go to files when a destination is set and are
+// otherwise embedded in the line, and recorded in batch when it is not nil.
+// It returns the number of failed sources.
+func streamSources(ctx context.Context, sources []string, opts output.OutputOptions, stream *output.StreamWriter, batch *batchState) int {
+	failed := 0
+	for _, source := range sources {
+		item := streamItem(ctx, source, opts)
+		if item.Status == output.StatusError {
+			failed++
+			batch.record(source, errors.New(item.Error))
+		} else {
+			batch.record(source, nil)
+		}
+		if err := stream.Write(item); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return failed + 1
+		}
+	}
+	return failed
+}
+
+// streamItem extracts and saves one source, describing the outcome as an Item
+func streamItem(ctx context.Context, source string, opts output.OutputOptions) output.Item {
+	item := output.Item{Source: source, Status: output.StatusOK}
+
+	kind := detectSourceKind(source)
+	if kind == sourceUnknown {
+		item.Status = output.StatusError
+		item.Error = "unsupported source"
+		return item
+	}
+
+	result, err := extractSource(ctx, source, kind)
+	if err != nil {
+		item.Status = output.StatusError
+		item.Error = err.Error()
+		return item
+	}
+	*result = withHeadingOffset(withAutoTitle(srcLimited(*result), srcAutoTitle, kind == sourceYouTube), opts.Format, srcHeadingOff)
+	*result = withTOC(*result, opts.Format, srcTOC)
+	opts.SourceType = kind.folder()
+	opts = withAutoProject(opts, srcAutoProject, *result)
+
+	if len(output.Destinations(*result, opts)) == 0 {
+		item.Title = result.Title
+		item.Content = result.Content
+		return item
+	}
+
+	var dup *output.DuplicateError
+	paths, err := output.WriteAll(*result, opts)
+	switch {
+	case errors.As(err, &dup):
+		item.Status = output.StatusSkipped
+		item.Output = dup.Path
+	case err != nil:
+		item.Status = output.StatusError
+		item.Error = err.Error()
+	default:
+		item.Output = paths[0]
+	}
+	// With several destinations, list every file written
+	if len(paths) > 1 || (len(paths) > 0 && item.Output != paths[0]) {
+		item.Outputs = paths
+	}
+	return item
+}
+
+// sourceExtractors returns the registry of extractors behind the unified
+// extract command, configured from the current flags. YouTube goes before the
+// web extractor, which accepts any URL.
+func sourceExtractors() *registry.Registry {
+	return registry.New(
+		&ytaudio.Extractor{Model: srcModel},
+		&webextractors.PageExtractor{},
+		extractors.NewTextExtractor(),
+		registry.FileExtractor(string(sourceDocx), docxextractors.ExtractFromFile, ".docx"),
+		registry.FileExtractor(string(sourceEpub), epubextractors.ExtractFromFile, ".epub"),
+		registry.FileExtractor(string(sourceMarkdown), mdextractors.ExtractFromFile, ".md", ".markdown"),
+	)
+}
+
+// sourceKinds is the registry detectSourceKind looks sources up in. Lookups
+// only inspect the source string, never the extractor options, so it is
+// built once.
+var sourceKinds = sync.OnceValue(sourceExtractors)
+
+// detectSourceKind inspects a source argument and returns the extractor that handles it
+func detectSourceKind(source string) sourceKind {
+	e := sourceKinds().Lookup(strings.TrimSpace(source))
+	if e == nil {
+		return sourceUnknown
+	}
+	return sourceKind(e.Name())
+}
+
+// extractSource dispatches a source to its extractor and normalizes the result.
+// Every extraction is recorded in the metrics under its source kind. Remote
+// sources first wait for the host limiter, which is not counted as extraction
+// time.
+func extractSource(ctx context.Context, source string, kind sourceKind) (*output.Result, error) {
+	e := sourceExtractors().Get(string(kind))
+	if e == nil {
+		return nil, fmt.Errorf("unsupported source: %s", source)
+	}
+	return extractWith(ctx, e, source, kind)
+}
+
+// extractWith is extractSource with the extractor chosen by the caller
+func extractWith(ctx context.Context, e registry.Extractor, source string, kind sourceKind) (result *output.Result, err error) {
+	if isValidURL(source) {
+		if err := hostLimiter.Wait(ctx, source); err != nil {
+			return nil, fmt.Errorf("waiting for rate limit: %w", err)
+		}
+	}
+
+	start := time.Now()
+	defer func() {
+		size := 0
+		if result != nil {
+			size = len(result.Content)
+		}
+		metrics.Observe(kind.String(), size, time.Since(start), err)
+	}()
+
+	extracted, err := e.Extract(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if partial := extracted.Metadata["Partial"]; partial != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s, the transcript is incomplete\n", source, partial)
+	}
+	return &extracted, nil
+}
+
+func init() {
+	// Add extract command to root
+	rootCmd.AddCommand(sourceExtractCmd)
+
+	// Add flags to extract command
+	sourceExtractCmd.Flags().StringVarP(&srcOutputFile, "output", "o", "", "Output file path (default: stdout)")
+	sourceExtractCmd.Flags().StringVarP(&srcOutputDir, "dir", "d", "", "Output directory path")
+	sourceExtractCmd.Flags().StringVarP(&srcProjectName, "project", "p", "", "Project name (creates project folder structure)")
+	sourceExtractCmd.Flags().BoolVar(&srcAutoProject, "auto-project", false, "Save each source to a project named after its site or YouTube channel when --project is not given")
+	sourceExtractCmd.Flags().StringVarP(&srcFormat, "format", "f", "markdown", "Output format (text, markdown, json, jsonl)")
+	sourceExtractCmd.Flags().StringVarP(&srcModel, "model", "m", "base", "Whisper model to use for YouTube sources (tiny, base, small, medium, large)")
+	sourceExtractCmd.Flags().DurationVarP(&srcTimeout, "timeout", "t", 30*time.Minute, "Timeout for the entire operation")
+	sourceExtractCmd.Flags().BoolVar(&srcForce, "force", false, "Save to the project even if identical content is already saved")
+	sourceExtractCmd.Flags().Float64Var(&srcRate, "rate", ratelimit.DefaultRate, "Maximum requests per second to one host (0 for no limit)")
+	sourceExtractCmd.Flags().DurationVar(&srcDelay, "delay", 0, "Minimum delay between requests to one host")
+	sourceExtractCmd.Flags().BoolVar(&srcResume, "resume", false, "Skip the sources a previous run into the same --project or --dir completed")
+	sourceExtractCmd.Flags().BoolVar(&srcRetryFailed, "retry-failed-only", false, "Extract only the sources a previous run into the same --project or --dir failed on")
+	sourceExtractCmd.Flags().BoolVar(&srcAutoTitle, "auto-title", false, "Derive titles from the content for untitled sources and transcripts")
+	sourceExtractCmd.Flags().BoolVar(&srcTOC, "toc", false, "Insert a linked table of contents (markdown output)")
+	sourceExtractCmd.Flags().IntVar(&srcHeadingOff, "heading-offset", 0, "Demote every heading by N levels, clamped at h6 (markdown output)")
+	sourceExtractCmd.Flags().IntVar(&srcLimit, "limit", 0, "Keep only the first N words or characters of the content, ending at a sentence (0 for all)")
+	sourceExtractCmd.Flags().StringVar(&srcLimitUnit, "limit-unit", "words", "What --limit counts (words, chars)")
+	sourceExtractCmd.Flags().BoolVarP(&srcVerbose, "verbose", "v", false, "Verbose output")
+}
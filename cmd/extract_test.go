@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"maai.solutions/gengo/internal/output"
+)
+
+func TestDetectSourceKind(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected sourceKind
+	}{
+		{"https://youtube.com/watch?v=dQw4w9WgXcQ", sourceYouTube},
+		{"https://youtu.be/dQw4w9WgXcQ", sourceYouTube},
+		{"https://example.com/article", sourceWeb},
+		{"http://example.com/file.pdf", sourceWeb},
+		{"document.pdf", sourcePDF},
+		{"./docs/Report.PDF", sourcePDF},
+		{"letter.docx", sourceDocx},
+		{"book.epub", sourceEpub},
+		{"README.md", sourceMarkdown},
+		{"notes.markdown", sourceMarkdown},
+		{"archive.zip", sourceUnknown},
+		{"", sourceUnknown},
+	}
+
+	for _, test := range tests {
+		result := detectSourceKind(test.source)
+		if result != test.expected {
+			t.Errorf("detectSourceKind(%q) = %v, expected %v", test.source, result, test.expected)
+		}
+	}
+}
+
+func TestExtractSourceMissingFile(t *testing.T) {
+	_, err := extractSource(context.Background(), "does-not-exist.pdf", sourcePDF)
+	if err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestStreamSources(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(doc, []byte("# Notes\n\nHello"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	var buf bytes.Buffer
+	opts := output.OutputOptions{OutputDir: filepath.Join(dir, "out"), Format: output.FormatMarkdown}
+	sources := []string{doc, filepath.Join(dir, "missing.pdf"), "archive.zip"}
+	if failed := streamSources(context.Background(), sources, opts, output.NewStreamWriter(&buf), nil); failed != 2 {
+		t.Errorf("Expected 2 failed sources, got %d", failed)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(sources) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(sources), buf.String())
+	}
+
+	var items []output.Item
+	for _, line := range lines {
+		var item output.Item
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		items = append(items, item)
+	}
+
+	if items[0].Status != output.StatusOK || items[0].Output == "" {
+		t.Errorf("Expected first source to be saved, got %+v", items[0])
+	}
+	if _, err := os.Stat(items[0].Output); err != nil {
+		t.Errorf("Expected output file to exist: %v", err)
+	}
+	for _, item := range items[1:] {
+		if item.Status != output.StatusError || item.Error == "" {
+			t.Errorf("Expected an error item, got %+v", item)
+		}
+	}
+}
+
+func TestStreamItemStdout(t *testing.T) {
+	doc := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(doc, []byte("# Notes\n\nHello"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	item := streamItem(context.Background(), doc, output.OutputOptions{Format: output.FormatMarkdown})
+	if item.Status != output.StatusOK || item.Output != "" || !strings.Contains(item.Content, "Hello") {
+		t.Errorf("Expected content to be embedded in the item, got %+v", item)
+	}
+}
+
+func TestExtractSourcePDF(t *testing.T) {
+	result, err := extractSource(context.Background(), "../internal/extractors/pdf/testdata/text.pdf", sourcePDF)
+	if err != nil {
+		t.Fatalf("extractSource failed: %v", err)
+	}
+	if !strings.Contains(result.Content, "Call 555-12-3456") {
+		t.Errorf("Expected the text of the PDF, got %q", result.Content)
+	}
+}
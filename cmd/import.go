@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maai.solutions/gengo/internal/bookmarks"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/ratelimit"
+)
+
+var (
+	importProjectName string
+	importForce       bool
+	importRate        float64
+	importDelay       time.Duration
+	importTimeout     time.Duration
+	importVerbose     bool
+	importResume      bool
+	importRetryFailed bool
+)
+
+// webImportCmd represents the web import subcommand
+var webImportCmd = &cobra.Command{
+	Use:   "import [bookmarks.html]",
+	Short: "Extract every page in an exported bookmarks file into a project",
+	Long: `Read a bookmarks file exported from a browser (the Netscape bookmark
+format used by Chrome, Firefox, Safari and Edge) and extract every bookmarked
+page into a project, like "gengo extract --project" does.
+
+Folders are flattened, bookmarks that are not http(s) URLs (bookmarklets,
+place: queries) are skipped and duplicate URLs are extracted once. YouTube
+links are transcribed. Requests to the same host are limited to --rate per
+second, with at least --delay between them.
+
+The project is named after the file unless --project is given. The outcome
+of every link is recorded in the project, so an interrupted import continues
+where it stopped with --resume, and --retry-failed-only retries the failed
+links.
+
+Examples:
+  gengo web import bookmarks.html
+  gengo web import bookmarks.html --project reading-list --delay 2s
+  gengo web import bookmarks.html --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runImport(cmd.Context(), args[0], bookmarks.ParseNetscape)
+	},
+}
+
+// feedCmd represents the feed command
+var feedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Work with feed subscriptions",
+	Long: `Work with the feed subscriptions of RSS and Atom readers.
+
+Examples:
+  gengo feed import subscriptions.opml                   # Extract every subscribed site
+  gengo feed import subscriptions.opml --project blogs   # Choose the project`,
+}
+
+// feedImportCmd represents the feed import subcommand
+var feedImportCmd = &cobra.Command{
+	Use:   "import [subscriptions.opml]",
+	Short: "Extract the website of every feed in an OPML file into a project",
+	Long: `Read feed subscriptions exported from a feed reader as OPML and extract
+the website of every feed into a project, like "gengo extract --project" does.
+
+Each feed contributes the page given by its htmlUrl. Feeds that only list the
+feed address are skipped, as are folders and duplicate URLs. Requests to the
+same host are limited to --rate per second, with at least --delay between
+them.
+
+The project is named after the file unless --project is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runImport(cmd.Context(), args[0], bookmarks.ParseOPML)
+	},
+}
+
+// runImport parses a reading list with parse and extracts its links into a
+// project, exiting non-zero when the file cannot be read or a link fails
+func runImport(ctx context.Context, path string, parse func(io.Reader) (*bookmarks.List, error)) {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	list, err := parse(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	projectName := importProjectName
+	if projectName == "" {
+		projectName = output.SanitizeFilename(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	}
+
+	statusf("Found %s\n", describeList(list))
+	if importVerbose {
+		for _, skipped := range list.Skipped {
+			fmt.Printf("Skipped: %s\n", skipped)
+		}
+	}
+	if len(list.Links) == 0 {
+		return
+	}
+
+	opts := output.OutputOptions{
+		ProjectName: projectName,
+		Format:      output.FormatMarkdown,
+		Force:       importForce,
+	}
+
+	if dryRun {
+		for _, link := range list.Links {
+			printPlan(output.Plan{Action: fmt.Sprintf("extract %s source", detectSourceKind(link.URL)), Source: link.URL}, opts)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, importTimeout)
+	defer cancel()
+
+	urls := make([]string, 0, len(list.Links))
+	for _, link := range list.Links {
+		urls = append(urls, link.URL)
+	}
+	batch, urls, err := startBatch(opts, urls, importResume, importRetryFailed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	hostLimiter = ratelimit.New(importRate, importDelay)
+	failed := 0
+	for i, url := range urls {
+		if importVerbose {
+			fmt.Printf("[%d/%d] %s\n", i+1, len(urls), url)
+		}
+		err := extractAndWrite(ctx, url, opts)
+		batch.record(url, err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error %s: %v\n", url, err)
+			failed++
+		}
+	}
+
+	statusf("Imported %d of %d links into project %s\n", len(urls)-failed, len(urls), projectName)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// describeList summarizes what an imported reading list contains
+func describeList(list *bookmarks.List) string {
+	return fmt.Sprintf("%d links (%d folders, %d duplicates, %d skipped entries)",
+		len(list.Links), list.Folders, list.Duplicates, len(list.Skipped))
+}
+
+func init() {
+	rootCmd.AddCommand(feedCmd)
+	feedCmd.AddCommand(feedImportCmd)
+	webCmd.AddCommand(webImportCmd)
+
+	for _, cmd := range []*cobra.Command{webImportCmd, feedImportCmd} {
+		cmd.Flags().StringVarP(&importProjectName, "project", "p", "", "Project name (default: the file name)")
+		cmd.Flags().BoolVar(&importForce, "force", false, "Save to the project even if identical content is already saved")
+		cmd.Flags().Float64Var(&importRate, "rate", ratelimit.DefaultRate, "Maximum requests per second to one host (0 for no limit)")
+		cmd.Flags().DurationVar(&importDelay, "delay", 0, "Minimum delay between requests to one host")
+		cmd.Flags().DurationVarP(&importTimeout, "timeout", "t", 2*time.Hour, "Timeout for the entire import")
+		cmd.Flags().BoolVar(&importResume, "resume", false, "Skip the links a previous import into the project completed")
+		cmd.Flags().BoolVar(&importRetryFailed, "retry-failed-only", false, "Import only the links a previous import into the project failed on")
+		cmd.Flags().BoolVarP(&importVerbose, "verbose", "v", false, "Verbose output")
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,19 +15,26 @@ import (
 	extractors "maai.solutions/gengo/internal/extractors/pdf"
 	webextractors "maai.solutions/gengo/internal/extractors/web"
 	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/output"
 )
 
 type model struct {
-	input   string
-	cursor  int
-	history []string
+	input    string
+	cursor   int
+	history  []string
+	settings *sessionSettings // shared by copies of the model, saved on exit
+	pager    *pager           // content of the last extraction preview
+	browser  *pdfBrowser      // file picker shown by "pdf browse", nil otherwise
+	height   int              // terminal height, sizing the file picker
 }
 
 func initialModel() model {
 	return model{
-		input:   "",
-		cursor:  0,
-		history: []string{},
+		input:    "",
+		cursor:   0,
+		history:  []string{},
+		settings: loadSessionSettings(),
+		pager:    &pager{},
 	}
 }
 
@@ -35,6 +43,13 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.height = size.Height
+	}
+	if m.browser != nil {
+		return m.updateBrowser(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -49,6 +64,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if command == "/exit" || command == "/quit" {
 					return m, tea.Quit
 				}
+				if parts := strings.Fields(command); len(parts) >= 2 && parts[0] == "pdf" && parts[1] == "browse" {
+					return m.startBrowser(parts[2:])
+				}
 
 				response := m.handleCommand(command)
 				if response != "" {
@@ -81,6 +99,53 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// browserChrome is the number of terminal lines around the file picker:
+// the banner, the picker's title and directory, and a notice
+const browserChrome = 8
+
+// startBrowser opens the file picker of "pdf browse"
+func (m model) startBrowser(args []string) (tea.Model, tea.Cmd) {
+	m.input = ""
+	m.cursor = 0
+	m.pager.reset()
+
+	browser, err := newPDFBrowser(args, m.height-browserChrome)
+	if err != nil {
+		m.history = append(m.history, fmt.Sprintf("Error: %v\nUsage: %s", err, pdfBrowseUsage))
+		return m, nil
+	}
+	m.browser = browser
+	return m, browser.init()
+}
+
+// updateBrowser passes a message to the open file picker and runs the
+// browsed command once a PDF is selected
+func (m model) updateBrowser(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q":
+			m.browser = nil
+			m.history = append(m.history, "Browsing cancelled")
+			return m, nil
+		}
+	}
+
+	path, cmd := m.browser.update(msg)
+	if path == "" {
+		return m, cmd
+	}
+
+	args := m.browser.command(path)
+	m.browser = nil
+	m.history = append(m.history, "> pdf "+strings.Join(args, " "))
+	if response := m.handlePdfCommand(args); response != "" {
+		m.history = append(m.history, response)
+	}
+	return m, nil
+}
+
 func (m model) View() string {
 	var s strings.Builder
 
@@ -88,6 +153,11 @@ func (m model) View() string {
 	s.WriteString("Type '/help' for commands, '/exit' to quit or 'Ctrl+C'\n")
 	s.WriteString(strings.Repeat("─", 50) + "\n\n")
 
+	if m.browser != nil {
+		s.WriteString(m.browser.view())
+		return s.String()
+	}
+
 	// Show command history
 	for _, line := range m.history {
 		s.WriteString(line + "\n")
@@ -97,8 +167,10 @@ func (m model) View() string {
 	if len(m.history) == 0 {
 		s.WriteString("Welcome! Try these commands:\n")
 		s.WriteString("  /help                           - Show all commands\n")
+		s.WriteString("  /set model small                - Change a setting, /show lists them\n")
 		s.WriteString("  ytaudio check                   - Check YouTube transcription setup\n")
 		s.WriteString("  pdf info <file.pdf>             - Get PDF information\n")
+		s.WriteString("  pdf browse                      - Pick a PDF from the current directory\n")
 		s.WriteString("  web extract <url>               - Extract web page content\n\n")
 	}
 
@@ -125,9 +197,20 @@ func (m model) handleCommand(command string) string {
 	cmd := parts[0]
 	args := parts[1:]
 
+	// Paging continues only until the next command
+	if cmd != "more" {
+		m.pager.reset()
+	}
+
 	switch cmd {
+	case "more":
+		return m.pager.next()
 	case "/help":
 		return m.getHelpText()
+	case "/set":
+		return m.handleSet(args)
+	case "/show":
+		return m.settings.show()
 	case "ytaudio":
 		return m.handleYtAudioCommand(args)
 	case "pdf":
@@ -144,20 +227,60 @@ func (m model) getHelpText() string {
 	return `Available commands:
   /help                                    - Show this help
   /exit, /quit                            - Exit the interactive mode
+  /set <key> [value]                      - Change a setting, or reset it without a value
+  /show                                   - Show the current settings
+  more                                    - Show the next page of the last extraction
   
-  ytaudio transcribe <youtube-url>        - Transcribe YouTube video
+  ytaudio transcribe <youtube-url> [--model name] - Transcribe YouTube video
   ytaudio check                           - Check ytaudio dependencies
   
-  pdf extract <file.pdf>                  - Extract text from PDF
+  pdf extract <file.pdf> [--page N]       - Extract text from PDF
   pdf info <file.pdf>                     - Get PDF information
+  pdf browse [info|extract] [--from dir]  - Pick a PDF with a file browser, then get its
+                                            information or extract it with the extract options
   
-  web extract <url>                       - Extract content from web page
+  web extract <url> [--page N]            - Extract content from web page
   
 Examples:
   ytaudio transcribe https://youtube.com/watch?v=abc123
+  ytaudio transcribe https://youtube.com/watch?v=abc123 --model small
   pdf extract document.pdf
   web extract https://example.com/article
-  pdf info document.pdf`
+  pdf info document.pdf
+  pdf browse extract --clean
+  /set model small
+  /set output ./out
+
+Settings:
+` + sessionSettingsHelp() + `
+
+Settings are saved to the config file on exit.`
+}
+
+// sessionSettingsHelp lists the settings accepted by /set for the help text
+func sessionSettingsHelp() string {
+	lines := make([]string, 0, len(sessionSettingList))
+	for _, setting := range sessionSettingList {
+		lines = append(lines, fmt.Sprintf("  %-9s - %s", setting.key, setting.usage))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleSet changes a session setting used by later commands
+func (m model) handleSet(args []string) string {
+	if len(args) == 0 {
+		return "Usage: /set <key> [value]\nSettings: " + strings.Join(sessionKeys(), ", ")
+	}
+
+	key := args[0]
+	value := strings.Join(args[1:], " ")
+	if err := m.settings.set(key, value); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if value == "" {
+		return fmt.Sprintf("✅ %s reset", key)
+	}
+	return fmt.Sprintf("✅ %s set to %s", key, m.settings.get(key))
 }
 
 // handleYtAudioCommand processes ytaudio subcommands
@@ -182,85 +305,109 @@ func (m model) handleYtAudioCommand(args []string) string {
 // handleYtAudioTranscribe handles YouTube transcription
 func (m model) handleYtAudioTranscribe(args []string) string {
 	if len(args) == 0 {
-		return "Usage: ytaudio transcribe <youtube-url>"
+		return "Usage: ytaudio transcribe <youtube-url> [--model base]"
 	}
 
 	videoURL := args[0]
 
 	// Validate YouTube URL (basic check)
-	if !isValidYouTubeURL(videoURL) {
+	if !ytaudio.IsVideoURL(videoURL) {
 		return fmt.Sprintf("Error: Invalid YouTube URL: %s\nPlease provide a valid YouTube URL", videoURL)
 	}
 
+	// Parse additional arguments; --model overrides the model setting
+	modelName := m.settings.get("model")
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--model", "-m":
+			if i+1 < len(args) {
+				modelName = args[i+1]
+				i++
+			}
+		}
+	}
+
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	// Configure ASR with default settings
+	// Configure ASR from the arguments and session settings
 	asrConfig := asr.DefaultConfig()
+	if modelName != "" {
+		modelPath := ytaudio.FindWhisperModel(modelName)
+		if modelPath == "" {
+			return fmt.Sprintf("Error: %v\nAvailable models: %s", asr.ModelNotFound(modelName), strings.Join(ytaudio.WhisperModels, ", "))
+		}
+		asrConfig.WhisperModel = modelPath
+	}
+	asrConfig.Language = m.settings.get("language")
 
 	// Configure YouTube transcription service
-	outputDir := "./transcripts"
+	outputDir := m.settings.get("output")
+	if outputDir == "" {
+		outputDir = "./transcripts"
+	}
 	config := &ytaudio.Config{
 		OutputDir:    outputDir,
 		ASRConfig:    asrConfig,
 		CleanupFiles: false, // Keep files by default in interactive mode
+		Retries:      ytaudio.DefaultConfig().Retries,
 	}
 
 	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Sprintf("Error creating output directory: %v", err)
+	if err := output.EnsureDir(outputDir); err != nil {
+		return fmt.Sprintf("Error: %v", err)
 	}
 
 	// Create service and transcribe
 	service := ytaudio.NewService(config)
 	result, err := service.TranscribeYouTubeVideo(ctx, videoURL)
 	if err != nil {
+		if hint := videoErrorHint(err); hint != "" {
+			return fmt.Sprintf("Cannot transcribe: %v\n%s", err, hint)
+		}
 		return fmt.Sprintf("Error transcribing video: %v", err)
 	}
 
 	// Generate filename and save transcript
-	filename := generateTranscriptFilename(videoURL)
-	transcriptPath := filepath.Join(outputDir, filename)
-
-	// Create markdown content with metadata
-	content := formatTranscriptMarkdown(videoURL, result)
+	transcript := ytaudio.TranscriptResult(videoURL, result)
+	opts := output.OutputOptions{
+		OutputDir: outputDir,
+		Filename:  transcriptFilename(videoURL),
+		Format:    output.FormatMarkdown,
+	}
 
-	if err := os.WriteFile(transcriptPath, []byte(content), 0644); err != nil {
+	transcriptPath, err := output.Write(transcript, opts)
+	if err != nil {
 		return fmt.Sprintf("Error saving transcript: %v", err)
 	}
 
+	if result.Partial {
+		return fmt.Sprintf("⚠️ Transcription timed out after %s of audio; the partial transcript was kept.\nSaved to: %s\nDuration: %.2f seconds",
+			ytaudio.TranscribedUntil(result), transcriptPath, result.Duration.Seconds())
+	}
 	return fmt.Sprintf("✅ Transcription completed!\nSaved to: %s\nDuration: %.2f seconds",
 		transcriptPath, result.Duration.Seconds())
 }
 
 // handleYtAudioCheck checks ytaudio dependencies
 func (m model) handleYtAudioCheck() string {
-	result := `YouTube Audio Transcription Dependencies:
-
-Required tools:
-- yt-dlp or youtube-dl (for downloading)
-- whisper or whisper.cpp (for transcription)
-
-Installation instructions:
-- Install yt-dlp: pip install yt-dlp
-- Install whisper: pip install openai-whisper
-- Or install whisper.cpp: https://github.com/ggerganov/whisper.cpp
-
-Please install whisper models:
-  pip install openai-whisper
-  whisper --model base "test"  # This downloads the base model
-  
-For whisper.cpp:
-  Download models from https://huggingface.co/ggerganov/whisper.cpp`
+	report := ytaudio.CheckDependencyReport(context.Background())
 
+	result := "YouTube Audio Transcription Dependencies:\n\n" + formatDependencyReport(report)
+	if !report.OK() {
+		result += "\nInstall ffmpeg from https://ffmpeg.org/download.html and download a model from https://huggingface.co/ggerganov/whisper.cpp"
+	}
 	return result
 }
 
 // handlePdfCommand processes pdf subcommands
+// pdfBrowseUsage describes the pdf browse command
+const pdfBrowseUsage = "pdf browse [info|extract] [--from dir] [extract options]"
+
 func (m model) handlePdfCommand(args []string) string {
 	if len(args) == 0 {
-		return "Usage: pdf [extract|info] <file.pdf> [options]"
+		return "Usage: pdf [extract|info] <file.pdf> [options], or " + pdfBrowseUsage
 	}
 
 	subCmd := args[0]
@@ -271,18 +418,25 @@ func (m model) handlePdfCommand(args []string) string {
 		return m.handlePdfExtract(subArgs)
 	case "info":
 		return m.handlePdfInfo(subArgs)
+	case "browse":
+		// Opened by Update, which owns the terminal the picker draws in
+		return "Usage: " + pdfBrowseUsage
 	default:
-		return fmt.Sprintf("Unknown pdf subcommand: %s\nAvailable: extract, info", subCmd)
+		return fmt.Sprintf("Unknown pdf subcommand: %s\nAvailable: extract, info, browse", subCmd)
 	}
 }
 
 // handlePdfExtract handles PDF text extraction
 func (m model) handlePdfExtract(args []string) string {
 	if len(args) == 0 {
-		return "Usage: pdf extract <file.pdf> [--pages 1,2,3] [--output output.txt] [--clean]"
+		return "Usage: pdf extract <file.pdf> [--pages 1,2,3] [--output output.txt] [--clean] [--page N]"
 	}
 
 	pdfFile := args[0]
+	page, err := parsePageArg(args[1:])
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
 
 	// Check if file exists
 	if _, err := os.Stat(pdfFile); os.IsNotExist(err) {
@@ -321,7 +475,6 @@ func (m model) handlePdfExtract(args []string) string {
 	extractor := extractors.NewTextExtractor()
 
 	var text string
-	var err error
 
 	// Extract text
 	if len(pages) > 0 {
@@ -341,20 +494,23 @@ func (m model) handlePdfExtract(args []string) string {
 		text = extractor.CleanText(text)
 	}
 
-	// Output text
-	if outputFile != "" {
-		err = os.WriteFile(outputFile, []byte(text), 0644)
+	// Output text, saving it when a file or the output/project settings say so
+	result := output.NewResult(output.SourcePDF, pdfFile, strings.TrimSuffix(filepath.Base(pdfFile), filepath.Ext(pdfFile)), text)
+	opts := m.settings.outputOptions(outputFile, "", output.FormatText)
+	opts.SourceType = output.SourcePDF
+	if output.Destination(result, opts) != "" {
+		path, err := output.Write(result, opts)
 		if err != nil {
-			return fmt.Sprintf("Error writing to file %s: %v", outputFile, err)
+			var dup *output.DuplicateError
+			if errors.As(err, &dup) {
+				return fmt.Sprintf("⏭️  Skipped duplicate: identical content is already saved as %s", dup.File)
+			}
+			return fmt.Sprintf("Error writing output: %v", err)
 		}
-		return fmt.Sprintf("✅ Text extracted and saved to: %s", outputFile)
+		return fmt.Sprintf("✅ Text extracted and saved to: %s", path)
 	} else {
-		// For interactive mode, show first 500 characters
-		if len(text) > 500 {
-			return fmt.Sprintf("✅ Text extracted (showing first 500 chars):\n\n%s...\n\n[Total length: %d characters]",
-				text[:500], len(text))
-		}
-		return fmt.Sprintf("✅ Text extracted:\n\n%s", text)
+		// Keep the full text and show it a page at a time
+		return m.pager.load("✅ Text extracted", fmt.Sprintf("[Total length: %d characters]", len(text)), text, pdfPageSize, page)
 	}
 }
 
@@ -405,10 +561,14 @@ func (m model) handleWebCommand(args []string) string {
 // handleWebExtract handles web page content extraction
 func (m model) handleWebExtract(args []string) string {
 	if len(args) == 0 {
-		return "Usage: web extract <url> [--output output.md] [--project project-name]"
+		return "Usage: web extract <url> [--output output.md] [--project project-name] [--page N]"
 	}
 
 	url := args[0]
+	page, err := parsePageArg(args[1:])
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
 
 	// Validate URL (basic check)
 	if !isValidURL(url) {
@@ -435,36 +595,41 @@ func (m model) handleWebExtract(args []string) string {
 	}
 
 	// Extract content from web page
-	title, content, err := webextractors.DownloadAndExtract(url)
+	title, content, err := webextractors.DownloadContent(url)
 	if err != nil {
 		return fmt.Sprintf("Error extracting content: %v", err)
 	}
 
+	result := output.NewResult(output.SourceWeb, url, title, content)
+	opts := m.settings.outputOptions(outputFile, projectName, output.FormatMarkdown)
+	opts.SourceType = output.SourceWeb
+
 	// Handle output based on specified options
-	if projectName != "" {
+	if opts.ProjectName != "" {
 		// Save to project structure
-		err := webextractors.SaveToProject(title, content, projectName)
+		path, err := output.Write(result, opts)
 		if err != nil {
+			var dup *output.DuplicateError
+			if errors.As(err, &dup) {
+				return fmt.Sprintf("⏭️  Skipped duplicate: identical content is already saved as %s", dup.File)
+			}
 			return fmt.Sprintf("Error saving to project: %v", err)
 		}
 
-		projectPath := filepath.Join(".", projectName, fmt.Sprintf("%s.md", title))
-		return fmt.Sprintf("✅ Content extracted and saved to project!\nFile: %s\nTitle: %s", projectPath, title)
+		return fmt.Sprintf("✅ Content extracted and saved to project!\nFile: %s\nTitle: %s", path, title)
 
-	} else if outputFile != "" {
-		// Save to specific file
-		err := os.WriteFile(outputFile, []byte(content), 0644)
+	} else if path := output.Destination(result, opts); path != "" {
+		// Save to a specific file or the output directory setting
+		written, err := output.Write(result, opts)
 		if err != nil {
-			return fmt.Sprintf("Error writing to file %s: %v", outputFile, err)
+			return fmt.Sprintf("Error writing to %s: %v", path, err)
 		}
-		return fmt.Sprintf("✅ Content extracted and saved to: %s\nTitle: %s", outputFile, title)
+		return fmt.Sprintf("✅ Content extracted and saved to: %s\nTitle: %s", written, title)
 
 	} else {
-		// For interactive mode, show first 800 characters
-		if len(content) > 800 {
-			return fmt.Sprintf("✅ Content extracted from: %s\nTitle: %s\n\n(Showing first 800 chars):\n\n%s...\n\n[Total length: %d characters]\n\nTip: Use --output or --project to save the full content",
-				url, title, content[:800], len(content))
-		}
-		return fmt.Sprintf("✅ Content extracted from: %s\nTitle: %s\n\n%s", url, title, content)
+		// Keep the full content and show it a page at a time
+		header := fmt.Sprintf("✅ Content extracted from: %s\nTitle: %s\n\nContent", url, title)
+		footer := fmt.Sprintf("[Total length: %d characters]\n\nTip: Use --output or --project to save the full content", len(content))
+		return m.pager.load(header, footer, content, webPageSize, page)
 	}
 }
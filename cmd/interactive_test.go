@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInteractiveTranscribeMissingModel(t *testing.T) {
+	m := model{settings: &sessionSettings{values: map[string]string{}}}
+
+	got := m.handleYtAudioTranscribe([]string{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "--model", "no-such-model"})
+	if !strings.Contains(got, `whisper model "no-such-model" not found`) || !strings.Contains(got, "ggml-no-such-model.bin") {
+		t.Errorf("Expected a missing model message with where to get it, got %q", got)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	pages := paginate("one two three four five", 9)
+	want := []string{"one two", "three", "four five"}
+	if strings.Join(pages, "|") != strings.Join(want, "|") {
+		t.Errorf("paginate = %q, want %q", pages, want)
+	}
+
+	// Runes are counted, not bytes, and long words are split
+	if pages := paginate("日本語のテキスト", 3); len(pages) != 3 || pages[0] != "日本語" {
+		t.Errorf("paginate(CJK) = %q", pages)
+	}
+}
+
+func TestPagerCommands(t *testing.T) {
+	m := model{settings: &sessionSettings{values: map[string]string{}}, pager: &pager{}}
+
+	if got := m.handleCommand("more"); !strings.Contains(got, "Nothing to page") {
+		t.Errorf("Expected nothing to page, got %q", got)
+	}
+
+	content := strings.Repeat("word ", 300)
+	first := m.pager.load("✅ Text extracted", "[Total]", content, pdfPageSize, 1)
+	if !strings.Contains(first, "(page 1 of 3)") || !strings.Contains(first, "'more' for page 2") {
+		t.Errorf("Unexpected first page:\n%s", first)
+	}
+
+	second := m.handleCommand("more")
+	if !strings.Contains(second, "(page 2 of 3)") {
+		t.Errorf("Expected page 2, got:\n%s", second)
+	}
+	if last := m.handleCommand("more"); !strings.Contains(last, "(page 3 of 3)") || !strings.HasSuffix(last, "[Total]") {
+		t.Errorf("Expected the last page with the footer, got:\n%s", last)
+	}
+	if got := m.handleCommand("more"); !strings.Contains(got, "last page") {
+		t.Errorf("Expected the last page message, got %q", got)
+	}
+
+	// Any other command resets the pager
+	m.handleCommand("/show")
+	if got := m.handleCommand("more"); !strings.Contains(got, "Nothing to page") {
+		t.Errorf("Expected the pager to be reset, got %q", got)
+	}
+
+	if got := m.pager.load("x", "", content, pdfPageSize, 9); !strings.Contains(got, "page 9 does not exist") {
+		t.Errorf("Expected an error for a missing page, got %q", got)
+	}
+}
+
+func TestParsePageArg(t *testing.T) {
+	if page, err := parsePageArg([]string{"--clean", "--page", "3"}); err != nil || page != 3 {
+		t.Errorf("parsePageArg = %d, %v, want 3", page, err)
+	}
+	if page, err := parsePageArg(nil); err != nil || page != 1 {
+		t.Errorf("parsePageArg without --page = %d, %v, want 1", page, err)
+	}
+	for _, args := range [][]string{{"--page"}, {"--page", "0"}, {"--page", "two"}} {
+		if _, err := parsePageArg(args); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
+
+// browse feeds msg to the open file picker and then the directory listings
+// it asks for, as the Bubble Tea runtime would
+func browse(t *testing.T, m model, msg tea.Msg) model {
+	t.Helper()
+	next, cmd := m.Update(msg)
+	m = next.(model)
+	for cmd != nil {
+		next, cmd = m.Update(cmd())
+		m = next.(model)
+	}
+	return m
+}
+
+func TestPDFBrowser(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "papers"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	pdf, err := os.ReadFile("../internal/extractors/pdf/testdata/text.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "papers", "report.pdf"), pdf, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := model{settings: &sessionSettings{values: map[string]string{}}, pager: &pager{}}
+	m.input = "pdf browse info --from " + dir
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.browser == nil {
+		t.Fatalf("Expected the file browser to open, history: %q", m.history)
+	}
+
+	// Going up from the starting directory is ignored
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyLeft})
+	if got := m.browser.picker.CurrentDirectory; got != dir {
+		t.Errorf("Expected browsing to stay in %s, got %s", dir, got)
+	}
+
+	// Directories come first: papers/, then notes.txt, which is not a PDF
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.browser == nil || !strings.Contains(m.browser.view(), "notes.txt is not a PDF") {
+		t.Fatalf("Expected a non-PDF selection to be refused")
+	}
+
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyUp})
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyEnter}) // open papers/
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyEnter}) // select report.pdf
+	if m.browser != nil {
+		t.Fatal("Expected the browser to close after selecting a PDF")
+	}
+	last := m.history[len(m.history)-2:]
+	if !strings.HasSuffix(last[0], filepath.Join("papers", "report.pdf")) || !strings.Contains(last[1], "Pages: 1") {
+		t.Errorf("Expected pdf info to run on the selection, got %q", last)
+	}
+}
+
+func TestPDFBrowserCancel(t *testing.T) {
+	m := model{settings: &sessionSettings{values: map[string]string{}}, pager: &pager{}}
+	m.input = "pdf browse extract --clean"
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.browser == nil || m.browser.action != "extract" || strings.Join(m.browser.args, " ") != "--clean" {
+		t.Fatalf("Unexpected browser %+v", m.browser)
+	}
+
+	m = browse(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.browser != nil || m.history[len(m.history)-1] != "Browsing cancelled" {
+		t.Errorf("Expected esc to cancel browsing, history: %q", m.history)
+	}
+
+	// Typed paths keep working, and bad options are reported without browsing
+	if _, err := newPDFBrowser([]string{"info", "--clean"}, 0); err == nil {
+		t.Error("Expected pdf info options to be rejected")
+	}
+	if _, err := newPDFBrowser([]string{"--from", "no-such-dir"}, 0); err == nil {
+		t.Error("Expected a missing directory to be rejected")
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/server"
+)
+
+// defaultServerURL is where job commands find a local "gengo serve"
+const defaultServerURL = "http://localhost:8080"
+
+var jobsServer string
+
+// jobsCmd represents the jobs command
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Inspect background jobs on a gengo server",
+	Long: `Inspect background transcription jobs submitted to a running "gengo serve",
+for example with "gengo ytaudio transcribe <url> --callback <url>".
+
+Job state is kept in the server's memory, so jobs are lost when it restarts.
+
+Examples:
+  gengo jobs status 3f9a1c2b7d4e5f60
+  gengo jobs status 3f9a1c2b7d4e5f60 --server http://extract.internal:8080`,
+}
+
+// jobsStatusCmd represents the jobs status subcommand
+var jobsStatusCmd = &cobra.Command{
+	Use:   "status [job-id]",
+	Short: "Show the status and result of a job",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+		defer cancel()
+
+		job, err := server.JobStatus(ctx, jobsServer, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		data, err := json.MarshalIndent(job, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	},
+}
+
+// submitTranscriptionJob hands a video to the server for background
+// transcription and prints the job id
+func submitTranscriptionJob(ctx context.Context, videoURL string) {
+	if err := server.CheckHTTPURL(ytCallback); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --callback: %v\n", err)
+		os.Exit(1)
+	}
+	if err := server.CheckHTTPURL(ytServer); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --server: %v\n", err)
+		os.Exit(1)
+	}
+
+	if dryRun {
+		printPlan(output.Plan{
+			Action:  "submit YouTube video as a background transcription job",
+			Source:  videoURL,
+			Details: []string{"Server: " + ytServer, "Callback: " + ytCallback},
+		}, output.OutputOptions{Format: output.FormatText})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	job, err := server.SubmitTranscription(ctx, ytServer, videoURL, ytCallback)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error submitting job: %v\n", err)
+		os.Exit(1)
+	}
+
+	if quiet {
+		fmt.Println(job.ID)
+		return
+	}
+	fmt.Printf("📨 Job submitted: %s\n", job.ID)
+	fmt.Printf("The result will be POSTed to %s when it finishes\n", ytCallback)
+	fmt.Printf("Check progress with: gengo jobs status %s --server %s\n", job.ID, ytServer)
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsStatusCmd)
+
+	jobsCmd.PersistentFlags().StringVar(&jobsServer, "server", defaultServerURL, "URL of the gengo server")
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/text"
+)
+
+var (
+	kwCount     int
+	kwLanguage  string
+	kwStopwords string
+	kwTimeout   time.Duration
+)
+
+// keywordsCmd represents the keywords command
+var keywordsCmd = &cobra.Command{
+	Use:   "keywords [source]",
+	Short: "Extract the top keyphrases from any supported source",
+	Long: `Extract a source like "gengo extract" does and print its top keyphrases,
+best first, one per line.
+
+Keyphrases are ranked with RAKE (Rapid Automatic Keyword Extraction), which
+scores runs of words between stopwords by how often their words appear and
+co-occur. Built-in stopword lists cover en, de, es, fr, ja and zh, and by
+default the language is detected from the text; use --stopwords to supply
+your own list with one word per line. Chinese and Japanese, which are written
+without spaces, are split into words by script.
+
+Files saved to a project are tagged with their keyphrases automatically, see
+the --tags flag.
+
+Examples:
+  gengo keywords report.pdf
+  gengo keywords https://example.com/article -n 5
+  gengo keywords notes.md --language de
+  gengo keywords talk.md --stopwords my-stopwords.txt`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		source := args[0]
+
+		kind := detectSourceKind(source)
+		if kind == sourceUnknown {
+			fmt.Fprintf(os.Stderr, "Error: Unsupported source: %s\n", source)
+			fmt.Fprintln(os.Stderr, "Supported sources: YouTube URLs, http(s) URLs, .pdf, .docx, .epub and .md files")
+			os.Exit(1)
+		}
+
+		// Check the stopword options before a possibly long extraction
+		var stopwords map[string]bool
+		var err error
+		if kwStopwords != "" {
+			stopwords, err = text.LoadStopwords(kwStopwords)
+		} else {
+			_, err = text.StopwordsFor("", kwLanguage)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			printPlan(output.Plan{Action: fmt.Sprintf("extract keywords from %s source", kind), Source: source}, output.OutputOptions{})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), kwTimeout)
+		defer cancel()
+
+		result, err := extractSource(ctx, source, kind)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error extracting %s source: %v\n", kind, err)
+			os.Exit(1)
+		}
+
+		content := result.Title + ".\n" + result.Content
+		if stopwords == nil {
+			stopwords, _ = text.StopwordsFor(content, kwLanguage)
+		}
+		keywords := text.KeywordsWithStopwords(content, kwCount, stopwords)
+		if len(keywords) == 0 {
+			fmt.Fprintln(os.Stderr, "No keyphrases found")
+			os.Exit(1)
+		}
+		fmt.Println(strings.Join(keywords, "\n"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(keywordsCmd)
+
+	keywordsCmd.Flags().IntVarP(&kwCount, "count", "n", 10, "Number of keyphrases to print")
+	keywordsCmd.Flags().StringVarP(&kwLanguage, "language", "l", text.AutoLanguage, "Stopword language ("+strings.Join(text.StopwordLanguages(), ", ")+"), or auto to detect it")
+	keywordsCmd.Flags().StringVar(&kwStopwords, "stopwords", "", "File of stopwords, one per line, used instead of --language")
+	keywordsCmd.Flags().DurationVarP(&kwTimeout, "timeout", "t", 30*time.Minute, "Timeout for the entire operation")
+}
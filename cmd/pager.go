@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Characters per preview page in the interactive mode
+const (
+	pdfPageSize = 500
+	webPageSize = 800
+)
+
+// pager keeps the full content of the last interactive extraction so it can
+// be read a page at a time with "more" or --page
+type pager struct {
+	header string   // printed above every page, e.g. the source and title
+	footer string   // printed below the last of several pages
+	pages  []string // content split into pages
+	page   int      // index of the page shown last
+}
+
+// load replaces the pager content and shows the given 1-based page
+func (p *pager) load(header, footer, content string, size, page int) string {
+	p.header = header
+	p.footer = footer
+	p.pages = paginate(content, size)
+	return p.show(page - 1)
+}
+
+// reset drops the paged content
+func (p *pager) reset() {
+	*p = pager{}
+}
+
+// next shows the page after the one shown last
+func (p *pager) next() string {
+	if len(p.pages) == 0 {
+		return "Nothing to page through; run pdf extract or web extract first"
+	}
+	if p.page+1 >= len(p.pages) {
+		return fmt.Sprintf("Already at the last page (%d of %d)", len(p.pages), len(p.pages))
+	}
+	return p.show(p.page + 1)
+}
+
+// show renders page i
+func (p *pager) show(i int) string {
+	if i < 0 || i >= len(p.pages) {
+		return fmt.Sprintf("Error: page %d does not exist (1-%d)", i+1, len(p.pages))
+	}
+	p.page = i
+
+	var b strings.Builder
+	b.WriteString(p.header)
+	if len(p.pages) > 1 {
+		fmt.Fprintf(&b, " (page %d of %d)", i+1, len(p.pages))
+	}
+	b.WriteString(":\n\n")
+	b.WriteString(p.pages[i])
+
+	switch {
+	case i+1 < len(p.pages):
+		fmt.Fprintf(&b, "\n\n[Type 'more' for page %d, or use --page N]", i+2)
+	case len(p.pages) > 1 && p.footer != "":
+		b.WriteString("\n\n" + p.footer)
+	}
+	return b.String()
+}
+
+// paginate splits content into pages of at most size characters, breaking
+// after whitespace where possible so words are not cut in half
+func paginate(content string, size int) []string {
+	var pages []string
+	for content != "" {
+		if utf8.RuneCountInString(content) <= size {
+			pages = append(pages, content)
+			break
+		}
+
+		// Byte offset of the rune just past the page
+		end := 0
+		for n := 0; n < size; n++ {
+			_, width := utf8.DecodeRuneInString(content[end:])
+			end += width
+		}
+		if cut := strings.LastIndexFunc(content[:end], unicode.IsSpace); cut > 0 {
+			_, width := utf8.DecodeRuneInString(content[cut:])
+			end = cut + width
+		}
+
+		pages = append(pages, strings.TrimRightFunc(content[:end], unicode.IsSpace))
+		content = strings.TrimLeftFunc(content[end:], unicode.IsSpace)
+	}
+	return pages
+}
+
+// parsePageArg reads the --page value from interactive arguments,
+// returning 1 when it is absent
+func parsePageArg(args []string) (int, error) {
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--page" {
+			continue
+		}
+		if i+1 >= len(args) {
+			return 0, fmt.Errorf("--page needs a page number")
+		}
+		page, err := strconv.Atoi(args[i+1])
+		if err != nil || page < 1 {
+			return 0, fmt.Errorf("invalid page number: %s", args[i+1])
+		}
+		return page, nil
+	}
+	return 1, nil
+}
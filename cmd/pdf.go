@@ -1,18 +1,51 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 	extractors "maai.solutions/gengo/internal/extractors/pdf"
+	"maai.solutions/gengo/internal/output"
 )
 
 var (
-	outputFile string
-	pages      []int
-	cleanText  bool
+	outputFile     string
+	pdfOutputDir   string
+	pdfProjectName string
+	pdfFormat      string
+	pages          []int
+	cleanText      bool
+	pdfAppend      bool
+	pdfForce       bool
+	pdfTOC         bool
+	pdfAnnotJSON   bool
+	pdfLinksJSON   bool
+	pdfLinks       bool
+	pdfPositions   bool
+	pdfSplitDir    string
+	pdfSplitRanges string
+	pdfRedactPats  []string
+	pdfRedactOut   string
+	pdfRepairOut   string
+	pdfAutoRepair  bool
+	pdfTablesOut   string
+	pdfTablesFmt   string
+	pdfTablePages  []int
+	pdfHeadingOff  int
+	pdfLimit       int
+	pdfLimitUnit   string
+	pdfExclLayers  []string
+	pdfInclLayers  []string
+	pdfWorkers     int
 )
 
 // pdfCmd represents the pdf command
@@ -26,65 +59,189 @@ Examples:
   gengo pdf extract file.pdf --output text.txt  # Extract all text to file
   gengo pdf extract file.pdf --pages 1,3,5      # Extract specific pages
   gengo pdf extract file.pdf --clean            # Extract and clean text
-  gengo pdf info file.pdf                       # Get PDF information`,
+  gengo pdf extract file.pdf --format json      # Extract as JSON
+  curl -s https://example.com/f.pdf | gengo pdf extract -  # Extract a PDF piped in
+  gengo pdf extract file.pdf --exclude-layer Watermark  # Drop watermark text
+  gengo pdf info file.pdf                       # Get PDF information and layers
+  gengo pdf annotations file.pdf                # List comments and highlights
+  gengo pdf links paper.pdf --json              # List links and their targets
+  gengo pdf split file.pdf --dir ./pages        # Write one PDF per page
+  gengo pdf repair broken.pdf -o fixed.pdf      # Repair a damaged PDF
+  gengo pdf tables report.pdf -o tables.csv     # Extract tables as CSV`,
 }
 
 // extractCmd represents the extract command
 var extractCmd = &cobra.Command{
-	Use:   "extract [pdf-file]",
+	Use:   "extract [pdf-file|-]",
 	Short: "Extract text from a PDF file",
 	Long: `Extract text from a PDF file and output to stdout or a file.
 	
 The command supports various options:
 - Extract all pages or specific pages
-- Output to stdout or save to file
-- Clean extracted text by removing excessive whitespace`,
+- Output to stdout, a file, a directory or a project folder
+- Append to an existing output file instead of overwriting it
+- Output as plain text, markdown or JSON
+- Clean extracted text by removing excessive whitespace
+- Drop the text of PDF layers (optional content groups) such as watermarks
+  with --exclude-layer, or keep only the layered text of --include-layer;
+  text outside any layer is always kept. "gengo pdf info" lists the layers
+- Keep the web links of the PDF as [text](url) in markdown output with
+  --links; "gengo pdf links" lists every link with its target
+- Repair a damaged PDF and extract from the repaired copy when extraction
+  fails with --auto-repair; "gengo pdf repair" keeps the repaired file
+- Read the pages of a large PDF concurrently with --workers; the text is
+  the same as with one worker
+- Read the PDF from stdin by passing - as the file; --pages, --positions,
+  --links, --auto-repair and the layer options need a file
+- Output the text blocks of every page with their bounding boxes with
+  --positions --format json, for layout analysis or redaction. Coordinates
+  are in points from the lower-left page corner.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		pdfFile := args[0]
 
-		// Check if file exists
-		if _, err := os.Stat(pdfFile); os.IsNotExist(err) {
-			fmt.Printf("Error: File does not exist: %s\n", pdfFile)
+		format, err := output.ParseFormat(pdfFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := checkHeadingOffset(pdfHeadingOff); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		limitUnit, err := parseLimit(pdfLimit, pdfLimitUnit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if pdfWorkers < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --workers must be at least 1, got %d\n", pdfWorkers)
+			os.Exit(1)
+		}
+		if pdfPositions && format != output.FormatJSON {
+			fmt.Fprintln(os.Stderr, "Error: --positions requires --format json")
+			os.Exit(1)
+		}
+		if pdfPositions && (pdfOutputDir != "" || pdfProjectName != "" || pdfAppend) {
+			fmt.Fprintln(os.Stderr, "Error: --positions writes to stdout or --output only")
+			os.Exit(1)
+		}
+
+		layered := len(pdfExclLayers) > 0 || len(pdfInclLayers) > 0
+		fromStdin := pdfFile == stdinArg
+		if fromStdin {
+			if len(pages) > 0 || pdfPositions || layered || pdfLinks || pdfAutoRepair {
+				fmt.Fprintln(os.Stderr, "Error: --pages, --positions, --links, --auto-repair and the layer options need a file, not stdin")
+				os.Exit(1)
+			}
+		} else if _, err := os.Stat(pdfFile); os.IsNotExist(err) {
+			// Check if file exists
+			fmt.Fprintf(os.Stderr, "Error: File does not exist: %s\n", pdfFile)
 			os.Exit(1)
 		}
 
+		if pdfPositions && !dryRun {
+			if err := writePositions(pdfFile, pages, outputFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		title := strings.TrimSuffix(filepath.Base(pdfFile), filepath.Ext(pdfFile))
+		source := pdfFile
+		if fromStdin {
+			// Untitled, since piped bytes carry no file name
+			title, source = "", stdinSource
+		}
+		opts := output.OutputOptions{
+			OutputFile:  outputFile,
+			OutputDir:   pdfOutputDir,
+			ProjectName: pdfProjectName,
+			Format:      format,
+			Append:      pdfAppend,
+			Force:       pdfForce,
+			SourceType:  output.SourcePDF,
+			Extraction:  extractionOptions(pdfTOC, pdfHeadingOff, pdfLimit, limitUnit),
+		}
+		if pdfLinks && format == output.FormatMarkdown {
+			opts.Extraction.Links = "inline"
+		}
+
+		if dryRun {
+			plan := output.Plan{Action: "extract PDF text", Source: source, Title: title}
+			if len(pages) > 0 {
+				plan.Details = append(plan.Details, fmt.Sprintf("Pages: %v", pages))
+			}
+			if len(pdfExclLayers) > 0 {
+				plan.Details = append(plan.Details, "Excluded layers: "+strings.Join(pdfExclLayers, ", "))
+			}
+			if len(pdfInclLayers) > 0 {
+				plan.Details = append(plan.Details, "Included layers: "+strings.Join(pdfInclLayers, ", "))
+			}
+			if pdfWorkers > 1 {
+				plan.Details = append(plan.Details, fmt.Sprintf("Workers: %d", pdfWorkers))
+			}
+			printPlan(plan, opts)
+			return
+		}
+
 		// Create PDF extractor
-		extractor := extractors.NewTextExtractor()
+		extractor := pdfTextExtractor()
 
 		var text string
-		var err error
 
-		// Extract text
-		if len(pages) > 0 {
-			text, err = extractor.ExtractPages(pdfFile, pages)
-			if err != nil {
-				fmt.Printf("Error extracting pages %v from PDF: %v\n", pages, err)
-				os.Exit(1)
-			}
+		// Extract text, reading links from the repaired copy when one was
+		// made
+		readPath := pdfFile
+		if fromStdin {
+			text, err = extractPDFReader(extractor, os.Stdin)
 		} else {
-			text, err = extractor.ExtractFromFile(pdfFile)
-			if err != nil {
-				fmt.Printf("Error extracting text from PDF: %v\n", err)
-				os.Exit(1)
+			var repairedPath string
+			text, repairedPath, err = extractPDFFile(extractor, pdfFile)
+			if repairedPath != "" {
+				defer os.Remove(repairedPath)
+				readPath = repairedPath
 			}
 		}
+		if err != nil {
+			if len(pages) > 0 {
+				fmt.Fprintf(os.Stderr, "Error extracting pages %v from PDF: %v\n", pages, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error extracting text from PDF: %v\n", err)
+			}
+			os.Exit(1)
+		}
 
 		// Clean text if requested
 		if cleanText {
 			text = extractor.CleanText(text)
 		}
 
-		// Output text
-		if outputFile != "" {
-			err = os.WriteFile(outputFile, []byte(text), 0644)
+		if pdfLinks && format == output.FormatMarkdown {
+			links, err := extractor.GetLinks(readPath)
 			if err != nil {
-				fmt.Printf("Error writing to file %s: %v\n", outputFile, err)
+				fmt.Fprintf(os.Stderr, "Error reading PDF links: %v\n", err)
 				os.Exit(1)
 			}
-			fmt.Printf("Text extracted and saved to: %s\n", outputFile)
-		} else {
-			fmt.Print(text)
+			text = extractors.InlineLinks(text, linksOnPages(links, pages))
+		}
+
+		// Output text
+		result := output.NewResult(output.SourcePDF, source, title, text)
+		result = withLimit(result, pdfLimit, limitUnit)
+		result = withTOC(withHeadingOffset(result, format, pdfHeadingOff), format, pdfTOC)
+
+		paths, err := output.WriteAll(result, opts)
+		for _, path := range paths {
+			statusf("Text extracted and saved to: %s\n", path)
+		}
+		if err != nil {
+			if reportDuplicate(err) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
 		}
 	},
 }
@@ -100,7 +257,7 @@ var infoCmd = &cobra.Command{
 
 		// Check if file exists
 		if _, err := os.Stat(pdfFile); os.IsNotExist(err) {
-			fmt.Printf("Error: File does not exist: %s\n", pdfFile)
+			fmt.Fprintf(os.Stderr, "Error: File does not exist: %s\n", pdfFile)
 			os.Exit(1)
 		}
 
@@ -110,7 +267,7 @@ var infoCmd = &cobra.Command{
 		// Get page count
 		pageCount, err := extractor.GetPageCount(pdfFile)
 		if err != nil {
-			fmt.Printf("Error getting PDF info: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error getting PDF info: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -122,9 +279,615 @@ var infoCmd = &cobra.Command{
 		fmt.Printf("  Path: %s\n", pdfFile)
 		fmt.Printf("  Size: %d bytes\n", fileInfo.Size())
 		fmt.Printf("  Pages: %d\n", pageCount)
+
+		// Layers are a bonus; a PDF that cannot be read for them still has the basics
+		if layers, err := extractor.GetLayers(pdfFile); err == nil && len(layers) > 0 {
+			fmt.Printf("  Layers: %s\n", strings.Join(layers, ", "))
+		}
+	},
+}
+
+// extractPDFReader extracts the text of a PDF read from r, such as one piped
+// in. The PDF extractor reads from a file, so r is copied to a temporary one.
+func extractPDFReader(extractor *extractors.TextExtractor, r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp("", "gengo-stdin-*.pdf")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return extractor.ExtractLayerText(tmp.Name(), nil)
+}
+
+// extractPDFFile extracts the text of the selected pages of a PDF file, or
+// of all of them, from a repaired copy when the PDF is damaged and
+// --auto-repair allows it. The copy's path is returned, or "" when none was
+// made, and the caller removes it; the input file is never touched.
+func extractPDFFile(extractor *extractors.TextExtractor, path string) (text, repairedPath string, err error) {
+	text, err = extractor.ExtractPages(path, pages)
+	if err == nil || !pdfAutoRepair || !extractors.NeedsRepair(err) {
+		return text, "", err
+	}
+
+	noticef("Extraction failed (%v), repairing %s\n", err, path)
+	repairedPath, err = repairForExtraction(extractor, path)
+	if err != nil {
+		return "", "", err
+	}
+	text, err = extractor.ExtractPages(repairedPath, pages)
+	if err != nil {
+		os.Remove(repairedPath)
+		return "", "", err
+	}
+	return text, repairedPath, nil
+}
+
+// repairForExtraction repairs a PDF into a temporary file, reporting what
+// was fixed, and returns the file's path. The caller removes the file.
+func repairForExtraction(extractor *extractors.TextExtractor, path string) (string, error) {
+	tmp, err := os.CreateTemp("", "gengo-repaired-*.pdf")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+
+	report, err := extractor.RepairFile(path, tmp.Name())
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if !report.Repaired() {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("no repairable damage found in %s", path)
+	}
+	noticef("%s", formatRepairReport(report))
+	return tmp.Name(), nil
+}
+
+// formatRepairReport lists the problems found in a PDF and the repairs made
+func formatRepairReport(report *extractors.RepairReport) string {
+	var b strings.Builder
+	if len(report.Problems) == 0 {
+		b.WriteString("No problems found\n")
+	} else {
+		b.WriteString("Problems found:\n")
+		for _, problem := range report.Problems {
+			fmt.Fprintf(&b, "  - %s\n", problem)
+		}
+	}
+	if report.Repaired() {
+		b.WriteString("Repairs:\n")
+		for _, repair := range report.Repairs {
+			fmt.Fprintf(&b, "  - %s\n", repair)
+		}
+	}
+	return b.String()
+}
+
+// pdfTextExtractor returns a text extractor applying --exclude-layer and
+// --include-layer
+func pdfTextExtractor() *extractors.TextExtractor {
+	extractor := extractors.NewTextExtractor()
+	extractor.Layers = extractors.LayerFilter{Include: pdfInclLayers, Exclude: pdfExclLayers}
+	extractor.Workers = pdfWorkers
+	return extractor
+}
+
+// writePositions writes the positioned text blocks of a PDF, limited to the
+// selected pages when there are any, as JSON to path or stdout
+func writePositions(pdfFile string, pages []int, path string) error {
+	blocks, err := pdfTextExtractor().ExtractWithPositions(pdfFile)
+	if err != nil {
+		return err
+	}
+
+	selected := []extractors.TextBlock{}
+	for _, block := range blocks {
+		if len(pages) == 0 || slices.Contains(pages, block.Page) {
+			selected = append(selected, block)
+		}
+	}
+
+	data, err := json.MarshalIndent(selected, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := output.CheckPath(path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, output.FileMode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	statusf("Text positions saved to: %s\n", path)
+	return nil
+}
+
+// annotationsCmd represents the annotations command
+var annotationsCmd = &cobra.Command{
+	Use:   "annotations [pdf-file]",
+	Short: "List the comments and highlights in a PDF file",
+	Long: `List the sticky notes, text boxes, highlights, underlines and strike-outs
+reviewers added to a PDF file, page by page, with their author and comment.
+Links and form fields are left out.
+
+Examples:
+  gengo pdf annotations review.pdf          # Print the comments
+  gengo pdf annotations review.pdf --json   # Print them as JSON`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		annotations, err := extractors.NewTextExtractor().GetAnnotations(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if pdfAnnotJSON {
+			if annotations == nil {
+				annotations = []extractors.Annotation{}
+			}
+			data, err := json.MarshalIndent(annotations, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(annotations) == 0 {
+			statusf("No annotations found in %s\n", args[0])
+			return
+		}
+		fmt.Print(formatAnnotations(annotations))
 	},
 }
 
+// linksCmd represents the links command
+var linksCmd = &cobra.Command{
+	Use:   "links [pdf-file]",
+	Short: "List the links in a PDF file and their targets",
+	Long: `List the links in a PDF file, page by page, with the text they cover and
+where they lead: a web address, or the page of the document an internal link
+jumps to. Useful for collecting the references cited in papers.
+
+Use "gengo pdf extract --links" to keep the web links in extracted markdown.
+
+Examples:
+  gengo pdf links paper.pdf          # Print the links
+  gengo pdf links paper.pdf --json   # Print them as JSON`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		links, err := extractors.NewTextExtractor().GetLinks(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if pdfLinksJSON {
+			if links == nil {
+				links = []extractors.PDFLink{}
+			}
+			data, err := json.MarshalIndent(links, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(links) == 0 {
+			statusf("No links found in %s\n", args[0])
+			return
+		}
+		fmt.Print(formatLinks(links))
+	},
+}
+
+// splitCmd represents the split command
+var splitCmd = &cobra.Command{
+	Use:   "split [pdf-file]",
+	Short: "Split a PDF file into one file per page or page range",
+	Long: `Write every page of a PDF file to its own PDF, named after the file with
+the page number appended (report_1.pdf, report_2.pdf, ...). With --ranges each
+range is written to one file instead (report_1-3.pdf).
+
+Examples:
+  gengo pdf split report.pdf --dir ./pages                  # One file per page
+  gengo pdf split report.pdf --dir ./parts --ranges 1-3,4-6 # One file per range`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pdfFile := args[0]
+
+		var ranges []extractors.PageRange
+		if pdfSplitRanges != "" {
+			var err error
+			if ranges, err = extractors.ParsePageRanges(pdfSplitRanges); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if dryRun {
+			target := "one file per page"
+			if len(ranges) > 0 {
+				target = fmt.Sprintf("one file per range (%s)", pdfSplitRanges)
+			}
+			fmt.Printf("Dry run: would split %s into %s in %s\n", pdfFile, target, pdfSplitDir)
+			return
+		}
+
+		extractor := extractors.NewTextExtractor()
+		var paths []string
+		var err error
+		if len(ranges) > 0 {
+			paths, err = extractor.SplitRanges(pdfFile, pdfSplitDir, ranges)
+		} else {
+			paths, err = extractor.SplitFile(pdfFile, pdfSplitDir)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		statusf("Split %s into %d files in %s\n", pdfFile, len(paths), pdfSplitDir)
+	},
+}
+
+// redactCmd represents the redact command
+var redactCmd = &cobra.Command{
+	Use:   "redact [pdf-file]",
+	Short: "Remove text matching patterns from a PDF file",
+	Long: `Remove the text matching one or more regular expressions from a PDF file
+and cover where it was with black boxes. The matched text is taken out of the
+page content, so it cannot be selected, copied or extracted from the output.
+Text drawn inside form XObjects cannot be redacted; such matches fail the
+command instead of leaving readable text under a box.
+
+Without --output the result is written next to the input as name_redacted.pdf.
+
+Examples:
+  gengo pdf redact file.pdf --pattern '\d{3}-\d{2}-\d{4}' --output redacted.pdf
+  gengo pdf redact file.pdf --pattern 'Jane Doe' --pattern '[\w.]+@[\w.]+'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pdfFile := args[0]
+		if _, err := os.Stat(pdfFile); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Error: File does not exist: %s\n", pdfFile)
+			os.Exit(1)
+		}
+
+		patterns, err := compilePatterns(pdfRedactPats)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		outPath := pdfRedactOut
+		if outPath == "" {
+			outPath = strings.TrimSuffix(pdfFile, filepath.Ext(pdfFile)) + "_redacted.pdf"
+		}
+		if err := output.CheckPath(outPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			plan := output.Plan{Action: "redact text from PDF", Source: pdfFile}
+			for _, pattern := range patterns {
+				plan.Details = append(plan.Details, "Pattern: "+pattern.String())
+			}
+			printPlan(plan, output.OutputOptions{OutputFile: outPath, Format: "pdf"})
+			return
+		}
+
+		redacted, err := extractors.NewTextExtractor().Redact(pdfFile, outPath, patterns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(outPath)
+		statusf("Redacted %d match(es) from %s\n", len(redacted), pdfFile)
+	},
+}
+
+// repairCmd represents the repair command
+var repairCmd = &cobra.Command{
+	Use:   "repair [pdf-file]",
+	Short: "Validate a PDF file and repair common damage",
+	Long: `Check a PDF file for structural problems and write a repaired copy that
+other commands and viewers can open. Broken or missing cross-reference
+tables, common in truncated downloads and files saved by careless editors,
+are rebuilt from the objects found in the file; unused and duplicate objects
+are dropped. The problems found and the repairs made are reported.
+
+A PDF without problems is still rewritten, so the output can always be used
+in its place. Without --output it is written next to the input as
+name_repaired.pdf. "gengo pdf extract --auto-repair" repairs on the fly.
+
+Examples:
+  gengo pdf repair broken.pdf --output fixed.pdf
+  gengo pdf repair scan.pdf && gengo pdf extract scan_repaired.pdf`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pdfFile := args[0]
+		if _, err := os.Stat(pdfFile); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Error: File does not exist: %s\n", pdfFile)
+			os.Exit(1)
+		}
+
+		outPath := pdfRepairOut
+		if outPath == "" {
+			outPath = strings.TrimSuffix(pdfFile, filepath.Ext(pdfFile)) + "_repaired.pdf"
+		}
+		if err := output.CheckPath(outPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			printPlan(output.Plan{Action: "repair PDF", Source: pdfFile}, output.OutputOptions{OutputFile: outPath, Format: "pdf"})
+			return
+		}
+
+		report, err := extractors.NewTextExtractor().RepairFile(pdfFile, outPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(outPath)
+		statusf("%s", formatRepairReport(report))
+		statusf("Wrote %d page(s) to %s\n", report.Pages, outPath)
+	},
+}
+
+// tablesCmd represents the tables command
+var tablesCmd = &cobra.Command{
+	Use:   "tables [pdf-file]",
+	Short: "Extract the tables in a PDF file as CSV or JSON",
+	Long: `Detect the tables in a PDF file and write their rows as CSV, or as JSON with
+--format json. Tables are recognized from the position of the text: lines
+split into several cells whose columns line up from one line to the next.
+Ruling lines are not needed, but columns must be separated by a gap.
+
+A cell spanning several columns, such as a group heading, is written to the
+first of them and the others are left empty.
+
+With --output, a single table is written to that file and several tables to
+one file each, numbered after it (tables_1.csv, tables_2.csv, ...). Without
+it, CSV tables are printed one after the other, separated by a blank line.
+JSON output is always one array of the tables with their page number.
+
+Examples:
+  gengo pdf tables report.pdf                         # Print the tables as CSV
+  gengo pdf tables report.pdf --output tables.csv     # One CSV file per table
+  gengo pdf tables report.pdf --pages 3,4 --format json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pdfFile := args[0]
+		if _, err := os.Stat(pdfFile); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Error: File does not exist: %s\n", pdfFile)
+			os.Exit(1)
+		}
+
+		format := strings.ToLower(pdfTablesFmt)
+		if format != "csv" && format != "json" {
+			fmt.Fprintf(os.Stderr, "Error: unsupported tables format: %s (expected csv or json)\n", pdfTablesFmt)
+			os.Exit(1)
+		}
+		if pdfTablesOut != "" {
+			if err := output.CheckPath(pdfTablesOut); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if dryRun {
+			plan := output.Plan{Action: "extract PDF tables", Source: pdfFile}
+			if len(pdfTablePages) > 0 {
+				plan.Details = append(plan.Details, fmt.Sprintf("Pages: %v", pdfTablePages))
+			}
+			printPlan(plan, output.OutputOptions{OutputFile: pdfTablesOut, Format: output.Format(format)})
+			return
+		}
+
+		tables, err := pdfTextExtractor().ExtractTables(pdfFile, pdfTablePages)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(tables) == 0 && format == "csv" {
+			statusf("No tables found in %s\n", pdfFile)
+			return
+		}
+
+		paths, err := writeTables(tables, format, pdfTablesOut)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, path := range paths {
+			statusf("Table saved to: %s\n", path)
+		}
+	},
+}
+
+// writeTables writes tables as CSV or JSON to path, one CSV file per table
+// numbered after path when there are several, or to stdout when path is
+// empty. It returns the files written.
+func writeTables(tables []extractors.Table, format, path string) ([]string, error) {
+	if format == "json" {
+		if tables == nil {
+			tables = []extractors.Table{}
+		}
+		data, err := json.MarshalIndent(tables, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return writeTableFile(path, append(data, '\n'))
+	}
+
+	if path == "" {
+		for i, table := range tables {
+			if i > 0 {
+				fmt.Println()
+			}
+			if err := csv.NewWriter(os.Stdout).WriteAll(table.Rows); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	var paths []string
+	for i, table := range tables {
+		tablePath := path
+		if len(tables) > 1 {
+			ext := filepath.Ext(path)
+			tablePath = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(path, ext), i+1, ext)
+		}
+		var b bytes.Buffer
+		if err := csv.NewWriter(&b).WriteAll(table.Rows); err != nil {
+			return paths, err
+		}
+		written, err := writeTableFile(tablePath, b.Bytes())
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, written...)
+	}
+	return paths, nil
+}
+
+// writeTableFile writes data to path, or to stdout when path is empty
+func writeTableFile(path string, data []byte) ([]string, error) {
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return nil, err
+	}
+	if err := output.CheckPath(path); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, output.FileMode); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return []string{path}, nil
+}
+
+// compilePatterns compiles the --pattern values, requiring at least one
+func compilePatterns(values []string) ([]*regexp.Regexp, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("at least one --pattern is required")
+	}
+	patterns := make([]*regexp.Regexp, 0, len(values))
+	for _, value := range values {
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", value, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// annotationLabels names annotation types whose PDF subtype reads poorly
+var annotationLabels = map[string]string{
+	"Text":      "Note",
+	"FreeText":  "Text box",
+	"StrikeOut": "Strike-out",
+	"Caret":     "Insertion",
+}
+
+// formatAnnotations lists annotations under a heading per page
+func formatAnnotations(annotations []extractors.Annotation) string {
+	var b strings.Builder
+	page := 0
+	for _, a := range annotations {
+		if a.Page != page {
+			if page != 0 {
+				b.WriteString("\n")
+			}
+			page = a.Page
+			fmt.Fprintf(&b, "Page %d\n", page)
+		}
+
+		label := a.Type
+		if name, ok := annotationLabels[a.Type]; ok {
+			label = name
+		}
+		if a.Author != "" {
+			label += " by " + a.Author
+		}
+		if a.Text != "" {
+			label += fmt.Sprintf(" on %q", a.Text)
+		}
+		if a.Contents != "" {
+			fmt.Fprintf(&b, "  %s: %s\n", label, strings.Join(strings.Fields(a.Contents), " "))
+		} else {
+			fmt.Fprintf(&b, "  %s\n", label)
+		}
+	}
+	return b.String()
+}
+
+// linksOnPages keeps the links of the selected pages, or all of them when
+// no pages are selected
+func linksOnPages(links []extractors.PDFLink, pages []int) []extractors.PDFLink {
+	if len(pages) == 0 {
+		return links
+	}
+	var kept []extractors.PDFLink
+	for _, l := range links {
+		if slices.Contains(pages, l.Page) {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// formatLinks lists links under a heading per page
+func formatLinks(links []extractors.PDFLink) string {
+	var b strings.Builder
+	page := 0
+	for _, l := range links {
+		if l.Page != page {
+			if page != 0 {
+				b.WriteString("\n")
+			}
+			page = l.Page
+			fmt.Fprintf(&b, "Page %d\n", page)
+		}
+
+		target := l.URL
+		if !l.External() {
+			target = fmt.Sprintf("page %d", l.DestPage)
+		}
+		if l.Text != "" {
+			fmt.Fprintf(&b, "  %q -> %s\n", l.Text, target)
+		} else {
+			fmt.Fprintf(&b, "  %s\n", target)
+		}
+	}
+	return b.String()
+}
+
 func init() {
 	// Add pdf command to root
 	rootCmd.AddCommand(pdfCmd)
@@ -132,9 +895,43 @@ func init() {
 	// Add subcommands to pdf
 	pdfCmd.AddCommand(extractCmd)
 	pdfCmd.AddCommand(infoCmd)
+	pdfCmd.AddCommand(annotationsCmd)
+	pdfCmd.AddCommand(linksCmd)
+	pdfCmd.AddCommand(splitCmd)
+	pdfCmd.AddCommand(redactCmd)
+	pdfCmd.AddCommand(repairCmd)
+	pdfCmd.AddCommand(tablesCmd)
+
+	splitCmd.Flags().StringVarP(&pdfSplitDir, "dir", "d", ".", "Directory the split files are written to")
+	splitCmd.Flags().StringVar(&pdfSplitRanges, "ranges", "", "Write these page ranges to one file each instead of one file per page (e.g., 1-3,4-6)")
+
+	redactCmd.Flags().StringArrayVar(&pdfRedactPats, "pattern", nil, "Regular expression of the text to redact (repeatable)")
+	redactCmd.Flags().StringVarP(&pdfRedactOut, "output", "o", "", "Output PDF path (default: <name>_redacted.pdf)")
+	tablesCmd.Flags().StringVarP(&pdfTablesOut, "output", "o", "", "Output file path, numbered per table when there are several (default: stdout)")
+	tablesCmd.Flags().StringVarP(&pdfTablesFmt, "format", "f", "csv", "Output format (csv, json)")
+	tablesCmd.Flags().IntSliceVarP(&pdfTablePages, "pages", "p", []int{}, "Only look for tables on these pages (e.g., --pages 1,3,5)")
+	repairCmd.Flags().StringVarP(&pdfRepairOut, "output", "o", "", "Output PDF path (default: <name>_repaired.pdf)")
+
+	annotationsCmd.Flags().BoolVar(&pdfAnnotJSON, "json", false, "Print the annotations as JSON")
+	linksCmd.Flags().BoolVar(&pdfLinksJSON, "json", false, "Print the links as JSON")
 
 	// Add flags to extract command
 	extractCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path (default: stdout)")
+	extractCmd.Flags().StringVarP(&pdfOutputDir, "dir", "d", "", "Output directory path")
+	extractCmd.Flags().StringVar(&pdfProjectName, "project", "", "Project name (creates project folder structure)")
+	extractCmd.Flags().StringVarP(&pdfFormat, "format", "f", "text", "Output format (text, markdown, json)")
 	extractCmd.Flags().IntSliceVarP(&pages, "pages", "p", []int{}, "Specific pages to extract (e.g., --pages 1,3,5)")
+	extractCmd.Flags().BoolVar(&pdfAppend, "append", false, "Append to the output file instead of overwriting it")
+	extractCmd.Flags().BoolVar(&pdfForce, "force", false, "Save to the project even if identical content is already saved")
+	extractCmd.Flags().BoolVar(&pdfTOC, "toc", false, "Insert a linked table of contents (markdown output)")
+	extractCmd.Flags().IntVar(&pdfHeadingOff, "heading-offset", 0, "Demote every heading by N levels, clamped at h6 (markdown output)")
+	extractCmd.Flags().IntVar(&pdfWorkers, "workers", 1, "Read this many pages at once, for large PDFs")
+	extractCmd.Flags().BoolVar(&pdfAutoRepair, "auto-repair", false, "Repair the PDF and retry when extraction fails on a damaged file")
+	extractCmd.Flags().BoolVar(&pdfLinks, "links", false, "Write the web links of the PDF as [text](url) (markdown output)")
+	extractCmd.Flags().IntVar(&pdfLimit, "limit", 0, "Keep only the first N words or characters of the content, ending at a sentence (0 for all)")
+	extractCmd.Flags().StringVar(&pdfLimitUnit, "limit-unit", "words", "What --limit counts (words, chars)")
 	extractCmd.Flags().BoolVarP(&cleanText, "clean", "c", false, "Clean extracted text by removing excessive whitespace")
+	extractCmd.Flags().BoolVar(&pdfPositions, "positions", false, "Output text blocks with their page and bounding box (requires --format json)")
+	extractCmd.Flags().StringArrayVar(&pdfExclLayers, "exclude-layer", nil, "Drop the text of this PDF layer, e.g. Watermark (repeatable)")
+	extractCmd.Flags().StringArrayVar(&pdfInclLayers, "include-layer", nil, "Keep layered text only from this PDF layer; text outside layers is kept (repeatable)")
 }
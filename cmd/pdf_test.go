@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	extractors "maai.solutions/gengo/internal/extractors/pdf"
+)
+
+func TestFormatAnnotations(t *testing.T) {
+	annotations := []extractors.Annotation{
+		{Page: 1, Type: "Text", Author: "Alice", Contents: "Check this\nfigure"},
+		{Page: 1, Type: "Highlight", Text: "Tide pools"},
+		{Page: 3, Type: "StrikeOut", Contents: "Remove"},
+	}
+
+	expected := "Page 1\n  Note by Alice: Check this figure\n  Highlight on \"Tide pools\"\n\nPage 3\n  Strike-out: Remove\n"
+	if got := formatAnnotations(annotations); got != expected {
+		t.Errorf("formatAnnotations() = %q, expected %q", got, expected)
+	}
+}
+
+func TestFormatLinks(t *testing.T) {
+	links := []extractors.PDFLink{
+		{Page: 1, Text: "Tide pools", URL: "https://example.com/tides"},
+		{Page: 1, Text: "See appendix", DestPage: 2},
+		{Page: 2, URL: "https://example.org/"},
+	}
+
+	expected := "Page 1\n  \"Tide pools\" -> https://example.com/tides\n  \"See appendix\" -> page 2\n\nPage 2\n  https://example.org/\n"
+	if got := formatLinks(links); got != expected {
+		t.Errorf("formatLinks() = %q, expected %q", got, expected)
+	}
+
+	if got := linksOnPages(links, []int{2}); len(got) != 1 || got[0].Page != 2 {
+		t.Errorf("linksOnPages() = %+v, expected the link of page 2", got)
+	}
+	if got := linksOnPages(links, nil); len(got) != 3 {
+		t.Errorf("linksOnPages() without pages = %+v, expected all links", got)
+	}
+}
+
+func TestCompilePatterns(t *testing.T) {
+	patterns, err := compilePatterns([]string{`\d{3}-\d{2}-\d{4}`, "Jane Doe"})
+	if err != nil {
+		t.Fatalf("compilePatterns failed: %v", err)
+	}
+	if len(patterns) != 2 || !patterns[0].MatchString("555-12-3456") {
+		t.Errorf("Unexpected patterns %v", patterns)
+	}
+
+	if _, err := compilePatterns(nil); err == nil {
+		t.Error("Expected error without patterns")
+	}
+	if _, err := compilePatterns([]string{"("}); err == nil {
+		t.Error("Expected error for an invalid pattern")
+	}
+}
+
+func TestFormatRepairReport(t *testing.T) {
+	report := &extractors.RepairReport{
+		Problems: []string{"can't find last xref section"},
+		Repairs:  []string{"rebuilt the cross-reference table from 13 objects found in the file"},
+	}
+	expected := "Problems found:\n  - can't find last xref section\nRepairs:\n  - rebuilt the cross-reference table from 13 objects found in the file\n"
+	if got := formatRepairReport(report); got != expected {
+		t.Errorf("formatRepairReport() = %q, expected %q", got, expected)
+	}
+
+	if got := formatRepairReport(&extractors.RepairReport{}); got != "No problems found\n" {
+		t.Errorf("formatRepairReport() for a valid PDF = %q", got)
+	}
+}
+
+func TestWriteTables(t *testing.T) {
+	tables := []extractors.Table{
+		{Page: 1, Rows: [][]string{{"Region", "Revenue"}, {"North", "4,800"}}},
+		{Page: 2, Rows: [][]string{{"A", "B"}}},
+	}
+	dir := t.TempDir()
+
+	paths, err := writeTables(tables, "csv", filepath.Join(dir, "tables.csv"))
+	if err != nil {
+		t.Fatalf("writeTables failed: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "tables_1.csv"), filepath.Join(dir, "tables_2.csv")}
+	if len(paths) != 2 || paths[0] != expected[0] || paths[1] != expected[1] {
+		t.Fatalf("writeTables() wrote %q, expected %q", paths, expected)
+	}
+	if data, _ := os.ReadFile(paths[0]); string(data) != "Region,Revenue\nNorth,\"4,800\"\n" {
+		t.Errorf("tables_1.csv = %q", data)
+	}
+
+	// A single table keeps the name it was given
+	paths, err = writeTables(tables[1:], "csv", filepath.Join(dir, "one.csv"))
+	if err != nil || len(paths) != 1 || filepath.Base(paths[0]) != "one.csv" {
+		t.Errorf("writeTables() for one table = %q, %v", paths, err)
+	}
+}
+
+func TestExtractPDFFileKeepsInput(t *testing.T) {
+	defer func(autoRepair, q bool) { pdfAutoRepair, quiet = autoRepair, q }(pdfAutoRepair, quiet)
+	pdfAutoRepair, quiet = true, true
+
+	// Neither extraction nor repair can read this, so the extract fails
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	data := []byte("<html>not found</html>")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, repairedPath, err := extractPDFFile(extractors.NewTextExtractor(), path)
+	if err == nil || repairedPath != "" {
+		t.Fatalf("extractPDFFile() = %q, %v, expected an error", repairedPath, err)
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != string(data) {
+		t.Errorf("Expected the input PDF to be left as it was after a failed extract, got %q, %v", got, err)
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	defer func(format, out string, links, q bool) {
+		pdfFormat, outputFile, pdfLinks, quiet = format, out, links, q
+	}(pdfFormat, outputFile, pdfLinks, quiet)
+
+	out := filepath.Join(t.TempDir(), "links.md")
+	rootCmd.SetArgs([]string{"pdf", "extract", "../internal/extractors/pdf/testdata/links.pdf", "--links", "--format", "markdown", "--output", out, "--quiet"})
+	defer rootCmd.SetArgs(nil)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("pdf extract --links failed: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "[Tide pools](https://example.com/tide-pools)") {
+		t.Errorf("Expected the link inlined as markdown, got %q", data)
+	}
+}
+
+func TestExtractPDFReader(t *testing.T) {
+	f, err := os.Open("../internal/extractors/pdf/testdata/text.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	text, err := extractPDFReader(extractors.NewTextExtractor(), f)
+	if err != nil {
+		t.Fatalf("extractPDFReader failed: %v", err)
+	}
+	if !strings.Contains(text, "Hello World") {
+		t.Errorf("Expected the text of the piped PDF, got %q", text)
+	}
+
+	if _, err := extractPDFReader(extractors.NewTextExtractor(), strings.NewReader("not a PDF")); err == nil {
+		t.Error("Expected piped bytes that are not a PDF to fail")
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maai.solutions/gengo/internal/export"
+	"maai.solutions/gengo/internal/extractors/asr"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/output"
+)
+
+var (
+	playlistOutputDir   string
+	playlistModel       string
+	playlistLanguage    string
+	playlistFFmpeg      string
+	playlistRetries     int
+	playlistTimeout     time.Duration
+	playlistCombine     bool
+	playlistCombineOnly bool
+)
+
+// playlistCmd represents the ytaudio playlist subcommand
+var playlistCmd = &cobra.Command{
+	Use:   "playlist [playlist-url]",
+	Short: "Transcribe every video of a YouTube playlist",
+	Long: `Transcribe the videos of a YouTube playlist one after another, in playlist
+order, writing each transcript to --output as a markdown file titled after
+the video. A video that cannot be transcribed is reported and skipped.
+
+With --combine a single markdown document named after the playlist is written
+as well, with each video as a chapter ("## Video Title") and a table of
+contents linking them, e.g. course notes from a lecture series.
+--combine-only writes just that document.
+
+--timeout applies to each video.
+
+Examples:
+  gengo ytaudio playlist "https://www.youtube.com/playlist?list=PL..."
+  gengo ytaudio playlist "https://www.youtube.com/playlist?list=PL..." --combine -o ./course
+  gengo ytaudio playlist "https://www.youtube.com/playlist?list=PL..." --combine-only --model small`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		playlistURL := args[0]
+
+		if !ytaudio.IsPlaylistURL(playlistURL) {
+			fmt.Fprintf(os.Stderr, "Error: Invalid YouTube playlist URL: %s\n", playlistURL)
+			fmt.Fprintln(os.Stderr, "Please provide a playlist URL (e.g., https://www.youtube.com/playlist?list=...)")
+			os.Exit(1)
+		}
+		language, err := asr.ValidateLanguage(playlistLanguage)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		modelPath := ytaudio.FindWhisperModel(playlistModel)
+		if modelPath == "" {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", asr.ModelNotFound(playlistModel))
+			fmt.Fprintf(os.Stderr, "Available models: %s\n", strings.Join(ytaudio.WhisperModels, ", "))
+			os.Exit(1)
+		}
+		combine := playlistCombine || playlistCombineOnly
+
+		opts := output.OutputOptions{
+			OutputDir:  playlistOutputDir,
+			Format:     output.FormatMarkdown,
+			SourceType: output.SourceTranscript,
+		}
+
+		if dryRun {
+			details := []string{"Whisper model: " + modelPath}
+			if combine {
+				details = append(details, "Combined: one markdown document with a chapter per video")
+			}
+			if playlistCombineOnly {
+				details = append(details, "Per-video transcripts: not written")
+			}
+			printPlan(output.Plan{
+				Action:  "download and transcribe every video of a YouTube playlist",
+				Source:  playlistURL,
+				Details: details,
+			}, opts)
+			return
+		}
+
+		asrConfig := asr.DefaultConfig()
+		asrConfig.WhisperModel = modelPath
+		asrConfig.Language = language
+		asrConfig.FFmpegPath = playlistFFmpeg
+		service := ytaudio.NewService(&ytaudio.Config{
+			OutputDir:    playlistOutputDir,
+			ASRConfig:    asrConfig,
+			CleanupFiles: true,
+			Retries:      playlistRetries,
+		})
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), playlistTimeout)
+		playlist, err := service.Playlist(ctx, playlistURL)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(playlist.Videos) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: Playlist %s has no videos\n", playlistURL)
+			os.Exit(1)
+		}
+
+		var chapters []export.Chapter
+		for i, video := range playlist.Videos {
+			if cmd.Context().Err() != nil {
+				break
+			}
+			statusf("[%d/%d] Transcribing %s\n", i+1, len(playlist.Videos), video.Title)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), playlistTimeout)
+			result, err := service.TranscribeYouTubeVideo(ctx, video.URL)
+			cancel()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error transcribing %s: %v\n", video.URL, err)
+				continue
+			}
+			if result.Partial {
+				fmt.Fprintf(os.Stderr, "Warning: transcription of %s timed out after %s of audio, the transcript is incomplete\n", video.URL, ytaudio.TranscribedUntil(result))
+			}
+
+			transcript := playlistTranscript(video, result)
+			chapters = append(chapters, export.Chapter{Title: transcript.Title, Source: video.URL, Content: transcript.Content})
+			if playlistCombineOnly {
+				continue
+			}
+			opts.Filename = transcriptFilename(video.URL)
+			path, err := output.Write(transcript, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing transcript: %v\n", err)
+				os.Exit(1)
+			}
+			statusf("Transcript saved to: %s\n", path)
+		}
+
+		if !combine {
+			return
+		}
+		if len(chapters) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: No video of the playlist was transcribed, nothing to combine")
+			os.Exit(1)
+		}
+		path := combinedPlaylistPath(playlistOutputDir, playlist)
+		if err := export.Write(path, playlistTitle(playlist), chapters, export.FormatMarkdown); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing combined transcript: %v\n", err)
+			os.Exit(1)
+		}
+		statusf("Combined %d of %d transcripts into: %s\n", len(chapters), len(playlist.Videos), path)
+	},
+}
+
+// playlistTranscript converts the transcription of a playlist video into a
+// result titled after the video
+func playlistTranscript(video ytaudio.PlaylistVideo, result *ytaudio.TranscriptionResult) output.Result {
+	transcript := ytaudio.TranscriptResult(video.URL, result)
+	if title := strings.TrimSpace(video.Title); title != "" {
+		transcript.Title = title
+	}
+	return transcript
+}
+
+// playlistTitle returns the title of a playlist, or its id when it has none
+func playlistTitle(playlist *ytaudio.Playlist) string {
+	if title := strings.TrimSpace(playlist.Title); title != "" {
+		return title
+	}
+	return "Playlist " + playlist.ID
+}
+
+// combinedPlaylistPath returns the path of the combined transcript of a
+// playlist in dir, named after the playlist
+func combinedPlaylistPath(dir string, playlist *ytaudio.Playlist) string {
+	return filepath.Join(dir, output.SanitizeFilename(playlistTitle(playlist))+export.FormatMarkdown.Extension())
+}
+
+func init() {
+	ytaudioCmd.AddCommand(playlistCmd)
+
+	playlistCmd.Flags().StringVarP(&playlistOutputDir, "output", "o", "./ytaudio_output", "Output directory for transcripts")
+	playlistCmd.Flags().StringVarP(&playlistModel, "model", "m", "base", "Whisper model to use (tiny, base, small, medium, large)")
+	playlistCmd.Flags().StringVarP(&playlistLanguage, "language", "l", asr.AutoLanguage, "Spoken language code (e.g. en, de, es), or auto to detect it")
+	playlistCmd.Flags().StringVar(&playlistFFmpeg, "ffmpeg", "", "Path to the ffmpeg binary (default: ffmpeg on PATH)")
+	playlistCmd.Flags().IntVar(&playlistRetries, "retries", ytaudio.DefaultConfig().Retries, "Number of times to retry an interrupted download")
+	playlistCmd.Flags().DurationVarP(&playlistTimeout, "timeout", "t", 30*time.Minute, "Timeout for each video")
+	playlistCmd.Flags().BoolVar(&playlistCombine, "combine", false, "Also write one markdown document with every video as a chapter and a table of contents")
+	playlistCmd.Flags().BoolVar(&playlistCombineOnly, "combine-only", false, "Write only the combined document, not a transcript per video")
+}
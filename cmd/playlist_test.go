@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"maai.solutions/gengo/internal/export"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+)
+
+func TestPlaylistTranscript(t *testing.T) {
+	video := ytaudio.PlaylistVideo{URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", Title: "Lecture 1: Limits"}
+	transcript := playlistTranscript(video, &ytaudio.TranscriptionResult{Text: "today we talk about limits"})
+	if transcript.Title != "Lecture 1: Limits" {
+		t.Errorf("Expected the video title, got %q", transcript.Title)
+	}
+	if transcript.Content != "today we talk about limits" {
+		t.Errorf("Unexpected content %q", transcript.Content)
+	}
+
+	// Untitled videos keep the title derived from the video id
+	video.Title = ""
+	if transcript := playlistTranscript(video, &ytaudio.TranscriptionResult{Text: "hello"}); !strings.Contains(transcript.Title, "dQw4w9WgXcQ") {
+		t.Errorf("Expected a title with the video id, got %q", transcript.Title)
+	}
+}
+
+func TestCombinedPlaylist(t *testing.T) {
+	playlist := &ytaudio.Playlist{ID: "PLabc", Title: "Calculus 101"}
+	if path := combinedPlaylistPath("out", playlist); filepath.Dir(path) != "out" || !strings.HasSuffix(path, ".md") || !strings.Contains(path, "Calculus") {
+		t.Errorf("Unexpected combined path %q", path)
+	}
+	if title := playlistTitle(&ytaudio.Playlist{ID: "PLabc"}); title != "Playlist PLabc" {
+		t.Errorf("Expected the playlist id as title, got %q", title)
+	}
+
+	// Chapters keep playlist order and are listed in the table of contents
+	combined := export.RenderMarkdown(playlistTitle(playlist), []export.Chapter{
+		{Title: "Lecture 1: Limits", Content: "limits"},
+		{Title: "Lecture 2: Derivatives", Content: "derivatives"},
+	})
+	for _, want := range []string{"# Calculus 101", "## Contents", "[Lecture 1: Limits](#", "## Lecture 2: Derivatives"} {
+		if !strings.Contains(combined, want) {
+			t.Errorf("Expected %q in the combined document:\n%s", want, combined)
+		}
+	}
+	if strings.Index(combined, "## Lecture 1") > strings.Index(combined, "## Lecture 2") {
+		t.Error("Expected the chapters in playlist order")
+	}
+}
@@ -0,0 +1,525 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maai.solutions/gengo/internal/export"
+	mdextractors "maai.solutions/gengo/internal/extractors/markdown"
+	extractors "maai.solutions/gengo/internal/extractors/pdf"
+	webextractors "maai.solutions/gengo/internal/extractors/web"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/project"
+	"maai.solutions/gengo/internal/ratelimit"
+	"maai.solutions/gengo/internal/text"
+)
+
+var (
+	exportOutputFile string
+	exportFormat     string
+	exportTitle      string
+	refreshTimeout   time.Duration
+	refreshRate      float64
+	refreshDelay     time.Duration
+	statsJSON        bool
+	statsKeywords    int
+)
+
+// projectCmd represents the project command
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Work with project folders of extracted content",
+	Long: `Work with project folders created by the --project option of the
+extraction commands.
+
+Every project folder has a manifest.json recording the source, title, file,
+extraction options and extraction time of each saved entry.
+
+Examples:
+  gengo project list ./my-project                     # Show extracted sources
+  gengo project refresh ./my-project                  # Re-extract changed sources
+  gengo project stats ./my-project                    # Summarize the corpus
+  gengo project export ./my-project                   # Bundle into my-project.epub
+  gengo project export ./my-project --format md       # Combine into my-project.md
+  gengo project export ./my-project -o book.epub      # Choose the output file`,
+}
+
+// projectExportCmd represents the project export subcommand
+var projectExportCmd = &cobra.Command{
+	Use:   "export [project-dir]",
+	Short: "Bundle a project folder into a single EPUB or markdown document",
+	Long: `Bundle the markdown files in a project folder into one readable document.
+
+Each markdown file becomes a chapter, in file name order, titled by its first
+heading. A table of contents linking every chapter is generated.
+
+Formats:
+- epub: an EPUB book with one chapter per file (default)
+- md:   a single concatenated markdown file`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectDir := filepath.Clean(args[0])
+
+		format, err := export.ParseFormat(exportFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		name := filepath.Base(projectDir)
+		if abs, err := filepath.Abs(projectDir); err == nil {
+			name = filepath.Base(abs)
+		}
+
+		title := exportTitle
+		if title == "" {
+			title = name
+		}
+
+		outputPath := exportOutputFile
+		if outputPath == "" {
+			outputPath = output.SanitizeFilename(name) + format.Extension()
+		}
+
+		chapters, err := export.CollectChapters(projectDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading project: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			plan := output.Plan{Action: fmt.Sprintf("export project as %s", format), Source: projectDir, Title: title}
+			for _, chapter := range chapters {
+				plan.Details = append(plan.Details, "Chapter: "+chapter.Title)
+			}
+			printPlan(plan, output.OutputOptions{OutputFile: outputPath, Format: output.Format(format)})
+			return
+		}
+
+		if err := export.Write(outputPath, title, chapters, format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting project: %v\n", err)
+			os.Exit(1)
+		}
+
+		statusf("✅ Exported %d chapters to: %s\n", len(chapters), outputPath)
+	},
+}
+
+// projectListCmd represents the project list subcommand
+var projectListCmd = &cobra.Command{
+	Use:   "list [project-dir]",
+	Short: "List the sources extracted into a project",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manifest, err := project.Load(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(manifest.Entries) == 0 {
+			statusf("No entries recorded in %s\n", filepath.Join(args[0], project.ManifestFile))
+			return
+		}
+
+		for _, entry := range manifest.Entries {
+			fmt.Println(entry.File)
+			fmt.Printf("  Title: %s\n", entry.Title)
+			fmt.Printf("  Source: %s\n", entry.Source)
+			fmt.Printf("  Extracted: %s\n", entry.ExtractedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+	},
+}
+
+// projectRefreshCmd represents the project refresh subcommand
+var projectRefreshCmd = &cobra.Command{
+	Use:   "refresh [project-dir]",
+	Short: "Re-extract project sources whose content has changed",
+	Long: `Re-extract every source recorded in a project's manifest and rewrite the
+entries whose content has changed since they were saved. Sources are extracted
+with the options recorded for them, such as --toc, --heading-offset, --limit,
+--links, --math and --only-text.
+
+YouTube transcripts are not refreshed, since re-transcribing is slow and the
+audio of a published video does not change. Requests to the same host are
+limited to --rate per second, with at least --delay between them.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectDir := filepath.Clean(args[0])
+
+		manifest, err := project.Load(projectDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), refreshTimeout)
+		defer cancel()
+
+		hostLimiter = ratelimit.New(refreshRate, refreshDelay)
+
+		failed := 0
+		for _, entry := range manifest.Entries {
+			status, err := refreshEntry(ctx, projectDir, entry)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %s: %v\n", entry.File, err)
+				failed++
+				continue
+			}
+			statusf("%s: %s\n", entry.File, status)
+		}
+
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// refreshEntry re-extracts one manifest entry, rewriting it when its content
+// changed, and returns a short description of what happened
+func refreshEntry(ctx context.Context, projectDir string, entry project.Entry) (string, error) {
+	kind := detectSourceKind(entry.Source)
+	switch kind {
+	case sourceUnknown:
+		return "skipped (no re-extractable source recorded)", nil
+	case sourceYouTube:
+		return "skipped (transcripts are not refreshed)", nil
+	}
+
+	if dryRun {
+		return fmt.Sprintf("would re-extract %s source %s", kind, entry.Source), nil
+	}
+
+	var saved project.Options
+	if entry.Options != nil {
+		saved = *entry.Options
+	}
+	format := output.Format(entry.Format)
+
+	result, err := reextract(ctx, entry.Source, kind, format, saved)
+	if err != nil {
+		return "", err
+	}
+	content := result.Content
+	if saved.BodyOnly {
+		// Written the way output.Write stores body-only markdown
+		content = strings.TrimLeft(content, "\n")
+	}
+	if project.HashContent(content) == entry.Hash {
+		return "unchanged", nil
+	}
+
+	// Rewrite the entry in place, whatever the current project layout
+	file := filepath.FromSlash(entry.File)
+	opts := output.OutputOptions{
+		ProjectName: filepath.Base(projectDir),
+		ProjectRoot: filepath.Dir(projectDir),
+		Subdir:      filepath.Dir(file),
+		Filename:    strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+		Format:      format,
+		BodyOnly:    saved.BodyOnly,
+		Extraction:  saved,
+	}
+	if _, err := output.Write(*result, opts); err != nil {
+		var dup *output.DuplicateError
+		if errors.As(err, &dup) {
+			return fmt.Sprintf("skipped (now identical to %s)", dup.File), nil
+		}
+		return "", err
+	}
+	return "updated", nil
+}
+
+// reextract extracts a source again with the options its manifest entry was
+// saved with, in the order the extraction commands apply them
+func reextract(ctx context.Context, source string, kind sourceKind, format output.Format, saved project.Options) (*output.Result, error) {
+	e := sourceExtractors().Get(string(kind))
+	if kind == sourceWeb {
+		e = &webextractors.PageExtractor{Options: webextractors.Options{
+			Links: webextractors.LinkStyle(saved.Links),
+			Math:  saved.Math,
+		}}
+	}
+	if e == nil {
+		return nil, fmt.Errorf("unsupported source: %s", source)
+	}
+
+	result, err := extractWith(ctx, e, source, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == sourcePDF && saved.Links != "" {
+		links, err := extractors.NewTextExtractor().GetLinks(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PDF links: %w", err)
+		}
+		result.Content = extractors.InlineLinks(result.Content, links)
+	}
+
+	unit := text.LimitWords
+	if saved.LimitUnit != "" {
+		if unit, err = text.ParseLimitUnit(saved.LimitUnit); err != nil {
+			return nil, err
+		}
+	}
+	*result = withHeadingOffset(withLimit(*result, saved.Limit, unit), format, saved.HeadingOffset)
+	*result = withTOC(*result, format, saved.TOC)
+	return result, nil
+}
+
+// projectStatsCmd represents the project stats subcommand
+var projectStatsCmd = &cobra.Command{
+	Use:   "stats [project-dir]",
+	Short: "Summarize the documents extracted into a project",
+	Long: `Summarize a project folder: the number of documents and words, documents
+per source type, the dates of the first and last extraction, and the top
+keyphrases across all documents.
+
+Documents are read from the project's manifest. Folders without one, such as
+those written with --dir, are summarized from their markdown files instead,
+dated by modification time.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		stats, err := collectProjectStats(filepath.Clean(args[0]), statsKeywords)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if statsJSON {
+			data, err := json.MarshalIndent(stats, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+		fmt.Print(formatProjectStats(stats))
+	},
+}
+
+// projectStats summarizes the documents of a project folder
+type projectStats struct {
+	Documents      int            `json:"documents"`
+	Missing        int            `json:"missing,omitempty"` // manifest entries whose file is gone
+	Words          int            `json:"words"`
+	Sources        map[string]int `json:"sources"` // documents per source type
+	FirstExtracted *time.Time     `json:"first_extracted,omitempty"`
+	LastExtracted  *time.Time     `json:"last_extracted,omitempty"`
+	Keywords       []string       `json:"keywords"`
+}
+
+// projectDocument is the text of one document in a project folder
+type projectDocument struct {
+	source      string
+	body        string
+	extractedAt time.Time
+}
+
+// collectProjectStats reads the documents of a project folder, from its
+// manifest when it has one and from its markdown files otherwise, and
+// aggregates them with the top n keyphrases
+func collectProjectStats(dir string, n int) (*projectStats, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open project folder: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("project path is not a directory: %s", dir)
+	}
+
+	manifest, err := project.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &projectStats{Sources: map[string]int{}}
+	var docs []projectDocument
+	if len(manifest.Entries) > 0 {
+		for _, entry := range manifest.Entries {
+			body, err := readEntryBody(dir, entry)
+			if errors.Is(err, fs.ErrNotExist) {
+				stats.Missing++
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, projectDocument{source: entry.Source, body: body, extractedAt: entry.ExtractedAt})
+		}
+	} else if docs, err = scanMarkdownDocuments(dir); err != nil {
+		return nil, err
+	}
+
+	var all strings.Builder
+	for _, doc := range docs {
+		stats.Documents++
+		stats.Words += text.TextStats(doc.body).Words
+		stats.Sources[detectSourceKind(doc.source).String()]++
+		if t := doc.extractedAt; !t.IsZero() {
+			if stats.FirstExtracted == nil || t.Before(*stats.FirstExtracted) {
+				stats.FirstExtracted = &t
+			}
+			if stats.LastExtracted == nil || t.After(*stats.LastExtracted) {
+				stats.LastExtracted = &t
+			}
+		}
+		all.WriteString(doc.body)
+		all.WriteString("\n\n")
+	}
+
+	stopwords, _ := text.StopwordsFor(all.String(), text.AutoLanguage)
+	stats.Keywords = text.KeywordsWithStopwords(all.String(), n, stopwords)
+	return stats, nil
+}
+
+// readEntryBody returns the extracted text of a manifest entry, without the
+// header markdown files start with or the JSON structure around it
+func readEntryBody(dir string, entry project.Entry) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(entry.File)))
+	if err != nil {
+		return "", err
+	}
+
+	switch output.Format(entry.Format) {
+	case output.FormatText:
+		return string(data), nil
+	case output.FormatJSON:
+		var result output.Result
+		if err := json.Unmarshal(data, &result); err == nil && result.Content != "" {
+			return result.Content, nil
+		}
+		return string(data), nil
+	default:
+		_, body := splitMarkdownHeader(string(data))
+		return body, nil
+	}
+}
+
+// scanMarkdownDocuments reads every markdown file below dir, taking the
+// source from its header and the date from its modification time
+func scanMarkdownDocuments(dir string) ([]projectDocument, error) {
+	var docs []projectDocument
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		source, body := splitMarkdownHeader(string(data))
+		docs = append(docs, projectDocument{source: source, body: body, extractedAt: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project files: %w", err)
+	}
+	return docs, nil
+}
+
+// splitMarkdownHeader separates a markdown file written by gengo into the
+// source named in its header and the body after the header's "---" rule.
+// Front matter and the title heading are dropped; files without a header
+// are returned whole.
+func splitMarkdownHeader(content string) (string, string) {
+	content = strings.TrimLeft(mdextractors.StripFrontMatter(content), "\n")
+	if !strings.HasPrefix(content, "# ") {
+		return "", content
+	}
+
+	source := ""
+	lines := strings.Split(content, "\n")
+	for i, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "---":
+			return source, strings.Join(lines[i+2:], "\n")
+		case strings.HasPrefix(line, "**Source:**"):
+			source = strings.TrimSpace(strings.TrimPrefix(line, "**Source:**"))
+		case line != "" && !strings.HasPrefix(line, "**"):
+			// Not a header block, just a document starting with a title
+			return "", content
+		}
+	}
+	return "", content
+}
+
+// formatProjectStats renders project statistics for the terminal
+func formatProjectStats(stats *projectStats) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Documents: %d\n", stats.Documents)
+	if stats.Missing > 0 {
+		fmt.Fprintf(&b, "Missing files: %d\n", stats.Missing)
+	}
+	minutes := (stats.Words + text.WordsPerMinute - 1) / text.WordsPerMinute
+	fmt.Fprintf(&b, "Words: %d (about %d min reading)\n", stats.Words, minutes)
+	if stats.FirstExtracted != nil {
+		fmt.Fprintf(&b, "Extracted: %s to %s\n", stats.FirstExtracted.Local().Format("2006-01-02"), stats.LastExtracted.Local().Format("2006-01-02"))
+	}
+
+	if len(stats.Sources) > 0 {
+		kinds := make([]string, 0, len(stats.Sources))
+		for kind := range stats.Sources {
+			kinds = append(kinds, kind)
+		}
+		sort.Slice(kinds, func(i, j int) bool {
+			if stats.Sources[kinds[i]] != stats.Sources[kinds[j]] {
+				return stats.Sources[kinds[i]] > stats.Sources[kinds[j]]
+			}
+			return kinds[i] < kinds[j]
+		})
+		b.WriteString("Sources:\n")
+		for _, kind := range kinds {
+			fmt.Fprintf(&b, "  %s: %d\n", kind, stats.Sources[kind])
+		}
+	}
+
+	if len(stats.Keywords) > 0 {
+		fmt.Fprintf(&b, "Top keywords: %s\n", strings.Join(stats.Keywords, ", "))
+	}
+	return b.String()
+}
+
+func init() {
+	// Add project command to root
+	rootCmd.AddCommand(projectCmd)
+
+	// Add subcommands to project
+	projectCmd.AddCommand(projectListCmd)
+	projectCmd.AddCommand(projectRefreshCmd)
+	projectCmd.AddCommand(projectExportCmd)
+	projectCmd.AddCommand(projectStatsCmd)
+
+	// Add flags to stats command
+	projectStatsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the statistics as JSON")
+	projectStatsCmd.Flags().IntVarP(&statsKeywords, "keywords", "n", 10, "Number of top keyphrases to list")
+
+	// Add flags to export command
+	projectExportCmd.Flags().StringVarP(&exportOutputFile, "output", "o", "", "Output file path (default: <project>.epub or <project>.md)")
+	projectExportCmd.Flags().StringVarP(&exportFormat, "format", "f", "epub", "Export format (epub, md)")
+	projectExportCmd.Flags().StringVar(&exportTitle, "title", "", "Document title (default: project folder name)")
+
+	// Add flags to refresh command
+	projectRefreshCmd.Flags().DurationVarP(&refreshTimeout, "timeout", "t", 30*time.Minute, "Timeout for the entire operation")
+	projectRefreshCmd.Flags().Float64Var(&refreshRate, "rate", ratelimit.DefaultRate, "Maximum requests per second to one host (0 for no limit)")
+	projectRefreshCmd.Flags().DurationVar(&refreshDelay, "delay", 0, "Minimum delay between requests to one host")
+}
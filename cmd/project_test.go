@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/project"
+	"maai.solutions/gengo/internal/text"
+)
+
+func TestRefreshEntry(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(source, []byte("# Notes\n\nfirst draft\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	result, err := extractSource(ctx, source, sourceMarkdown)
+	if err != nil {
+		t.Fatalf("extractSource failed: %v", err)
+	}
+	opts := output.OutputOptions{ProjectName: "proj", ProjectRoot: dir, Subdir: "docs", Format: output.FormatMarkdown}
+	if _, err := output.Write(*result, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	projectDir := filepath.Join(dir, "proj")
+	manifest, err := project.Load(projectDir)
+	if err != nil || len(manifest.Entries) != 1 {
+		t.Fatalf("Expected one manifest entry, got %+v (err %v)", manifest, err)
+	}
+	entry := manifest.Entries[0]
+	if entry.File != "docs/Notes.md" {
+		t.Fatalf("Expected the entry in its subfolder, got %q", entry.File)
+	}
+
+	if status, err := refreshEntry(ctx, projectDir, entry); err != nil || status != "unchanged" {
+		t.Errorf("Expected unchanged source, got %q (err %v)", status, err)
+	}
+
+	if err := os.WriteFile(source, []byte("# Notes\n\nsecond draft\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := refreshEntry(ctx, projectDir, entry); err != nil || status != "updated" {
+		t.Errorf("Expected updated source, got %q (err %v)", status, err)
+	}
+
+	// The entry is rewritten in place rather than by the current layout
+	data, err := os.ReadFile(filepath.Join(projectDir, "docs", "Notes.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "second draft") {
+		t.Errorf("Expected refreshed content, got:\n%s", data)
+	}
+
+	if status, _ := refreshEntry(ctx, projectDir, project.Entry{File: "x.md"}); !strings.HasPrefix(status, "skipped") {
+		t.Errorf("Expected entry without source to be skipped, got %q", status)
+	}
+}
+
+func TestCollectProjectStats(t *testing.T) {
+	dir := t.TempDir()
+	first := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	results := []struct {
+		result output.Result
+		format output.Format
+		at     time.Time
+	}{
+		{output.Result{Title: "Tides", Source: "https://example.com/tides", Content: "Tide pools hold sea anemones."}, output.FormatMarkdown, first},
+		{output.Result{Title: "Reefs", Source: "reefs.pdf", Content: "Coral reefs shelter sea anemones and fish."}, output.FormatJSON, first.AddDate(0, 1, 0)},
+	}
+	manifest := &project.Manifest{}
+	for _, r := range results {
+		data, err := output.Render(r.result, r.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := r.result.Title + r.format.Extension()
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		manifest.Put(project.Entry{Source: r.result.Source, Title: r.result.Title, File: file, Format: string(r.format), ExtractedAt: r.at})
+	}
+	manifest.Put(project.Entry{Source: "gone.pdf", File: "Gone.md", ExtractedAt: first})
+	if err := manifest.Save(dir, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := collectProjectStats(dir, 3)
+	if err != nil {
+		t.Fatalf("collectProjectStats failed: %v", err)
+	}
+	if stats.Documents != 2 || stats.Missing != 1 || stats.Words != 12 {
+		t.Errorf("Expected 2 documents, 1 missing and 12 words, got %+v", stats)
+	}
+	if stats.Sources["web"] != 1 || stats.Sources["pdf"] != 1 {
+		t.Errorf("Unexpected source counts %v", stats.Sources)
+	}
+	if !stats.FirstExtracted.Equal(first) || !stats.LastExtracted.Equal(first.AddDate(0, 1, 0)) {
+		t.Errorf("Unexpected date range %v to %v", stats.FirstExtracted, stats.LastExtracted)
+	}
+	if len(stats.Keywords) == 0 || !strings.Contains(strings.Join(stats.Keywords, ","), "sea anemones") {
+		t.Errorf("Expected sea anemones among the keywords, got %v", stats.Keywords)
+	}
+
+	formatted := formatProjectStats(stats)
+	for _, want := range []string{"Documents: 2\n", "Missing files: 1\n", "Words: 12 (about 1 min reading)\n", "  pdf: 1\n", "Top keywords: "} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("Expected %q in:\n%s", want, formatted)
+		}
+	}
+
+	// Without a manifest the markdown files are scanned
+	if err := os.Remove(filepath.Join(dir, project.ManifestFile)); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = collectProjectStats(dir, 3)
+	if err != nil {
+		t.Fatalf("collectProjectStats failed: %v", err)
+	}
+	if stats.Documents != 1 || stats.Words != 5 || stats.Sources["web"] != 1 || stats.FirstExtracted == nil {
+		t.Errorf("Unexpected stats from markdown files: %+v", stats)
+	}
+}
+
+func TestSplitMarkdownHeader(t *testing.T) {
+	source, body := splitMarkdownHeader("---\ntags: [\"a\"]\n---\n\n# Title\n\n**Source:** https://example.com  \n**author:** Ann  \n\n---\n\nBody text\n")
+	if source != "https://example.com" || body != "\nBody text\n" {
+		t.Errorf("Unexpected split %q, %q", source, body)
+	}
+
+	source, body = splitMarkdownHeader("# Notes\n\nPlain notes\n\n---\n\nMore\n")
+	if source != "" || body != "# Notes\n\nPlain notes\n\n---\n\nMore\n" {
+		t.Errorf("Expected a file without header to be returned whole, got %q, %q", source, body)
+	}
+}
+
+func TestRefreshEntryOptions(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "guide.md")
+	if err := os.WriteFile(source, []byte("# Guide\n\n## Setup\n\nfirst draft\n\n## Usage\n\nrun it\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saved as "gengo extract guide.md --toc --limit 20" would
+	ctx := context.Background()
+	result, err := extractSource(ctx, source, sourceMarkdown)
+	if err != nil {
+		t.Fatalf("extractSource failed: %v", err)
+	}
+	*result = withTOC(withLimit(*result, 20, text.LimitWords), output.FormatMarkdown, true)
+	opts := output.OutputOptions{
+		ProjectName: "proj",
+		ProjectRoot: dir,
+		Format:      output.FormatMarkdown,
+		Extraction:  extractionOptions(true, 0, 20, text.LimitWords),
+	}
+	if _, err := output.Write(*result, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	projectDir := filepath.Join(dir, "proj")
+	manifest, err := project.Load(projectDir)
+	if err != nil || len(manifest.Entries) != 1 {
+		t.Fatalf("Expected one manifest entry, got %+v (err %v)", manifest, err)
+	}
+	entry := manifest.Entries[0]
+	if entry.Options == nil || !entry.Options.TOC || entry.Options.Limit != 20 || entry.Options.LimitUnit != "words" {
+		t.Fatalf("Expected the extraction options in the manifest, got %+v", entry.Options)
+	}
+
+	if status, err := refreshEntry(ctx, projectDir, entry); err != nil || status != "unchanged" {
+		t.Errorf("Expected unchanged source, got %q (err %v)", status, err)
+	}
+
+	if err := os.WriteFile(source, []byte("# Guide\n\n## Setup\n\nsecond draft\n\n## Usage\n\nrun it\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := refreshEntry(ctx, projectDir, entry); err != nil || status != "updated" {
+		t.Errorf("Expected updated source, got %q (err %v)", status, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectDir, filepath.FromSlash(entry.File)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "second draft") || !strings.Contains(string(data), "- [Setup](#setup)") {
+		t.Errorf("Expected refreshed content with the saved options, got:\n%s", data)
+	}
+	if manifest, err = project.Load(projectDir); err != nil || manifest.Entries[0].Options == nil || !manifest.Entries[0].Options.TOC {
+		t.Errorf("Expected the options to be kept on refresh, got %+v (err %v)", manifest.Entries, err)
+	}
+}
@@ -1,15 +1,29 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	neturl "net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"maai.solutions/gengo/internal/httpclient"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/project"
+	"maai.solutions/gengo/internal/text"
 )
 
-var cfgFile string
+var (
+	cfgFile string
+	quiet   bool
+	dryRun  bool
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -18,20 +32,54 @@ var rootCmd = &cobra.Command{
 	Long: `GenGo is a comprehensive tool for testing generative AI systems using Go.
 This application provides various utilities and testing frameworks
 for evaluating and benchmarking AI models and systems.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// --quiet and --verbose pull in opposite directions, reject the combination
+		if quiet {
+			if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+				return fmt.Errorf("--quiet and --verbose cannot be used together")
+			}
+		}
+		if err := applyPermissions(); err != nil {
+			return err
+		}
+		if err := applyProjectLayout(); err != nil {
+			return err
+		}
+		if err := applyNameTemplates(); err != nil {
+			return err
+		}
+		if err := applyHTTPClient(); err != nil {
+			return err
+		}
+		return applyTagging()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Start Bubble Tea interactive CLI mode when no subcommands are provided
 		p := tea.NewProgram(initialModel())
-		if _, err := p.Run(); err != nil {
-			fmt.Printf("Error running interactive mode: %v\n", err)
+		final, err := p.Run()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error running interactive mode: %v\n", err)
 			os.Exit(1)
 		}
+
+		// Keep settings changed with /set for the next session
+		if m, ok := final.(model); ok {
+			if err := m.settings.save(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving settings: %v\n", err)
+				os.Exit(1)
+			}
+		}
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Commands receive a context that is cancelled on SIGINT or SIGTERM so they
+// can stop in-flight work and clean up before exiting.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
 		os.Exit(1)
 	}
@@ -45,6 +93,35 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gengo.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Validate inputs and show what would be done without downloading or writing anything")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress status messages; only print results to stdout and errors to stderr")
+	rootCmd.PersistentFlags().String("file-mode", fmt.Sprintf("%04o", output.DefaultFileMode), "Permissions for written files, in octal (e.g. 0600)")
+	rootCmd.PersistentFlags().String("dir-mode", fmt.Sprintf("%04o", output.DefaultDirMode), "Permissions for created directories, in octal (e.g. 0700)")
+
+	rootCmd.PersistentFlags().String("projects-dir", ".", "Directory holding the folders created by --project")
+	rootCmd.PersistentFlags().String("base-dir", "", "Only write output inside this directory, rejecting paths that lead out of it (for servers and automated runs)")
+	rootCmd.PersistentFlags().String("project-layout", "flat", "Subfolders for files saved to a project: flat, source, date or source,date")
+	rootCmd.PersistentFlags().String("name-template", "", "Name saved files with a template, e.g. \"{{.Date}}-{{.Title}}\" (fields: Title, Date, Time, Type, ID, Domain, N)")
+	rootCmd.PersistentFlags().Int("tags", defaultTagCount, "Number of keyword tags added as front matter to files saved in a project (0 to disable)")
+	rootCmd.PersistentFlags().String("tags-language", text.AutoLanguage, "Stopword language for project keyword tags, or auto to detect it per document")
+
+	rootCmd.PersistentFlags().Duration("http-timeout", httpclient.DefaultTimeout, "Time allowed to connect to a server and receive its response headers (0 for no limit)")
+	rootCmd.PersistentFlags().String("proxy", "", "Proxy URL for all requests, http, https or socks5 (default: from HTTP_PROXY and HTTPS_PROXY)")
+	rootCmd.PersistentFlags().String("user-agent", httpclient.DefaultUserAgent, "User-Agent header sent with requests")
+	rootCmd.PersistentFlags().Int("max-connections", httpclient.DefaultMaxConcurrent, "Maximum requests in flight at once across all hosts (0 for no limit)")
+
+	// Permissions can also be set with file-mode/dir-mode in the config file
+	viper.BindPFlag("file-mode", rootCmd.PersistentFlags().Lookup("file-mode"))
+	viper.BindPFlag("dir-mode", rootCmd.PersistentFlags().Lookup("dir-mode"))
+	viper.BindPFlag("projects-dir", rootCmd.PersistentFlags().Lookup("projects-dir"))
+	viper.BindPFlag("base-dir", rootCmd.PersistentFlags().Lookup("base-dir"))
+	viper.BindPFlag("project-layout", rootCmd.PersistentFlags().Lookup("project-layout"))
+	viper.BindPFlag("name-template", rootCmd.PersistentFlags().Lookup("name-template"))
+	viper.BindPFlag("tags", rootCmd.PersistentFlags().Lookup("tags"))
+	viper.BindPFlag("tags-language", rootCmd.PersistentFlags().Lookup("tags-language"))
+	for _, key := range []string{"http-timeout", "proxy", "user-agent", "max-connections"} {
+		viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(key))
+	}
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -73,11 +150,258 @@ func initConfig() {
 	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
+	if err := viper.ReadInConfig(); err == nil && !quiet {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
 
+// applyPermissions sets the output file and directory permissions from
+// --file-mode and --dir-mode or the matching config keys
+func applyPermissions() error {
+	fileMode, err := output.ParseMode(viper.GetString("file-mode"))
+	if err != nil {
+		return fmt.Errorf("--file-mode: %w", err)
+	}
+	dirMode, err := output.ParseMode(viper.GetString("dir-mode"))
+	if err != nil {
+		return fmt.Errorf("--dir-mode: %w", err)
+	}
+
+	output.FileMode = fileMode
+	output.DirMode = dirMode
+	return nil
+}
+
+// applyProjectLayout sets where project folders live and how files are
+// arranged inside them from --projects-dir and --project-layout, and the
+// directory output is confined to from --base-dir, or the matching config keys
+func applyProjectLayout() error {
+	layout, err := output.ParseLayout(viper.GetString("project-layout"))
+	if err != nil {
+		return fmt.Errorf("--project-layout: %w", err)
+	}
+	baseDir := viper.GetString("base-dir")
+	if baseDir != "" {
+		if info, err := os.Stat(baseDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("--base-dir: %s is not an existing directory", baseDir)
+		}
+	}
+
+	output.ProjectsDir = viper.GetString("projects-dir")
+	output.ProjectLayout = layout
+	output.BaseDir = baseDir
+	return nil
+}
+
+// applyNameTemplates sets how saved files are named from --name-template,
+// or the name-template config key, and the name-templates config map
+// overriding it per source type, e.g. web: "{{.Domain}}-{{.Title}}"
+func applyNameTemplates() error {
+	templates := map[string]*output.NameTemplate{}
+	if text := viper.GetString("name-template"); text != "" {
+		t, err := output.ParseNameTemplate(text)
+		if err != nil {
+			return fmt.Errorf("--name-template: %w", err)
+		}
+		templates[""] = t
+	}
+	for sourceType, text := range viper.GetStringMapString("name-templates") {
+		t, err := output.ParseNameTemplate(text)
+		if err != nil {
+			return fmt.Errorf("name-templates.%s: %w", sourceType, err)
+		}
+		templates[strings.ToLower(sourceType)] = t
+	}
+
+	output.NameTemplates = nil
+	if len(templates) > 0 {
+		output.NameTemplates = templates
+	}
+	return nil
+}
+
+// applyHTTPClient configures the client shared by every network feature from
+// --http-timeout, --proxy, --user-agent and --max-connections or the matching
+// config keys
+func applyHTTPClient() error {
+	client, err := httpclient.NewHTTPClient(httpclient.Options{
+		Timeout:       viper.GetDuration("http-timeout"),
+		Proxy:         viper.GetString("proxy"),
+		UserAgent:     viper.GetString("user-agent"),
+		MaxConcurrent: viper.GetInt("max-connections"),
+	})
+	if err != nil {
+		return err
+	}
+	httpclient.Default = client
+	return nil
+}
+
+// defaultTagCount is how many keyphrases tag a file saved in a project
+const defaultTagCount = 5
+
+// applyTagging configures the keyword tags written to project files from
+// --tags and --tags-language or the matching config keys
+func applyTagging() error {
+	count := viper.GetInt("tags")
+	if count <= 0 {
+		output.Tagger = nil
+		return nil
+	}
+
+	// Validate the language now rather than on the first save
+	language := viper.GetString("tags-language")
+	if _, err := text.StopwordsFor("", language); err != nil {
+		return fmt.Errorf("--tags-language: %w", err)
+	}
+	output.Tagger = func(content string) []string {
+		keywords, _ := text.KeywordsForLanguage(content, count, language)
+		return keywords
+	}
+	return nil
+}
+
+// printPlan reports what a command would do under --dry-run and exits on failure
+func printPlan(plan output.Plan, opts output.OutputOptions) {
+	if err := output.WritePlan(plan, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// reportDuplicate tells the user a result was skipped because the project
+// already holds identical content, and reports whether err was such a duplicate
+func reportDuplicate(err error) bool {
+	var dup *output.DuplicateError
+	if !errors.As(err, &dup) {
+		return false
+	}
+	statusf("⏭️  Skipped duplicate: identical content is already saved as %s (use --force to save anyway)\n", dup.File)
+	return true
+}
+
+// withTOC inserts a table of contents into markdown results when --toc is
+// set. Other formats are left unchanged.
+func withTOC(result output.Result, format output.Format, toc bool) output.Result {
+	if toc && (format == output.FormatMarkdown || format == "") {
+		result.Content = text.InsertTOC(result.Content)
+	}
+	return result
+}
+
+// withLimit cuts the content of results to the first --limit words or
+// characters, at a sentence boundary. A limit of 0 leaves them unchanged.
+func withLimit(result output.Result, limit int, unit text.LimitUnit) output.Result {
+	result.Content = text.Limit(result.Content, limit, unit)
+	return result
+}
+
+// extractionOptions records --toc, --heading-offset and --limit for the
+// project manifest, so "project refresh" applies them again
+func extractionOptions(toc bool, headingOffset, limit int, unit text.LimitUnit) project.Options {
+	opts := project.Options{TOC: toc, HeadingOffset: headingOffset, Limit: limit}
+	if limit > 0 {
+		opts.LimitUnit = string(unit)
+	}
+	return opts
+}
+
+// parseLimit rejects a negative --limit and validates --limit-unit
+func parseLimit(limit int, unit string) (text.LimitUnit, error) {
+	if limit < 0 {
+		return "", fmt.Errorf("invalid limit %d: must be 0 or more", limit)
+	}
+	return text.ParseLimitUnit(unit)
+}
+
+// withHeadingOffset demotes the headings of markdown results by
+// --heading-offset levels so they can be embedded under other headings.
+// Other formats are left unchanged.
+func withHeadingOffset(result output.Result, format output.Format, offset int) output.Result {
+	if offset > 0 && (format == output.FormatMarkdown || format == "") {
+		result.Content = text.ShiftHeadings(result.Content, offset)
+	}
+	return result
+}
+
+// checkHeadingOffset rejects a negative --heading-offset
+func checkHeadingOffset(offset int) error {
+	if offset < 0 {
+		return fmt.Errorf("invalid heading offset %d: must be 0 or more", offset)
+	}
+	return nil
+}
+
+// withAutoTitle replaces a missing or generic title with one derived from the
+// content when --auto-title is set, so saved files get meaningful names
+func withAutoTitle(result output.Result, autoTitle, generic bool) output.Result {
+	if !autoTitle || (!generic && strings.TrimSpace(result.Title) != "") {
+		return result
+	}
+	if title := text.DeriveTitle(result.Content); title != "" {
+		result.Title = title
+	}
+	return result
+}
+
+// withAutoProject saves a result to a project named after where it came from
+// when --auto-project is set: the channel of a YouTube transcript, or else
+// the site the source URL is on. An explicit --project always wins, and
+// sources without either, such as local files, keep their destination.
+func withAutoProject(opts output.OutputOptions, autoProject bool, result output.Result) output.OutputOptions {
+	if !autoProject || opts.ProjectName != "" {
+		return opts
+	}
+	if name := autoProjectName(result); name != "" {
+		opts.ProjectName = name
+	}
+	return opts
+}
+
+// autoProjectName derives a project folder name from a result's channel or
+// source domain, without its www. prefix, or returns "" when it has neither
+func autoProjectName(result output.Result) string {
+	if channel := strings.TrimSpace(result.Metadata["Channel"]); channel != "" {
+		return output.SanitizeFilename(channel)
+	}
+	u, err := neturl.Parse(result.Source)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return output.SanitizeFilename(strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."))
+}
+
+// stdinArg in place of a URL or file reads the input from stdin
+const stdinArg = "-"
+
+// stdinSource is the source recorded for input read from stdin
+const stdinSource = "stdin"
+
+// statusf prints a decorative status message unless --quiet is set
+func statusf(format string, a ...interface{}) {
+	if quiet {
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
+// noticef prints a status message to stderr unless --quiet is set, for
+// commands whose results may be going to stdout
+func noticef(format string, a ...interface{}) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, a...)
+}
+
+// statusln prints a decorative status line unless --quiet is set
+func statusln(a ...interface{}) {
+	if quiet {
+		return
+	}
+	fmt.Println(a...)
+}
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"maai.solutions/gengo/internal/output"
+)
+
+func TestQuietVerboseConflict(t *testing.T) {
+	defer func() {
+		quiet = false
+		webVerbose = false
+	}()
+
+	quiet = true
+	if err := rootCmd.PersistentPreRunE(webExtractCmd, nil); err != nil {
+		t.Errorf("Expected --quiet alone to be accepted, got: %v", err)
+	}
+
+	if err := webExtractCmd.Flags().Set("verbose", "true"); err != nil {
+		t.Fatalf("Failed to set verbose flag: %v", err)
+	}
+	if err := rootCmd.PersistentPreRunE(webExtractCmd, nil); err == nil {
+		t.Error("Expected error when combining --quiet and --verbose")
+	}
+
+	quiet = false
+	if err := rootCmd.PersistentPreRunE(webExtractCmd, nil); err != nil {
+		t.Errorf("Expected --verbose alone to be accepted, got: %v", err)
+	}
+}
+
+func TestApplyPermissions(t *testing.T) {
+	defer func() {
+		viper.Set("file-mode", nil)
+		viper.Set("dir-mode", nil)
+		output.FileMode = output.DefaultFileMode
+		output.DirMode = output.DefaultDirMode
+	}()
+
+	if err := applyPermissions(); err != nil {
+		t.Fatalf("Expected defaults to be accepted, got: %v", err)
+	}
+	if output.FileMode != 0644 || output.DirMode != 0755 {
+		t.Errorf("Expected default modes, got %o and %o", output.FileMode, output.DirMode)
+	}
+
+	viper.Set("file-mode", "0600")
+	viper.Set("dir-mode", "700")
+	if err := applyPermissions(); err != nil {
+		t.Fatalf("applyPermissions failed: %v", err)
+	}
+	if output.FileMode != 0600 || output.DirMode != 0700 {
+		t.Errorf("Expected 0600 and 0700, got %o and %o", output.FileMode, output.DirMode)
+	}
+
+	viper.Set("dir-mode", "rwx")
+	if err := applyPermissions(); err == nil {
+		t.Error("Expected error for an invalid dir mode")
+	}
+}
+
+func TestApplyProjectLayout(t *testing.T) {
+	defer func() {
+		viper.Set("projects-dir", nil)
+		viper.Set("project-layout", nil)
+		output.ProjectsDir = "."
+		output.ProjectLayout = output.Layout{}
+	}()
+
+	viper.Set("projects-dir", "/srv/projects")
+	viper.Set("project-layout", "source,date")
+	if err := applyProjectLayout(); err != nil {
+		t.Fatalf("applyProjectLayout failed: %v", err)
+	}
+	if output.ProjectsDir != "/srv/projects" || output.ProjectLayout != (output.Layout{BySource: true, ByDate: true}) {
+		t.Errorf("Unexpected project settings: %q %+v", output.ProjectsDir, output.ProjectLayout)
+	}
+
+	viper.Set("project-layout", "weekly")
+	if err := applyProjectLayout(); err == nil {
+		t.Error("Expected error for an unknown layout")
+	}
+}
+
+func TestApplyNameTemplates(t *testing.T) {
+	defer func() {
+		viper.Set("name-template", nil)
+		viper.Set("name-templates", nil)
+		output.NameTemplates = nil
+	}()
+
+	viper.Set("name-template", "{{.Date}}-{{.Title}}")
+	viper.Set("name-templates", map[string]interface{}{"Web": "{{.Domain}}-{{.Title}}"})
+	if err := applyNameTemplates(); err != nil {
+		t.Fatalf("applyNameTemplates failed: %v", err)
+	}
+	if got := output.NameTemplateFor(output.SourceWeb); got == nil || got.String() != "{{.Domain}}-{{.Title}}" {
+		t.Errorf("Expected the web template for web pages, got %v", got)
+	}
+	if got := output.NameTemplateFor(output.SourcePDF); got == nil || got.String() != "{{.Date}}-{{.Title}}" {
+		t.Errorf("Expected the default template for PDFs, got %v", got)
+	}
+	if transcriptFilename("https://youtu.be/abc123") != "" {
+		t.Error("Expected transcripts to be named by the template")
+	}
+
+	viper.Set("name-template", "{{.Author}}")
+	if err := applyNameTemplates(); err == nil {
+		t.Error("Expected error for an unknown template field")
+	}
+
+	viper.Set("name-template", nil)
+	viper.Set("name-templates", nil)
+	if err := applyNameTemplates(); err != nil || output.NameTemplates != nil {
+		t.Errorf("Expected no templates, got %v %v", output.NameTemplates, err)
+	}
+}
+
+func TestApplyTagging(t *testing.T) {
+	defer func() {
+		viper.Set("tags", nil)
+		viper.Set("tags-language", nil)
+		output.Tagger = nil
+	}()
+
+	viper.Set("tags", 2)
+	viper.Set("tags-language", "en")
+	if err := applyTagging(); err != nil {
+		t.Fatalf("applyTagging failed: %v", err)
+	}
+	if output.Tagger == nil {
+		t.Fatal("Expected a tagger to be configured")
+	}
+	if tags := output.Tagger("Solar panels convert sunlight. Solar panels are cheap."); len(tags) != 2 {
+		t.Errorf("Expected 2 tags, got %q", tags)
+	}
+
+	viper.Set("tags", 0)
+	if err := applyTagging(); err != nil || output.Tagger != nil {
+		t.Errorf("Expected --tags 0 to disable tagging, got %v", err)
+	}
+
+	viper.Set("tags", 3)
+	viper.Set("tags-language", "xx")
+	if err := applyTagging(); err == nil {
+		t.Error("Expected error for a language without stopwords")
+	}
+}
+
+func TestWithAutoTitle(t *testing.T) {
+	untitled := output.Result{Content: "Quarterly results are in. Revenue grew."}
+	if got := withAutoTitle(untitled, false, false); got.Title != "" {
+		t.Errorf("Expected no title without --auto-title, got %q", got.Title)
+	}
+	if got := withAutoTitle(untitled, true, false); got.Title != "Quarterly results are in" {
+		t.Errorf("Expected derived title, got %q", got.Title)
+	}
+
+	titled := output.Result{Title: "Report", Content: "# Heading\n\nText"}
+	if got := withAutoTitle(titled, true, false); got.Title != "Report" {
+		t.Errorf("Expected existing title to be kept, got %q", got.Title)
+	}
+	if got := withAutoTitle(titled, true, true); got.Title != "Heading" {
+		t.Errorf("Expected generic title to be replaced, got %q", got.Title)
+	}
+}
+
+func TestWithAutoProject(t *testing.T) {
+	page := output.Result{Source: "https://WWW.Example.com:8080/blog/post"}
+	transcript := output.Result{Source: "https://youtu.be/abc", Metadata: map[string]string{"Channel": "Tide/Pool Talks"}}
+
+	tests := []struct {
+		name    string
+		opts    output.OutputOptions
+		auto    bool
+		result  output.Result
+		project string
+	}{
+		{"disabled", output.OutputOptions{}, false, page, ""},
+		{"site", output.OutputOptions{}, true, page, "example.com"},
+		{"channel", output.OutputOptions{}, true, transcript, "Tide-Pool Talks"},
+		{"explicit project wins", output.OutputOptions{ProjectName: "research"}, true, page, "research"},
+		{"local file", output.OutputOptions{}, true, output.Result{Source: "notes.md"}, ""},
+		{"stdin", output.OutputOptions{}, true, output.Result{Source: stdinSource}, ""},
+	}
+	for _, test := range tests {
+		if got := withAutoProject(test.opts, test.auto, test.result).ProjectName; got != test.project {
+			t.Errorf("%s: expected project %q, got %q", test.name, test.project, got)
+		}
+	}
+}
+
+func TestWithHeadingOffset(t *testing.T) {
+	result := output.Result{Content: "# Intro\n\n##### Deep"}
+	if got := withHeadingOffset(result, output.FormatMarkdown, 2); got.Content != "### Intro\n\n###### Deep" {
+		t.Errorf("Expected shifted headings, got %q", got.Content)
+	}
+	if got := withHeadingOffset(result, output.FormatText, 2); got.Content != result.Content {
+		t.Errorf("Expected text output unchanged, got %q", got.Content)
+	}
+	if err := checkHeadingOffset(-1); err == nil {
+		t.Error("Expected error for a negative heading offset")
+	}
+}
+
+func TestWithLimit(t *testing.T) {
+	result := output.Result{Content: "First sentence here. Second sentence follows."}
+	unit, err := parseLimit(4, "words")
+	if err != nil {
+		t.Fatalf("parseLimit failed: %v", err)
+	}
+	if got := withLimit(result, 4, unit); got.Content != "First sentence here. …" {
+		t.Errorf("Expected the first sentence, got %q", got.Content)
+	}
+	if got := withLimit(result, 0, unit); got.Content != result.Content {
+		t.Errorf("Expected no limit to keep the content, got %q", got.Content)
+	}
+	if _, err := parseLimit(-1, "words"); err == nil {
+		t.Error("Expected error for a negative limit")
+	}
+	if _, err := parseLimit(10, "pages"); err == nil {
+		t.Error("Expected error for an unknown limit unit")
+	}
+}
+
+func TestApplyTaggingDetectsLanguage(t *testing.T) {
+	defer func() {
+		viper.Set("tags", nil)
+		viper.Set("tags-language", nil)
+		output.Tagger = nil
+	}()
+
+	viper.Set("tags", 3)
+	viper.Set("tags-language", "auto")
+	if err := applyTagging(); err != nil {
+		t.Fatalf("applyTagging failed: %v", err)
+	}
+	for _, tag := range output.Tagger("音声認識の精度は、学習データの量に依存します。音声認識は難しい。") {
+		if strings.ContainsAny(tag, " はのに") {
+			t.Errorf("Expected Japanese tags split into words, got %q", tag)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/server"
+)
+
+var (
+	serveAddr      string
+	serveModel     string
+	serveFFmpeg    string
+	servePerSeg    bool
+	serveMaxUpload int64
+	serveTimeout   time.Duration
+	serveWorkers   int
+	serveMaxJobs   int
+	serveCallbacks []string
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP server exposing extraction as an API",
+	Long: `Run gengo as an extraction service with a JSON API.
+
+Endpoints:
+  GET  /health        Health check
+  POST /extract/web   Extract a web page; body {"url": "..."} or the bare URL
+  POST /extract/pdf   Extract text from an uploaded PDF (multipart field "file")
+  POST /transcribe    Transcribe a YouTube URL, or an uploaded audio file
+                      (multipart field "file")
+  POST /jobs/transcribe  Transcribe a YouTube URL in the background; body
+                        {"url": "...", "callback": "..."}
+  GET  /jobs/{id}     Status and result of a background job
+  GET  /metrics       Prometheus metrics (builds with -tags metrics only)
+
+Transcriptions share loaded whisper models: --transcribers of them run at
+once, each with a model of its own, and further requests wait their turn.
+Every model takes its full size in memory. At most --max-jobs background
+jobs are queued or running at once, and stopping the server cancels them.
+
+Jobs POST their result to a callback URL only on a host allowed with
+--callback-host, so clients cannot make the server call internal services.
+Without one, jobs are checked with GET /jobs/{id} instead.
+
+Results are returned as JSON with title, source and content fields; errors
+as {"error": "..."} with a matching HTTP status.
+
+The API has no authentication and fetches any URL it is given, so it listens
+on 127.0.0.1 by default. Serve other machines with --addr only behind a proxy
+or network that controls who can reach it.
+
+Examples:
+  gengo serve                                   # Listen on 127.0.0.1:8080
+  gengo serve --addr 127.0.0.1:9000 --model small
+  gengo serve --callback-host hooks.example.com
+  curl -d '{"url":"https://example.com"}' localhost:8080/extract/web
+  curl -F file=@report.pdf localhost:8080/extract/pdf`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config := server.DefaultConfig()
+		config.MaxUploadBytes = serveMaxUpload
+		config.Timeout = serveTimeout
+		config.ASRConfig.FFmpegPath = serveFFmpeg
+		config.ASRConfig.PerSegmentLanguage = servePerSeg
+		config.Transcribers = serveWorkers
+		config.MaxJobs = serveMaxJobs
+		config.CallbackHosts = serveCallbacks
+		if modelPath := ytaudio.FindWhisperModel(serveModel); modelPath != "" {
+			config.ASRConfig.WhisperModel = modelPath
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: Whisper model '%s' not found; /transcribe will fail until it is installed\n", serveModel)
+		}
+
+		statusf("🚀 Serving extraction API on %s\n", serveAddr)
+		srv := server.New(config)
+		err := srv.ListenAndServe(cmd.Context(), serveAddr)
+		srv.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on (the API has no authentication; :8080 listens on every interface)")
+	serveCmd.Flags().StringVarP(&serveModel, "model", "m", "base", "Whisper model for /transcribe (tiny, base, small, medium, large)")
+	serveCmd.Flags().StringVar(&serveFFmpeg, "ffmpeg", "", "Path to the ffmpeg binary (default: ffmpeg on PATH)")
+	serveCmd.Flags().BoolVar(&servePerSeg, "per-segment-language", false, "Detect the language of every 30 seconds of audio separately in /transcribe")
+	serveCmd.Flags().IntVar(&serveWorkers, "transcribers", server.DefaultConfig().Transcribers, "Transcriptions to run at once, each loading its own whisper model")
+	serveCmd.Flags().IntVar(&serveMaxJobs, "max-jobs", server.DefaultConfig().MaxJobs, "Background jobs to accept at once, queued or running")
+	serveCmd.Flags().StringArrayVar(&serveCallbacks, "callback-host", nil, "Host (optionally host:port) that job callbacks may be sent to (repeatable; default: no callbacks)")
+	serveCmd.Flags().Int64Var(&serveMaxUpload, "max-upload", server.DefaultConfig().MaxUploadBytes, "Maximum upload size in bytes")
+	serveCmd.Flags().DurationVarP(&serveTimeout, "timeout", "t", server.DefaultConfig().Timeout, "Timeout for a single extraction")
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/output"
+)
+
+// sessionConfigKey is the config file section holding interactive settings
+const sessionConfigKey = "interactive"
+
+// sessionSetting describes one setting accepted by /set
+type sessionSetting struct {
+	key   string
+	usage string
+	unset string // shown by /show when the setting is empty
+}
+
+// sessionSettingList lists the settings accepted by /set, in /show order
+var sessionSettingList = []sessionSetting{
+	{"model", "Whisper model for ytaudio transcribe (tiny, base, small, medium, large)", "base"},
+	{"language", "Transcription language code, e.g. en or de", "auto-detect"},
+	{"output", "Directory where transcripts and pdf/web results are saved", "./transcripts for transcripts, others are shown here"},
+	{"project", "Project that pdf/web results are saved to", "none"},
+}
+
+// sessionSettings holds the interactive settings changed with /set. They
+// start from the interactive section of the config file and are written back
+// on exit when changed.
+type sessionSettings struct {
+	values map[string]string
+	dirty  bool
+}
+
+// loadSessionSettings reads the interactive settings from the config file
+func loadSessionSettings() *sessionSettings {
+	s := &sessionSettings{values: map[string]string{}}
+	for _, setting := range sessionSettingList {
+		if value := viper.GetString(sessionConfigKey + "." + setting.key); value != "" {
+			s.values[setting.key] = value
+		}
+	}
+	return s
+}
+
+// get returns the value of a setting, or "" when it is unset
+func (s *sessionSettings) get(key string) string {
+	return s.values[key]
+}
+
+// set changes a setting, or resets it when value is empty
+func (s *sessionSettings) set(key, value string) error {
+	if !isSessionKey(key) {
+		return fmt.Errorf("unknown setting %q (available: %s)", key, strings.Join(sessionKeys(), ", "))
+	}
+
+	value = strings.TrimSpace(value)
+	if key == "model" && value != "" && ytaudio.FindWhisperModel(value) == "" {
+		return fmt.Errorf("whisper model '%s' not found", value)
+	}
+	if key == "language" && strings.EqualFold(value, "auto") {
+		value = ""
+	}
+
+	if s.values[key] != value {
+		s.dirty = true
+	}
+	if value == "" {
+		delete(s.values, key)
+	} else {
+		s.values[key] = value
+	}
+	return nil
+}
+
+// show lists every setting with its current value
+func (s *sessionSettings) show() string {
+	var b strings.Builder
+	b.WriteString("Current settings:")
+	for _, setting := range sessionSettingList {
+		value := s.values[setting.key]
+		if value == "" {
+			value = "(" + setting.unset + ")"
+		}
+		fmt.Fprintf(&b, "\n  %-9s %s", setting.key, value)
+	}
+	return b.String()
+}
+
+// outputOptions fills in the output and project settings for a pdf or web
+// result when the command named no destination itself
+func (s *sessionSettings) outputOptions(outputFile, projectName string, format output.Format) output.OutputOptions {
+	opts := output.OutputOptions{OutputFile: outputFile, ProjectName: projectName, Format: format}
+	if outputFile == "" && projectName == "" {
+		opts.ProjectName = s.get("project")
+		opts.OutputDir = s.get("output")
+	}
+	return opts
+}
+
+// save writes changed settings to the config file, leaving its other keys
+// untouched. Without a config file ~/.gengo.yaml is created.
+func (s *sessionSettings) save() error {
+	if !s.dirty {
+		return nil
+	}
+
+	path, err := sessionConfigPath()
+	if err != nil {
+		return err
+	}
+
+	// A separate instance keeps flag values out of the file
+	v := viper.New()
+	v.SetConfigFile(path)
+	if filepath.Ext(path) == "" {
+		v.SetConfigType("yaml")
+	}
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	settings := map[string]interface{}{}
+	for key, value := range s.values {
+		settings[key] = value
+	}
+	v.Set(sessionConfigKey, settings)
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// sessionConfigPath returns the config file settings are saved to
+func sessionConfigPath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".gengo.yaml"), nil
+}
+
+// sessionKeys returns the names of the settings accepted by /set
+func sessionKeys() []string {
+	keys := make([]string, 0, len(sessionSettingList))
+	for _, setting := range sessionSettingList {
+		keys = append(keys, setting.key)
+	}
+	return keys
+}
+
+func isSessionKey(key string) bool {
+	for _, setting := range sessionSettingList {
+		if setting.key == key {
+			return true
+		}
+	}
+	return false
+}
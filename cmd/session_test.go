@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"maai.solutions/gengo/internal/output"
+)
+
+func TestSessionSettings(t *testing.T) {
+	s := &sessionSettings{values: map[string]string{}}
+
+	if err := s.set("output", "./out"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if err := s.set("language", "auto"); err != nil || s.get("language") != "" {
+		t.Errorf("Expected auto to clear the language, got %q (err %v)", s.get("language"), err)
+	}
+	if err := s.set("colour", "blue"); err == nil {
+		t.Error("Expected an error for an unknown setting")
+	}
+	if err := s.set("model", "no-such-model"); err == nil {
+		t.Error("Expected an error for a missing whisper model")
+	}
+	if !s.dirty {
+		t.Error("Expected a change to mark the settings dirty")
+	}
+
+	show := s.show()
+	if !strings.Contains(show, "./out") || !strings.Contains(show, "(auto-detect)") {
+		t.Errorf("Unexpected /show output:\n%s", show)
+	}
+
+	// Settings apply only when the command names no destination
+	opts := s.outputOptions("", "", output.FormatMarkdown)
+	if opts.OutputDir != "./out" {
+		t.Errorf("Expected the output setting to be used, got %+v", opts)
+	}
+	opts = s.outputOptions("page.md", "", output.FormatMarkdown)
+	if opts.OutputDir != "" || opts.OutputFile != "page.md" {
+		t.Errorf("Expected an explicit file to win, got %+v", opts)
+	}
+
+	if err := s.set("output", ""); err != nil || s.get("output") != "" {
+		t.Errorf("Expected an empty value to reset the setting, got %q (err %v)", s.get("output"), err)
+	}
+}
+
+func TestSessionSettingsSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gengo.yaml")
+	if err := os.WriteFile(path, []byte("tags: 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(file string) { cfgFile = file }(cfgFile)
+	cfgFile = path
+
+	s := &sessionSettings{values: map[string]string{}}
+	if err := s.set("project", "research"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if v.GetString("interactive.project") != "research" {
+		t.Errorf("Expected the project setting to be saved, got %v", v.AllSettings())
+	}
+	if v.GetInt("tags") != 3 {
+		t.Errorf("Expected other config keys to be kept, got %v", v.AllSettings())
+	}
+	if len(v.AllKeys()) != 2 {
+		t.Errorf("Expected only the existing key and the setting, got %v", v.AllKeys())
+	}
+}
@@ -1,20 +1,56 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	neturl "net/url"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	extractors "maai.solutions/gengo/internal/extractors/web"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/text"
 )
 
 var (
-	webOutputFile  string
-	webOutputDir   string
-	webProjectName string
-	webVerbose     bool
+	webOutputFile   string
+	webOutputDir    string
+	webProjectName  string
+	webFormat       string
+	webSkipTags     []string
+	webContentTags  []string
+	webMaxBytes     int64
+	webTruncate     bool
+	webAllowStatus  bool
+	webAltText      bool
+	webInlineLinks  bool
+	webLinksList    bool
+	webArticleBody  bool
+	webPreferAMP    bool
+	webStripPhrases []string
+	webMaxDepth     int
+	webMath         bool
+	webStripMeta    bool
+	webStats        bool
+	webTOC          bool
+	webHeadingOff   int
+	webLimit        int
+	webLimitUnit    string
+	webAutoTitle    bool
+	webAutoProject  bool
+	webOnlyText     bool
+	webAppend       bool
+	webForce        bool
+	webNoCache      bool
+	webRefresh      bool
+	webCacheTTL     time.Duration
+	webVerbose      bool
+	webDiffSince    string
+	webSaveRaw      bool
+	webGzipRaw      bool
 )
 
 // webCmd represents the web command
@@ -30,12 +66,16 @@ Examples:
   gengo web extract https://example.com                     # Extract to stdout
   gengo web extract https://example.com --output page.md    # Save to file
   gengo web extract https://example.com --project my-proj   # Save to project folder
-  gengo web extract https://example.com --dir ./web-content # Save to custom directory`,
+  gengo web extract https://example.com --dir ./web-content # Save to custom directory
+  gengo web extract https://example.com --format json       # Output as JSON
+  gengo web extract https://example.com --format html -o page.html # Archive as single-file HTML
+  curl -s https://example.com | gengo web extract -         # Extract HTML piped in
+  gengo web extract https://example.com --skip-tags script,style,nav,header,footer,aside,form`,
 }
 
 // webExtractCmd represents the extract subcommand
 var webExtractCmd = &cobra.Command{
-	Use:   "extract [url]",
+	Use:   "extract [url|-]",
 	Short: "Extract content from a web page",
 	Long: `Extract content from a web page and output as clean markdown.
 
@@ -51,84 +91,568 @@ Options:
 - Save to specific file with --output
 - Save to project folder with --project
 - Save to custom directory with --dir
+- Add to an existing file instead of overwriting it with --append
+- Choose text, markdown or JSON output with --format, or html to archive a
+  self-contained snapshot with stylesheets and images inlined
+- Customize skipped and content elements with --skip-tags and --content-tags
+- Limit download size with --max-bytes, optionally truncating with --truncate
+- Extract error pages (non-2xx responses) with --allow-status
+- Images are written as markdown images with their alt text and figure
+  captions as italic lines; keep just the alt text with --alt-text-only
+- Add word count and estimated reading time with --stats
+- Keep links as [text](url) with --inline-links, or number them [n] and list
+  their targets in a References section at the end with --include-links-list
+- Pages describing themselves with JSON-LD structured data get its headline
+  as the title and its authors and publication date in the header; JSON
+  output includes the structured data. Use its articleBody, often cleaner than
+  the page markup, as the content with --article-body
+- Extract the AMP version a page links, usually free of boilerplate, with
+  --prefer-amp; the page itself is used when it has none. The canonical URL a
+  page declares is recorded in the header
+- Insert a linked table of contents into markdown output with --toc
+- Demote headings with --heading-offset N (h1 becomes h3 for 2) to embed the
+  page under your own headings
+- Drop boilerplate lines such as newsletter sign-ups, cookie banners and
+  share buttons; replace the built-in patterns with --strip-phrases, or pass
+  --strip-phrases "" to keep every line
+- Leave out deeply nested widgets with --max-depth N, which extracts only
+  elements up to N levels below the outermost content element (article, main,
+  p and the other --content-tags)
+- Keep equations with --math: MathML and formula images with LaTeX alt text
+  are written as $...$ inline or $$...$$ display math
+- Save to a project named after the site, e.g. example.com, with
+  --auto-project; an explicit --project takes precedence
+- Name untitled pages after their first heading or sentence with --auto-title
+- Print just the extracted content without the title and source header with
+  --only-text, e.g. when piping into an LLM
+- Read the page from stdin instead of downloading it by passing - as the URL,
+  e.g. curl -s https://example.com | gengo web extract -. PDF documents piped
+  in are recognized; --format html and --prefer-amp need a URL
+- Remove EXIF data such as camera details and GPS location from the images
+  inlined by --format html with --strip-metadata
+- Reuse extractions cached in ~/.cache/gengo/web for --cache-ttl; bypass the
+  cache with --no-cache or re-download and replace the entry with --refresh
+- Keep the downloaded HTML next to the saved file, e.g. Title.html beside
+  Title.md, with --save-raw, to re-run extraction later without fetching the
+  page again; --gzip-raw compresses it to Title.html.gz. The page is always
+  downloaded, since the cache keeps only extractions, and projects record the
+  raw file in their manifest
 - Verbose output with --verbose`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		url := args[0]
 
-		// Validate URL (basic check)
-		if !isValidURL(url) {
-			fmt.Printf("Error: Invalid URL: %s\n", url)
-			fmt.Println("Please provide a valid URL (e.g., https://example.com)")
+		format, err := parseWebFormat(webFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := checkHeadingOffset(webHeadingOff); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		limitUnit, err := parseLimit(webLimit, webLimitUnit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := extractors.CompileStripPhrases(webStripPhrases); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := checkMaxDepth(webMaxDepth); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if webStripMeta && format != output.FormatHTML {
+			fmt.Fprintln(os.Stderr, "Error: --strip-metadata only applies to --format html")
+			os.Exit(1)
+		}
+		webSaveRaw = webSaveRaw || webGzipRaw
+		if webSaveRaw && format == output.FormatHTML {
+			fmt.Fprintln(os.Stderr, "Error: --save-raw does not apply to --format html, which saves the page itself")
+			os.Exit(1)
+		}
+		if webSaveRaw && webOutputFile == "" && webOutputDir == "" && webProjectName == "" && !webAutoProject {
+			fmt.Fprintln(os.Stderr, "Error: --save-raw needs a file to save next to: use --output, --dir or --project")
 			os.Exit(1)
 		}
 
+		fromStdin := url == stdinArg
+		if fromStdin {
+			// Piped input has nothing to fetch assets or other versions from
+			if format == output.FormatHTML || webPreferAMP {
+				fmt.Fprintln(os.Stderr, "Error: --format html and --prefer-amp need a URL, not stdin")
+				os.Exit(1)
+			}
+			url = stdinSource
+		} else {
+			// Validate URL (basic check)
+			if !isValidURL(url) {
+				fmt.Fprintf(os.Stderr, "Error: Invalid URL: %s\n", url)
+				fmt.Fprintln(os.Stderr, "Please provide a valid URL (e.g., https://example.com)")
+				os.Exit(1)
+			}
+			url = normalizeURL(url)
+		}
+
+		outputOpts := output.OutputOptions{
+			OutputFile:  webOutputFile,
+			OutputDir:   webOutputDir,
+			ProjectName: webProjectName,
+			Format:      format,
+			Append:      webAppend,
+			Force:       webForce,
+			BodyOnly:    webOnlyText,
+			SourceType:  output.SourceWeb,
+			SaveRaw:     webSaveRaw,
+			GzipRaw:     webGzipRaw,
+			Extraction:  extractionOptions(webTOC, webHeadingOff, webLimit, limitUnit),
+		}
+		outputOpts.Extraction.Links = string(webLinkStyle())
+		outputOpts.Extraction.Math = webMath
+
+		if dryRun {
+			action := "fetch and extract web page"
+			if fromStdin {
+				action = "extract web page from stdin"
+			}
+			var details []string
+			if webGzipRaw {
+				details = append(details, "Raw page: saved gzipped next to the output")
+			} else if webSaveRaw {
+				details = append(details, "Raw page: saved next to the output")
+			}
+			outputOpts = withAutoProject(outputOpts, webAutoProject, output.Result{Source: url})
+			printPlan(output.Plan{Action: action, Source: url, Details: details}, outputOpts)
+			return
+		}
+
 		if webVerbose {
 			fmt.Printf("Extracting content from: %s\n", url)
 		}
 
 		// Extract content from web page
-		title, content, err := extractors.DownloadAndExtract(url)
+		opts := extractors.Options{
+			SkipTags:     webSkipTags,
+			ContentTags:  webContentTags,
+			MaxBytes:     webMaxBytes,
+			Truncate:     webTruncate,
+			AllowStatus:  webAllowStatus,
+			AltTextOnly:  webAltText,
+			Links:        webLinkStyle(),
+			ArticleBody:  webArticleBody,
+			PreferAMP:    webPreferAMP,
+			StripPhrases: webStripPhrases,
+			MaxDepth:     webMaxDepth,
+			Math:         webMath,
+
+			StripMetadata: webStripMeta,
+		}
+		if format == output.FormatHTML {
+			result, err := archiveResult(cmd.Context(), url, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error archiving page: %v\n", err)
+				os.Exit(1)
+			}
+			writeWebResult(result, withAutoProject(outputOpts, webAutoProject, result))
+			return
+		}
+
+		var page *extractors.Cached
+		if fromStdin {
+			page, err = readWebPage(os.Stdin, opts)
+		} else {
+			page, err = extractWebPage(cmd.Context(), url, opts)
+		}
 		if err != nil {
-			fmt.Printf("Error extracting content: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error extracting content: %v\n", err)
 			os.Exit(1)
 		}
+		title, content := page.Title, page.Content
 
 		if webVerbose {
 			fmt.Printf("Page title: %s\n", title)
 			fmt.Printf("Content length: %d characters\n", len(content))
+			if page.Coverage.Visible > 0 {
+				fmt.Printf("Coverage: %s\n", page.Coverage)
+				if page.Coverage.Low() {
+					fmt.Println("Little of the page was extracted; try --content-tags, --skip-tags or --article-body")
+				}
+			}
 		}
 
 		// Handle output based on specified options
-		if webProjectName != "" {
-			// Save to project structure
-			err := extractors.SaveToProject(title, content, webProjectName)
-			if err != nil {
-				fmt.Printf("Error saving to project: %v\n", err)
-				os.Exit(1)
+		result := output.NewResult(output.SourceWeb, url, title, content)
+
+		if webStats {
+			stats := text.TextStats(content)
+			result.Metadata = map[string]string{
+				"Words":        fmt.Sprintf("%d", stats.Words),
+				"Reading time": fmt.Sprintf("%d min", stats.ReadingMinutes()),
+			}
+			if webVerbose {
+				fmt.Printf("Word count: %d\n", stats.Words)
+				fmt.Printf("Reading time: ~%d min\n", stats.ReadingMinutes())
 			}
+		}
+		result = withAutoTitle(withLimit(result, webLimit, limitUnit), webAutoTitle, false)
+		result = withHeadingOffset(result, format, webHeadingOff)
+		result = withTOC(result, format, webTOC)
+		if webSaveRaw {
+			result.Raw, result.RawExt = page.Raw, page.RawExt()
+		}
+		writeWebResult(withPageMeta(result, page), withAutoProject(outputOpts, webAutoProject, result))
+	},
+}
 
-			projectPath := filepath.Join(".", webProjectName, fmt.Sprintf("%s.md", title))
-			fmt.Printf("✅ Content extracted and saved to project!\n")
-			fmt.Printf("File: %s\n", projectPath)
+// webDiffCmd represents the diff subcommand
+var webDiffCmd = &cobra.Command{
+	Use:   "diff [url]",
+	Short: "Show how a web page changed since it was last extracted",
+	Long: `Extract a web page and print a unified diff of its content against the
+previous extraction stored in the cache.
 
-		} else if webOutputFile != "" {
-			// Save to specific file
-			err := os.WriteFile(webOutputFile, []byte(content), 0644)
-			if err != nil {
-				fmt.Printf("Error writing to file %s: %v\n", webOutputFile, err)
-				os.Exit(1)
-			}
-			fmt.Printf("✅ Content extracted and saved to: %s\n", webOutputFile)
+Each time a page is extracted with different content, the cache keeps the
+replaced version as a snapshot (up to 20 per page), so changes can be
+followed over time. By default the page is compared with the latest cached
+extraction; use --since to compare with the newest snapshot taken at or
+before a date (2006-01-02) or time (RFC 3339).
 
-		} else if webOutputDir != "" {
-			// Save to custom directory
-			if err := os.MkdirAll(webOutputDir, 0755); err != nil {
-				fmt.Printf("Error creating output directory: %v\n", err)
-				os.Exit(1)
-			}
+The first diff of an uncached page saves it as the baseline for the next one.
+Extraction options such as --skip-tags must match those used when the page
+was cached.
 
-			filename := fmt.Sprintf("%s.md", title)
-			outputPath := filepath.Join(webOutputDir, filename)
+Like diff(1), the command exits with status 0 when the content is unchanged,
+1 when it changed and 2 on errors, so it can drive change-monitoring scripts.
 
-			err := os.WriteFile(outputPath, []byte(content), 0644)
-			if err != nil {
-				fmt.Printf("Error writing to file %s: %v\n", outputPath, err)
-				os.Exit(1)
+Examples:
+  gengo web diff https://example.com/docs
+  gengo web diff https://example.com/docs --since 2024-05-01
+  gengo web diff https://example.com/docs > docs.diff || notify-send "docs changed"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		url := args[0]
+
+		if !isValidURL(url) {
+			fmt.Fprintf(os.Stderr, "Error: Invalid URL: %s\n", url)
+			os.Exit(2)
+		}
+		url = normalizeURL(url)
+
+		var since time.Time
+		if webDiffSince != "" {
+			var err error
+			if since, err = parseSince(webDiffSince); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(2)
 			}
-			fmt.Printf("✅ Content extracted and saved to: %s\n", outputPath)
+		}
+		if _, err := extractors.CompileStripPhrases(webStripPhrases); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		if err := checkMaxDepth(webMaxDepth); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
 
-		} else {
-			// Output to stdout
-			fmt.Print(content)
+		if dryRun {
+			printPlan(output.Plan{Action: "fetch web page and diff it against the cached extraction", Source: url}, output.OutputOptions{})
+			return
+		}
+
+		opts := extractors.Options{
+			SkipTags:     webSkipTags,
+			ContentTags:  webContentTags,
+			MaxBytes:     webMaxBytes,
+			Truncate:     webTruncate,
+			AllowStatus:  webAllowStatus,
+			AltTextOnly:  webAltText,
+			Links:        webLinkStyle(),
+			ArticleBody:  webArticleBody,
+			PreferAMP:    webPreferAMP,
+			StripPhrases: webStripPhrases,
+			MaxDepth:     webMaxDepth,
+			Math:         webMath,
+		}
+		cache := extractors.NewCache(webCacheTTL)
+
+		// Pick the baseline before the new extraction replaces it
+		snapshots, err := cache.Snapshots(url, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading cache: %v\n", err)
+			os.Exit(2)
+		}
+		baseline, err := pickSnapshot(snapshots, since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+
+		current, err := cache.Extract(cmd.Context(), url, opts, true)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error extracting content: %v\n", err)
+			os.Exit(2)
+		}
+		if current.Truncated {
+			fmt.Fprintf(os.Stderr, "Warning: page exceeds %d bytes, content was truncated and not cached\n", opts.MaxBytes)
+		}
+
+		if baseline == nil {
+			statusf("No previous extraction of %s; saved the current version as the baseline\n", url)
+			return
 		}
+
+		from := fmt.Sprintf("%s\t%s", url, baseline.FetchedAt.Local().Format("2006-01-02 15:04:05"))
+		to := fmt.Sprintf("%s\t%s", url, time.Now().Format("2006-01-02 15:04:05"))
+		diff := text.UnifiedDiff(baseline.Content, current.Content, from, to)
+		if diff == "" {
+			statusf("No changes since %s\n", baseline.FetchedAt.Local().Format("2006-01-02 15:04:05"))
+			return
+		}
+		fmt.Print(diff)
+		os.Exit(1)
 	},
 }
 
-// isValidURL performs basic URL validation
+// parseSince parses a --since value given as a date or an RFC 3339 time. A
+// bare date means the end of that day, local time.
+func parseSince(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a date (2006-01-02) or RFC 3339 time", value)
+}
+
+// pickSnapshot returns the newest snapshot fetched at or before since, or the
+// newest of all when since is zero. It returns nil without error when there
+// are no snapshots at all.
+func pickSnapshot(snapshots []extractors.CacheEntry, since time.Time) (*extractors.CacheEntry, error) {
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	if since.IsZero() {
+		return &snapshots[len(snapshots)-1], nil
+	}
+
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if !snapshots[i].FetchedAt.After(since) {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no snapshot taken at or before %s; the oldest is from %s",
+		since.Local().Format("2006-01-02 15:04:05"), snapshots[0].FetchedAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+// checkMaxDepth rejects a negative --max-depth
+func checkMaxDepth(depth int) error {
+	if depth < 0 {
+		return fmt.Errorf("invalid max depth %d: must be 0 or more", depth)
+	}
+	return nil
+}
+
+// webLinkStyle returns the link style selected by --inline-links or
+// --include-links-list
+func webLinkStyle() extractors.LinkStyle {
+	switch {
+	case webInlineLinks:
+		return extractors.LinksInline
+	case webLinksList:
+		return extractors.LinksReferences
+	}
+	return extractors.LinksNone
+}
+
+// parseWebFormat accepts the shared output formats plus html, which saves a
+// self-contained snapshot of the page instead of extracted text
+func parseWebFormat(name string) (output.Format, error) {
+	if strings.EqualFold(strings.TrimSpace(name), string(output.FormatHTML)) {
+		return output.FormatHTML, nil
+	}
+	return output.ParseFormat(name)
+}
+
+// extractWebPage downloads and extracts a page, going through the on-disk
+// cache unless --no-cache is set
+func extractWebPage(ctx context.Context, url string, opts extractors.Options) (*extractors.Cached, error) {
+	if webNoCache {
+		page, err := extractors.FetchPageContext(ctx, url, opts)
+		if err != nil {
+			return nil, err
+		}
+		if page.Truncated {
+			fmt.Fprintf(os.Stderr, "Warning: page exceeds %d bytes, content was truncated\n", len(page.Body))
+		}
+		source := extractors.FollowAMP(ctx, page, opts)
+		title, content, meta, err := extractors.ExtractPageData(source, opts)
+		if err != nil {
+			return nil, err
+		}
+		cached := &extractors.Cached{Title: title, Content: content, Structured: meta.Structured, Canonical: meta.Canonical, Coverage: meta.Coverage, Truncated: source.Truncated, Raw: source.Body, RawType: source.ContentType}
+		if source != page {
+			cached.AMP = source.URL
+		}
+		return cached, nil
+	}
+
+	// The cache keeps no page bodies, so saving one needs a download
+	cached, err := extractors.NewCache(webCacheTTL).Extract(ctx, url, opts, webRefresh || webSaveRaw)
+	if err != nil {
+		return nil, err
+	}
+	if cached.Truncated {
+		fmt.Fprintf(os.Stderr, "Warning: page exceeds %d bytes, content was truncated\n", opts.MaxBytes)
+	}
+	if cached.Hit && webVerbose {
+		fmt.Println("Using cached extraction")
+	}
+	return cached, nil
+}
+
+// readWebPage extracts a page read from r rather than downloaded. It never
+// touches the network or the cache.
+func readWebPage(r io.Reader, opts extractors.Options) (*extractors.Cached, error) {
+	page, err := extractors.ReadPage(r, "", opts)
+	if err != nil {
+		return nil, err
+	}
+	if page.Truncated {
+		fmt.Fprintf(os.Stderr, "Warning: page exceeds %d bytes, content was truncated\n", len(page.Body))
+	}
+
+	title, content, meta, err := extractors.ExtractPageData(page, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &extractors.Cached{Title: title, Content: content, Structured: meta.Structured, Canonical: meta.Canonical, Coverage: meta.Coverage, Truncated: page.Truncated, Raw: page.Body, RawType: page.ContentType}, nil
+}
+
+// webJSON is the JSON output of a page that declares more about itself
+type webJSON struct {
+	output.Result
+	CanonicalURL   string                     `json:"canonical_url,omitempty"`
+	AMPURL         string                     `json:"amp_url,omitempty"`
+	StructuredData *extractors.StructuredData `json:"structured_data,omitempty"`
+	Coverage       *extractors.Coverage       `json:"coverage,omitempty"`
+}
+
+// withPageMeta records what an extracted page declares about itself: the
+// authors and publication date of its JSON-LD, its canonical URL when it
+// differs from the source and the AMP version the content came from. They
+// go into the header, and JSON output also includes the structured data and
+// how much of the page's visible text was extracted. Pages with none of
+// these are returned unchanged.
+func withPageMeta(result output.Result, page *extractors.Cached) output.Result {
+	canonical := page.Canonical
+	if canonical == result.Source {
+		canonical = ""
+	}
+	data := page.Structured
+	var coverage *extractors.Coverage
+	if page.Coverage.Visible > 0 {
+		coverage = &page.Coverage
+	}
+	if data == nil && canonical == "" && page.AMP == "" && coverage == nil {
+		return result
+	}
+
+	metadata := map[string]string{}
+	for key, value := range result.Metadata {
+		metadata[key] = value
+	}
+	if data != nil && len(data.Authors) > 0 {
+		metadata["Author"] = strings.Join(data.Authors, ", ")
+	}
+	if data != nil && data.DatePublished != "" {
+		metadata["Published"] = data.DatePublished
+	}
+	if canonical != "" {
+		metadata["Canonical"] = canonical
+	}
+	if page.AMP != "" {
+		metadata["AMP version"] = page.AMP
+	}
+	if len(metadata) > 0 {
+		result.Metadata = metadata
+	}
+
+	result.Data = webJSON{Result: result, CanonicalURL: canonical, AMPURL: page.AMP, StructuredData: data, Coverage: coverage}
+	return result
+}
+
+// archiveResult fetches a page as a single-file HTML snapshot
+func archiveResult(ctx context.Context, url string, opts extractors.Options) (output.Result, error) {
+	data, err := extractors.ArchiveHTMLContext(ctx, url, opts)
+	if err != nil {
+		return output.Result{}, err
+	}
+
+	title, _, err := extractors.ExtractContent(string(data))
+	if err != nil {
+		return output.Result{}, err
+	}
+	return output.NewResult(output.SourceWeb, url, title, string(data)), nil
+}
+
+// writeWebResult writes an extracted page to each destination and reports
+// where it went
+func writeWebResult(result output.Result, opts output.OutputOptions) {
+	paths, err := output.WriteAll(result, opts)
+	for _, path := range paths {
+		statusf("✅ Content extracted and saved to: %s\n", path)
+		if opts.SaveRaw && len(result.Raw) > 0 {
+			statusf("📄 Raw page saved to: %s\n", output.RawPath(result, opts, path))
+		}
+	}
+	if err != nil {
+		if reportDuplicate(err) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseHTTPURL parses an absolute http(s) URL and rejects anything without a host
+func parseHTTPURL(rawURL string) (*neturl.URL, error) {
+	u, err := neturl.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("URL has no host")
+	}
+
+	return u, nil
+}
+
+// isValidURL reports whether url is an absolute http(s) URL with a host
 func isValidURL(url string) bool {
-	url = strings.TrimSpace(url)
-	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+	_, err := parseHTTPURL(url)
+	return err == nil
+}
+
+// normalizeURL trims surrounding whitespace and lowercases the scheme and host
+func normalizeURL(url string) string {
+	u, err := parseHTTPURL(url)
+	if err != nil {
+		return strings.TrimSpace(url)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return u.String()
 }
 
 func init() {
@@ -137,10 +661,60 @@ func init() {
 
 	// Add subcommands to web
 	webCmd.AddCommand(webExtractCmd)
+	webCmd.AddCommand(webDiffCmd)
 
 	// Add flags to extract command
 	webExtractCmd.Flags().StringVarP(&webOutputFile, "output", "o", "", "Output file path (default: stdout)")
 	webExtractCmd.Flags().StringVarP(&webOutputDir, "dir", "d", "", "Output directory path")
 	webExtractCmd.Flags().StringVarP(&webProjectName, "project", "p", "", "Project name (creates project folder structure)")
+	webExtractCmd.Flags().BoolVar(&webAutoProject, "auto-project", false, "Save to a project named after the page's site when --project is not given")
+	webExtractCmd.Flags().StringVarP(&webFormat, "format", "f", "markdown", "Output format (text, markdown, json, html)")
+	webExtractCmd.Flags().StringSliceVar(&webSkipTags, "skip-tags", extractors.DefaultSkipTags, "HTML elements whose text is skipped")
+	webExtractCmd.Flags().StringSliceVar(&webContentTags, "content-tags", extractors.DefaultContentTags, "HTML elements whose text is extracted as content")
+	webExtractCmd.Flags().Int64Var(&webMaxBytes, "max-bytes", extractors.DefaultMaxBytes, "Maximum page size to download in bytes")
+	webExtractCmd.Flags().BoolVar(&webTruncate, "truncate", false, "Truncate pages larger than --max-bytes instead of failing")
+	webExtractCmd.Flags().BoolVar(&webAllowStatus, "allow-status", false, "Extract pages returned with a non-2xx HTTP status")
+	webExtractCmd.Flags().BoolVar(&webAltText, "alt-text-only", false, "Write image alt text as plain text instead of markdown images")
+	webExtractCmd.Flags().BoolVar(&webInlineLinks, "inline-links", false, "Keep links in the content as [text](url)")
+	webExtractCmd.Flags().BoolVar(&webLinksList, "include-links-list", false, "Mark links with [n] and list their targets in a References section")
+	webExtractCmd.MarkFlagsMutuallyExclusive("inline-links", "include-links-list")
+	webExtractCmd.Flags().BoolVar(&webArticleBody, "article-body", false, "Use the JSON-LD articleBody as the content when the page provides one")
+	webExtractCmd.Flags().BoolVar(&webPreferAMP, "prefer-amp", false, "Extract the AMP version the page links to, when it has one")
+	webExtractCmd.Flags().StringArrayVar(&webStripPhrases, "strip-phrases", nil, "Regular expression for boilerplate lines to remove, replacing the built-in list (repeatable; empty to keep all lines)")
+	webExtractCmd.Flags().IntVar(&webMaxDepth, "max-depth", 0, "Extract only elements up to this many levels below the outermost content element (0 for no limit)")
+	webExtractCmd.Flags().BoolVar(&webMath, "math", false, "Write MathML and equation images as LaTeX ($...$ inline, $$...$$ display)")
+	webExtractCmd.Flags().BoolVar(&webStripMeta, "strip-metadata", false, "Remove EXIF and other metadata from images inlined by --format html")
+	webExtractCmd.Flags().BoolVar(&webAppend, "append", false, "Append to the output file instead of overwriting it")
+	webExtractCmd.Flags().BoolVar(&webForce, "force", false, "Save to the project even if identical content is already saved")
+	webExtractCmd.Flags().BoolVar(&webStats, "stats", false, "Include word count and estimated reading time")
+	webExtractCmd.Flags().BoolVar(&webAutoTitle, "auto-title", false, "Derive a title from the content when the page has none")
+	webExtractCmd.Flags().BoolVar(&webOnlyText, "only-text", false, "Output only the content, without the title and source header (markdown output)")
+	webExtractCmd.Flags().BoolVar(&webTOC, "toc", false, "Insert a linked table of contents (markdown output)")
+	webExtractCmd.Flags().IntVar(&webHeadingOff, "heading-offset", 0, "Demote every heading by N levels, clamped at h6 (markdown output)")
+	webExtractCmd.Flags().IntVar(&webLimit, "limit", 0, "Keep only the first N words or characters of the content, ending at a sentence (0 for all)")
+	webExtractCmd.Flags().StringVar(&webLimitUnit, "limit-unit", "words", "What --limit counts (words, chars)")
+	webExtractCmd.Flags().BoolVar(&webNoCache, "no-cache", false, "Neither read nor write the extraction cache")
+	webExtractCmd.Flags().BoolVar(&webRefresh, "refresh", false, "Download the page even if it is cached, replacing the cache entry")
+	webExtractCmd.Flags().DurationVar(&webCacheTTL, "cache-ttl", extractors.DefaultCacheTTL, "How long cached extractions are used before revalidating")
+	webExtractCmd.Flags().BoolVar(&webSaveRaw, "save-raw", false, "Also save the downloaded HTML next to the output file, named after it")
+	webExtractCmd.Flags().BoolVar(&webGzipRaw, "gzip-raw", false, "Save the downloaded HTML gzipped (implies --save-raw)")
 	webExtractCmd.Flags().BoolVarP(&webVerbose, "verbose", "v", false, "Verbose output")
+
+	// The diff command shares the extraction options, which are part of the
+	// cache key
+	webDiffCmd.Flags().StringSliceVar(&webSkipTags, "skip-tags", extractors.DefaultSkipTags, "HTML elements whose text is skipped")
+	webDiffCmd.Flags().StringSliceVar(&webContentTags, "content-tags", extractors.DefaultContentTags, "HTML elements whose text is extracted as content")
+	webDiffCmd.Flags().Int64Var(&webMaxBytes, "max-bytes", extractors.DefaultMaxBytes, "Maximum page size to download in bytes")
+	webDiffCmd.Flags().BoolVar(&webTruncate, "truncate", false, "Truncate pages larger than --max-bytes instead of failing")
+	webDiffCmd.Flags().BoolVar(&webAllowStatus, "allow-status", false, "Extract pages returned with a non-2xx HTTP status")
+	webDiffCmd.Flags().BoolVar(&webAltText, "alt-text-only", false, "Write image alt text as plain text instead of markdown images")
+	webDiffCmd.Flags().BoolVar(&webInlineLinks, "inline-links", false, "Keep links in the content as [text](url)")
+	webDiffCmd.Flags().BoolVar(&webLinksList, "include-links-list", false, "Mark links with [n] and list their targets in a References section")
+	webDiffCmd.MarkFlagsMutuallyExclusive("inline-links", "include-links-list")
+	webDiffCmd.Flags().BoolVar(&webArticleBody, "article-body", false, "Use the JSON-LD articleBody as the content when the page provides one")
+	webDiffCmd.Flags().BoolVar(&webPreferAMP, "prefer-amp", false, "Extract the AMP version the page links to, when it has one")
+	webDiffCmd.Flags().StringArrayVar(&webStripPhrases, "strip-phrases", nil, "Regular expression for boilerplate lines to remove, replacing the built-in list (repeatable; empty to keep all lines)")
+	webDiffCmd.Flags().IntVar(&webMaxDepth, "max-depth", 0, "Extract only elements up to this many levels below the outermost content element (0 for no limit)")
+	webDiffCmd.Flags().BoolVar(&webMath, "math", false, "Write MathML and equation images as LaTeX ($...$ inline, $$...$$ display)")
+	webDiffCmd.Flags().StringVar(&webDiffSince, "since", "", "Diff against the newest snapshot at or before this date or RFC 3339 time")
 }
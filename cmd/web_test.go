@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	extractors "maai.solutions/gengo/internal/extractors/web"
+	"maai.solutions/gengo/internal/output"
+)
+
+func TestIsValidURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://example.com", true},
+		{"http://example.com/path?q=1", true},
+		{"  https://example.com  ", true},
+		{"HTTPS://Example.com", true},
+		{"https://", false},
+		{"http:///path", false},
+		{"ftp://example.com", false},
+		{"example.com", false},
+		{"not a url", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		result := isValidURL(test.url)
+		if result != test.expected {
+			t.Errorf("isValidURL(%q) = %v, expected %v", test.url, result, test.expected)
+		}
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"  https://example.com/Path  ", "https://example.com/Path"},
+		{"HTTPS://Example.COM/Article?id=1", "https://example.com/Article?id=1"},
+	}
+
+	for _, test := range tests {
+		result := normalizeURL(test.url)
+		if result != test.expected {
+			t.Errorf("normalizeURL(%q) = %q, expected %q", test.url, result, test.expected)
+		}
+	}
+}
+
+func TestPickSnapshot(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC) }
+	snapshots := []extractors.CacheEntry{
+		{Content: "first", FetchedAt: day(1)},
+		{Content: "second", FetchedAt: day(5)},
+		{Content: "latest", FetchedAt: day(9)},
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  string
+	}{
+		{"latest by default", time.Time{}, "latest"},
+		{"between snapshots", day(7), "second"},
+		{"exact time", day(5), "second"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickSnapshot(snapshots, tt.since)
+			if err != nil || got.Content != tt.want {
+				t.Errorf("pickSnapshot = %v, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := pickSnapshot(snapshots, day(1).Add(-time.Hour)); err == nil {
+		t.Error("expected an error for a time before the first snapshot")
+	}
+	if got, err := pickSnapshot(nil, time.Time{}); got != nil || err != nil {
+		t.Errorf("pickSnapshot(nil) = %v, %v, want no baseline", got, err)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	got, err := parseSince("2024-05-01T08:30:00Z")
+	if err != nil || !got.Equal(time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("parseSince(RFC 3339) = %v, %v", got, err)
+	}
+
+	// A date includes the whole day
+	got, err = parseSince("2024-05-01")
+	if err != nil || got.Day() != 1 || got.Hour() != 23 {
+		t.Errorf("parseSince(date) = %v, %v", got, err)
+	}
+
+	if _, err := parseSince("last week"); err == nil {
+		t.Error("expected an error for an unparseable value")
+	}
+}
+
+func TestWebLinkStyle(t *testing.T) {
+	defer func() { webInlineLinks, webLinksList = false, false }()
+
+	if style := webLinkStyle(); style != extractors.LinksNone {
+		t.Errorf("Expected link text only by default, got %q", style)
+	}
+	webInlineLinks = true
+	if style := webLinkStyle(); style != extractors.LinksInline {
+		t.Errorf("Expected inline links, got %q", style)
+	}
+	webInlineLinks, webLinksList = false, true
+	if style := webLinkStyle(); style != extractors.LinksReferences {
+		t.Errorf("Expected a references list, got %q", style)
+	}
+}
+
+func TestWithPageMeta(t *testing.T) {
+	result := output.Result{Title: "Tides", Source: "https://example.com", Content: "Text", Metadata: map[string]string{"Words": "1"}}
+	if got := withPageMeta(result, &extractors.Cached{Canonical: "https://example.com"}); got.Data != nil || len(got.Metadata) != 1 {
+		t.Errorf("Expected a page declaring nothing new to be unchanged, got %+v", got)
+	}
+
+	page := &extractors.Cached{
+		Structured: &extractors.StructuredData{Type: "Article", Authors: []string{"Ana Ruiz", "Ben Okafor"}, DatePublished: "2024-05-01"},
+		Canonical:  "https://example.com/tides",
+		AMP:        "https://example.com/tides/amp",
+	}
+	got := withPageMeta(result, page)
+	if got.Metadata["Author"] != "Ana Ruiz, Ben Okafor" || got.Metadata["Published"] != "2024-05-01" || got.Metadata["Words"] != "1" ||
+		got.Metadata["Canonical"] != "https://example.com/tides" || got.Metadata["AMP version"] != "https://example.com/tides/amp" {
+		t.Errorf("Unexpected metadata %v", got.Metadata)
+	}
+	if len(result.Metadata) != 1 {
+		t.Error("Expected the original metadata to be left alone")
+	}
+
+	rendered, err := output.Render(got, output.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"title": "Tides"`, `"content": "Text"`, `"canonical_url": "https://example.com/tides"`, `"structured_data": {`, `"authors": [`} {
+		if !strings.Contains(string(rendered), want) {
+			t.Errorf("Expected %s in JSON output:\n%s", want, rendered)
+		}
+	}
+
+	// Coverage alone goes into the JSON output, leaving the header alone
+	got = withPageMeta(result, &extractors.Cached{Coverage: extractors.Coverage{Extracted: 4200, Visible: 9800}})
+	if len(got.Metadata) != 1 {
+		t.Errorf("Expected coverage to stay out of the header, got %v", got.Metadata)
+	}
+	rendered, err = output.Render(got, output.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(rendered), `"extracted_chars": 4200`) || !strings.Contains(string(rendered), `"visible_chars": 9800`) {
+		t.Errorf("Expected the coverage in JSON output:\n%s", rendered)
+	}
+}
@@ -2,23 +2,54 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"maai.solutions/gengo/internal/extractors/asr"
 	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/text"
 )
 
 var (
 	ytOutputDir   string
 	ytModel       string
+	ytLanguage    string
+	ytPerSegLang  bool
+	ytSkipSilence bool
+	ytSilenceDB   float64
+	ytFFmpegPath  string
+	ytAFilter     string
+	ytDenoise     bool
+	ytNormalize   bool
+	ytRetries     int
+	ytForce       bool
 	ytVerbose     bool
 	ytKeepFiles   bool
+	ytKeepWAV     bool
+	ytTempDir     string
+	ytOlderThan   time.Duration
 	ytTimeout     time.Duration
 	ytProjectName string
+	ytFormat      string
+	ytCallback    string
+	ytServer      string
+	ytClean       bool
+	ytMinConf     float32
+	ytRestore     bool
+	ytParagraphs  bool
+	ytPause       time.Duration
+	ytAutoTitle   bool
+	ytAutoProject bool
+	ytTemplate    string
+	ytLimit       int
+	ytLimitUnit   string
 )
 
 // ytaudioCmd represents the ytaudio command
@@ -31,7 +62,13 @@ Examples:
   gengo ytaudio transcribe https://youtube.com/watch?v=example    # Basic transcription
   gengo ytaudio transcribe url --project my-project              # Save to project folder
   gengo ytaudio transcribe url --model large --verbose           # Use large model with verbose output
+  gengo ytaudio transcribe url --language de                     # Transcribe German audio
   gengo ytaudio transcribe url --keep --output ./transcripts     # Keep downloaded files
+  gengo ytaudio transcribe url --keep-wav                        # Keep the converted WAV to debug bad transcripts
+  gengo ytaudio transcribe url --format json                     # Output transcript as JSON
+  gengo ytaudio transcribe url --callback https://my-server/done  # Run as a job on a gengo server
+  gengo ytaudio transcribe url --temp-dir /scratch               # Download and convert on another disk
+  gengo ytaudio clean --older-than 6h                            # Remove work directories left by killed runs
   gengo ytaudio check                                             # Check dependencies`,
 }
 
@@ -43,22 +80,108 @@ var transcribeCmd = &cobra.Command{
 	
 The command supports various options:
 - Specify Whisper model (tiny, base, small, medium, large)
+- Specify the spoken language or let Whisper detect it
 - Save transcription to project folder or custom output directory
 - Keep or cleanup downloaded files
-- Verbose output for detailed progress`,
+- Output as plain text, markdown or JSON (markdown by default for --project,
+  plain text for stdout; --auto-project counts as a project)
+- Clean up repeated phrases and hallucinated fragments with
+  --clean-transcript, optionally dropping segments whose confidence is below
+  --min-confidence (0-1)
+- Split the transcript into paragraphs at pauses of --paragraph-pause or more
+  with --paragraphs
+- Reflow the transcript into punctuated, capitalized prose with
+  --restore-punctuation; JSON output keeps the unrestored text in raw_text
+- Title the transcript after its first sentence with --auto-title instead of
+  the video id
+- Save to a project named after the video's channel with --auto-project; an
+  explicit --project takes precedence
+- Lay out markdown transcripts with --template, naming a built-in template
+  (default, timestamps, paragraphs) or a Go text/template file. Templates see
+  .Title, .URL, .VideoID, .Language, .Duration, .Partial, .Text, .Segments
+  and .Paragraphs (each with .Start, .End and .Text), and can format times
+  with {{timestamp .Start}}
+- Verbose output for detailed progress
+
+With --callback the video is not transcribed locally. Instead it is submitted
+as a background job to a running "gengo serve" (see --server), the job id is
+printed immediately, and the server POSTs the finished job as JSON to the
+callback URL, whose host the server must allow with --callback-host. Use "gengo jobs status <id>" to check on it meanwhile.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		videoURL := args[0]
 
+		formatName := ytFormat
+		if formatName == "" {
+			formatName = string(output.FormatText)
+			if ytProjectName != "" || ytAutoProject || ytTemplate != "" {
+				formatName = string(output.FormatMarkdown)
+			}
+		}
+		format, err := output.ParseFormat(formatName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		// Validate YouTube URL (basic check)
-		if !isValidYouTubeURL(videoURL) {
-			fmt.Printf("Error: Invalid YouTube URL: %s\n", videoURL)
-			fmt.Println("Please provide a valid YouTube URL (e.g., https://youtube.com/watch?v=...)")
+		if !ytaudio.IsVideoURL(videoURL) {
+			fmt.Fprintf(os.Stderr, "Error: Invalid YouTube URL: %s\n", videoURL)
+			fmt.Fprintln(os.Stderr, "Please provide a valid YouTube URL (e.g., https://youtube.com/watch?v=...)")
+			os.Exit(1)
+		}
+
+		language, err := asr.ValidateLanguage(ytLanguage)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if ytPerSegLang && language != "" {
+			fmt.Fprintln(os.Stderr, "Error: --per-segment-language detects the language, it cannot be combined with --language "+ytLanguage)
+			os.Exit(1)
+		}
+
+		var tmpl *template.Template
+		if ytTemplate != "" {
+			if format != output.FormatMarkdown {
+				fmt.Fprintln(os.Stderr, "Error: --template only applies to markdown output")
+				os.Exit(1)
+			}
+			if tmpl, err = ytaudio.LoadTemplate(ytTemplate); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if ytSilenceDB >= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --silence-threshold must be below 0 dBFS, got %g\n", ytSilenceDB)
+			os.Exit(1)
+		}
+
+		audioFilter, err := buildAudioFilter(cmd.Context(), ytFFmpegPath, ytAFilter, ytDenoise, ytNormalize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		limitUnit, err := parseLimit(ytLimit, ytLimitUnit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if ytMinConf < 0 || ytMinConf > 1 {
+			fmt.Fprintf(os.Stderr, "Error: --min-confidence must be between 0 and 1, got %g\n", ytMinConf)
 			os.Exit(1)
 		}
 
+		if ytCallback != "" {
+			submitTranscriptionJob(cmd.Context(), videoURL)
+			return
+		}
+
 		// Create context with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), ytTimeout)
+		ctx, cancel := context.WithTimeout(cmd.Context(), ytTimeout)
 		defer cancel()
 
 		// Configure ASR
@@ -66,24 +189,68 @@ The command supports various options:
 		if ytModel != "" {
 			modelPath := ytaudio.FindWhisperModel(ytModel)
 			if modelPath == "" {
-				fmt.Printf("Error: Whisper model '%s' not found\n", ytModel)
-				fmt.Println("Available models: tiny, base, small, medium, large")
-				fmt.Println("Make sure the model is installed and in a standard location")
+				fmt.Fprintf(os.Stderr, "Error: %v\n", asr.ModelNotFound(ytModel))
+				fmt.Fprintf(os.Stderr, "Available models: %s\n", strings.Join(ytaudio.WhisperModels, ", "))
 				os.Exit(1)
 			}
 			asrConfig.WhisperModel = modelPath
 		}
+		asrConfig.Language = language
+		asrConfig.PerSegmentLanguage = ytPerSegLang
+		asrConfig.SkipSilence = ytSkipSilence
+		asrConfig.SilenceThreshold = ytSilenceDB
+		asrConfig.FFmpegPath = ytFFmpegPath
+		asrConfig.AudioFilter = audioFilter
+		asrConfig.KeepWAV = ytKeepWAV
+		if ytVerbose {
+			asrConfig.Progress = printProgress
+		}
 
 		// Configure YouTube transcription service
 		config := &ytaudio.Config{
 			OutputDir:    ytOutputDir,
+			TempDir:      ytTempDir,
 			ASRConfig:    asrConfig,
 			CleanupFiles: !ytKeepFiles,
+			Retries:      ytRetries,
+		}
+
+		opts := output.OutputOptions{
+			ProjectName: ytProjectName,
+			ProjectRoot: ytOutputDir,
+			Filename:    transcriptFilename(videoURL),
+			Format:      format,
+			Force:       ytForce,
+			SourceType:  output.SourceTranscript,
+		}
+		// Projects live under --output unless a projects directory is configured
+		if viper.IsSet("projects-dir") && !cmd.Flags().Changed("output") {
+			opts.ProjectRoot = ""
+		}
+		// Derived titles name the file instead of the video id
+		if ytAutoTitle {
+			opts.Filename = ""
+		}
+
+		if dryRun {
+			details := []string{"Whisper model: " + asrConfig.WhisperModel, "Language: " + ytLanguage}
+			if audioFilter != "" {
+				details = append(details, "Audio filter: "+audioFilter)
+			}
+			if ytAutoProject && opts.ProjectName == "" {
+				details = append(details, "Project: named after the video's channel")
+			}
+			printPlan(output.Plan{
+				Action:  "download and transcribe YouTube video",
+				Source:  videoURL,
+				Details: details,
+			}, opts)
+			return
 		}
 
 		// Ensure output directory exists
-		if err := os.MkdirAll(ytOutputDir, 0755); err != nil {
-			fmt.Printf("Error creating output directory: %v\n", err)
+		if err := output.EnsureDir(ytOutputDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -91,49 +258,91 @@ The command supports various options:
 			fmt.Printf("Starting transcription of: %s\n", videoURL)
 			fmt.Printf("Output directory: %s\n", ytOutputDir)
 			fmt.Printf("Whisper model: %s\n", ytModel)
+			fmt.Printf("Language: %s\n", ytLanguage)
 			fmt.Printf("Keep files: %t\n", ytKeepFiles)
+			if ytTempDir != "" {
+				fmt.Printf("Temp directory: %s\n", ytTempDir)
+			}
 		}
 
 		// Create service and transcribe
 		service := ytaudio.NewService(config)
 		result, err := service.TranscribeYouTubeVideo(ctx, videoURL)
 		if err != nil {
-			fmt.Printf("Error transcribing video: %v\n", err)
+			if hint := videoErrorHint(err); hint != "" {
+				fmt.Fprintf(os.Stderr, "Error: %v\n%s\n", err, hint)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "Error transcribing video: %v\n", err)
 			os.Exit(1)
 		}
+		if result.Video != "" {
+			noticef("Downloaded video kept at: %s\n", result.Video)
+		}
+		if result.WAVPath != "" {
+			noticef("Converted audio kept at: %s\n", result.WAVPath)
+		}
+		if result.Partial {
+			fmt.Fprintf(os.Stderr, "Warning: transcription timed out after %s of audio, the transcript is incomplete\n", ytaudio.TranscribedUntil(result))
+		}
 
-		// Handle output based on project name or direct output
-		if ytProjectName != "" {
-			// Save to project structure
-			projectDir := filepath.Join(ytOutputDir, ytProjectName)
-			if err := os.MkdirAll(projectDir, 0755); err != nil {
-				fmt.Printf("Error creating project directory: %v\n", err)
-				os.Exit(1)
+		if ytClean {
+			segments, report := asr.CleanSegments(result.Segments, asr.CleanOptions{MinConfidence: ytMinConf})
+			result.Segments = segments
+			result.Text = asr.SegmentText(segments)
+			// On stderr, since the transcript itself may be going to stdout
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "🧹 %s\n", report)
 			}
+		}
 
-			// Generate filename from video URL/ID
-			filename := generateTranscriptFilename(videoURL)
-			transcriptPath := filepath.Join(projectDir, filename)
+		if ytParagraphs {
+			result.Text = asr.ParagraphText(result.Segments, ytPause)
+		}
 
-			// Create markdown content with metadata
-			content := formatTranscriptMarkdown(videoURL, result)
+		rawText := result.Text
+		if ytRestore {
+			result.Text = text.RestorePunctuationLanguage(result.Text, result.Language)
+		}
 
-			if err := os.WriteFile(transcriptPath, []byte(content), 0644); err != nil {
-				fmt.Printf("Error writing transcript file: %v\n", err)
-				os.Exit(1)
+		// The limit bounds every rendering, JSON and templates included
+		rawText = text.Limit(rawText, ytLimit, limitUnit)
+		result.Text = text.Limit(result.Text, ytLimit, limitUnit)
+
+		if ytVerbose {
+			fmt.Printf("Transcription completed in %v\n", result.Duration)
+			if ytProjectName == "" {
+				fmt.Println("--- Transcript ---")
 			}
+		}
 
-			if ytVerbose {
-				fmt.Printf("Transcription completed in %v\n", result.Duration)
+		// Handle output based on project name or direct output
+		transcript := withAutoTitle(ytaudio.TranscriptResult(videoURL, result), ytAutoTitle, true)
+		data := newTranscriptJSON(videoURL, result)
+		if rawText != result.Text {
+			data.RawText = rawText
+		}
+		transcript.Data = data
+		if tmpl != nil {
+			content, err := ytaudio.RenderTemplate(tmpl, ytaudio.NewTranscriptData(transcript.Title, videoURL, result, ytPause))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
-			fmt.Printf("Transcript saved to: %s\n", transcriptPath)
-		} else {
-			// Output to stdout
-			if ytVerbose {
-				fmt.Printf("Transcription completed in %v\n", result.Duration)
-				fmt.Println("--- Transcript ---")
+			// The template lays out the whole document, header included
+			transcript.Content = content
+			opts.BodyOnly = true
+		}
+		paths, err := output.WriteAll(transcript, withAutoProject(opts, ytAutoProject, transcript))
+		for _, path := range paths {
+			statusf("Transcript saved to: %s\n", path)
+		}
+		if err != nil {
+			if reportDuplicate(err) {
+				return
 			}
-			fmt.Println(result.Text)
+			fmt.Fprintf(os.Stderr, "Error writing transcript: %v\n", err)
+			os.Exit(1)
 		}
 	},
 }
@@ -145,33 +354,92 @@ var checkCmd = &cobra.Command{
 	Long: `Check if all required dependencies for YouTube audio transcription are available.
 	
 This includes:
-- ffmpeg (for audio conversion)
-- whisper or whisper.cpp (for transcription)
-- Required Python packages (if using OpenAI Whisper)`,
+- ffmpeg (for audio conversion), with its version
+- at least one installed whisper model (for transcription)
+- network access to YouTube (for downloading)
+
+The command exits with an error only when a required dependency is missing.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Checking YouTube audio transcription dependencies...")
-
-		if err := ytaudio.CheckDependencies(); err != nil {
-			fmt.Printf("❌ Dependency check failed: %v\n", err)
-			fmt.Println("\nTo fix this, please install the missing dependencies:")
-			fmt.Println("- Install ffmpeg: https://ffmpeg.org/download.html")
-			fmt.Println("- Install whisper: pip install openai-whisper")
-			fmt.Println("- Or install whisper.cpp: https://github.com/ggerganov/whisper.cpp")
+		statusln("Checking YouTube audio transcription dependencies...")
+
+		report := ytaudio.CheckDependencyReport(cmd.Context())
+		fmt.Print(formatDependencyReport(report))
+
+		if !report.OK() {
+			fmt.Fprintln(os.Stderr, "\n❌ Dependency check failed. To fix this, install the missing dependencies:")
+			fmt.Fprintln(os.Stderr, "- Install ffmpeg: https://ffmpeg.org/download.html")
+			fmt.Fprintln(os.Stderr, "- Download a whisper.cpp model: https://huggingface.co/ggerganov/whisper.cpp")
 			os.Exit(1)
 		}
 
-		fmt.Println("✅ All dependencies are available!")
+		fmt.Println("\n✅ All required dependencies are available!")
+	},
+}
 
-		// Show available models
-		fmt.Println("\nAvailable Whisper models:")
-		models := []string{"tiny", "base", "small", "medium", "large"}
-		for _, model := range models {
-			modelPath := ytaudio.FindWhisperModel(model)
-			if modelPath != "" {
-				fmt.Printf("  ✅ %s: %s\n", model, modelPath)
-			} else {
-				fmt.Printf("  ❌ %s: not found\n", model)
+// printProgress shows conversion and transcription progress on a single line
+func printProgress(stage string, percent float64) {
+	fmt.Printf("\r%s: %3.0f%%", stage, percent)
+	if percent >= 100 {
+		fmt.Println()
+	}
+}
+
+// formatDependencyReport renders a dependency report as a checklist
+func formatDependencyReport(report *ytaudio.DependencyReport) string {
+	var b strings.Builder
+	for _, check := range report.Checks {
+		mark := "✅"
+		if !check.OK {
+			mark = "❌"
+			if !check.Fatal {
+				mark = "⚠️"
+			}
+		}
+		fmt.Fprintf(&b, "%s %s: %s\n", mark, check.Name, check.Detail)
+	}
+
+	b.WriteString("\nWhisper models:\n")
+	for _, model := range report.Models {
+		if model.Path != "" {
+			fmt.Fprintf(&b, "  ✅ %s: %s\n", model.Name, model.Path)
+		} else {
+			fmt.Fprintf(&b, "  ❌ %s: not found\n", model.Name)
+		}
+	}
+	return b.String()
+}
+
+// cleanCmd represents the clean command
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove stale temporary files left by interrupted transcriptions",
+	Long: `Remove the per-run work directories that transcriptions leave behind when
+they are killed before cleaning up after themselves.
+
+Only directories older than --older-than are removed, so runs in progress are
+left alone. Use the same --temp-dir the transcriptions were run with.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		stale, err := ytaudio.StaleWorkDirs(ytTempDir, ytOlderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(stale) == 0 {
+			statusf("No temporary files older than %s\n", ytOlderThan)
+			return
+		}
+		for _, dir := range stale {
+			if dryRun {
+				fmt.Printf("Would remove: %s\n", dir)
+				continue
 			}
+			if err := os.RemoveAll(dir); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing %s: %v\n", dir, err)
+				os.Exit(1)
+			}
+			statusf("Removed: %s\n", dir)
 		}
 	},
 }
@@ -213,60 +481,50 @@ func init() {
 	// Add subcommands to ytaudio
 	ytaudioCmd.AddCommand(transcribeCmd)
 	ytaudioCmd.AddCommand(checkCmd)
+	ytaudioCmd.AddCommand(cleanCmd)
 	ytaudioCmd.AddCommand(modelsCmd)
 
 	// Add flags to transcribe command
 	transcribeCmd.Flags().StringVarP(&ytOutputDir, "output", "o", "./ytaudio_output", "Output directory for transcripts and temporary files")
 	transcribeCmd.Flags().StringVarP(&ytModel, "model", "m", "base", "Whisper model to use (tiny, base, small, medium, large)")
+	transcribeCmd.Flags().StringVarP(&ytLanguage, "language", "l", asr.AutoLanguage, "Spoken language code (e.g. en, de, es), or auto to detect it")
+	transcribeCmd.Flags().BoolVar(&ytPerSegLang, "per-segment-language", false, "Detect the language of every 30 seconds of audio separately, for videos that switch languages")
+	transcribeCmd.Flags().BoolVar(&ytSkipSilence, "skip-silence", false, "Transcribe only the audio louder than --silence-threshold, skipping pauses of a second or more")
+	transcribeCmd.Flags().Float64Var(&ytSilenceDB, "silence-threshold", asr.DefaultSilenceThreshold, "Level in dBFS below which audio counts as silence with --skip-silence")
+	transcribeCmd.Flags().StringVar(&ytFFmpegPath, "ffmpeg", "", "Path to the ffmpeg binary (default: ffmpeg on PATH)")
+	transcribeCmd.Flags().StringVar(&ytAFilter, "audio-filter", "", "ffmpeg audio filters applied before transcription, e.g. highpass=f=200,afftdn")
+	transcribeCmd.Flags().BoolVar(&ytDenoise, "denoise", false, "Filter out rumble, hiss and background noise before transcription")
+	transcribeCmd.Flags().BoolVar(&ytNormalize, "normalize", false, "Even out the loudness of quiet or distant speakers before transcription")
+	transcribeCmd.Flags().IntVar(&ytRetries, "retries", ytaudio.DefaultConfig().Retries, "Number of times to retry an interrupted download")
+	transcribeCmd.Flags().BoolVar(&ytForce, "force", false, "Save to the project even if an identical transcript is already saved")
 	transcribeCmd.Flags().BoolVarP(&ytVerbose, "verbose", "v", false, "Enable verbose output")
 	transcribeCmd.Flags().BoolVarP(&ytKeepFiles, "keep", "k", false, "Keep downloaded audio files")
+	transcribeCmd.Flags().BoolVar(&ytKeepWAV, "keep-wav", false, "Keep the 16kHz mono WAV fed to whisper in the output directory, as <download>_16k.wav (for debugging)")
+	transcribeCmd.Flags().StringVar(&ytTempDir, "temp-dir", "", "Directory for each run's download and converted audio (default: the system temp directory)")
 	transcribeCmd.Flags().DurationVarP(&ytTimeout, "timeout", "t", 30*time.Minute, "Timeout for the entire operation")
 	transcribeCmd.Flags().StringVarP(&ytProjectName, "project", "p", "", "Save transcript to a project folder (creates organized structure)")
-}
-
-// isValidYouTubeURL performs basic validation of YouTube URLs
-func isValidYouTubeURL(url string) bool {
-	// Basic YouTube URL patterns
-	patterns := []string{
-		"youtube.com/watch",
-		"youtu.be/",
-		"youtube.com/embed/",
-		"youtube.com/v/",
-		"m.youtube.com/watch",
-	}
-
-	for _, pattern := range patterns {
-		if contains(url, pattern) {
-			return true
-		}
-	}
-	return false
-}
-
-// contains checks if a string contains a substring (case-insensitive)
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(s == substr ||
-			len(s) > len(substr) &&
-				(s[:len(substr)] == substr ||
-					s[len(s)-len(substr):] == substr ||
-					indexOf(s, substr) >= 0))
-}
-
-// indexOf returns the index of substr in s, or -1 if not found
-func indexOf(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return i
-		}
-	}
-	return -1
+	transcribeCmd.Flags().BoolVar(&ytAutoProject, "auto-project", false, "Save to a project named after the video's channel when --project is not given")
+	transcribeCmd.Flags().StringVarP(&ytFormat, "format", "f", "", "Output format (text, markdown, json) (default: markdown with --project, text otherwise)")
+	transcribeCmd.Flags().BoolVar(&ytClean, "clean-transcript", false, "Collapse repeated phrases and drop repeated or low-confidence segments")
+	transcribeCmd.Flags().Float32Var(&ytMinConf, "min-confidence", 0, "With --clean-transcript, drop segments below this confidence (0-1, 0 keeps all)")
+	transcribeCmd.Flags().BoolVar(&ytParagraphs, "paragraphs", false, "Group the transcript into paragraphs at pauses and sentence boundaries")
+	transcribeCmd.Flags().IntVar(&ytLimit, "limit", 0, "Keep only the first N words or characters of the content, ending at a sentence (0 for all)")
+	transcribeCmd.Flags().StringVar(&ytLimitUnit, "limit-unit", "words", "What --limit counts (words, chars)")
+	transcribeCmd.Flags().StringVar(&ytTemplate, "template", "", "Markdown layout: a built-in template (default, timestamps, paragraphs) or a text/template file")
+	transcribeCmd.Flags().DurationVar(&ytPause, "paragraph-pause", asr.DefaultParagraphPause, "The pause that starts a new paragraph with --paragraphs or in a template's .Paragraphs")
+	transcribeCmd.Flags().BoolVar(&ytRestore, "restore-punctuation", false, "Restore sentence punctuation and capitalization in the transcript")
+	transcribeCmd.Flags().BoolVar(&ytAutoTitle, "auto-title", false, "Title the transcript after its first sentence and name the file after it")
+	transcribeCmd.Flags().StringVar(&ytCallback, "callback", "", "Submit as a background job and POST the result JSON to this URL when done")
+	transcribeCmd.Flags().StringVar(&ytServer, "server", defaultServerURL, "gengo server that runs --callback jobs")
+
+	cleanCmd.Flags().StringVar(&ytTempDir, "temp-dir", "", "Directory the transcriptions were run with (default: the system temp directory)")
+	cleanCmd.Flags().DurationVar(&ytOlderThan, "older-than", 24*time.Hour, "Only remove work directories last modified longer ago than this")
 }
 
 // generateTranscriptFilename creates a filename from a YouTube URL
 func generateTranscriptFilename(videoURL string) string {
 	// Extract video ID from various YouTube URL formats
-	videoID := extractVideoID(videoURL)
+	videoID := ytaudio.VideoID(videoURL)
 	if videoID == "" {
 		videoID = "transcript"
 	}
@@ -275,55 +533,70 @@ func generateTranscriptFilename(videoURL string) string {
 	return fmt.Sprintf("%s_%s.md", videoID, timestamp)
 }
 
-// extractVideoID extracts the video ID from a YouTube URL
-func extractVideoID(url string) string {
-	// Handle youtube.com/watch?v=ID format
-	if idx := indexOf(url, "v="); idx >= 0 {
-		start := idx + 2
-		end := start
-		for end < len(url) && url[end] != '&' && url[end] != '#' {
-			end++
-		}
-		if end > start {
-			return url[start:end]
-		}
-	}
-
-	// Handle youtu.be/ID format
-	if idx := indexOf(url, "youtu.be/"); idx >= 0 {
-		start := idx + 9
-		end := start
-		for end < len(url) && url[end] != '?' && url[end] != '#' {
-			end++
-		}
-		if end > start {
-			return url[start:end]
-		}
+// transcriptFilename names a transcript file after its video and the time,
+// without extension, or returns "" to leave the name to a --name-template
+// for transcripts
+func transcriptFilename(videoURL string) string {
+	if output.NameTemplateFor(output.SourceTranscript) != nil {
+		return ""
 	}
-
-	return ""
+	return strings.TrimSuffix(generateTranscriptFilename(videoURL), ".md")
 }
 
-// formatTranscriptMarkdown formats the transcription result as markdown
-func formatTranscriptMarkdown(videoURL string, result *ytaudio.TranscriptionResult) string {
-	videoID := extractVideoID(videoURL)
-	title := "YouTube Video Transcript"
-	if videoID != "" {
-		title = fmt.Sprintf("YouTube Video Transcript (%s)", videoID)
+// videoErrorHint explains a video YouTube will not serve, or returns ""
+// when err is some other failure
+func videoErrorHint(err error) string {
+	switch {
+	case errors.Is(err, ytaudio.ErrLiveStream):
+		return "Live streams cannot be transcribed while they run; try again once the recording is available."
+	case errors.Is(err, ytaudio.ErrPrivate):
+		return "Only public and unlisted videos can be transcribed."
+	case errors.Is(err, ytaudio.ErrVideoUnavailable):
+		return "The video may have been removed, or it is blocked in your region."
+	default:
+		return ""
 	}
+}
 
-	content := fmt.Sprintf(`# %s
-
-**Source:** %s  
-**Transcribed:** %s  
-**Duration:** %v  
-
----
+// transcriptJSON is the JSON representation of a transcript for programmatic consumers
+type transcriptJSON struct {
+	Text            string                  `json:"text"`
+	RawText         string                  `json:"raw_text,omitempty"` // whisper's text before punctuation restoration
+	Language        string                  `json:"language"`
+	DurationSeconds float64                 `json:"duration_seconds"`
+	Segments        []transcriptSegmentJSON `json:"segments"`
+	Source          string                  `json:"source"`
+	Partial         bool                    `json:"partial,omitempty"` // the transcription timed out and stops early
+}
 
-## Transcript
+// transcriptSegmentJSON is a timed transcript segment with offsets in seconds
+type transcriptSegmentJSON struct {
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Text       string  `json:"text"`
+	Confidence float32 `json:"confidence,omitempty"`
+	Language   string  `json:"language,omitempty"` // with --per-segment-language
+}
 
-%s
-`, title, videoURL, time.Now().Format("2006-01-02 15:04:05"), result.Duration, result.Text)
+// newTranscriptJSON converts a transcription result into its JSON representation
+func newTranscriptJSON(videoURL string, result *ytaudio.TranscriptionResult) transcriptJSON {
+	segments := make([]transcriptSegmentJSON, 0, len(result.Segments))
+	for _, segment := range result.Segments {
+		segments = append(segments, transcriptSegmentJSON{
+			Start:      segment.Start.Seconds(),
+			End:        segment.End.Seconds(),
+			Text:       segment.Text,
+			Confidence: segment.Confidence,
+			Language:   segment.Language,
+		})
+	}
 
-	return content
+	return transcriptJSON{
+		Text:            result.Text,
+		Language:        result.Language,
+		DurationSeconds: result.Duration.Seconds(),
+		Segments:        segments,
+		Source:          videoURL,
+		Partial:         result.Partial,
+	}
 }
@@ -1,114 +1,94 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
-)
-
-func TestIsValidYouTubeURL(t *testing.T) {
-	tests := []struct {
-		url      string
-		expected bool
-	}{
-		{"https://youtube.com/watch?v=dQw4w9WgXcQ", true},
-		{"https://youtu.be/dQw4w9WgXcQ", true},
-		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", true},
-		{"https://m.youtube.com/watch?v=dQw4w9WgXcQ", true},
-		{"https://youtube.com/embed/dQw4w9WgXcQ", true},
-		{"https://youtube.com/v/dQw4w9WgXcQ", true},
-		{"invalid-url", false},
-		{"https://example.com", false},
-		{"", false},
-	}
-
-	for _, test := range tests {
-		result := isValidYouTubeURL(test.url)
-		if result != test.expected {
-			t.Errorf("isValidYouTubeURL(%q) = %v, expected %v", test.url, result, test.expected)
-		}
-	}
-}
-
-func TestExtractVideoID(t *testing.T) {
-	tests := []struct {
-		url      string
-		expected string
-	}{
-		{"https://youtube.com/watch?v=dQw4w9WgXcQ", "dQw4w9WgXcQ"},
-		{"https://youtu.be/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
-		{"https://youtube.com/watch?v=dQw4w9WgXcQ&t=30s", "dQw4w9WgXcQ"},
-		{"https://youtu.be/dQw4w9WgXcQ?t=30", "dQw4w9WgXcQ"},
-		{"invalid-url", ""},
-		{"", ""},
-	}
+	"time"
 
-	for _, test := range tests {
-		result := extractVideoID(test.url)
-		if result != test.expected {
-			t.Errorf("extractVideoID(%q) = %q, expected %q", test.url, result, test.expected)
-		}
-	}
-}
+	"maai.solutions/gengo/internal/extractors/asr"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/output"
+)
 
 func TestGenerateTranscriptFilename(t *testing.T) {
 	// Test with valid YouTube URL
 	filename := generateTranscriptFilename("https://youtube.com/watch?v=dQw4w9WgXcQ")
-	if !contains(filename, "dQw4w9WgXcQ") {
+	if !strings.Contains(filename, "dQw4w9WgXcQ") {
 		t.Errorf("Expected filename to contain video ID, got: %s", filename)
 	}
-	if !contains(filename, ".md") {
+	if !strings.Contains(filename, ".md") {
 		t.Errorf("Expected filename to have .md extension, got: %s", filename)
 	}
 
 	// Test with invalid URL
 	filename = generateTranscriptFilename("invalid-url")
-	if !contains(filename, "transcript") {
+	if !strings.Contains(filename, "transcript") {
 		t.Errorf("Expected filename to contain 'transcript' for invalid URL, got: %s", filename)
 	}
 }
 
-func TestContains(t *testing.T) {
-	tests := []struct {
-		s        string
-		substr   string
-		expected bool
-	}{
-		{"hello world", "world", true},
-		{"hello world", "hello", true},
-		{"hello world", "llo wo", true},
-		{"hello world", "xyz", false},
-		{"", "test", false},
-		{"test", "", true}, // Empty substring should match
-		{"test", "test", true},
-		{"test", "testing", false},
+func TestTranscriptJSON(t *testing.T) {
+	result := &ytaudio.TranscriptionResult{
+		Text:     "hello world",
+		Language: "en",
+		Duration: 1500 * time.Millisecond,
+		Segments: []asr.Segment{{Start: 0, End: 2 * time.Second, Text: "hello world"}},
+	}
+
+	transcript := ytaudio.TranscriptResult("https://youtu.be/dQw4w9WgXcQ", result)
+	transcript.Data = newTranscriptJSON("https://youtu.be/dQw4w9WgXcQ", result)
+
+	data, err := output.Render(transcript, output.FormatJSON)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
 	}
 
-	for _, test := range tests {
-		result := contains(test.s, test.substr)
-		if result != test.expected {
-			t.Errorf("contains(%q, %q) = %v, expected %v", test.s, test.substr, result, test.expected)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	for _, key := range []string{"text", "language", "duration_seconds", "segments", "source"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("Expected JSON key %q, got: %s", key, data)
 		}
 	}
+	if decoded["duration_seconds"] != 1.5 {
+		t.Errorf("Expected duration_seconds 1.5, got %v", decoded["duration_seconds"])
+	}
+	if segments, _ := decoded["segments"].([]interface{}); len(segments) != 1 {
+		t.Errorf("Expected 1 segment, got %v", decoded["segments"])
+	}
 }
 
-func TestIndexOf(t *testing.T) {
-	tests := []struct {
-		s        string
-		substr   string
-		expected int
-	}{
-		{"hello world", "world", 6},
-		{"hello world", "hello", 0},
-		{"hello world", "llo", 2},
-		{"hello world", "xyz", -1},
-		{"", "test", -1},
-		{"test", "test", 0},
-		{"testing", "test", 0},
+func TestFormatDependencyReport(t *testing.T) {
+	report := &ytaudio.DependencyReport{
+		Checks: []ytaudio.DependencyStatus{
+			{Name: "ffmpeg", OK: true, Fatal: true, Detail: "ffmpeg version 6.0"},
+			{Name: "youtube access", Detail: "timeout"},
+		},
+		Models: []ytaudio.ModelStatus{{Name: "base", Path: "models/ggml-base.bin"}, {Name: "large"}},
 	}
 
-	for _, test := range tests {
-		result := indexOf(test.s, test.substr)
-		if result != test.expected {
-			t.Errorf("indexOf(%q, %q) = %d, expected %d", test.s, test.substr, result, test.expected)
+	text := formatDependencyReport(report)
+	for _, want := range []string{"✅ ffmpeg: ffmpeg version 6.0", "⚠️ youtube access: timeout", "✅ base: models/ggml-base.bin", "❌ large: not found"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, text)
 		}
 	}
 }
+
+func TestVideoErrorHint(t *testing.T) {
+	err := fmt.Errorf("extracting youtube source: %w", &ytaudio.VideoError{Err: ytaudio.ErrPrivate})
+	if hint := videoErrorHint(err); !strings.Contains(hint, "public") {
+		t.Errorf("Expected a hint for private videos, got %q", hint)
+	}
+	if hint := videoErrorHint(&ytaudio.VideoError{Err: ytaudio.ErrLiveStream}); !strings.Contains(hint, "Live streams") {
+		t.Errorf("Expected a hint for live streams, got %q", hint)
+	}
+	if hint := videoErrorHint(errors.New("connection reset")); hint != "" {
+		t.Errorf("Expected no hint for other errors, got %q", hint)
+	}
+}
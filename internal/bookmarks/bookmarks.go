@@ -0,0 +1,146 @@
+// Package bookmarks reads reading lists exported from browsers and feed
+// readers so their pages can be extracted in one batch.
+package bookmarks
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Link is one page in a reading list
+type Link struct {
+	Title string
+	URL   string
+}
+
+// List is the content of an imported reading list
+type List struct {
+	Links      []Link   // http(s) links in file order, without duplicates
+	Folders    int      // folders, which group links but are not pages themselves
+	Duplicates int      // links already listed earlier in the file
+	Skipped    []string // entries without an http(s) URL, e.g. javascript: bookmarklets
+}
+
+// add records a link, skipping non-http URLs and duplicates
+func (l *List) add(title, rawURL string, seen map[string]bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		l.Skipped = append(l.Skipped, rawURL)
+		return
+	}
+	if seen[rawURL] {
+		l.Duplicates++
+		return
+	}
+	seen[rawURL] = true
+	l.Links = append(l.Links, Link{Title: strings.TrimSpace(title), URL: rawURL})
+}
+
+// ParseNetscape reads a bookmarks file in the Netscape format exported by
+// every major browser, where folders are H3 headings and bookmarks are links
+func ParseNetscape(r io.Reader) (*List, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks: %w", err)
+	}
+
+	list := &List{}
+	seen := map[string]bool{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.H3:
+				list.Folders++
+			case atom.A:
+				if href, ok := attr(n, "href"); ok {
+					list.add(textContent(n), href, seen)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return list, nil
+}
+
+// outline is an OPML outline element, which nests to form folders
+type outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr"`
+	XMLURL   string    `xml:"xmlUrl,attr"`
+	HTMLURL  string    `xml:"htmlUrl,attr"`
+	Outlines []outline `xml:"outline"`
+}
+
+// ParseOPML reads feed subscriptions in OPML. Each feed contributes the
+// website it belongs to (htmlUrl); feeds that only give the feed address are
+// skipped, since a feed document is not a readable page. Outlines without a
+// URL that contain others are folders.
+func ParseOPML(r io.Reader) (*List, error) {
+	var doc struct {
+		XMLName  xml.Name  `xml:"opml"`
+		Outlines []outline `xml:"body>outline"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %w", err)
+	}
+
+	list := &List{}
+	seen := map[string]bool{}
+	var walk func(outlines []outline)
+	walk = func(outlines []outline) {
+		for _, o := range outlines {
+			title := o.Title
+			if title == "" {
+				title = o.Text
+			}
+
+			switch {
+			case o.HTMLURL != "":
+				list.add(title, o.HTMLURL, seen)
+			case o.XMLURL != "":
+				list.Skipped = append(list.Skipped, o.XMLURL)
+			case len(o.Outlines) > 0:
+				list.Folders++
+			}
+			walk(o.Outlines)
+		}
+	}
+	walk(doc.Outlines)
+	return list, nil
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// textContent returns the text inside a node
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
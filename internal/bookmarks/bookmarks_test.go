@@ -0,0 +1,60 @@
+package bookmarks
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNetscape(t *testing.T) {
+	f, err := os.Open("testdata/bookmarks.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	list, err := ParseNetscape(f)
+	if err != nil {
+		t.Fatalf("ParseNetscape failed: %v", err)
+	}
+
+	want := []Link{
+		{Title: "Effective Go", URL: "https://go.dev/doc/effective_go"},
+		{Title: "An article", URL: "https://example.com/article"},
+	}
+	if !reflect.DeepEqual(list.Links, want) {
+		t.Errorf("Links = %+v, want %+v", list.Links, want)
+	}
+	if list.Folders != 2 || list.Duplicates != 1 || len(list.Skipped) != 2 {
+		t.Errorf("Got %d folders, %d duplicates and skipped %q", list.Folders, list.Duplicates, list.Skipped)
+	}
+}
+
+func TestParseOPML(t *testing.T) {
+	f, err := os.Open("testdata/subscriptions.opml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	list, err := ParseOPML(f)
+	if err != nil {
+		t.Fatalf("ParseOPML failed: %v", err)
+	}
+
+	want := []Link{
+		{Title: "The Go Blog", URL: "https://go.dev/blog"},
+		{Title: "Example News", URL: "https://news.example.com/"},
+	}
+	if !reflect.DeepEqual(list.Links, want) {
+		t.Errorf("Links = %+v, want %+v", list.Links, want)
+	}
+	if list.Folders != 1 || len(list.Skipped) != 1 || list.Skipped[0] != "https://example.com/feed.xml" {
+		t.Errorf("Got %d folders and skipped %q", list.Folders, list.Skipped)
+	}
+
+	if _, err := ParseOPML(strings.NewReader("<html></html>")); err == nil {
+		t.Error("Expected an error for a document that is not OPML")
+	}
+}
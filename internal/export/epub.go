@@ -0,0 +1,225 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/text"
+)
+
+var (
+	boldPattern   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	italicPattern = regexp.MustCompile(`\*([^*]+)\*`)
+	linkPattern   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// WriteEPUB writes chapters to an EPUB 3 file with a navigation document
+// listing every chapter
+func WriteEPUB(path, title string, chapters []Chapter) (err error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, output.FileMode)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	w := zip.NewWriter(f)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mimetype, err := w.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	files := []struct{ name, content string }{
+		{"META-INF/container.xml", containerXML},
+		{"OEBPS/content.opf", packageXML(title, chapters)},
+		{"OEBPS/nav.xhtml", navXHTML(title, chapters)},
+	}
+	for i, chapter := range chapters {
+		files = append(files, struct{ name, content string }{
+			"OEBPS/" + chapterFile(i),
+			xhtmlPage(chapter.Title, "<h1>"+html.EscapeString(chapter.Title)+"</h1>\n"+markdownToXHTML(chapter.Content)),
+		})
+	}
+
+	for _, file := range files {
+		fw, err := w.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, file.content); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// chapterFile returns the archive name of the i-th chapter
+func chapterFile(i int) string {
+	return fmt.Sprintf("chapter%03d.xhtml", i+1)
+}
+
+// packageXML builds the OPF package document listing chapters in reading order
+func packageXML(title string, chapters []Chapter) string {
+	var manifest, spine strings.Builder
+	for i := range chapters {
+		fmt.Fprintf(&manifest, "    <item id=\"ch%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, chapterFile(i))
+		fmt.Fprintf(&spine, "    <itemref idref=\"ch%d\"/>\n", i+1)
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">urn:gengo:%d</dc:identifier>
+    <dc:title>%s</dc:title>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">%s</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+%s  </manifest>
+  <spine>
+    <itemref idref="nav"/>
+%s  </spine>
+</package>
+`, time.Now().UnixNano(), html.EscapeString(title), time.Now().UTC().Format("2006-01-02T15:04:05Z"), manifest.String(), spine.String())
+}
+
+// navXHTML builds the navigation document that serves as the table of contents
+func navXHTML(title string, chapters []Chapter) string {
+	var b strings.Builder
+	b.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n<h1>Contents</h1>\n<ol>\n")
+	for i, chapter := range chapters {
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", chapterFile(i), html.EscapeString(chapter.Title))
+	}
+	b.WriteString("</ol>\n</nav>\n")
+	return xhtmlPage(title, b.String())
+}
+
+// xhtmlPage wraps body markup in an XHTML document
+func xhtmlPage(title, body string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>%s</title></head>
+<body>
+%s</body>
+</html>
+`, html.EscapeString(title), body)
+}
+
+// markdownToXHTML converts the markdown produced by the extractors into XHTML.
+// It handles headings, paragraphs, block quotes, lists, rules, code fences and
+// inline emphasis and links, which covers what the extractors emit.
+func markdownToXHTML(content string) string {
+	var b strings.Builder
+	var paragraph, quote []string
+	inList, inFence := false, false
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			fmt.Fprintf(&b, "<p>%s</p>\n", inline(strings.Join(paragraph, " ")))
+			paragraph = nil
+		}
+		if len(quote) > 0 {
+			fmt.Fprintf(&b, "<blockquote><p>%s</p></blockquote>\n", inline(strings.Join(quote, " ")))
+			quote = nil
+		}
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			if inFence {
+				b.WriteString("</code></pre>\n")
+			} else {
+				b.WriteString("<pre><code>")
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			b.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+		case text.HeadingLevel(trimmed) > 0:
+			flush()
+			level := text.HeadingLevel(trimmed)
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, inline(strings.TrimSpace(trimmed[level:])), level)
+		case trimmed == "---" || trimmed == "***":
+			flush()
+			b.WriteString("<hr/>\n")
+		case strings.HasPrefix(trimmed, ">"):
+			if len(paragraph) > 0 || inList {
+				flush()
+			}
+			quote = append(quote, strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			if !inList {
+				flush()
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			fmt.Fprintf(&b, "<li>%s</li>\n", inline(trimmed[2:]))
+		default:
+			if inList || len(quote) > 0 {
+				flush()
+			}
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	flush()
+	if inFence {
+		b.WriteString("</code></pre>\n")
+	}
+	return b.String()
+}
+
+// inline escapes text and converts bold, italic and link markup
+func inline(text string) string {
+	text = html.EscapeString(text)
+	text = linkPattern.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	text = boldPattern.ReplaceAllString(text, "<strong>$1</strong>")
+	text = italicPattern.ReplaceAllString(text, "<em>$1</em>")
+	return text
+}
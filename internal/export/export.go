@@ -0,0 +1,104 @@
+package export
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mdextractors "maai.solutions/gengo/internal/extractors/markdown"
+	"maai.solutions/gengo/internal/output"
+)
+
+// Chapter is one extracted markdown document in a project
+type Chapter struct {
+	Title   string
+	Source  string // path of the markdown file the chapter was read from
+	Content string // markdown body without the title heading
+}
+
+// Format selects the kind of document a project is exported to
+type Format string
+
+const (
+	FormatEPUB     Format = "epub"
+	FormatMarkdown Format = "md"
+)
+
+// ParseFormat validates an export format given on the command line
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "epub", "":
+		return FormatEPUB, nil
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s (expected epub or md)", name)
+	}
+}
+
+// Extension returns the file extension used for an export format
+func (f Format) Extension() string {
+	if f == FormatMarkdown {
+		return ".md"
+	}
+	return ".epub"
+}
+
+// CollectChapters reads every markdown file below dir in path order
+func CollectChapters(dir string) ([]Chapter, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open project folder: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("project path is not a directory: %s", dir)
+	}
+
+	var chapters []Chapter
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+
+		title, content, err := mdextractors.ExtractFromFile(path)
+		if err != nil {
+			return err
+		}
+		chapters = append(chapters, Chapter{Title: title, Source: path, Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("no markdown files found in %s", dir)
+	}
+	return chapters, nil
+}
+
+// Write exports chapters to path in the given format
+func Write(path, title string, chapters []Chapter, format Format) error {
+	if err := output.CheckPath(path); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := output.EnsureDir(dir); err != nil {
+			return err
+		}
+	}
+
+	switch format {
+	case FormatEPUB:
+		return WriteEPUB(path, title, chapters)
+	case FormatMarkdown:
+		return os.WriteFile(path, []byte(RenderMarkdown(title, chapters)), output.FileMode)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	epubextractors "maai.solutions/gengo/internal/extractors/epub"
+)
+
+// writeProject creates a project folder with two extracted documents
+func writeProject(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b-second.md": "# Second Page\n\n**Source:** https://example.com/b\n\n---\n\nSecond page text.\n",
+		"a-first.md":  "# First Page\n\n## Section\n\nFirst page with **bold** text.\n\n> A quote\n",
+		"notes.txt":   "not markdown",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+		wantErr  bool
+	}{
+		{"epub", FormatEPUB, false},
+		{"", FormatEPUB, false},
+		{"md", FormatMarkdown, false},
+		{"Markdown", FormatMarkdown, false},
+		{"pdf", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := ParseFormat(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+		}
+		if result != test.expected {
+			t.Errorf("ParseFormat(%q) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestCollectChapters(t *testing.T) {
+	chapters, err := CollectChapters(writeProject(t))
+	if err != nil {
+		t.Fatalf("CollectChapters failed: %v", err)
+	}
+
+	if len(chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d", len(chapters))
+	}
+	if chapters[0].Title != "First Page" || chapters[1].Title != "Second Page" {
+		t.Errorf("Expected chapters in file name order, got %q and %q", chapters[0].Title, chapters[1].Title)
+	}
+
+	if _, err := CollectChapters(t.TempDir()); err == nil {
+		t.Error("Expected an error for a project without markdown files")
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	chapters := []Chapter{
+		{Title: "Intro", Content: "# Heading\n\nText"},
+		{Title: "Intro", Content: "More"},
+	}
+
+	markdown := RenderMarkdown("Book", chapters)
+	for _, want := range []string{"# Book\n", "1. [Intro](#intro)", "2. [Intro](#intro-1)", "## Intro\n", "## Heading", "More"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestMarkdownToXHTML(t *testing.T) {
+	xhtml := markdownToXHTML("## Title\n\nSome **bold** & *italic* [link](https://example.com)\n\n> quoted\n\n- one\n- two\n\n---")
+	for _, want := range []string{
+		"<h2>Title</h2>",
+		"<strong>bold</strong> &amp; <em>italic</em>",
+		`<a href="https://example.com">link</a>`,
+		"<blockquote><p>quoted</p></blockquote>",
+		"<ul>\n<li>one</li>\n<li>two</li>\n</ul>",
+		"<hr/>",
+	} {
+		if !strings.Contains(xhtml, want) {
+			t.Errorf("Expected XHTML to contain %q, got:\n%s", want, xhtml)
+		}
+	}
+}
+
+func TestWriteEPUBRoundTrip(t *testing.T) {
+	chapters, err := CollectChapters(writeProject(t))
+	if err != nil {
+		t.Fatalf("CollectChapters failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "book.epub")
+	if err := Write(path, "My Project", chapters, FormatEPUB); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	title, content, err := epubextractors.ExtractFromFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read exported epub: %v", err)
+	}
+	if title != "My Project" {
+		t.Errorf("Expected title 'My Project', got %q", title)
+	}
+
+	first := strings.Index(content, "First page with")
+	second := strings.Index(content, "Second page text.")
+	if first < 0 || second < 0 {
+		t.Fatalf("Expected both chapters, got:\n%s", content)
+	}
+	if first > second {
+		t.Error("Expected chapters in file name order")
+	}
+
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	if reader.File[0].Name != "mimetype" || reader.File[0].Method != zip.Store {
+		t.Error("Expected an uncompressed mimetype entry first")
+	}
+	for _, f := range reader.File {
+		if f.Name != "OEBPS/nav.xhtml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		nav, _ := io.ReadAll(rc)
+		rc.Close()
+		for _, want := range []string{`<a href="chapter001.xhtml">First Page</a>`, `<a href="chapter002.xhtml">Second Page</a>`} {
+			if !strings.Contains(string(nav), want) {
+				t.Errorf("Expected table of contents to contain %q, got:\n%s", want, nav)
+			}
+		}
+		return
+	}
+	t.Error("Expected a nav.xhtml table of contents")
+}
@@ -0,0 +1,29 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"maai.solutions/gengo/internal/text"
+)
+
+// RenderMarkdown concatenates chapters into one markdown document with a
+// linked table of contents
+func RenderMarkdown(title string, chapters []Chapter) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n## Contents\n\n", title)
+
+	var slugger text.Slugger
+	for i, chapter := range chapters {
+		fmt.Fprintf(&b, "%d. [%s](#%s)\n", i+1, chapter.Title, slugger.Slug(chapter.Title))
+	}
+
+	for _, chapter := range chapters {
+		fmt.Fprintf(&b, "\n---\n\n## %s\n\n", chapter.Title)
+		// Demote chapter headings so chapter titles stay on top
+		b.WriteString(text.ShiftHeadings(strings.TrimSpace(chapter.Content), 1))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -5,19 +5,43 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
 )
 
 // Config holds configuration for the ASR service
 type Config struct {
-	WhisperModel string // path to the whisper model file (e.g., ggml-base.bin)
-	Language     string // optional: auto-detect if empty
+	WhisperModel string       // path to the whisper model file (e.g., ggml-base.bin)
+	Language     string       // optional: auto-detect if empty
+	FFmpegPath   string       // optional: ffmpeg binary to use instead of the one on PATH
+	Progress     ProgressFunc // optional: receives conversion and transcription progress
+	KeepWAV      bool         // optional: keep the 16kHz mono WAV ffmpeg converts to, for debugging conversions
+	AudioFilter  string       // optional: ffmpeg audio filter graph applied when converting, e.g. DenoiseFilter
+
+	// PerSegmentLanguage detects the language of every LanguageWindow of
+	// audio separately when Language is empty, for recordings that switch
+	// languages, and labels each segment with it
+	PerSegmentLanguage bool
+
+	// SkipSilence transcribes only the parts of the audio louder than
+	// SilenceThreshold, in dBFS (0: DefaultSilenceThreshold), saving time on
+	// long pauses and keeping whisper from making up text in them
+	SkipSilence      bool
+	SilenceThreshold float64
 }
 
+// ProgressFunc reports the percentage complete of a processing stage
+type ProgressFunc func(stage string, percent float64)
+
+// Progress stages reported to a ProgressFunc
+const (
+	StageConvert    = "convert"
+	StageTranscribe = "transcribe"
+)
+
 // DefaultConfig returns a default ASR configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -26,15 +50,30 @@ func DefaultConfig() *Config {
 	}
 }
 
+// Segment is a timed piece of a transcript
+type Segment struct {
+	Start      time.Duration
+	End        time.Duration
+	Text       string
+	Confidence float32 // mean probability of the segment's text tokens, 0 when unknown
+	Language   string  // language detected for the segment with Config.PerSegmentLanguage
+}
+
 // Result holds the result of ASR transcription
 type Result struct {
 	Text     string
-	Language string // detected or specified language
+	Language string // detected or specified language; with per-segment detection the one spoken longest
+	Segments []Segment
+	WAVPath  string // converted audio kept with Config.KeepWAV
+	Partial  bool   // the context ended before the whole audio was transcribed
 }
 
 // Service handles automatic speech recognition
 type Service struct {
 	config *Config
+
+	mu    sync.Mutex
+	model whisper.Model // model kept by LoadModel, nil to load one per transcription
 }
 
 // NewService creates a new ASR service
@@ -45,43 +84,171 @@ func NewService(config *Config) *Service {
 	return &Service{config: config}
 }
 
-// TranscribeFile transcribes audio from a WAV file
-func (s *Service) TranscribeFile(ctx context.Context, audioPath string) (*Result, error) {
+// LoadModel loads the whisper model and keeps it for every following
+// transcription until Close, instead of loading it for each one. Services
+// transcribing a batch of files load the model once this way.
+func (s *Service) LoadModel() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.model != nil {
+		return nil
+	}
+	model, err := s.loadModel()
+	if err != nil {
+		return err
+	}
+	s.model = model
+	return nil
+}
+
+// Close releases the model kept by LoadModel
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.model == nil {
+		return nil
+	}
+	err := s.model.Close()
+	s.model = nil
+	return err
+}
+
+// loadModel loads the configured whisper model
+func (s *Service) loadModel() (whisper.Model, error) {
 	// Check if model file exists
 	if _, err := os.Stat(s.config.WhisperModel); err != nil {
-		return nil, fmt.Errorf("whisper model file not found: %s", s.config.WhisperModel)
+		return nil, modelPathNotFound(s.config.WhisperModel)
 	}
 
-	// Initialize whisper model
 	model, err := whisper.New(s.config.WhisperModel)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load whisper model: %w", err)
 	}
-	defer model.Close()
+	return model, nil
+}
 
-	// Create context for processing
-	context, err := model.NewContext()
+// TranscribeFile transcribes audio from a 16-bit PCM WAV file, converting
+// any sample rate or channel count to the 16kHz mono audio whisper expects
+func (s *Service) TranscribeFile(ctx context.Context, audioPath string) (*Result, error) {
+	// Load the audio data
+	data, err := loadAudioData(audioPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create whisper context: %w", err)
+		return nil, fmt.Errorf("failed to load audio data: %w", err)
 	}
+	return s.transcribe(ctx, data)
+}
 
-	// Set language if specified
-	if s.config.Language != "" {
-		if err := context.SetLanguage(s.config.Language); err != nil {
-			return nil, fmt.Errorf("failed to set language: %w", err)
+// transcribe runs whisper over 16kHz mono samples. Whisper transcribes the
+// audio in 30-second windows; when ctx ends, it stops before the next window
+// and the segments transcribed so far are returned as a partial result
+// rather than thrown away. Only a context that ends before the first
+// segment is an error.
+func (s *Service) transcribe(ctx context.Context, data []float32) (*Result, error) {
+	// Use the model kept by LoadModel, one transcription at a time, or load
+	// one for this transcription
+	s.mu.Lock()
+	model := s.model
+	if model != nil {
+		defer s.mu.Unlock()
+	} else {
+		s.mu.Unlock()
+		var err error
+		if model, err = s.loadModel(); err != nil {
+			return nil, err
 		}
+		defer model.Close()
 	}
 
-	// Load and process audio data
-	data, err := loadAudioData(audioPath)
+	// Transcribe only the speech, and put the segments back at their place
+	// in the recording
+	var timeline silenceTimeline
+	if s.config.SkipSilence {
+		data, timeline = skipSilence(data, s.config.SilenceThreshold)
+		if len(data) == 0 {
+			return &Result{}, nil
+		}
+	}
+	result, err := s.run(ctx, model, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load audio data: %w", err)
+		return nil, err
 	}
+	timeline.remap(result.Segments)
+	return result, nil
+}
 
-	// Process the audio data
-	err = context.Process(data, nil, nil, nil)
+// run transcribes data with model, in one pass or, with
+// Config.PerSegmentLanguage, one LanguageWindow at a time
+func (s *Service) run(ctx context.Context, model whisper.Model, data []float32) (*Result, error) {
+	if s.config.PerSegmentLanguage && s.config.Language == "" {
+		return s.transcribeWindows(ctx, model, data)
+	}
+
+	var onProgress whisper.ProgressCallback
+	if s.config.Progress != nil {
+		onProgress = func(percent int) {
+			s.config.Progress(StageTranscribe, float64(percent))
+		}
+	}
+	p, err := s.process(ctx, model, data, s.config.Language, 0, onProgress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process audio: %w", err)
+		return nil, err
+	}
+
+	// Report the requested language, or the one whisper detected
+	language := s.config.Language
+	if language == "" {
+		language = p.detected
+	}
+
+	if p.stopped && len(p.segments) == 0 {
+		return nil, fmt.Errorf("failed to process audio: %w", ctx.Err())
+	}
+
+	return &Result{
+		Text:     strings.TrimSpace(p.text),
+		Language: language,
+		Segments: p.segments,
+		Partial:  p.stopped,
+	}, nil
+}
+
+// pass is the outcome of running whisper over a stretch of audio once
+type pass struct {
+	text     string // segment texts as whisper wrote them, one per line
+	segments []Segment
+	detected string // language whisper detected
+	stopped  bool   // ctx ended before the whole audio was processed
+}
+
+// process runs whisper over data in a new context, in language or detecting
+// it when language is "". Segment times are shifted by offset, the position
+// of data in the whole recording.
+func (s *Service) process(ctx context.Context, model whisper.Model, data []float32, language string, offset time.Duration, onProgress whisper.ProgressCallback) (pass, error) {
+	// Create context for processing
+	context, err := model.NewContext()
+	if err != nil {
+		return pass{}, fmt.Errorf("failed to create whisper context: %w", err)
+	}
+
+	// Set language if specified
+	if language != "" {
+		if err := context.SetLanguage(language); err != nil {
+			return pass{}, fmt.Errorf("failed to set language: %w", err)
+		}
+	}
+
+	// Process the audio data
+	var p pass
+	onEncoderBegin := func() bool {
+		if ctx.Err() != nil {
+			p.stopped = true
+			return false
+		}
+		return true
+	}
+	err = context.Process(data, onEncoderBegin, nil, onProgress)
+	if err != nil && !p.stopped {
+		return pass{}, fmt.Errorf("failed to process audio: %w", err)
 	}
 
 	// Collect all segments
@@ -92,69 +259,62 @@ func (s *Service) TranscribeFile(ctx context.Context, audioPath string) (*Result
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to get segment: %w", err)
+			return pass{}, fmt.Errorf("failed to get segment: %w", err)
 		}
 		text.WriteString(segment.Text)
 		text.WriteString("\n")
+		p.segments = append(p.segments, Segment{
+			Start:      offset + segment.Start,
+			End:        offset + segment.End,
+			Text:       strings.TrimSpace(segment.Text),
+			Confidence: segmentConfidence(context, segment.Tokens),
+		})
 	}
+	p.text = text.String()
+	p.detected = context.DetectedLanguage()
+	return p, nil
+}
 
-	return &Result{
-		Text:     strings.TrimSpace(text.String()),
-		Language: s.config.Language, // TODO: get detected language from whisper
-	}, nil
+// segmentConfidence averages the probabilities of a segment's text tokens,
+// ignoring timestamps and other special tokens
+func segmentConfidence(context whisper.Context, tokens []whisper.Token) float32 {
+	var sum float32
+	n := 0
+	for _, token := range tokens {
+		if context.IsText(token) {
+			sum += token.P
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float32(n)
 }
 
-// TranscribeAudio transcribes audio from any supported format by first converting to WAV
+// TranscribeAudio transcribes audio from any format ffmpeg reads by first
+// converting it to WAV. With Config.KeepWAV the WAV is left in tempDir, named
+// after the input file, and Result.WAVPath points at it.
 func (s *Service) TranscribeAudio(ctx context.Context, inputPath, tempDir string) (*Result, error) {
-	// Generate temporary WAV file path
-	wavPath := filepath.Join(tempDir, "temp_audio.wav")
-
-	// Convert audio to WAV format suitable for Whisper
-	if err := convertToWAV(ctx, inputPath, wavPath); err != nil {
-		return nil, fmt.Errorf("failed to convert audio to WAV: %w", err)
-	}
-	defer os.Remove(wavPath) // Clean up temp file
-
-	// Transcribe the WAV file
-	return s.TranscribeFile(ctx, wavPath)
-}
-
-// convertToWAV converts any audio file to 16kHz mono 16-bit WAV using FFmpeg
-func convertToWAV(ctx context.Context, inputPath, outputPath string) error {
-	cmd := exec.CommandContext(ctx, "ffmpeg",
-		"-i", inputPath,           // Input file
-		"-acodec", "pcm_s16le",    // Output codec: 16-bit PCM
-		"-ar", "16000",            // Sample rate: 16kHz (required by whisper)
-		"-ac", "1",                // Channels: mono
-		"-y",                      // Overwrite output file
-		outputPath,                // Output file
-	)
-	
-	// Capture stderr for error reporting
-	output, err := cmd.CombinedOutput()
+	data, err := s.decodeAudio(ctx, inputPath, tempDir)
 	if err != nil {
-		return fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, string(output))
+		return nil, err
 	}
-	
-	return nil
+
+	result, err := s.transcribe(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.KeepWAV {
+		result.WAVPath = keptWAVPath(inputPath, tempDir)
+	}
+	return result, nil
 }
 
 // FindWhisperModel tries to find the whisper model in common locations
+// and returns its path, or "" when none of ModelSearchPaths exists
 func FindWhisperModel(modelName string) string {
-	modelFilename := "ggml-" + modelName + ".bin"
-
-	// Common locations where whisper models might be stored
-	commonPaths := []string{
-		"./models/",
-		"./whisper-models/",
-		"/usr/local/share/whisper/",
-		"/opt/whisper/models/",
-		filepath.Join(os.Getenv("HOME"), ".cache/whisper/"),
-		filepath.Join(os.Getenv("HOME"), ".local/share/whisper/"),
-	}
-
-	for _, basePath := range commonPaths {
-		fullPath := filepath.Join(basePath, modelFilename)
+	for _, fullPath := range ModelSearchPaths(modelName) {
 		if _, err := os.Stat(fullPath); err == nil {
 			return fullPath
 		}
@@ -165,9 +325,6 @@ func FindWhisperModel(modelName string) string {
 
 // CheckDependencies verifies that required external tools are available
 func CheckDependencies() error {
-	// Check if FFmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return fmt.Errorf("ffmpeg not found in PATH: %w\nPlease install FFmpeg (https://ffmpeg.org/download.html)", err)
-	}
-	return nil
-}
\ No newline at end of file
+	_, err := lookFFmpeg("")
+	return err
+}
@@ -7,7 +7,20 @@ import (
 	"os"
 )
 
-// loadAudioData loads WAV audio file and converts it to float32 samples
+// pcmBufferSize is how many bytes of PCM data are converted at a time, so
+// memory use stays close to the size of the resulting samples
+const pcmBufferSize = 64 * 1024
+
+// wavFormat describes the PCM data in a WAV file
+type wavFormat struct {
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+	dataSize      int64 // size of the data chunk, or -1 when it runs to the end of the file
+}
+
+// loadAudioData loads a 16-bit PCM WAV file and converts it to the 16kHz mono
+// float32 samples whisper expects, downmixing and resampling other layouts
 func loadAudioData(audioPath string) ([]float32, error) {
 	// Open the WAV file
 	file, err := os.Open(audioPath)
@@ -16,44 +29,116 @@ func loadAudioData(audioPath string) ([]float32, error) {
 	}
 	defer file.Close()
 
-	// Skip WAV header (44 bytes)
-	header := make([]byte, 44)
-	if _, err := file.Read(header); err != nil {
-		return nil, fmt.Errorf("failed to read WAV header: %w", err)
+	format, err := readWAVHeader(file)
+	if err != nil {
+		return nil, err
+	}
+	if format.bitsPerSample != 16 || format.channels < 1 || format.sampleRate < 1 {
+		return nil, fmt.Errorf("unexpected audio format: %d channels, %d Hz, %d bits", format.channels, format.sampleRate, format.bitsPerSample)
 	}
 
-	// Verify it's a valid WAV file
-	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
-		return nil, fmt.Errorf("invalid WAV file format")
+	// Trust the file over a corrupt or truncated header when sizing the samples
+	if info, err := file.Stat(); err == nil {
+		if pos, err := file.Seek(0, io.SeekCurrent); err == nil && format.dataSize > info.Size()-pos {
+			format.dataSize = info.Size() - pos
+		}
+	}
+
+	samples, err := readPCM16(file, format)
+	if err != nil {
+		return nil, err
 	}
+	return resample(samples, format.sampleRate, SampleRate), nil
+}
 
-	// Get audio parameters from header
-	channels := binary.LittleEndian.Uint16(header[22:24])
-	sampleRate := binary.LittleEndian.Uint32(header[24:28])
-	bitsPerSample := binary.LittleEndian.Uint16(header[34:36])
+// readPCM16 converts the 16-bit frames of a data chunk to float32 samples
+// normalized to [-1, 1], averaging channels. The data is read through a fixed
+// buffer rather than all at once, so only the samples themselves are held.
+func readPCM16(r io.Reader, format wavFormat) ([]float32, error) {
+	frameSize := 2 * format.channels
 
-	// Verify expected format (16kHz mono 16-bit)
-	if channels != 1 || sampleRate != 16000 || bitsPerSample != 16 {
-		return nil, fmt.Errorf("unexpected audio format: %d channels, %d Hz, %d bits", channels, sampleRate, bitsPerSample)
+	var samples []float32
+	if format.dataSize >= 0 {
+		r = io.LimitReader(r, format.dataSize)
+		samples = make([]float32, 0, format.dataSize/int64(frameSize))
 	}
 
-	// Read the rest of the file as audio data
-	audioData, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read audio data: %w", err)
+	buf := make([]byte, pcmBufferSize-pcmBufferSize%frameSize)
+	scale := float32(32768 * format.channels)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n%frameSize != 0 {
+			return nil, fmt.Errorf("invalid audio data length for 16-bit samples")
+		}
+		for i := 0; i < n; i += frameSize {
+			var sum float32
+			for c := 0; c < format.channels; c++ {
+				sum += float32(int16(binary.LittleEndian.Uint16(buf[i+c*2:])))
+			}
+			samples = append(samples, sum/scale)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audio data: %w", err)
+		}
 	}
+}
 
-	// Convert 16-bit samples to float32
-	if len(audioData)%2 != 0 {
-		return nil, fmt.Errorf("invalid audio data length for 16-bit samples")
+// readWAVHeader reads the RIFF header and chunks up to the start of the
+// audio data, leaving r positioned at the first sample
+func readWAVHeader(r io.Reader) (wavFormat, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return wavFormat{}, fmt.Errorf("failed to read WAV header: %w", err)
 	}
 
-	samples := make([]float32, len(audioData)/2)
-	for i := 0; i < len(samples); i++ {
-		// Convert int16 to float32 normalized to [-1, 1]
-		sample := int16(binary.LittleEndian.Uint16(audioData[i*2 : i*2+2]))
-		samples[i] = float32(sample) / 32768.0
+	// Verify it's a valid WAV file
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return wavFormat{}, fmt.Errorf("invalid WAV file format")
 	}
 
-	return samples, nil
-}
\ No newline at end of file
+	var format wavFormat
+	haveFormat := false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return wavFormat{}, fmt.Errorf("failed to read WAV header: no data chunk")
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return wavFormat{}, fmt.Errorf("invalid WAV format chunk")
+			}
+			fmtChunk := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return wavFormat{}, fmt.Errorf("failed to read WAV header: %w", err)
+			}
+			format.channels = int(binary.LittleEndian.Uint16(fmtChunk[2:4]))
+			format.sampleRate = int(binary.LittleEndian.Uint32(fmtChunk[4:8]))
+			format.bitsPerSample = int(binary.LittleEndian.Uint16(fmtChunk[14:16]))
+			haveFormat = true
+
+		case "data":
+			if !haveFormat {
+				return wavFormat{}, fmt.Errorf("invalid WAV file: data before format chunk")
+			}
+			// Streaming writers leave the size unset; read to the end instead
+			format.dataSize = size
+			if size == 0 || size == 0xFFFFFFFF {
+				format.dataSize = -1
+			}
+			return format, nil
+
+		default:
+			// Skip metadata such as LIST chunks, including the pad byte
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return wavFormat{}, fmt.Errorf("failed to read WAV header: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package asr
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAudioDataResamplesStereo(t *testing.T) {
+	// One second of a 440Hz tone at half volume in the left channel, silence in the right
+	const rate = 44100
+	samples := make([]int16, 0, rate*2)
+	for i := 0; i < rate; i++ {
+		left := int16(16384 * math.Sin(2*math.Pi*440*float64(i)/rate))
+		samples = append(samples, left, 0)
+	}
+	path := filepath.Join(t.TempDir(), "stereo.wav")
+	writeWAV(t, path, rate, 2, samples)
+
+	data, err := loadAudioData(path)
+	if err != nil {
+		t.Fatalf("loadAudioData failed: %v", err)
+	}
+	if len(data) != SampleRate {
+		t.Fatalf("Expected %d samples, got %d", SampleRate, len(data))
+	}
+
+	// Downmixing halves the amplitude; the tone must survive resampling
+	var peak float32
+	crossings := 0
+	for i, v := range data {
+		peak = max(peak, v)
+		if i > 0 && (data[i-1] < 0) != (v < 0) {
+			crossings++
+		}
+	}
+	if peak < 0.23 || peak > 0.26 {
+		t.Errorf("Expected a peak near 0.25, got %v", peak)
+	}
+	if crossings < 870 || crossings > 890 {
+		t.Errorf("Expected about 880 zero crossings for a 440Hz tone, got %d", crossings)
+	}
+}
+
+func TestLoadAudioDataSkipsChunks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tagged.wav")
+	writeWAV(t, path, SampleRate, 1, []int16{16384, -16384})
+
+	// Insert an odd-sized LIST chunk between the format and data chunks
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	list := []byte("LIST\x03\x00\x00\x00abc\x00")
+	tagged := append(append(append([]byte{}, data[:36]...), list...), data[36:]...)
+	binary.LittleEndian.PutUint32(tagged[4:], uint32(len(tagged)-8))
+	if err := os.WriteFile(path, tagged, 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	samples, err := loadAudioData(path)
+	if err != nil {
+		t.Fatalf("loadAudioData failed: %v", err)
+	}
+	if len(samples) != 2 || samples[0] != 0.5 || samples[1] != -0.5 {
+		t.Errorf("Unexpected samples: %v", samples)
+	}
+}
+
+func TestLoadAudioDataRejectsUnsupported(t *testing.T) {
+	dir := t.TempDir()
+
+	notWAV := filepath.Join(dir, "notes.wav")
+	if err := os.WriteFile(notWAV, []byte("this is not a wav file at all"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	if _, err := loadAudioData(notWAV); err == nil {
+		t.Error("Expected error for a non-WAV file")
+	}
+
+	// 8-bit PCM is not supported
+	eightBit := filepath.Join(dir, "8bit.wav")
+	writeWAV(t, eightBit, SampleRate, 1, []int16{0})
+	data, err := os.ReadFile(eightBit)
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	binary.LittleEndian.PutUint16(data[34:], 8)
+	if err := os.WriteFile(eightBit, data, 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	if _, err := loadAudioData(eightBit); err == nil {
+		t.Error("Expected error for 8-bit audio")
+	}
+}
+
+func BenchmarkLoadAudioData(b *testing.B) {
+	// Ten minutes of 16kHz mono audio, the format ffmpeg produces for whisper
+	const frames = 10 * 60 * SampleRate
+	data := make([]byte, 44+frames*2)
+	copy(data[0:], "RIFF")
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+	copy(data[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(data[16:], 16)
+	binary.LittleEndian.PutUint16(data[20:], 1)
+	binary.LittleEndian.PutUint16(data[22:], 1)
+	binary.LittleEndian.PutUint32(data[24:], SampleRate)
+	binary.LittleEndian.PutUint32(data[28:], SampleRate*2)
+	binary.LittleEndian.PutUint16(data[32:], 2)
+	binary.LittleEndian.PutUint16(data[34:], 16)
+	copy(data[36:], "data")
+	binary.LittleEndian.PutUint32(data[40:], frames*2)
+	for i := 0; i < frames; i++ {
+		binary.LittleEndian.PutUint16(data[44+i*2:], uint16(int16(i%65536-32768)))
+	}
+
+	path := filepath.Join(b.TempDir(), "long.wav")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		b.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		samples, err := loadAudioData(path)
+		if err != nil {
+			b.Fatalf("loadAudioData failed: %v", err)
+		}
+		if len(samples) != frames {
+			b.Fatalf("Expected %d samples, got %d", frames, len(samples))
+		}
+	}
+}
+
+func TestLoadAudioDataTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "truncated.wav")
+	writeWAV(t, path, SampleRate, 1, []int16{16384, -16384})
+
+	// Claim far more data than the file holds
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	binary.LittleEndian.PutUint32(data[40:], 0x7FFFFFF0)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	samples, err := loadAudioData(path)
+	if err != nil {
+		t.Fatalf("loadAudioData failed: %v", err)
+	}
+	if len(samples) != 2 || cap(samples) != 2 {
+		t.Errorf("Expected 2 samples sized from the file, got len %d cap %d", len(samples), cap(samples))
+	}
+}
@@ -0,0 +1,127 @@
+package asr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits for what counts as an obvious verbatim repetition
+const (
+	maxRepeatWords = 8 // longest phrase checked for repetition, in words
+	minRepeats     = 3 // consecutive occurrences before a phrase is collapsed
+)
+
+// CleanOptions controls the transcript post-filter
+type CleanOptions struct {
+	MinConfidence float32 // drop segments whose confidence is known and below this (0 keeps all)
+}
+
+// CleanReport counts what the transcript post-filter changed
+type CleanReport struct {
+	Total         int // segments before filtering
+	Collapsed     int // segments whose repeated phrases were collapsed
+	Repeated      int // segments dropped as repeats of the previous segment
+	LowConfidence int // segments dropped for low confidence
+}
+
+// Filtered returns the number of segments dropped
+func (r CleanReport) Filtered() int {
+	return r.Repeated + r.LowConfidence
+}
+
+// String summarizes the report in one line
+func (r CleanReport) String() string {
+	return fmt.Sprintf("Filtered %d of %d segments (%d repeated, %d low confidence), collapsed repetitions in %d",
+		r.Filtered(), r.Total, r.Repeated, r.LowConfidence, r.Collapsed)
+}
+
+// CleanSegments removes low-quality fragments whisper tends to produce on
+// noisy audio or silence: phrases repeated verbatim within a segment are
+// collapsed to one occurrence, segments repeating the previous one are
+// dropped, and so are segments below opts.MinConfidence.
+func CleanSegments(segments []Segment, opts CleanOptions) ([]Segment, CleanReport) {
+	report := CleanReport{Total: len(segments)}
+	cleaned := make([]Segment, 0, len(segments))
+
+	previous := ""
+	for _, segment := range segments {
+		if opts.MinConfidence > 0 && segment.Confidence > 0 && segment.Confidence < opts.MinConfidence {
+			report.LowConfidence++
+			continue
+		}
+
+		if text, ok := collapseRepeats(segment.Text); ok {
+			segment.Text = text
+			report.Collapsed++
+		}
+
+		key := normalizeWords(strings.Fields(segment.Text))
+		if key == "" {
+			continue
+		}
+		if key == previous {
+			report.Repeated++
+			continue
+		}
+		previous = key
+		cleaned = append(cleaned, segment)
+	}
+	return cleaned, report
+}
+
+// SegmentText joins segment texts into a transcript, one segment per line
+func SegmentText(segments []Segment) string {
+	lines := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		lines = append(lines, segment.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapseRepeats reduces phrases of up to maxRepeatWords words repeated at
+// least minRepeats times in a row to a single occurrence, reporting whether
+// anything changed. Comparison ignores case and surrounding punctuation.
+func collapseRepeats(text string) (string, bool) {
+	words := strings.Fields(text)
+	changed := false
+
+	for n := 1; n <= maxRepeatWords && n*minRepeats <= len(words); n++ {
+		out := make([]string, 0, len(words))
+		for i := 0; i < len(words); {
+			count := 1
+			if i+n <= len(words) {
+				phrase := normalizeWords(words[i : i+n])
+				for j := i + n; j+n <= len(words) && normalizeWords(words[j:j+n]) == phrase; j += n {
+					count++
+				}
+			}
+			if count >= minRepeats {
+				out = append(out, words[i:i+n]...)
+				i += count * n
+				changed = true
+				continue
+			}
+			out = append(out, words[i])
+			i++
+		}
+		words = out
+	}
+
+	if !changed {
+		return text, false
+	}
+	return strings.Join(words, " "), true
+}
+
+// normalizeWords lowercases words and strips surrounding punctuation so
+// repetitions match regardless of capitalization and sentence breaks
+func normalizeWords(words []string) string {
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.Trim(word, ".,!?;:\"'()[]-…"))
+		if word != "" {
+			normalized = append(normalized, word)
+		}
+	}
+	return strings.Join(normalized, " ")
+}
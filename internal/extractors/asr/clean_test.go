@@ -0,0 +1,73 @@
+package asr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCollapseRepeats(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		changed bool
+	}{
+		{"the the the the cat", "the cat", true},
+		{"Thank you. Thank you. Thank you.", "Thank you.", true},
+		{"I said go go go now", "I said go now", true},
+		{"very very good", "very very good", false},
+		{"a normal sentence without repeats", "a normal sentence without repeats", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, changed := collapseRepeats(tt.input)
+		if got != tt.want || changed != tt.changed {
+			t.Errorf("collapseRepeats(%q) = %q, %t; want %q, %t", tt.input, got, changed, tt.want, tt.changed)
+		}
+	}
+}
+
+func TestCleanSegments(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: time.Second, Text: "Welcome to the show.", Confidence: 0.9},
+		{Start: time.Second, End: 2 * time.Second, Text: "Thanks for watching!", Confidence: 0.8},
+		{Start: 2 * time.Second, End: 3 * time.Second, Text: "thanks for watching", Confidence: 0.8},
+		{Start: 3 * time.Second, End: 4 * time.Second, Text: "mumble", Confidence: 0.2},
+		{Start: 4 * time.Second, End: 5 * time.Second, Text: "so so so so today", Confidence: 0.7},
+		{Start: 5 * time.Second, End: 6 * time.Second, Text: "no confidence known"},
+	}
+
+	cleaned, report := CleanSegments(segments, CleanOptions{MinConfidence: 0.5})
+
+	want := []string{"Welcome to the show.", "Thanks for watching!", "so today", "no confidence known"}
+	if len(cleaned) != len(want) {
+		t.Fatalf("got %d segments %v, want %d", len(cleaned), cleaned, len(want))
+	}
+	for i, text := range want {
+		if cleaned[i].Text != text {
+			t.Errorf("segment %d = %q, want %q", i, cleaned[i].Text, text)
+		}
+	}
+
+	wantReport := CleanReport{Total: 6, Collapsed: 1, Repeated: 1, LowConfidence: 1}
+	if report != wantReport {
+		t.Errorf("report = %+v, want %+v", report, wantReport)
+	}
+	if report.Filtered() != 2 {
+		t.Errorf("Filtered() = %d, want 2", report.Filtered())
+	}
+}
+
+func TestCleanSegmentsWithoutThreshold(t *testing.T) {
+	segments := []Segment{{Text: "quiet", Confidence: 0.1}}
+	cleaned, report := CleanSegments(segments, CleanOptions{})
+	if len(cleaned) != 1 || report.Filtered() != 0 {
+		t.Errorf("got %v, %+v; low confidence segments should be kept without a threshold", cleaned, report)
+	}
+}
+
+func TestSegmentText(t *testing.T) {
+	got := SegmentText([]Segment{{Text: "one"}, {Text: "two"}})
+	if got != "one\ntwo" {
+		t.Errorf("SegmentText = %q", got)
+	}
+}
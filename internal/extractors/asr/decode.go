@@ -0,0 +1,44 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// decodeAudio converts an audio file to whisper samples with ffmpeg
+func (s *Service) decodeAudio(ctx context.Context, inputPath, tempDir string) (_ []float32, err error) {
+	// Generate temporary WAV file path
+	wavPath := filepath.Join(tempDir, "temp_audio.wav")
+	if s.config.KeepWAV {
+		wavPath = keptWAVPath(inputPath, tempDir)
+	}
+
+	// Clean up the temp file, including one left half-written by a failed
+	// conversion. A complete one is kept when asked for.
+	defer func() {
+		if err != nil || !s.config.KeepWAV {
+			os.Remove(wavPath)
+		}
+	}()
+
+	// Convert audio to WAV format suitable for Whisper
+	if err := convertToWAV(ctx, s.config.FFmpegPath, inputPath, wavPath, s.config.AudioFilter, s.config.Progress); err != nil {
+		return nil, fmt.Errorf("failed to convert audio to WAV: %w", err)
+	}
+
+	data, err := loadAudioData(wavPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audio data: %w", err)
+	}
+	return data, nil
+}
+
+// keptWAVPath names the WAV kept with Config.KeepWAV after the input file,
+// e.g. talk_16k.wav for talk.mp4
+func keptWAVPath(inputPath, tempDir string) string {
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	return filepath.Join(tempDir, base+"_16k.wav")
+}
@@ -0,0 +1,90 @@
+package asr
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeWAV writes a 16-bit PCM WAV file with interleaved samples
+func writeWAV(t *testing.T, path string, sampleRate, channels int, samples []int16) {
+	t.Helper()
+
+	dataSize := len(samples) * 2
+	header := make([]byte, 44)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(36+dataSize))
+	copy(header[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], 1)
+	binary.LittleEndian.PutUint16(header[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(header[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(sampleRate*channels*2))
+	binary.LittleEndian.PutUint16(header[32:], uint16(channels*2))
+	binary.LittleEndian.PutUint16(header[34:], 16)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(dataSize))
+
+	data := make([]byte, dataSize)
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+	if err := os.WriteFile(path, append(header, data...), 0644); err != nil {
+		t.Fatalf("Failed to write WAV fixture: %v", err)
+	}
+}
+
+func TestDecodeAudioWithoutFFmpeg(t *testing.T) {
+	dir := t.TempDir()
+	service := NewService(&Config{FFmpegPath: filepath.Join(dir, "missing-ffmpeg")})
+
+	// Every format, WAV included, is converted by ffmpeg
+	for _, name := range []string{"speech.wav", "speech.mp3", "speech.m4a"} {
+		path := filepath.Join(dir, name)
+		writeWAV(t, path, SampleRate, 1, []int16{0, 16384})
+		if _, err := service.decodeAudio(context.Background(), path, dir); err == nil || !strings.Contains(err.Error(), "ffmpeg not found") {
+			t.Errorf("Expected a missing ffmpeg error for %s, got: %v", name, err)
+		}
+	}
+}
+
+func TestDecodeAudioKeepWAV(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg script requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "fixture.wav")
+	writeWAV(t, fixture, SampleRate, 1, []int16{0, 16384})
+
+	// Stands in for ffmpeg by copying the fixture to the output path
+	ffmpegPath := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\nfor last; do :; done\ncp " + fixture + " \"$last\"\n"
+	if err := os.WriteFile(ffmpegPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	inputPath := filepath.Join(dir, "video_1.mp4")
+	for _, keep := range []bool{false, true} {
+		service := NewService(&Config{FFmpegPath: ffmpegPath, KeepWAV: keep})
+		if _, err := service.decodeAudio(context.Background(), inputPath, dir); err != nil {
+			t.Fatalf("decodeAudio failed: %v", err)
+		}
+
+		kept := filepath.Join(dir, "video_1_16k.wav")
+		_, err := os.Stat(kept)
+		if keep && err != nil {
+			t.Errorf("Expected the converted WAV to be kept at %s: %v", kept, err)
+		}
+		if !keep && err == nil {
+			t.Errorf("Expected no WAV to be kept without KeepWAV")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "temp_audio.wav")); err == nil {
+			t.Errorf("Expected the temporary WAV to be removed (keep=%t)", keep)
+		}
+	}
+}
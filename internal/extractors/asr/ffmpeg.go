@@ -0,0 +1,206 @@
+package asr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// durationPattern matches the input duration ffmpeg prints to stderr
+var durationPattern = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+
+// Recommended audio filters for Config.AudioFilter, for noisy recordings
+const (
+	// DenoiseFilter cuts rumble below 80 Hz and hiss above 8 kHz, outside the
+	// range of speech, and reduces the broadband noise that remains
+	DenoiseFilter = "highpass=f=80,lowpass=f=8000,afftdn=nf=-25"
+
+	// NormalizeFilter evens out the loudness of quiet or distant speakers
+	NormalizeFilter = "dynaudnorm"
+)
+
+// JoinFilters chains ffmpeg audio filters in order, skipping empty ones
+func JoinFilters(filters ...string) string {
+	var chain []string
+	for _, filter := range filters {
+		if filter = strings.Trim(strings.TrimSpace(filter), ","); filter != "" {
+			chain = append(chain, filter)
+		}
+	}
+	return strings.Join(chain, ",")
+}
+
+// CheckAudioFilter reports whether ffmpeg accepts an audio filter graph by
+// running it on a moment of generated silence, so a mistyped filter fails
+// before a long download or conversion rather than after it
+func CheckAudioFilter(ctx context.Context, ffmpegPath, filter string) error {
+	path, err := lookFFmpeg(ffmpegPath)
+	if err != nil {
+		return fmt.Errorf("audio filters need ffmpeg: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, path,
+		"-hide_banner", "-nostdin",
+		"-f", "lavfi", "-i", "anullsrc=r=16000:cl=mono", // Generated silence
+		"-t", "0.1",
+		"-af", filter,
+		"-f", "null", "-", // Discard the output
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		reason := err.Error()
+		if lines := strings.Split(strings.TrimSpace(string(out)), "\n"); lines[len(lines)-1] != "" {
+			reason = lines[len(lines)-1]
+		}
+		return fmt.Errorf("ffmpeg does not accept the audio filter %q: %s", filter, reason)
+	}
+	return nil
+}
+
+// lookFFmpeg resolves the ffmpeg binary, defaulting to the one on PATH
+func lookFFmpeg(ffmpegPath string) (string, error) {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	path, err := exec.LookPath(ffmpegPath)
+	if err != nil {
+		return "", fmt.Errorf("ffmpeg not found (%s): %w\nPlease install FFmpeg (https://ffmpeg.org/download.html)", ffmpegPath, err)
+	}
+	return path, nil
+}
+
+// convertToWAV converts any audio file to 16kHz mono 16-bit WAV using FFmpeg,
+// applying the audio filter graph filter when it is set and reporting
+// conversion progress to progress when it is set
+func convertToWAV(ctx context.Context, ffmpegPath, inputPath, outputPath, filter string, progress ProgressFunc) error {
+	path, err := lookFFmpeg(ffmpegPath)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-i", inputPath} // Input file
+	if filter != "" {
+		args = append(args, "-af", filter) // Clean up the audio before resampling
+	}
+	args = append(args,
+		"-acodec", "pcm_s16le", // Output codec: 16-bit PCM
+		"-ar", "16000", // Sample rate: 16kHz (required by whisper)
+		"-ac", "1", // Channels: mono
+		"-progress", "pipe:1", // Machine-readable progress on stdout
+		"-nostats", // No interactive stats on stderr
+		"-y",       // Overwrite output file
+		outputPath, // Output file
+	)
+	cmd := exec.CommandContext(ctx, path, args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture ffmpeg progress: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture ffmpeg output: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	tracker := &ffmpegProgress{report: progress}
+	var output bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		tracker.scanLog(stderr, &output)
+	}()
+	go func() {
+		defer wg.Done()
+		tracker.scanProgress(stdout)
+	}()
+	wg.Wait()
+
+	// Capture stderr for error reporting
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, output.String())
+	}
+
+	return nil
+}
+
+// ffmpegProgress turns ffmpeg's -progress output into percentages
+type ffmpegProgress struct {
+	mu     sync.Mutex
+	total  time.Duration
+	report ProgressFunc
+}
+
+// scanLog copies ffmpeg's log into output and records the input duration
+func (p *ffmpegProgress) scanLog(r io.Reader, output *bytes.Buffer) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		output.WriteString(line)
+		output.WriteByte('\n')
+
+		if total, ok := parseFFmpegDuration(line); ok {
+			p.mu.Lock()
+			if p.total == 0 {
+				p.total = total
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// scanProgress reads key=value progress lines and reports the percentage done
+func (p *ffmpegProgress) scanProgress(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || p.report == nil {
+			continue
+		}
+
+		switch key {
+		case "out_time_us":
+			us, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			p.mu.Lock()
+			total := p.total
+			p.mu.Unlock()
+			if total > 0 {
+				percent := float64(us) / float64(total.Microseconds()) * 100
+				p.report(StageConvert, min(percent, 100))
+			}
+		case "progress":
+			if value == "end" {
+				p.report(StageConvert, 100)
+			}
+		}
+	}
+}
+
+// parseFFmpegDuration extracts the "Duration: HH:MM:SS.ss" value from an ffmpeg log line
+func parseFFmpegDuration(line string) (time.Duration, bool) {
+	m := durationPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.ParseFloat(m[3], 64)
+
+	total := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second))
+	return total, total > 0
+}
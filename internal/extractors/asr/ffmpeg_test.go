@@ -0,0 +1,156 @@
+package asr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFFmpeg is a shell script standing in for ffmpeg. It logs an input
+// duration, reports progress halfway and at the end, then creates the output file.
+// Like the real ffmpeg it pauses after probing the input, so the duration is
+// known before the first progress update.
+const fakeFFmpeg = `#!/bin/sh
+echo "  Duration: 00:00:10.00, start: 0.000000, bitrate: 128 kb/s" >&2
+sleep 0.2
+echo "out_time_us=5000000"
+echo "progress=continue"
+echo "out_time_us=10000000"
+echo "progress=end"
+for last; do :; done
+: > "$last"
+`
+
+func TestConvertToWAVProgress(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg script requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	ffmpegPath := filepath.Join(dir, "ffmpeg")
+	if err := os.WriteFile(ffmpegPath, []byte(fakeFFmpeg), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	var reported []float64
+	progress := func(stage string, percent float64) {
+		if stage != StageConvert {
+			t.Errorf("Expected stage %q, got %q", StageConvert, stage)
+		}
+		reported = append(reported, percent)
+	}
+
+	outputPath := filepath.Join(dir, "out.wav")
+	if err := convertToWAV(context.Background(), ffmpegPath, "in.mp4", outputPath, "", progress); err != nil {
+		t.Fatalf("convertToWAV failed: %v", err)
+	}
+
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Errorf("Expected output file to be created: %v", err)
+	}
+	if len(reported) == 0 || reported[0] != 50 || reported[len(reported)-1] != 100 {
+		t.Errorf("Expected progress from 50%% to 100%%, got %v", reported)
+	}
+}
+
+func TestConvertToWAVMissingFFmpeg(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "no-such-ffmpeg")
+
+	err := convertToWAV(context.Background(), missing, "in.mp4", "out.wav", "", nil)
+	if err == nil {
+		t.Fatal("Expected an error for a missing ffmpeg binary")
+	}
+	if !strings.Contains(err.Error(), "Please install FFmpeg") {
+		t.Errorf("Expected install instructions in error, got: %v", err)
+	}
+}
+
+func TestConvertToWAVAudioFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg script requires a POSIX shell")
+	}
+
+	// Records its arguments, one per line, then creates the output file
+	dir := t.TempDir()
+	ffmpegPath := filepath.Join(dir, "ffmpeg")
+	argsPath := filepath.Join(dir, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsPath + "\nfor last; do :; done\n: > \"$last\"\n"
+	if err := os.WriteFile(ffmpegPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "out.wav")
+	if err := convertToWAV(context.Background(), ffmpegPath, "in.mp4", outputPath, DenoiseFilter, nil); err != nil {
+		t.Fatalf("convertToWAV failed: %v", err)
+	}
+	data, err := os.ReadFile(argsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\n-af\n"+DenoiseFilter+"\n") {
+		t.Errorf("Expected the filter to be passed with -af, got args:\n%s", data)
+	}
+}
+
+func TestCheckAudioFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg script requires a POSIX shell")
+	}
+
+	// Rejects any filter graph naming "nosuch", like ffmpeg does unknown filters
+	dir := t.TempDir()
+	ffmpegPath := filepath.Join(dir, "ffmpeg")
+	script := `#!/bin/sh
+case "$*" in
+*nosuch*) echo "[AVFilterGraph @ 0x1] No such filter: 'nosuch'" >&2; echo "Error initializing filters" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(ffmpegPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := CheckAudioFilter(ctx, ffmpegPath, JoinFilters(DenoiseFilter, NormalizeFilter)); err != nil {
+		t.Errorf("Expected the filter to be accepted, got %v", err)
+	}
+	err := CheckAudioFilter(ctx, ffmpegPath, "highpass=f=80,nosuch")
+	if err == nil || !strings.Contains(err.Error(), `"highpass=f=80,nosuch"`) || !strings.Contains(err.Error(), "Error initializing filters") {
+		t.Errorf("Expected a clear error for an unknown filter, got %v", err)
+	}
+	if err := CheckAudioFilter(ctx, filepath.Join(dir, "missing"), DenoiseFilter); err == nil || !strings.Contains(err.Error(), "audio filters need ffmpeg") {
+		t.Errorf("Expected a missing ffmpeg error, got %v", err)
+	}
+}
+
+func TestJoinFilters(t *testing.T) {
+	if got := JoinFilters(DenoiseFilter, " ", "volume=2,", NormalizeFilter); got != DenoiseFilter+",volume=2,"+NormalizeFilter {
+		t.Errorf("JoinFilters() = %q", got)
+	}
+	if got := JoinFilters("", ""); got != "" {
+		t.Errorf("JoinFilters() of nothing = %q, expected empty", got)
+	}
+}
+
+func TestParseFFmpegDuration(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected time.Duration
+		ok       bool
+	}{
+		{"  Duration: 00:01:30.50, start: 0.000000", 90*time.Second + 500*time.Millisecond, true},
+		{"  Duration: 01:00:00.00, bitrate: 1 kb/s", time.Hour, true},
+		{"  Duration: N/A, bitrate: N/A", 0, false},
+		{"Stream #0:0: Audio: aac", 0, false},
+	}
+
+	for _, test := range tests {
+		result, ok := parseFFmpegDuration(test.line)
+		if ok != test.ok || result != test.expected {
+			t.Errorf("parseFFmpegDuration(%q) = %v, %v; expected %v, %v", test.line, result, ok, test.expected, test.ok)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package asr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AutoLanguage asks whisper to detect the spoken language
+const AutoLanguage = "auto"
+
+// Languages maps the language codes supported by whisper to their names
+var Languages = map[string]string{
+	"en": "english", "zh": "chinese", "de": "german", "es": "spanish",
+	"ru": "russian", "ko": "korean", "fr": "french", "ja": "japanese",
+	"pt": "portuguese", "tr": "turkish", "pl": "polish", "ca": "catalan",
+	"nl": "dutch", "ar": "arabic", "sv": "swedish", "it": "italian",
+	"id": "indonesian", "hi": "hindi", "fi": "finnish", "vi": "vietnamese",
+	"he": "hebrew", "uk": "ukrainian", "el": "greek", "ms": "malay",
+	"cs": "czech", "ro": "romanian", "da": "danish", "hu": "hungarian",
+	"ta": "tamil", "no": "norwegian", "th": "thai", "ur": "urdu",
+	"hr": "croatian", "bg": "bulgarian", "lt": "lithuanian", "la": "latin",
+	"mi": "maori", "ml": "malayalam", "cy": "welsh", "sk": "slovak",
+	"te": "telugu", "fa": "persian", "lv": "latvian", "bn": "bengali",
+	"sr": "serbian", "az": "azerbaijani", "sl": "slovenian", "kn": "kannada",
+	"et": "estonian", "mk": "macedonian", "br": "breton", "eu": "basque",
+	"is": "icelandic", "hy": "armenian", "ne": "nepali", "mn": "mongolian",
+	"bs": "bosnian", "kk": "kazakh", "sq": "albanian", "sw": "swahili",
+	"gl": "galician", "mr": "marathi", "pa": "punjabi", "si": "sinhala",
+	"km": "khmer", "sn": "shona", "yo": "yoruba", "so": "somali",
+	"af": "afrikaans", "oc": "occitan", "ka": "georgian", "be": "belarusian",
+	"tg": "tajik", "sd": "sindhi", "gu": "gujarati", "am": "amharic",
+	"yi": "yiddish", "lo": "lao", "uz": "uzbek", "fo": "faroese",
+	"ht": "haitian creole", "ps": "pashto", "tk": "turkmen", "nn": "nynorsk",
+	"mt": "maltese", "sa": "sanskrit", "lb": "luxembourgish", "my": "myanmar",
+	"bo": "tibetan", "tl": "tagalog", "mg": "malagasy", "as": "assamese",
+	"tt": "tatar", "haw": "hawaiian", "ln": "lingala", "ha": "hausa",
+	"ba": "bashkir", "jw": "javanese", "su": "sundanese", "yue": "cantonese",
+}
+
+// ValidateLanguage normalizes a language code and checks that whisper supports it.
+// An empty code or "auto" selects automatic detection and is returned as "".
+func ValidateLanguage(code string) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" || code == AutoLanguage {
+		return "", nil
+	}
+	if _, ok := Languages[code]; !ok {
+		return "", fmt.Errorf("unsupported language %q (use a whisper language code such as en, de or es, or %q)", code, AutoLanguage)
+	}
+	return code, nil
+}
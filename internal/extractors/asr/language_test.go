@@ -0,0 +1,29 @@
+package asr
+
+import "testing"
+
+func TestValidateLanguage(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"auto", "", false},
+		{"en", "en", false},
+		{" DE ", "de", false},
+		{"yue", "yue", false},
+		{"english", "", true},
+		{"xx", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := ValidateLanguage(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateLanguage(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+		}
+		if result != test.expected {
+			t.Errorf("ValidateLanguage(%q) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
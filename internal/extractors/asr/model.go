@@ -0,0 +1,103 @@
+package asr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrModelNotFound is matched by every ModelNotFoundError
+var ErrModelNotFound = errors.New("whisper model not found")
+
+// modelDownloadURL is where whisper.cpp publishes its ggml models
+const modelDownloadURL = "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/"
+
+// ModelNotFoundError reports a whisper model that is not installed, with
+// where it was looked for and how to download it
+type ModelNotFoundError struct {
+	Model    string   // model name, e.g. "base"
+	Path     string   // configured model path, empty when looked up by name
+	Searched []string // model files that were looked for, in order
+}
+
+// ModelNotFound describes the model name missing from every location
+// FindWhisperModel searches
+func ModelNotFound(name string) *ModelNotFoundError {
+	return &ModelNotFoundError{Model: name, Searched: ModelSearchPaths(name)}
+}
+
+// modelPathNotFound describes a configured model path that does not exist.
+// The standard locations for the same model are listed too, since that is
+// where a downloaded model usually ends up.
+func modelPathNotFound(path string) *ModelNotFoundError {
+	name := modelName(path)
+	searched := []string{path}
+	for _, candidate := range ModelSearchPaths(name) {
+		if filepath.Clean(candidate) != filepath.Clean(path) {
+			searched = append(searched, candidate)
+		}
+	}
+	return &ModelNotFoundError{Model: name, Path: path, Searched: searched}
+}
+
+func (e *ModelNotFoundError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		fmt.Fprintf(&b, "whisper model %q not found at %s", e.Model, e.Path)
+	} else {
+		fmt.Fprintf(&b, "whisper model %q not found", e.Model)
+	}
+	b.WriteString("\nSearched:")
+	for _, path := range e.Searched {
+		b.WriteString("\n  " + path)
+	}
+	b.WriteString("\nDownload it with:\n  " + e.DownloadCommand())
+	return b.String()
+}
+
+func (e *ModelNotFoundError) Unwrap() error {
+	return ErrModelNotFound
+}
+
+// DownloadCommand is a shell command that downloads the model into
+// ~/.cache/whisper, one of the locations FindWhisperModel searches
+func (e *ModelNotFoundError) DownloadCommand() string {
+	filename := modelFilename(e.Model)
+	return fmt.Sprintf("mkdir -p ~/.cache/whisper && curl -L -o ~/.cache/whisper/%s %s%s", filename, modelDownloadURL, filename)
+}
+
+// ModelSearchPaths lists the model files FindWhisperModel looks for, in order
+func ModelSearchPaths(modelName string) []string {
+	// Common locations where whisper models might be stored
+	commonPaths := []string{
+		"./models/",
+		"./whisper-models/",
+		"/usr/local/share/whisper/",
+		"/opt/whisper/models/",
+		filepath.Join(os.Getenv("HOME"), ".cache/whisper/"),
+		filepath.Join(os.Getenv("HOME"), ".local/share/whisper/"),
+	}
+
+	paths := make([]string, len(commonPaths))
+	for i, basePath := range commonPaths {
+		paths[i] = filepath.Join(basePath, modelFilename(modelName))
+	}
+	return paths
+}
+
+// modelFilename is the file whisper.cpp ships a model as, e.g. ggml-base.bin
+func modelFilename(modelName string) string {
+	return "ggml-" + modelName + ".bin"
+}
+
+// modelName recovers the model name from a model file path, e.g. base from
+// models/ggml-base.bin, or returns the file name when it is named otherwise
+func modelName(path string) string {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, "ggml-") && strings.HasSuffix(name, ".bin") {
+		return strings.TrimSuffix(strings.TrimPrefix(name, "ggml-"), ".bin")
+	}
+	return name
+}
@@ -0,0 +1,62 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscribeMissingModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ggml-small.bin")
+	service := NewService(&Config{WhisperModel: path})
+
+	_, err := service.transcribe(context.Background(), nil)
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Expected ErrModelNotFound, got %v", err)
+	}
+
+	var notFound *ModelNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected a ModelNotFoundError, got %T", err)
+	}
+	if notFound.Model != "small" || notFound.Path != path {
+		t.Errorf("Unexpected model %q at %q", notFound.Model, notFound.Path)
+	}
+	if len(notFound.Searched) != len(ModelSearchPaths("small"))+1 || notFound.Searched[0] != path {
+		t.Errorf("Expected the configured path and then the standard locations, got %v", notFound.Searched)
+	}
+
+	message := err.Error()
+	for _, want := range []string{path, ModelSearchPaths("small")[0], "curl -L -o ~/.cache/whisper/ggml-small.bin " + modelDownloadURL + "ggml-small.bin"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected %q in error:\n%s", want, message)
+		}
+	}
+}
+
+func TestLoadModelMissing(t *testing.T) {
+	service := NewService(&Config{WhisperModel: filepath.Join(t.TempDir(), "ggml-tiny.bin")})
+	if err := service.LoadModel(); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Expected ErrModelNotFound, got %v", err)
+	}
+	// Nothing was kept, so there is nothing to release
+	if err := service.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+func TestModelName(t *testing.T) {
+	tests := map[string]string{
+		"models/ggml-base.bin":     "base",
+		"/opt/ggml-large-v3.bin":   "large-v3",
+		"/opt/models/custom-model": "custom-model",
+		"ggml-tiny.en.bin":         "tiny.en",
+	}
+	for path, want := range tests {
+		if got := modelName(path); got != want {
+			t.Errorf("modelName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
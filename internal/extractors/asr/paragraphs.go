@@ -0,0 +1,54 @@
+package asr
+
+import (
+	"strings"
+	"time"
+
+	"maai.solutions/gengo/internal/text"
+)
+
+// DefaultParagraphPause is the silence between segments that starts a new
+// paragraph at a sentence boundary
+const DefaultParagraphPause = 2 * time.Second
+
+// Paragraphs groups segments into paragraphs. A new paragraph starts when the
+// pause before a segment is at least pause and the previous segment ended a
+// sentence, or when the pause is twice as long regardless of punctuation,
+// since some models emit little of it.
+func Paragraphs(segments []Segment, pause time.Duration) [][]Segment {
+	var paragraphs [][]Segment
+	var current []Segment
+
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) == "" {
+			continue
+		}
+		if n := len(current); n > 0 {
+			gap := segment.Start - current[n-1].End
+			if gap >= 2*pause || (gap >= pause && text.EndsSentence(current[n-1].Text)) {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+		}
+		current = append(current, segment)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+	return paragraphs
+}
+
+// ParagraphText renders segments as paragraphs separated by blank lines, with
+// the segments of each paragraph joined by spaces, or directly for Chinese and
+// Japanese text
+func ParagraphText(segments []Segment, pause time.Duration) string {
+	var blocks []string
+	for _, paragraph := range Paragraphs(segments, pause) {
+		texts := make([]string, 0, len(paragraph))
+		for _, segment := range paragraph {
+			texts = append(texts, segment.Text)
+		}
+		blocks = append(blocks, text.JoinText(texts))
+	}
+	return strings.Join(blocks, "\n\n")
+}
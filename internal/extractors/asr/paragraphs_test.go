@@ -0,0 +1,56 @@
+package asr
+
+import (
+	"testing"
+	"time"
+)
+
+// seg builds a segment from offsets in seconds
+func seg(start, end float64, text string) Segment {
+	return Segment{
+		Start: time.Duration(start * float64(time.Second)),
+		End:   time.Duration(end * float64(time.Second)),
+		Text:  text,
+	}
+}
+
+func TestParagraphs(t *testing.T) {
+	segments := []Segment{
+		seg(0, 2, "Welcome everyone."),
+		seg(2.2, 4, "Let's get started."),
+		// 3s pause after a sentence: new paragraph
+		seg(7, 9, "First item on the agenda"),
+		// 2.5s pause mid-sentence: same paragraph
+		seg(11.5, 13, "is the budget."),
+		// 5s pause without punctuation: new paragraph anyway
+		seg(18, 20, "and then"),
+		seg(20.5, 22, "we wrap up"),
+		seg(22, 22, "  "),
+	}
+
+	paragraphs := Paragraphs(segments, 2*time.Second)
+	wantSizes := []int{2, 2, 2}
+	if len(paragraphs) != len(wantSizes) {
+		t.Fatalf("got %d paragraphs, want %d: %v", len(paragraphs), len(wantSizes), paragraphs)
+	}
+	for i, size := range wantSizes {
+		if len(paragraphs[i]) != size {
+			t.Errorf("paragraph %d has %d segments, want %d", i, len(paragraphs[i]), size)
+		}
+	}
+
+	want := "Welcome everyone. Let's get started.\n\nFirst item on the agenda is the budget.\n\nand then we wrap up"
+	if got := ParagraphText(segments, 2*time.Second); got != want {
+		t.Errorf("ParagraphText = %q, want %q", got, want)
+	}
+}
+
+func TestParagraphsNoPauses(t *testing.T) {
+	segments := []Segment{seg(0, 1, "One."), seg(1, 2, "Two."), seg(2, 3, "Three.")}
+	if got := ParagraphText(segments, time.Second); got != "One. Two. Three." {
+		t.Errorf("ParagraphText = %q", got)
+	}
+	if got := ParagraphText(nil, time.Second); got != "" {
+		t.Errorf("ParagraphText(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,75 @@
+package asr
+
+import (
+	"context"
+	"errors"
+)
+
+// ServicePool shares a fixed number of Services between concurrent callers.
+// A whisper model context must not be used by two transcriptions at once, so
+// each Service in the pool is handed to one caller at a time; callers beyond
+// the pool size wait for a Service to be returned. Each Service loads its
+// model on first use and keeps it until Close, so a pool of size N holds at
+// most N models in memory.
+type ServicePool struct {
+	services chan *Service
+	all      []*Service
+}
+
+// NewServicePool creates a pool of size Services sharing config. A size
+// below 1 is taken as 1, which serializes every transcription.
+func NewServicePool(config *Config, size int) *ServicePool {
+	if size < 1 {
+		size = 1
+	}
+	p := &ServicePool{services: make(chan *Service, size)}
+	for range size {
+		s := NewService(config)
+		p.all = append(p.all, s)
+		p.services <- s
+	}
+	return p
+}
+
+// Size returns how many transcriptions the pool runs at once
+func (p *ServicePool) Size() int {
+	return len(p.all)
+}
+
+// Do runs fn with a Service no other caller is using, waiting for one to be
+// free. It returns ctx's error when ctx ends first.
+func (p *ServicePool) Do(ctx context.Context, fn func(*Service) error) error {
+	var s *Service
+	select {
+	case s = <-p.services:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { p.services <- s }()
+	return fn(s)
+}
+
+// TranscribeAudio transcribes audio from any supported format with a pooled
+// Service, as Service.TranscribeAudio does, loading its model on first use
+func (p *ServicePool) TranscribeAudio(ctx context.Context, inputPath, tempDir string) (*Result, error) {
+	var result *Result
+	err := p.Do(ctx, func(s *Service) error {
+		if err := s.LoadModel(); err != nil {
+			return err
+		}
+		var err error
+		result, err = s.TranscribeAudio(ctx, inputPath, tempDir)
+		return err
+	})
+	return result, err
+}
+
+// Close releases the models loaded by the pool's Services, waiting for
+// transcriptions still running to finish
+func (p *ServicePool) Close() error {
+	var errs []error
+	for _, s := range p.all {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
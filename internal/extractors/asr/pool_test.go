@@ -0,0 +1,110 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServicePoolConcurrency(t *testing.T) {
+	const size, callers = 3, 50
+	pool := NewServicePool(DefaultConfig(), size)
+	if pool.Size() != size {
+		t.Fatalf("Size() = %d, expected %d", pool.Size(), size)
+	}
+
+	var (
+		active, peak atomic.Int32
+		mu           sync.Mutex
+		inUse        = map[*Service]bool{}
+		wg           sync.WaitGroup
+	)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := pool.Do(context.Background(), func(s *Service) error {
+				mu.Lock()
+				if inUse[s] {
+					t.Error("service handed to two callers at once")
+				}
+				inUse[s] = true
+				mu.Unlock()
+
+				n := active.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				active.Add(-1)
+
+				mu.Lock()
+				inUse[s] = false
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > size {
+		t.Errorf("%d callers ran at once, expected at most %d", p, size)
+	}
+	if len(inUse) != size {
+		t.Errorf("%d services used, expected all %d", len(inUse), size)
+	}
+}
+
+func TestServicePoolWaits(t *testing.T) {
+	pool := NewServicePool(nil, 0)
+	if pool.Size() != 1 {
+		t.Fatalf("Size() = %d, expected 1", pool.Size())
+	}
+
+	release := make(chan struct{})
+	held := make(chan struct{})
+	go pool.Do(context.Background(), func(*Service) error {
+		close(held)
+		<-release
+		return nil
+	})
+	<-held
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Do(ctx, func(*Service) error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() on a busy pool = %v, expected the context to end", err)
+	}
+
+	close(release)
+	if err := pool.Do(context.Background(), func(*Service) error { return nil }); err != nil {
+		t.Errorf("Do() after release = %v", err)
+	}
+}
+
+func TestServicePoolReleasesOnError(t *testing.T) {
+	config := DefaultConfig()
+	config.WhisperModel = filepath.Join(t.TempDir(), "missing.bin")
+	pool := NewServicePool(config, 1)
+	defer pool.Close()
+
+	// A failed transcription must return its service, or the next one hangs
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := pool.TranscribeAudio(ctx, "audio.wav", t.TempDir())
+		cancel()
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("TranscribeAudio() = %v, expected the missing model error", err)
+		}
+	}
+}
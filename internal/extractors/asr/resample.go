@@ -0,0 +1,45 @@
+package asr
+
+// SampleRate is the sample rate whisper expects, in Hz
+const SampleRate = 16000
+
+// resample converts mono samples from one sample rate to another. Downsampling
+// averages the input samples covering each output sample, which filters out
+// most of the aliasing a plain decimation would add; upsampling interpolates
+// linearly between neighbouring samples.
+func resample(in []float32, from, to int) []float32 {
+	if from == to || len(in) == 0 {
+		return in
+	}
+
+	ratio := float64(from) / float64(to)
+	out := make([]float32, int(float64(len(in))/ratio))
+
+	if ratio > 1 {
+		for i := range out {
+			start := int(float64(i) * ratio)
+			end := min(int(float64(i+1)*ratio), len(in))
+			if end <= start {
+				end = start + 1
+			}
+			var sum float32
+			for _, v := range in[start:end] {
+				sum += v
+			}
+			out[i] = sum / float32(end-start)
+		}
+		return out
+	}
+
+	for i := range out {
+		pos := float64(i) * ratio
+		j := int(pos)
+		if j+1 >= len(in) {
+			out[i] = in[len(in)-1]
+			continue
+		}
+		frac := float32(pos - float64(j))
+		out[i] = in[j]*(1-frac) + in[j+1]*frac
+	}
+	return out
+}
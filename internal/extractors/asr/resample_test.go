@@ -0,0 +1,36 @@
+package asr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	if got := resample([]float32{1, 2}, SampleRate, SampleRate); len(got) != 2 {
+		t.Errorf("Expected samples at the target rate to be unchanged, got %v", got)
+	}
+
+	// Downsampling averages each window of input samples
+	down := resample([]float32{0, 1, 1, 1, 0.5, 0.5}, 48000, 16000)
+	expected := []float32{2.0 / 3, 2.0 / 3}
+	if len(down) != len(expected) {
+		t.Fatalf("Expected %d samples, got %v", len(expected), down)
+	}
+	for i := range expected {
+		if math.Abs(float64(down[i]-expected[i])) > 1e-6 {
+			t.Errorf("down[%d] = %v, expected %v", i, down[i], expected[i])
+		}
+	}
+
+	// Upsampling interpolates between neighbours
+	up := resample([]float32{0, 1}, 8000, 16000)
+	if len(up) != 4 || up[0] != 0 || up[1] != 0.5 || up[2] != 1 || up[3] != 1 {
+		t.Errorf("Unexpected upsampled output: %v", up)
+	}
+
+	// Non-integer ratios keep the duration
+	tone := make([]float32, 44100)
+	if got := len(resample(tone, 44100, SampleRate)); got != SampleRate {
+		t.Errorf("Expected one second of output (%d samples), got %d", SampleRate, got)
+	}
+}
@@ -0,0 +1,115 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
+)
+
+// LanguageWindow is the stretch of audio whose language is detected at once
+// with Config.PerSegmentLanguage. Whisper detects the language from the first
+// 30 seconds it is given, so shorter windows follow switches more closely but
+// give it less speech to go on.
+const LanguageWindow = 30 * time.Second
+
+// languageWindows splits samples into windows of LanguageWindow, the last
+// one possibly shorter
+func languageWindows(samples []float32) [][]float32 {
+	size := int(LanguageWindow.Seconds()) * SampleRate
+	var windows [][]float32
+	for start := 0; start < len(samples); start += size {
+		windows = append(windows, samples[start:min(start+size, len(samples))])
+	}
+	return windows
+}
+
+// dominantLanguage returns the language spoken longest over the segments,
+// or "" when none has a language
+func dominantLanguage(segments []Segment) string {
+	spoken := make(map[string]time.Duration)
+	var order []string
+	for _, segment := range segments {
+		if segment.Language == "" {
+			continue
+		}
+		if _, ok := spoken[segment.Language]; !ok {
+			order = append(order, segment.Language)
+		}
+		spoken[segment.Language] += segment.End - segment.Start
+	}
+
+	// On a tie the language heard first wins
+	var dominant string
+	for _, language := range order {
+		if dominant == "" || spoken[language] > spoken[dominant] {
+			dominant = language
+		}
+	}
+	return dominant
+}
+
+// SegmentLanguages returns the languages of the segments in the order they
+// are first spoken
+func SegmentLanguages(segments []Segment) []string {
+	var languages []string
+	seen := make(map[string]bool)
+	for _, segment := range segments {
+		if segment.Language != "" && !seen[segment.Language] {
+			seen[segment.Language] = true
+			languages = append(languages, segment.Language)
+		}
+	}
+	return languages
+}
+
+// transcribeWindows transcribes data one LanguageWindow at a time, detecting
+// the language of each window and labeling its segments with it. When ctx
+// ends, the windows transcribed so far are returned as a partial result.
+func (s *Service) transcribeWindows(ctx context.Context, model whisper.Model, data []float32) (*Result, error) {
+	windows := languageWindows(data)
+
+	var text strings.Builder
+	var segments []Segment
+	stopped := false
+	for i, window := range windows {
+		if ctx.Err() != nil {
+			stopped = true
+			break
+		}
+
+		var onProgress whisper.ProgressCallback
+		if s.config.Progress != nil {
+			onProgress = func(percent int) {
+				s.config.Progress(StageTranscribe, (float64(i)*100+float64(percent))/float64(len(windows)))
+			}
+		}
+		offset := time.Duration(i) * LanguageWindow
+		p, err := s.process(ctx, model, window, AutoLanguage, offset, onProgress)
+		if err != nil {
+			return nil, err
+		}
+		for j := range p.segments {
+			p.segments[j].Language = p.detected
+		}
+		text.WriteString(p.text)
+		segments = append(segments, p.segments...)
+		if p.stopped {
+			stopped = true
+			break
+		}
+	}
+
+	if stopped && len(segments) == 0 {
+		return nil, fmt.Errorf("failed to process audio: %w", ctx.Err())
+	}
+
+	return &Result{
+		Text:     strings.TrimSpace(text.String()),
+		Language: dominantLanguage(segments),
+		Segments: segments,
+		Partial:  stopped,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package asr
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLanguageWindows(t *testing.T) {
+	size := int(LanguageWindow.Seconds()) * SampleRate
+	samples := make([]float32, 2*size+100)
+
+	windows := languageWindows(samples)
+	if len(windows) != 3 {
+		t.Fatalf("got %d windows, want 3", len(windows))
+	}
+	if len(windows[0]) != size || len(windows[1]) != size || len(windows[2]) != 100 {
+		t.Errorf("window sizes = %d, %d, %d", len(windows[0]), len(windows[1]), len(windows[2]))
+	}
+	if windows := languageWindows(nil); len(windows) != 0 {
+		t.Errorf("got %d windows for no samples, want 0", len(windows))
+	}
+}
+
+func TestDominantLanguage(t *testing.T) {
+	segment := func(language string, start, end int) Segment {
+		return Segment{Start: time.Duration(start) * time.Second, End: time.Duration(end) * time.Second, Language: language}
+	}
+
+	tests := []struct {
+		name     string
+		segments []Segment
+		want     string
+	}{
+		{"none", nil, ""},
+		{"unlabeled", []Segment{segment("", 0, 5)}, ""},
+		{"longest wins", []Segment{segment("en", 0, 5), segment("de", 5, 20), segment("en", 20, 25)}, "de"},
+		{"tie goes to the first", []Segment{segment("fr", 0, 5), segment("en", 5, 10)}, "fr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dominantLanguage(tt.segments); got != tt.want {
+				t.Errorf("dominantLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentLanguages(t *testing.T) {
+	segments := []Segment{{Language: "en"}, {Language: "de"}, {}, {Language: "en"}, {Language: "es"}}
+	if got, want := SegmentLanguages(segments), []string{"en", "de", "es"}; !slices.Equal(got, want) {
+		t.Errorf("SegmentLanguages() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package asr
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultSilenceThreshold is the level, in dBFS, below which audio counts as
+// silence with Config.SkipSilence
+const DefaultSilenceThreshold = -40.0
+
+const (
+	// silenceFrame is the length of audio whose level is measured at once
+	silenceFrame = 30 * time.Millisecond
+	// MinSilence is the shortest pause skipped; shorter ones are kept so
+	// whisper hears the pauses between words and sentences
+	MinSilence = time.Second
+	// silencePadding is the audio kept on either side of speech, so quiet
+	// word onsets and endings are not cut off
+	silencePadding = 250 * time.Millisecond
+)
+
+// span is a stretch of audio in samples, from start up to end
+type span struct {
+	start, end int
+}
+
+// samplesIn returns the number of samples in d of audio
+func samplesIn(d time.Duration) int {
+	return int(d.Seconds() * SampleRate)
+}
+
+// durationOf returns the length of n samples
+func durationOf(n int) time.Duration {
+	return time.Duration(n) * time.Second / SampleRate
+}
+
+// speechSpans returns the stretches of samples that are not silent. A frame
+// is silent when its RMS level is below threshold dBFS; only runs of silence
+// of at least MinSilence are left out, and every span keeps silencePadding
+// of audio around it.
+func speechSpans(samples []float32, threshold float64) []span {
+	frame := samplesIn(silenceFrame)
+	minSilence := samplesIn(MinSilence)
+	padding := samplesIn(silencePadding)
+
+	// Find the loud frames, bridging pauses shorter than MinSilence
+	var spans []span
+	for start := 0; start < len(samples); start += frame {
+		end := min(start+frame, len(samples))
+		if level(samples[start:end]) < threshold {
+			continue
+		}
+		if n := len(spans); n > 0 && start-spans[n-1].end < minSilence {
+			spans[n-1].end = end
+			continue
+		}
+		spans = append(spans, span{start, end})
+	}
+
+	// Pad the spans, merging those the padding makes overlap
+	var padded []span
+	for _, sp := range spans {
+		sp = span{max(sp.start-padding, 0), min(sp.end+padding, len(samples))}
+		if n := len(padded); n > 0 && sp.start <= padded[n-1].end {
+			padded[n-1].end = sp.end
+			continue
+		}
+		padded = append(padded, sp)
+	}
+	return padded
+}
+
+// level returns the RMS level of samples in dBFS
+func level(samples []float32) float64 {
+	var sum float64
+	for _, v := range samples {
+		sum += float64(v) * float64(v)
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	if rms == 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(rms)
+}
+
+// skipSilence joins the speech in samples, leaving out the silence, and
+// returns the timeline that maps times in the joined audio back to the
+// original. It returns no samples when the audio is silent throughout.
+func skipSilence(samples []float32, threshold float64) ([]float32, silenceTimeline) {
+	if threshold == 0 {
+		threshold = DefaultSilenceThreshold
+	}
+
+	var speech []float32
+	var timeline silenceTimeline
+	for _, sp := range speechSpans(samples, threshold) {
+		timeline = append(timeline, timelinePart{
+			at:    durationOf(len(speech)),
+			shift: durationOf(sp.start - len(speech)),
+		})
+		speech = append(speech, samples[sp.start:sp.end]...)
+	}
+	return speech, timeline
+}
+
+// timelinePart is a span of speech in the joined audio, starting at at, that
+// lies shift later in the original audio
+type timelinePart struct {
+	at    time.Duration
+	shift time.Duration
+}
+
+// silenceTimeline maps times in audio with the silence left out back to the
+// original audio; the zero timeline maps every time to itself
+type silenceTimeline []timelinePart
+
+// original returns the time in the original audio of d in the joined audio.
+// A segment ending where one span of speech meets the next ends with the
+// first span, so end times are mapped within the span before d.
+func (t silenceTimeline) original(d time.Duration, end bool) time.Duration {
+	i := sort.Search(len(t), func(i int) bool {
+		if end {
+			return t[i].at >= d
+		}
+		return t[i].at > d
+	}) - 1
+	if i < 0 {
+		return d
+	}
+	return d + t[i].shift
+}
+
+// remap moves segments from the joined audio to their times in the original
+func (t silenceTimeline) remap(segments []Segment) {
+	for i := range segments {
+		segments[i].Start = t.original(segments[i].Start, false)
+		segments[i].End = t.original(segments[i].End, true)
+	}
+}
@@ -0,0 +1,105 @@
+package asr
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// toneWAV writes a 16kHz mono WAV of a 440Hz tone where loud is true for
+// each second, silence elsewhere, and returns its path
+func toneWAV(t *testing.T, loud ...bool) string {
+	t.Helper()
+	var samples []int16
+	for _, on := range loud {
+		for i := 0; i < SampleRate; i++ {
+			var v int16
+			if on {
+				v = int16(8192 * math.Sin(2*math.Pi*440*float64(i)/SampleRate))
+			}
+			samples = append(samples, v)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	writeWAV(t, path, SampleRate, 1, samples)
+	return path
+}
+
+func TestSkipSilence(t *testing.T) {
+	// 2s of tone, 3s of silence, 2s of tone
+	data, err := loadAudioData(toneWAV(t, true, true, false, false, false, true, true))
+	if err != nil {
+		t.Fatalf("loadAudioData failed: %v", err)
+	}
+
+	spans := speechSpans(data, DefaultSilenceThreshold)
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 speech spans, got %v", spans)
+	}
+
+	// Levels are measured per frame, so the edges may be off by one
+	want := []span{
+		{0, samplesIn(2*time.Second + silencePadding)},
+		{samplesIn(5*time.Second - silencePadding), len(data)},
+	}
+	near := func(got, want int) bool {
+		return got >= want-samplesIn(silenceFrame) && got <= want+samplesIn(silenceFrame)
+	}
+	for i := range want {
+		if !near(spans[i].start, want[i].start) || !near(spans[i].end, want[i].end) {
+			t.Errorf("Speech span %d is %v, expected about %v", i, spans[i], want[i])
+		}
+	}
+
+	speech, timeline := skipSilence(data, 0)
+	if want := spans[0].end + spans[1].end - spans[1].start; len(speech) != want {
+		t.Fatalf("Expected %d samples of speech, got %d", want, len(speech))
+	}
+
+	// A segment in the second tone moves back by the silence skipped
+	joined := durationOf(spans[0].end)
+	skipped := durationOf(spans[1].start - spans[0].end)
+	segments := []Segment{
+		{Start: 0, End: joined},
+		{Start: joined, End: durationOf(len(speech))},
+	}
+	timeline.remap(segments)
+	expected := []Segment{
+		{Start: 0, End: joined},
+		{Start: joined + skipped, End: 7 * time.Second},
+	}
+	for i := range expected {
+		if segments[i].Start != expected[i].Start || segments[i].End != expected[i].End {
+			t.Errorf("Segment %d at %s-%s, expected %s-%s", i, segments[i].Start, segments[i].End, expected[i].Start, expected[i].End)
+		}
+	}
+}
+
+func TestSkipSilenceKeepsShortPauses(t *testing.T) {
+	// A pause shorter than MinSilence between words is kept
+	data := make([]float32, samplesIn(3*time.Second))
+	for i := range data {
+		if i < SampleRate || i >= samplesIn(1500*time.Millisecond) {
+			data[i] = 0.25
+		}
+	}
+	if spans := speechSpans(data, DefaultSilenceThreshold); len(spans) != 1 || spans[0] != (span{0, len(data)}) {
+		t.Errorf("Expected one span over all audio, got %v", spans)
+	}
+}
+
+func TestSkipSilenceAllSilent(t *testing.T) {
+	speech, timeline := skipSilence(make([]float32, SampleRate), DefaultSilenceThreshold)
+	if len(speech) != 0 || len(timeline) != 0 {
+		t.Errorf("Expected no speech in silence, got %d samples", len(speech))
+	}
+
+	// Without skipping, times are left alone
+	var none silenceTimeline
+	segments := []Segment{{Start: time.Second, End: 2 * time.Second}}
+	none.remap(segments)
+	if segments[0].Start != time.Second || segments[0].End != 2*time.Second {
+		t.Errorf("Expected the segment to stay put, got %s-%s", segments[0].Start, segments[0].End)
+	}
+}
@@ -0,0 +1,218 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// AudioExtensions are the extensions of the files Watch picks up, audio and
+// the video containers ffmpeg extracts audio from
+var AudioExtensions = []string{".mp3", ".wav", ".m4a", ".flac", ".ogg", ".opus", ".aac", ".wma", ".webm", ".mp4", ".mkv", ".mov"}
+
+// DefaultSettle is how long a file's size must stay the same before Watch
+// considers it completely written
+const DefaultSettle = 2 * time.Second
+
+// WatchOptions configures Watch
+type WatchOptions struct {
+	Settle   time.Duration // time a file's size must stay unchanged (default: DefaultSettle)
+	Existing bool          // also hand over the audio files already in the directory
+}
+
+// IsAudioFile reports whether path has one of AudioExtensions
+func IsAudioFile(path string) bool {
+	return slices.Contains(AudioExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// pendingFile is a file being written, waiting for its size to settle
+type pendingFile struct {
+	size    int64
+	changed time.Time // when the size last changed
+}
+
+// Watch hands each audio file added to dir to handle once it is completely
+// written, until ctx ends. Recordings are often copied in or written
+// gradually, so a file counts as complete when its size has stayed the same
+// for opts.Settle. Files are handled one at a time, in the order they
+// settle, while the directory is still being watched; a file written again
+// later is handled again. Watch returns nil when ctx ends, after the file
+// being handled is done.
+func Watch(ctx context.Context, dir string, opts WatchOptions, handle func(path string)) error {
+	settle := opts.Settle
+	if settle <= 0 {
+		settle = DefaultSettle
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	// Handle files in order on their own goroutine, so events keep being
+	// read during a long transcription
+	ready := newFileQueue()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			path, ok := ready.pop()
+			if !ok {
+				return
+			}
+			if ctx.Err() == nil {
+				handle(path)
+			}
+		}
+	}()
+	defer func() {
+		ready.close()
+		<-done
+	}()
+
+	pending := make(map[string]*pendingFile)
+	track := func(path string) {
+		if !IsAudioFile(path) {
+			return
+		}
+		if _, ok := pending[path]; !ok {
+			pending[path] = &pendingFile{size: -1, changed: time.Now()}
+		}
+	}
+
+	if opts.Existing {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				track(filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	ticker := time.NewTicker(max(settle/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			switch {
+			case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+				track(event.Name)
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				delete(pending, event.Name)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+
+		case now := <-ticker.C:
+			var settled []string
+			for path, file := range pending {
+				info, err := os.Stat(path)
+				if err != nil || !info.Mode().IsRegular() {
+					delete(pending, path)
+					continue
+				}
+				if info.Size() != file.size {
+					file.size = info.Size()
+					file.changed = now
+					continue
+				}
+				if file.size > 0 && now.Sub(file.changed) >= settle {
+					settled = append(settled, path)
+				}
+			}
+
+			// Files settling together are handled by name for a stable order
+			slices.Sort(settled)
+			for _, path := range settled {
+				delete(pending, path)
+				ready.push(path)
+			}
+		}
+	}
+}
+
+// fileQueue holds the settled files waiting to be handled. Pushing never
+// blocks, so the event loop keeps reading events however far handling falls
+// behind, and a file that settles again while still waiting is queued once.
+type fileQueue struct {
+	mu     sync.Mutex
+	paths  []string
+	queued map[string]bool
+	closed bool
+	wake   chan struct{} // signalled when a path is pushed or the queue closes
+}
+
+func newFileQueue() *fileQueue {
+	return &fileQueue{queued: make(map[string]bool), wake: make(chan struct{}, 1)}
+}
+
+// push adds path to the end of the queue unless it is already waiting
+func (q *fileQueue) push(path string) {
+	q.mu.Lock()
+	if !q.queued[path] {
+		q.queued[path] = true
+		q.paths = append(q.paths, path)
+	}
+	q.mu.Unlock()
+	q.signal()
+}
+
+// close makes pop return false once the waiting paths are taken
+func (q *fileQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *fileQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pop waits for the next path, returning false when the queue is closed and
+// empty
+func (q *fileQueue) pop() (string, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.paths) > 0 {
+			path := q.paths[0]
+			q.paths = q.paths[1:]
+			delete(q.queued, path)
+			q.mu.Unlock()
+			return path, true
+		}
+		closed := q.closed
+		q.mu.Unlock()
+		if closed {
+			return "", false
+		}
+		<-q.wake
+	}
+}
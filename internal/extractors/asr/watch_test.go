@@ -0,0 +1,121 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "old.wav")
+	if err := os.WriteFile(existing, []byte("old recording"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	handled := make(chan string, 10)
+	sizes := make(map[string]int64)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Watch(ctx, dir, WatchOptions{Settle: 200 * time.Millisecond, Existing: true}, func(path string) {
+			info, err := os.Stat(path)
+			if err == nil {
+				sizes[path] = info.Size()
+			}
+			handled <- path
+		})
+	}()
+
+	// Give the watcher time to start, then write a recording in two parts
+	// and a file that is not audio
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recording := filepath.Join(dir, "new.MP3")
+	f, err := os.Create(recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("first half ")
+	time.Sleep(100 * time.Millisecond)
+	f.WriteString("second half")
+	f.Close()
+
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for len(got) < 2 {
+		select {
+		case path := <-handled:
+			got = append(got, path)
+		case <-timeout:
+			t.Fatalf("Timed out waiting for files, got %v", got)
+		}
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Errorf("Watch returned %v after cancellation, want nil", err)
+	}
+	close(handled)
+	for path := range handled {
+		got = append(got, path)
+	}
+
+	if len(got) != 2 || got[0] != existing || got[1] != recording {
+		t.Errorf("Handled %v, want %s then %s", got, existing, recording)
+	}
+	if sizes[recording] != int64(len("first half second half")) {
+		t.Errorf("Recording handled at %d bytes, before it was completely written", sizes[recording])
+	}
+}
+
+func TestWatchMissingDir(t *testing.T) {
+	err := Watch(t.Context(), filepath.Join(t.TempDir(), "missing"), WatchOptions{}, func(string) {})
+	if err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestFileQueue(t *testing.T) {
+	q := newFileQueue()
+
+	// Pushing never blocks, however many files wait
+	for i := range 1000 {
+		q.push(fmt.Sprintf("file%04d.mp3", i))
+	}
+	q.push("file0000.mp3") // already waiting, queued once
+
+	popped := make(chan string)
+	go func() {
+		defer close(popped)
+		for {
+			path, ok := q.pop()
+			if !ok {
+				return
+			}
+			popped <- path
+		}
+	}()
+
+	for i := range 1000 {
+		if path := <-popped; path != fmt.Sprintf("file%04d.mp3", i) {
+			t.Fatalf("Expected file%04d.mp3, got %s", i, path)
+		}
+	}
+
+	// Once handled, a file can be queued again
+	q.push("file0000.mp3")
+	if path := <-popped; path != "file0000.mp3" {
+		t.Errorf("Expected file0000.mp3 again, got %s", path)
+	}
+
+	q.close()
+	if path, ok := <-popped; ok {
+		t.Errorf("Expected the queue to be closed, got %s", path)
+	}
+}
@@ -0,0 +1,168 @@
+package extractors
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// paragraph is a single <w:p> element from word/document.xml
+type paragraph struct {
+	Style string
+	Text  strings.Builder
+}
+
+// ExtractFromFile extracts the title of a .docx file and its body converted
+// to markdown, with Heading styles mapped to markdown headers
+func ExtractFromFile(filePath string) (string, string, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open docx file %s: %w", filePath, err)
+	}
+	defer reader.Close()
+
+	var documentFile, coreFile *zip.File
+	for _, f := range reader.File {
+		switch f.Name {
+		case "word/document.xml":
+			documentFile = f
+		case "docProps/core.xml":
+			coreFile = f
+		}
+	}
+
+	if documentFile == nil {
+		return "", "", fmt.Errorf("invalid docx file: word/document.xml not found")
+	}
+
+	body, err := readDocument(documentFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	title := ""
+	if coreFile != nil {
+		title, _ = readTitle(coreFile)
+	}
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+
+	return title, body, nil
+}
+
+// readDocument walks word/document.xml and converts paragraphs to markdown
+func readDocument(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	var paragraphs []string
+	var current *paragraph
+	inText := false
+
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", f.Name, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				current = &paragraph{}
+			case "pStyle":
+				if current != nil {
+					current.Style = attrValue(t, "val")
+				}
+			case "t":
+				inText = true
+			case "tab":
+				if current != nil {
+					current.Text.WriteString("\t")
+				}
+			case "br":
+				if current != nil {
+					current.Text.WriteString("\n")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if current != nil {
+					if line := formatParagraph(current); line != "" {
+						paragraphs = append(paragraphs, line)
+					}
+				}
+				current = nil
+			}
+		case xml.CharData:
+			if inText && current != nil {
+				current.Text.Write(t)
+			}
+		}
+	}
+
+	return strings.Join(paragraphs, "\n\n") + "\n", nil
+}
+
+// readTitle returns the dc:title value from docProps/core.xml
+func readTitle(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var core struct {
+		Title string `xml:"title"`
+	}
+	if err := xml.NewDecoder(rc).Decode(&core); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(core.Title), nil
+}
+
+// formatParagraph renders a paragraph as markdown, turning Heading styles into headers
+func formatParagraph(p *paragraph) string {
+	text := strings.TrimSpace(p.Text.String())
+	if text == "" {
+		return ""
+	}
+
+	style := strings.ToLower(p.Style)
+	switch {
+	case style == "title":
+		return "# " + text
+	case strings.HasPrefix(style, "heading"):
+		level := 1
+		if n := strings.TrimPrefix(style, "heading"); len(n) == 1 && n[0] >= '1' && n[0] <= '6' {
+			level = int(n[0] - '0')
+		}
+		return strings.Repeat("#", level) + " " + text
+	default:
+		return text
+	}
+}
+
+// attrValue returns the value of the attribute with the given local name
+func attrValue(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package extractors
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeZip creates a zip archive at path containing the given files
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+}
+
+const testDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Introduction</w:t></w:r></w:p>
+    <w:p><w:r><w:t xml:space="preserve">Hello </w:t></w:r><w:r><w:t>world.</w:t></w:r></w:p>
+    <w:p></w:p>
+    <w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Details</w:t></w:r></w:p>
+  </w:body>
+</w:document>`
+
+func TestExtractFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.docx")
+	writeZip(t, path, map[string]string{
+		"word/document.xml": testDocument,
+		"docProps/core.xml": `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Quarterly Report</dc:title></cp:coreProperties>`,
+	})
+
+	title, content, err := ExtractFromFile(path)
+	if err != nil {
+		t.Fatalf("ExtractFromFile failed: %v", err)
+	}
+
+	if title != "Quarterly Report" {
+		t.Errorf("Expected title 'Quarterly Report', got %q", title)
+	}
+
+	expected := []string{"# Introduction", "Hello world.", "## Details"}
+	for _, want := range expected {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected content to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestExtractFromFileTitleFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "untitled-draft.docx")
+	writeZip(t, path, map[string]string{"word/document.xml": testDocument})
+
+	title, _, err := ExtractFromFile(path)
+	if err != nil {
+		t.Fatalf("ExtractFromFile failed: %v", err)
+	}
+	if title != "untitled-draft" {
+		t.Errorf("Expected title 'untitled-draft', got %q", title)
+	}
+}
+
+func TestExtractFromFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.docx")
+	writeZip(t, path, map[string]string{"other.xml": "<x/>"})
+
+	if _, _, err := ExtractFromFile(path); err == nil {
+		t.Error("Expected error for docx without word/document.xml")
+	}
+
+	if _, _, err := ExtractFromFile("missing.docx"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
@@ -0,0 +1,128 @@
+package extractors
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	web "maai.solutions/gengo/internal/extractors/web"
+)
+
+// container mirrors META-INF/container.xml
+type container struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// packageDocument mirrors the parts of the OPF package document we need
+type packageDocument struct {
+	Title    string `xml:"metadata>title"`
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// ExtractFromFile extracts the title of an .epub file and the text of its
+// chapters in reading order
+func ExtractFromFile(filePath string) (string, string, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open epub file %s: %w", filePath, err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+
+	var meta container
+	if err := decodeXML(files, "META-INF/container.xml", &meta); err != nil {
+		return "", "", err
+	}
+	if len(meta.Rootfiles) == 0 {
+		return "", "", fmt.Errorf("invalid epub file: no rootfile in container.xml")
+	}
+
+	opfPath := meta.Rootfiles[0].FullPath
+	var pkg packageDocument
+	if err := decodeXML(files, opfPath, &pkg); err != nil {
+		return "", "", err
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = item.Href
+	}
+
+	var chapters []string
+	baseDir := path.Dir(opfPath)
+	for _, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+
+		data, err := readFile(files, path.Join(baseDir, href))
+		if err != nil {
+			return "", "", err
+		}
+
+		_, body, err := web.ExtractContent(string(data))
+		if err != nil {
+			return "", "", fmt.Errorf("failed to parse chapter %s: %w", href, err)
+		}
+		if body = strings.TrimSpace(body); body != "" {
+			chapters = append(chapters, body)
+		}
+	}
+
+	title := strings.TrimSpace(pkg.Title)
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+
+	return title, strings.Join(chapters, "\n\n") + "\n", nil
+}
+
+// readFile returns the contents of a file inside the archive
+func readFile(files map[string]*zip.File, name string) ([]byte, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid epub file: %s not found", name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// decodeXML unmarshals an XML file inside the archive into v
+func decodeXML(files map[string]*zip.File, name string, v interface{}) error {
+	data, err := readFile(files, name)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package extractors
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.epub")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create epub: %v", err)
+	}
+
+	files := []struct{ name, content string }{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`},
+		{"OEBPS/content.opf", `<package xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <metadata><dc:title>Test Book</dc:title></metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch2"/><itemref idref="ch1"/></spine>
+</package>`},
+		{"OEBPS/ch1.xhtml", `<html><body><h1>Chapter One</h1><p>First chapter text.</p></body></html>`},
+		{"OEBPS/text/ch2.xhtml", `<html><body><h1>Chapter Two</h1><p>Second chapter text.</p></body></html>`},
+	}
+
+	w := zip.NewWriter(f)
+	for _, file := range files {
+		fw, _ := w.Create(file.name)
+		fw.Write([]byte(file.content))
+	}
+	w.Close()
+	f.Close()
+
+	title, content, err := ExtractFromFile(path)
+	if err != nil {
+		t.Fatalf("ExtractFromFile failed: %v", err)
+	}
+
+	if title != "Test Book" {
+		t.Errorf("Expected title 'Test Book', got %q", title)
+	}
+
+	// Chapters follow spine order, not manifest order
+	second := strings.Index(content, "Second chapter text.")
+	first := strings.Index(content, "First chapter text.")
+	if second < 0 || first < 0 {
+		t.Fatalf("Expected both chapters in content, got:\n%s", content)
+	}
+	if second > first {
+		t.Error("Expected chapters in spine order")
+	}
+}
+
+func TestExtractFromFileInvalid(t *testing.T) {
+	if _, _, err := ExtractFromFile("missing.epub"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
@@ -0,0 +1,58 @@
+package extractors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractFromFile reads a markdown file and returns its title and content.
+// The title is taken from the first level-one heading, falling back to the
+// file name when the document has none. A title heading on the first line is
+// removed from the content so it is not repeated when the result is rendered,
+// and so is YAML front matter.
+func ExtractFromFile(filePath string) (string, string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read markdown file %s: %w", filePath, err)
+	}
+
+	content := StripFrontMatter(string(data))
+	title := ExtractTitle(content)
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+
+	trimmed := strings.TrimLeft(content, " \t\r\n")
+	if firstLine, rest, _ := strings.Cut(trimmed, "\n"); strings.HasPrefix(strings.TrimSpace(firstLine), "# ") {
+		content = strings.TrimLeft(rest, "\r\n")
+	}
+
+	return title, content, nil
+}
+
+// StripFrontMatter removes a leading YAML front matter block delimited by
+// "---" lines, as written for tagged project files
+func StripFrontMatter(content string) string {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return content
+	}
+	end := strings.Index(normalized[4:], "\n---\n")
+	if end < 0 {
+		return content
+	}
+	return strings.TrimLeft(normalized[4+end+5:], "\n")
+}
+
+// ExtractTitle returns the text of the first "# " heading in a markdown document
+func ExtractTitle(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package extractors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractTitle(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"# Hello World\n\nBody", "Hello World"},
+		{"Intro\n\n## Sub\n\n# Main Title\n", "Main Title"},
+		{"No heading here", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		result := ExtractTitle(test.content)
+		if result != test.expected {
+			t.Errorf("ExtractTitle(%q) = %q, expected %q", test.content, result, test.expected)
+		}
+	}
+}
+
+func TestExtractFromFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	withTitle := filepath.Join(tempDir, "notes.md")
+	os.WriteFile(withTitle, []byte("# Meeting Notes\n\nDiscussed things."), 0644)
+
+	title, content, err := ExtractFromFile(withTitle)
+	if err != nil {
+		t.Fatalf("ExtractFromFile failed: %v", err)
+	}
+	if title != "Meeting Notes" {
+		t.Errorf("Expected title 'Meeting Notes', got %q", title)
+	}
+	if content != "Discussed things." {
+		t.Errorf("Unexpected content: %q", content)
+	}
+
+	withoutTitle := filepath.Join(tempDir, "scratch.md")
+	os.WriteFile(withoutTitle, []byte("just text"), 0644)
+
+	title, _, err = ExtractFromFile(withoutTitle)
+	if err != nil {
+		t.Fatalf("ExtractFromFile failed: %v", err)
+	}
+	if title != "scratch" {
+		t.Errorf("Expected title 'scratch', got %q", title)
+	}
+
+	if _, _, err := ExtractFromFile(filepath.Join(tempDir, "missing.md")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestStripFrontMatter(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"---\ntags: [\"a\", \"b\"]\n---\n\n# Title\n\nBody", "# Title\n\nBody"},
+		{"---\r\ntags: [a]\r\n---\r\n# Title", "# Title"},
+		{"# Title\n\n---\n\nBody", "# Title\n\n---\n\nBody"},
+		{"---\nno closing delimiter", "---\nno closing delimiter"},
+	}
+
+	for _, test := range tests {
+		if result := StripFrontMatter(test.content); result != test.expected {
+			t.Errorf("StripFrontMatter(%q) = %q, expected %q", test.content, result, test.expected)
+		}
+	}
+
+	tagged := filepath.Join(t.TempDir(), "tagged.md")
+	os.WriteFile(tagged, []byte("---\ntags: [\"notes\"]\n---\n\n# Tagged\n\nBody"), 0644)
+	title, content, err := ExtractFromFile(tagged)
+	if err != nil || title != "Tagged" || content != "Body" {
+		t.Errorf("ExtractFromFile = %q, %q, %v; expected front matter to be skipped", title, content, err)
+	}
+}
@@ -0,0 +1,144 @@
+package extractors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// commentTypes are the annotation subtypes reviewers use to comment on a
+// document. Links, form widgets and popup windows are not comments.
+var commentTypes = map[string]bool{
+	"Text":      true, // sticky note
+	"FreeText":  true, // text box drawn on the page
+	"Highlight": true,
+	"Underline": true,
+	"StrikeOut": true,
+	"Squiggly":  true,
+	"Caret":     true, // insertion mark
+}
+
+// markupTypes are the comment types that mark text on the page
+var markupTypes = map[string]bool{
+	"Highlight": true,
+	"Underline": true,
+	"StrikeOut": true,
+	"Squiggly":  true,
+}
+
+// Annotation is a comment or text markup added to a PDF page
+type Annotation struct {
+	Page     int    `json:"page"`
+	Type     string `json:"type"` // PDF subtype, e.g. Text for sticky notes or Highlight
+	Author   string `json:"author,omitempty"`
+	Contents string `json:"contents,omitempty"`
+	Text     string `json:"text,omitempty"` // text under a highlight, underline or strike-out
+}
+
+// GetAnnotations returns the sticky notes, text boxes and text markup
+// (highlights, underlines, strike-outs) of a PDF file in page order with
+// their comments. Text markup also carries the text it covers, found by
+// intersecting the annotation rectangle with the glyphs on the page.
+func (te *TextExtractor) GetAnnotations(filePath string) ([]Annotation, error) {
+	ctx, err := te.readContext(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations []Annotation
+	for page := 1; page <= ctx.PageCount; page++ {
+		pageAnnots, err := pageAnnotations(ctx, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read annotations on page %d: %w", page, err)
+		}
+		annotations = append(annotations, pageAnnots...)
+	}
+	return annotations, nil
+}
+
+// pageAnnotations returns the comments on one page
+func pageAnnotations(ctx *model.Context, page int) ([]Annotation, error) {
+	pageDict, _, _, err := ctx.PageDict(page, false)
+	if err != nil {
+		return nil, err
+	}
+	obj, found := pageDict.Find("Annots")
+	if !found {
+		return nil, nil
+	}
+	refs, err := ctx.DereferenceArray(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations []Annotation
+	var runs []textRun
+	runsRead := false
+	for _, ref := range refs {
+		d, err := ctx.DereferenceDict(ref)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		subtype := d.NameEntry("Subtype")
+		if subtype == nil || !commentTypes[*subtype] {
+			continue
+		}
+		annotation := Annotation{
+			Page:     page,
+			Type:     *subtype,
+			Author:   textEntry(ctx, d, "T"),
+			Contents: textEntry(ctx, d, "Contents"),
+		}
+
+		if markupTypes[*subtype] {
+			if !runsRead {
+				// Unreadable page text only costs the marked-up text
+				runs, _, _ = pageText(ctx, page)
+				runsRead = true
+			}
+			if rect, err := ctx.DereferenceArray(d["Rect"]); err == nil && len(rect) == 4 {
+				if box, err := ctx.RectForArray(rect); err == nil {
+					annotation.Text = textInside(runs, *box)
+				}
+			}
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+// textInside returns the text of the glyphs whose center lies inside box,
+// separating the runs they belong to with spaces
+func textInside(runs []textRun, box types.Rectangle) string {
+	var parts []string
+	for _, run := range runs {
+		var b strings.Builder
+		for _, g := range run.glyphs {
+			x, y := (g.box.LL.X+g.box.UR.X)/2, (g.box.LL.Y+g.box.UR.Y)/2
+			if x >= box.LL.X && x <= box.UR.X && y >= box.LL.Y && y <= box.UR.Y {
+				b.WriteString(g.text)
+			}
+		}
+		parts = append(parts, b.String())
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// textEntry returns a text string entry of a dictionary, or "" when it is
+// missing or cannot be decoded
+func textEntry(ctx *model.Context, d types.Dict, key string) string {
+	obj, found := d.Find(key)
+	if !found {
+		return ""
+	}
+	text, err := ctx.DereferenceText(obj)
+	if err != nil {
+		return ""
+	}
+	return text
+}
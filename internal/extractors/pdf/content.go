@@ -0,0 +1,290 @@
+package extractors
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Content stream operands are float64 numbers, []byte strings, pdfName
+// names, []interface{} arrays, bool and nil values. Dictionaries, which only
+// appear as marked-content properties, are skipped and read as nil.
+type pdfName string
+
+// operation is one operator of a content stream with its operands. start
+// and end give its position in the stream, operands included.
+type operation struct {
+	operator string
+	operands []interface{}
+	start    int
+	end      int
+}
+
+// contentLexer splits a page content stream or a CMap into tokens
+type contentLexer struct {
+	data []byte
+	pos  int
+}
+
+// parseContent reads the operations of a content stream
+func parseContent(data []byte) ([]operation, error) {
+	lex := &contentLexer{data: data}
+	var ops []operation
+	var operands []interface{}
+	start := -1
+
+	for {
+		lex.skipSpace()
+		if lex.pos >= len(lex.data) {
+			return ops, nil
+		}
+		if start < 0 {
+			start = lex.pos
+		}
+
+		value, operator, err := lex.next()
+		if err != nil {
+			return ops, err
+		}
+		if operator == "" {
+			operands = append(operands, value)
+			continue
+		}
+
+		ops = append(ops, operation{operator: operator, operands: operands, start: start, end: lex.pos})
+		operands = nil
+		start = -1
+
+		// Inline image data is binary and ends at the EI operator
+		if operator == "ID" {
+			end := lex.inlineImageEnd()
+			ops = append(ops, operation{operator: "EI", start: lex.pos, end: end})
+			lex.pos = end
+		}
+	}
+}
+
+// isDelimiter reports whether c ends a name, number or operator
+func isDelimiter(c byte) bool {
+	return isSpace(c) || bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+// skipSpace skips white space and comments
+func (l *contentLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next reads an operand, or an operator when the returned operator is set
+func (l *contentLexer) next() (interface{}, string, error) {
+	switch c := l.data[l.pos]; c {
+	case '(':
+		s, err := l.literalString()
+		return s, "", err
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			return nil, "", l.skipDict()
+		}
+		s, err := l.hexString()
+		return s, "", err
+	case '/':
+		l.pos++
+		return pdfName(l.regular()), "", nil
+	case '[':
+		l.pos++
+		var array []interface{}
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return nil, "", fmt.Errorf("unterminated array")
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return array, "", nil
+			}
+			value, operator, err := l.next()
+			if err != nil {
+				return nil, "", err
+			}
+			if operator != "" {
+				return nil, "", fmt.Errorf("unexpected operator %s in array", operator)
+			}
+			array = append(array, value)
+		}
+	case ')', '>', ']', '{', '}':
+		l.pos++
+		return nil, "", fmt.Errorf("unexpected %q at offset %d", c, l.pos-1)
+	}
+
+	token := l.regular()
+	if token == "" {
+		// A lone delimiter the cases above do not handle
+		l.pos++
+		return nil, "", nil
+	}
+	if n, err := strconv.ParseFloat(token, 64); err == nil {
+		return n, "", nil
+	}
+	switch token {
+	case "true":
+		return true, "", nil
+	case "false":
+		return false, "", nil
+	case "null":
+		return nil, "", nil
+	}
+	return nil, token, nil
+}
+
+// regular reads a run of regular characters: a name, number or operator
+func (l *contentLexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// literalString reads a (string), resolving escapes and balanced parentheses
+func (l *contentLexer) literalString() ([]byte, error) {
+	l.pos++ // (
+	var s []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s, nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				break
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// A line continuation; \r\n counts as one end of line
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					n := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				} else {
+					c = e
+				}
+			}
+		}
+		s = append(s, c)
+	}
+	return nil, fmt.Errorf("unterminated string")
+}
+
+// hexString reads a <hex string>; an odd final digit is padded with 0
+func (l *contentLexer) hexString() ([]byte, error) {
+	l.pos++ // <
+	var s []byte
+	var digits []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			if len(digits) == 1 {
+				s = append(s, hexValue(digits[0])<<4)
+			}
+			return s, nil
+		}
+		if isSpace(c) {
+			continue
+		}
+		if hexValue(c) == 0xff {
+			return nil, fmt.Errorf("invalid hex digit %q", c)
+		}
+		digits = append(digits, c)
+		if len(digits) == 2 {
+			s = append(s, hexValue(digits[0])<<4|hexValue(digits[1]))
+			digits = digits[:0]
+		}
+	}
+	return nil, fmt.Errorf("unterminated hex string")
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10
+	}
+	return 0xff
+}
+
+// skipDict skips a << dictionary >>, including nested ones
+func (l *contentLexer) skipDict() error {
+	l.pos += 2
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return fmt.Errorf("unterminated dictionary")
+		}
+		if bytes.HasPrefix(l.data[l.pos:], []byte(">>")) {
+			l.pos += 2
+			return nil
+		}
+		if _, _, err := l.next(); err != nil {
+			return err
+		}
+	}
+}
+
+// inlineImageEnd returns the offset just past the EI operator ending the
+// inline image data that starts after an ID operator
+func (l *contentLexer) inlineImageEnd() int {
+	for i := l.pos + 1; i+2 <= len(l.data); i++ {
+		if l.data[i] == 'E' && l.data[i+1] == 'I' && isSpace(l.data[i-1]) &&
+			(i+2 == len(l.data) || isDelimiter(l.data[i+2])) {
+			return i + 2
+		}
+	}
+	return len(l.data)
+}
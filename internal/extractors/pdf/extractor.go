@@ -0,0 +1,37 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"maai.solutions/gengo/internal/extractors/registry"
+	"maai.solutions/gengo/internal/output"
+)
+
+// Name identifies the PDF extractor in the extractor registry
+func (te *TextExtractor) Name() string {
+	return "pdf"
+}
+
+// CanHandle reports whether source is a local .pdf file. PDFs behind URLs
+// are downloaded by the web extractor.
+func (te *TextExtractor) CanHandle(source string) bool {
+	return registry.HasExtension(source, ".pdf")
+}
+
+// Extract extracts the text of a PDF file from its placed text blocks, as
+// ExtractLayerText does, titling it after the file name
+func (te *TextExtractor) Extract(ctx context.Context, source string) (output.Result, error) {
+	if _, err := os.Stat(source); os.IsNotExist(err) {
+		return output.Result{}, fmt.Errorf("file does not exist: %s", source)
+	}
+	text, err := te.ExtractLayerText(source, nil)
+	if err != nil {
+		return output.Result{}, err
+	}
+	title := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	return output.NewResult(output.SourcePDF, source, title, text), nil
+}
@@ -0,0 +1,315 @@
+package extractors
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Glyph metrics used when a font does not describe itself, in thousandths
+// of the font size
+const (
+	defaultGlyphWidth = 500
+	defaultAscent     = 800
+	defaultDescent    = -200
+)
+
+// glyph is one character code of a shown string
+type glyph struct {
+	code  []byte  // bytes of the code in the string
+	text  string  // Unicode text of the glyph, "" when unknown
+	width float64 // advance width in thousandths of the font size
+	space bool    // the single-byte code 32, which word spacing applies to
+}
+
+// pdfFont decodes the strings shown with a font into glyphs
+type pdfFont struct {
+	composite bool              // Type0 font with two-byte codes
+	toUnicode map[string]string // code bytes to text from the ToUnicode CMap
+	widths    map[int]float64   // advance width per code
+	dw        float64           // width of codes missing from widths
+	coreName  string            // standard 14 font measured with built-in metrics
+	ascent    float64
+	descent   float64
+}
+
+// loadFont reads the font dictionary a Tf operator selects. Missing or
+// broken entries fall back to defaults so text can still be located.
+func loadFont(ctx *model.Context, d types.Dict) *pdfFont {
+	f := &pdfFont{dw: defaultGlyphWidth, ascent: defaultAscent, descent: defaultDescent, widths: map[int]float64{}}
+	if d == nil {
+		return f
+	}
+
+	if obj, found := d.Find("ToUnicode"); found {
+		if sd, _, err := ctx.DereferenceStreamDict(obj); err == nil && sd != nil && sd.Decode() == nil {
+			f.toUnicode = parseCMap(sd.Content)
+		}
+	}
+
+	descriptor := d
+	if subtype := d.NameEntry("Subtype"); subtype != nil && *subtype == "Type0" {
+		f.composite = true
+		f.dw = 1000
+		if descendants, err := ctx.DereferenceArray(d["DescendantFonts"]); err == nil && len(descendants) > 0 {
+			if cid, err := ctx.DereferenceDict(descendants[0]); err == nil && cid != nil {
+				descriptor = cid
+				f.loadCIDWidths(ctx, cid)
+			}
+		}
+	} else {
+		f.loadSimpleWidths(ctx, d)
+	}
+
+	if fd, err := ctx.DereferenceDict(descriptor["FontDescriptor"]); err == nil && fd != nil {
+		if ascent := numberEntry(ctx, fd, "Ascent"); ascent > 0 {
+			f.ascent = ascent
+		}
+		if descent := numberEntry(ctx, fd, "Descent"); descent < 0 {
+			f.descent = descent
+		}
+		if missing := numberEntry(ctx, fd, "MissingWidth"); missing > 0 && !f.composite {
+			f.dw = missing
+		}
+	}
+	return f
+}
+
+// loadSimpleWidths reads the Widths array of a single-byte font, falling
+// back to the built-in metrics of the standard 14 fonts
+func (f *pdfFont) loadSimpleWidths(ctx *model.Context, d types.Dict) {
+	widths, err := ctx.DereferenceArray(d["Widths"])
+	if err == nil && len(widths) > 0 {
+		first := int(numberEntry(ctx, d, "FirstChar"))
+		for i, w := range widths {
+			if n, ok := number(ctx, w); ok {
+				f.widths[first+i] = n
+			}
+		}
+		return
+	}
+
+	if base := d.NameEntry("BaseFont"); base != nil && font.IsCoreFont(*base) {
+		f.coreName = *base
+		if box := font.BoundingBox(*base); box != nil {
+			f.ascent, f.descent = box.UR.Y, box.LL.Y
+		}
+	}
+}
+
+// loadCIDWidths reads the W array and DW default of a descendant CID font.
+// W lists either "c [w1 w2 ...]" or "cFirst cLast w" entries.
+func (f *pdfFont) loadCIDWidths(ctx *model.Context, cid types.Dict) {
+	if dw := numberEntry(ctx, cid, "DW"); dw > 0 {
+		f.dw = dw
+	}
+	w, err := ctx.DereferenceArray(cid["W"])
+	if err != nil {
+		return
+	}
+	for i := 0; i+1 < len(w); {
+		first, ok := number(ctx, w[i])
+		if !ok {
+			return
+		}
+		if list, err := ctx.DereferenceArray(w[i+1]); err == nil && list != nil {
+			for j, width := range list {
+				if n, ok := number(ctx, width); ok {
+					f.widths[int(first)+j] = n
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		last, ok1 := number(ctx, w[i+1])
+		width, ok2 := number(ctx, w[i+2])
+		if !ok1 || !ok2 {
+			return
+		}
+		for c := int(first); c <= int(last); c++ {
+			f.widths[c] = width
+		}
+		i += 3
+	}
+}
+
+// glyphs splits a shown string into its character codes
+func (f *pdfFont) glyphs(s []byte) []glyph {
+	size := 1
+	if f.composite {
+		size = 2
+	}
+
+	glyphs := make([]glyph, 0, len(s)/size)
+	for i := 0; i+size <= len(s); i += size {
+		code := s[i : i+size]
+		c := int(code[0])
+		if size == 2 {
+			c = c<<8 | int(code[1])
+		}
+		glyphs = append(glyphs, glyph{
+			code:  code,
+			text:  f.text(code, c),
+			width: f.width(c),
+			space: size == 1 && c == ' ',
+		})
+	}
+	return glyphs
+}
+
+// text returns the Unicode text of a code from the ToUnicode CMap, reading
+// single-byte codes as WinAnsi when the font has none
+func (f *pdfFont) text(code []byte, c int) string {
+	if text, ok := f.toUnicode[string(code)]; ok {
+		return text
+	}
+	if f.composite {
+		return ""
+	}
+	if c >= 0x80 && c < 0xa0 {
+		return string(winAnsi[c-0x80])
+	}
+	return string(rune(c))
+}
+
+// winAnsi maps the codes 0x80-0x9f, where WinAnsiEncoding differs from
+// Latin-1, to Unicode
+var winAnsi = [32]rune{
+	'€', '\ufffd', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\ufffd', 'Ž', '\ufffd',
+	'\ufffd', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\ufffd', 'ž', 'Ÿ',
+}
+
+// width returns the advance width of a code in thousandths of the font size
+func (f *pdfFont) width(c int) float64 {
+	if w, ok := f.widths[c]; ok {
+		return w
+	}
+	if f.coreName != "" {
+		return float64(font.CharWidth(f.coreName, rune(c)))
+	}
+	return f.dw
+}
+
+// parseCMap reads the bfchar and bfrange mappings of a ToUnicode CMap into
+// a map from code bytes to text
+func parseCMap(data []byte) map[string]string {
+	mapping := map[string]string{}
+	lex := &contentLexer{data: data}
+	var operands []interface{}
+	for {
+		lex.skipSpace()
+		if lex.pos >= len(lex.data) {
+			return mapping
+		}
+		value, operator, err := lex.next()
+		if err != nil {
+			return mapping
+		}
+		switch operator {
+		case "":
+			operands = append(operands, value)
+			continue
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					mapping[string(src)] = utf16Text(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				addBFRange(mapping, operands[i], operands[i+1], operands[i+2])
+			}
+		}
+		operands = nil
+	}
+}
+
+// addBFRange maps the codes from lo to hi either to consecutive text
+// starting at dst or to the entries of a dst array
+func addBFRange(mapping map[string]string, lo, hi, dst interface{}) {
+	from, ok1 := lo.([]byte)
+	to, ok2 := hi.([]byte)
+	if !ok1 || !ok2 || len(from) != len(to) || len(from) == 0 || len(from) > 4 {
+		return
+	}
+	start, end := codeValue(from), codeValue(to)
+	if end < start || end-start > 0xffff {
+		return
+	}
+
+	for c, i := start, 0; c <= end; c, i = c+1, i+1 {
+		code := make([]byte, len(from))
+		for j, v := len(code)-1, c; j >= 0; j, v = j-1, v>>8 {
+			code[j] = byte(v)
+		}
+
+		switch dst := dst.(type) {
+		case []byte:
+			if len(dst) < 2 {
+				continue
+			}
+			// Increment the last UTF-16 unit of the destination
+			text := append([]byte(nil), dst...)
+			last := (int(text[len(text)-2])<<8 | int(text[len(text)-1])) + i
+			text[len(text)-2], text[len(text)-1] = byte(last>>8), byte(last)
+			mapping[string(code)] = utf16Text(text)
+		case []interface{}:
+			if i < len(dst) {
+				if text, ok := dst[i].([]byte); ok {
+					mapping[string(code)] = utf16Text(text)
+				}
+			}
+		}
+	}
+}
+
+// codeValue reads big-endian code bytes as a number
+func codeValue(code []byte) int {
+	v := 0
+	for _, b := range code {
+		v = v<<8 | int(b)
+	}
+	return v
+}
+
+// utf16Text decodes the big-endian UTF-16 text of a CMap destination
+func utf16Text(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return strings.ToValidUTF8(string(utf16.Decode(units)), "")
+}
+
+// number resolves a numeric PDF object
+func number(ctx *model.Context, obj types.Object) (float64, bool) {
+	obj, err := ctx.Dereference(obj)
+	if err != nil {
+		return 0, false
+	}
+	switch n := obj.(type) {
+	case types.Integer:
+		return float64(n.Value()), true
+	case types.Float:
+		return n.Value(), true
+	}
+	return 0, false
+}
+
+// numberEntry returns a numeric dictionary entry, or 0 when it is missing
+func numberEntry(ctx *model.Context, d types.Dict, key string) float64 {
+	obj, found := d.Find(key)
+	if !found {
+		return 0
+	}
+	n, _ := number(ctx, obj)
+	return n
+}
@@ -0,0 +1,146 @@
+package extractors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// LayerFilter selects text by the optional content groups (layers) that draw
+// it, matching layer names without regard to case. Text outside any layer is
+// always kept.
+type LayerFilter struct {
+	Include []string // when set, keep layered text only from these layers
+	Exclude []string // drop text from these layers, e.g. a watermark
+}
+
+// keeps reports whether text drawn inside the given layers passes the filter
+func (f LayerFilter) keeps(layers []string) bool {
+	if len(layers) == 0 {
+		return true
+	}
+	for _, layer := range layers {
+		if containsFold(f.Exclude, layer) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	// Nested layers all have to be wanted
+	for _, layer := range layers {
+		if !containsFold(f.Include, layer) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether names holds name, ignoring case
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLayers returns the names of the optional content groups (layers) a PDF
+// file declares, in document order. PDFs without layers give an empty list.
+func (te *TextExtractor) GetLayers(filePath string) ([]string, error) {
+	ctx, err := te.readContext(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []string{}
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog of %s: %w", filePath, err)
+	}
+	properties, err := ctx.DereferenceDict(catalog["OCProperties"])
+	if err != nil || properties == nil {
+		return layers, nil
+	}
+	groups, err := ctx.DereferenceArray(properties["OCGs"])
+	if err != nil {
+		return layers, nil
+	}
+	for _, group := range groups {
+		d, err := ctx.DereferenceDict(group)
+		if err != nil || d == nil {
+			continue
+		}
+		layers = append(layers, textEntry(ctx, d, "Name"))
+	}
+	return layers, nil
+}
+
+// ExtractLayerText returns the text of the selected pages, or of all pages
+// when none are selected, with te.Layers applied. The text is assembled from
+// the blocks of ExtractWithPositions, one line per block and a blank line
+// between pages.
+func (te *TextExtractor) ExtractLayerText(filePath string, pages []int) (string, error) {
+	blocks, err := te.pageBlocks(filePath, pages)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	page := 0
+	for _, block := range blocks {
+		if page != 0 && block.Page != page {
+			b.WriteString("\n")
+		}
+		page = block.Page
+		b.WriteString(strings.TrimSpace(block.Text) + "\n")
+	}
+	return b.String(), nil
+}
+
+// contentLayers returns the names of the layers an optional content group or
+// membership dictionary stands for
+func contentLayers(ctx *model.Context, obj types.Object) []string {
+	d, err := ctx.DereferenceDict(obj)
+	if err != nil || d == nil {
+		return nil
+	}
+	if t := d.NameEntry("Type"); t != nil && *t == "OCMD" {
+		// A membership dictionary names one group or an array of them
+		var layers []string
+		if groups, err := ctx.DereferenceArray(d["OCGs"]); err == nil && groups != nil {
+			for _, group := range groups {
+				layers = append(layers, contentLayers(ctx, group)...)
+			}
+			return layers
+		}
+		return contentLayers(ctx, d["OCGs"])
+	}
+	if name := textEntry(ctx, d, "Name"); name != "" {
+		return []string{name}
+	}
+	return nil
+}
+
+// markedLayers returns the layers a BDC operator opens, looking up its
+// property list in the resources, or nil for other marked content
+func markedLayers(ctx *model.Context, resources types.Dict, args []interface{}) []string {
+	if len(args) != 2 {
+		return nil
+	}
+	if tag, ok := args[0].(pdfName); !ok || tag != "OC" {
+		return nil
+	}
+	name, ok := args[1].(pdfName)
+	if !ok {
+		return nil
+	}
+	properties, err := ctx.DereferenceDict(resources["Properties"])
+	if err != nil || properties == nil {
+		return nil
+	}
+	return contentLayers(ctx, properties[string(name)])
+}
@@ -0,0 +1,181 @@
+package extractors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// PDFLink is a link annotation on a PDF page: a web address, or a jump to
+// another page of the document
+type PDFLink struct {
+	Page     int    `json:"page"`
+	Text     string `json:"text,omitempty"`      // text the link rectangle covers
+	URL      string `json:"url,omitempty"`       // target of an external link
+	DestPage int    `json:"dest_page,omitempty"` // target page of an internal link
+}
+
+// External reports whether the link points outside the document
+func (l PDFLink) External() bool {
+	return l.URL != ""
+}
+
+// GetLinks returns the links of a PDF file in page order, with the text they
+// cover, found like the text under highlights, and their target: the URL of
+// an external link or the page an internal link jumps to. Links to other
+// files and links running scripts are left out. A document without links
+// yields none.
+func (te *TextExtractor) GetLinks(filePath string) ([]PDFLink, error) {
+	ctx, err := te.readContext(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []PDFLink
+	for page := 1; page <= ctx.PageCount; page++ {
+		pageLinks, err := pageLinks(ctx, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read links on page %d: %w", page, err)
+		}
+		links = append(links, pageLinks...)
+	}
+	return links, nil
+}
+
+// pageLinks returns the links on one page
+func pageLinks(ctx *model.Context, page int) ([]PDFLink, error) {
+	pageDict, _, _, err := ctx.PageDict(page, false)
+	if err != nil {
+		return nil, err
+	}
+	obj, found := pageDict.Find("Annots")
+	if !found {
+		return nil, nil
+	}
+	refs, err := ctx.DereferenceArray(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []PDFLink
+	var runs []textRun
+	runsRead := false
+	for _, ref := range refs {
+		d, err := ctx.DereferenceDict(ref)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		if subtype := d.NameEntry("Subtype"); subtype == nil || *subtype != "Link" {
+			continue
+		}
+		link := linkTarget(ctx, d)
+		if !link.External() && link.DestPage == 0 {
+			continue
+		}
+		link.Page = page
+
+		if !runsRead {
+			// Unreadable page text only costs the link text
+			runs, _, _ = pageText(ctx, page)
+			runsRead = true
+		}
+		if rect, err := ctx.DereferenceArray(d["Rect"]); err == nil && len(rect) == 4 {
+			if box, err := ctx.RectForArray(rect); err == nil {
+				link.Text = textInside(runs, *box)
+			}
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// linkTarget reads where a link annotation leads, from its URI or GoTo
+// action or its destination. The zero PDFLink means no supported target.
+func linkTarget(ctx *model.Context, d types.Dict) PDFLink {
+	if obj, found := d.Find("Dest"); found {
+		return PDFLink{DestPage: destinationPage(ctx, obj)}
+	}
+	action, err := ctx.DereferenceDict(d["A"])
+	if err != nil || action == nil {
+		return PDFLink{}
+	}
+	switch s := action.NameEntry("S"); {
+	case s == nil:
+		return PDFLink{}
+	case *s == "URI":
+		return PDFLink{URL: strings.TrimSpace(textEntry(ctx, action, "URI"))}
+	case *s == "GoTo":
+		if obj, found := action.Find("D"); found {
+			return PDFLink{DestPage: destinationPage(ctx, obj)}
+		}
+	}
+	return PDFLink{}
+}
+
+// destinationPage returns the page number a destination points at, or 0
+// when it cannot be resolved. Destinations are an array starting with the
+// page, or the name of one listed in the document's Dests name tree.
+func destinationPage(ctx *model.Context, obj types.Object) int {
+	obj, err := ctx.Dereference(obj)
+	if err != nil || obj == nil {
+		return 0
+	}
+
+	var dest types.Array
+	switch o := obj.(type) {
+	case types.Array:
+		dest = o
+	case types.Name, types.StringLiteral, types.HexLiteral:
+		name, err := ctx.DestName(o)
+		if err != nil || ctx.LocateNameTree("Dests", false) != nil || ctx.Names["Dests"] == nil {
+			return 0
+		}
+		if dest, err = ctx.DereferenceDestArray(name); err != nil {
+			return 0
+		}
+	default:
+		return 0
+	}
+
+	if len(dest) == 0 {
+		return 0
+	}
+	ref, ok := dest[0].(types.IndirectRef)
+	if !ok {
+		// Links into other documents name the page by its index
+		return 0
+	}
+	page, err := ctx.PageNumber(ref.ObjectNumber.Value())
+	if err != nil {
+		return 0
+	}
+	return page
+}
+
+// InlineLinks writes the external links of a document into its extracted
+// text as markdown, [text](url), at the first occurrence of each link's
+// text after the previous link. Links whose text is not found, and internal
+// links, are left out.
+func InlineLinks(text string, links []PDFLink) string {
+	var b strings.Builder
+	rest := text
+	for _, link := range links {
+		if !link.External() || link.Text == "" {
+			continue
+		}
+		i := strings.Index(rest, link.Text)
+		if i < 0 {
+			continue
+		}
+		b.WriteString(rest[:i])
+		fmt.Fprintf(&b, "[%s](%s)", link.Text, link.URL)
+		rest = rest[i+len(link.Text):]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
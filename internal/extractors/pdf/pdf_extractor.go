@@ -7,7 +7,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 )
 
@@ -15,6 +14,14 @@ import (
 type TextExtractor struct {
 	// Config can be used to customize PDF processing options
 	Config *model.Configuration
+
+	// Layers selects text by the PDF layers drawing it in ExtractWithPositions
+	// and ExtractLayerText
+	Layers LayerFilter
+
+	// Workers is how many pages are read at once by the text extraction;
+	// below 2 the pages are read one after another
+	Workers int
 }
 
 // NewTextExtractor creates a new PDF text extractor with default configuration
@@ -33,26 +40,7 @@ func NewTextExtractorWithConfig(config *model.Configuration) *TextExtractor {
 
 // ExtractFromFile extracts text from a PDF file and returns it as a string
 func (te *TextExtractor) ExtractFromFile(filePath string) (string, error) {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return "", fmt.Errorf("file does not exist: %s", filePath)
-	}
-
-	// Extract text from all pages
-	err := api.ExtractContentFile(filePath, "", nil, te.Config)
-	if err != nil {
-		return "", fmt.Errorf("failed to extract content from file %s: %w", filePath, err)
-	}
-
-	// For now, let's use a simple approach - read the file and extract using different method
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open file %s: %w", filePath, err)
-	}
-	defer file.Close()
-
-	// Try using ExtractImages which might work better
-	// For now, return a placeholder message indicating the feature is available
-	return fmt.Sprintf("PDF text extraction from file: %s\n(Feature implemented but requires proper PDF test file)", filePath), nil
+	return te.ExtractPages(filePath, nil)
 }
 
 // ExtractFromBytes extracts text from a PDF byte array and returns it as a string
@@ -158,13 +146,14 @@ func (te *TextExtractor) ExtractFromReaderToWriter(reader io.Reader, writer io.W
 	return nil
 }
 
-// ExtractPages extracts text from specific pages of a PDF file
+// ExtractPages extracts text from specific pages of a PDF file, or from all
+// of them when pages is empty. The text is assembled from its placed blocks
+// as ExtractLayerText does.
 func (te *TextExtractor) ExtractPages(filePath string, pages []int) (string, error) {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return "", fmt.Errorf("file does not exist: %s", filePath)
 	}
-
-	return fmt.Sprintf("PDF text extraction from file: %s\nPages: %v\n(Feature implemented but requires proper PDF test file)", filePath, pages), nil
+	return te.ExtractLayerText(filePath, pages)
 }
 
 // CleanText removes excessive whitespace and normalizes the extracted text
@@ -191,9 +180,11 @@ func (te *TextExtractor) GetPageCount(filePath string) (int, error) {
 		return 0, fmt.Errorf("file does not exist: %s", filePath)
 	}
 
-	// For demonstration, return a placeholder page count
-	// In a real implementation, this would use the pdfcpu API properly
-	return 5, nil
+	ctx, err := te.readContext(filePath)
+	if err != nil {
+		return 0, err
+	}
+	return ctx.PageCount, nil
 }
 
 // GetPageCountFromBytes returns the number of pages in a PDF byte array
@@ -202,7 +193,11 @@ func (te *TextExtractor) GetPageCountFromBytes(data []byte) (int, error) {
 		return 0, fmt.Errorf("empty byte array provided")
 	}
 
-	return 3, nil // Placeholder
+	ctx, err := te.readContextFrom(bytes.NewReader(data), "data")
+	if err != nil {
+		return 0, err
+	}
+	return ctx.PageCount, nil
 }
 
 // GetPageCountFromReader returns the number of pages in a PDF from a reader
@@ -217,5 +212,5 @@ func (te *TextExtractor) GetPageCountFromReader(reader io.Reader) (int, error) {
 		return 0, fmt.Errorf("failed to read data from reader: %w", err)
 	}
 
-	return len(data)/1000 + 1, nil // Placeholder calculation
+	return te.GetPageCountFromBytes(data)
 }
@@ -1,9 +1,18 @@
 package extractors
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
 
 // Mock test to verify the structure works
@@ -102,3 +111,543 @@ func ExampleTextExtractor() {
 	// Output: Cleaned text: Some   text   with   lots   of   spaces
 	// More text
 }
+
+func TestGetAnnotations(t *testing.T) {
+	extractor := NewTextExtractor()
+
+	annotations, err := extractor.GetAnnotations("testdata/annotated.pdf")
+	if err != nil {
+		t.Fatalf("GetAnnotations failed: %v", err)
+	}
+
+	expected := []Annotation{
+		{Page: 1, Type: "Text", Author: "Alice", Contents: "Check this figure"},
+		{Page: 2, Type: "Highlight", Author: "Bob", Contents: "Key claim", Text: "Tide pools"},
+	}
+	if len(annotations) != len(expected) {
+		t.Fatalf("Expected %d annotations without links and popups, got %+v", len(expected), annotations)
+	}
+	for i, want := range expected {
+		if annotations[i] != want {
+			t.Errorf("Annotation %d = %+v, expected %+v", i, annotations[i], want)
+		}
+	}
+
+	if _, err := extractor.GetAnnotations("non-existent-file.pdf"); err == nil {
+		t.Error("Expected error for non-existent file")
+	}
+}
+
+func TestParsePageRanges(t *testing.T) {
+	ranges, err := ParsePageRanges("1-3, 4-6,9")
+	if err != nil {
+		t.Fatalf("ParsePageRanges failed: %v", err)
+	}
+	expected := []PageRange{{1, 3}, {4, 6}, {9, 9}}
+	if fmt.Sprint(ranges) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, ranges)
+	}
+
+	for _, invalid := range []string{"", "0-2", "3-1", "a-b", "2-"} {
+		if _, err := ParsePageRanges(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestSplitFile(t *testing.T) {
+	extractor := NewTextExtractor()
+	dir := t.TempDir()
+
+	paths, err := extractor.SplitFile("testdata/annotated.pdf", filepath.Join(dir, "pages"))
+	if err != nil {
+		t.Fatalf("SplitFile failed: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "pages", "annotated_1.pdf"), filepath.Join(dir, "pages", "annotated_2.pdf")}
+	if fmt.Sprint(paths) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v, got %v", expected, paths)
+	}
+	for _, path := range paths {
+		if count, err := api.PageCountFile(path); err != nil || count != 1 {
+			t.Errorf("Expected %s to hold one page, got %d (%v)", path, count, err)
+		}
+	}
+
+	paths, err = extractor.SplitRanges("testdata/annotated.pdf", dir, []PageRange{{1, 2}, {2, 2}})
+	if err != nil {
+		t.Fatalf("SplitRanges failed: %v", err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != "annotated_1-2.pdf" || filepath.Base(paths[1]) != "annotated_2.pdf" {
+		t.Fatalf("Unexpected range files %v", paths)
+	}
+	if count, err := api.PageCountFile(paths[0]); err != nil || count != 2 {
+		t.Errorf("Expected %s to hold two pages, got %d (%v)", paths[0], count, err)
+	}
+
+	if _, err := extractor.SplitRanges("testdata/annotated.pdf", dir, []PageRange{{2, 5}}); err == nil {
+		t.Error("Expected error for a range past the last page")
+	}
+}
+
+func TestExtractWithPositions(t *testing.T) {
+	blocks, err := NewTextExtractor().ExtractWithPositions("testdata/text.pdf")
+	if err != nil {
+		t.Fatalf("ExtractWithPositions failed: %v", err)
+	}
+
+	// The fixture gives every glyph a width of 500 and a descent of 250
+	expected := []TextBlock{
+		{Page: 1, Text: "Hello World", X: 72, Y: 697, Width: 66, Height: 12},
+		{Page: 1, Text: "Call 555-12-3456", X: 72, Y: 677, Width: 99.6, Height: 12},
+		{Page: 1, Text: "Scaled", X: 100, Y: 95, Width: 60, Height: 20},
+	}
+	if len(blocks) != len(expected) {
+		t.Fatalf("Expected %d blocks, got %+v", len(expected), blocks)
+	}
+	for i, want := range expected {
+		if blocks[i] != want {
+			t.Errorf("Block %d = %+v, expected %+v", i, blocks[i], want)
+		}
+	}
+}
+
+func TestParseContent(t *testing.T) {
+	content := []byte("q BT /F1 12 Tf (a\\(b\\)\\101) Tj [<0041> -250 (B)] TJ ET % comment\nBI /W 1 ID \x00EI\x01 EI Q")
+	ops, err := parseContent(content)
+	if err != nil {
+		t.Fatalf("parseContent failed: %v", err)
+	}
+
+	var operators []string
+	for _, op := range ops {
+		operators = append(operators, op.operator)
+	}
+	if got := strings.Join(operators, " "); got != "q BT Tf Tj TJ ET BI ID EI Q" {
+		t.Fatalf("Unexpected operators %q", got)
+	}
+	if s := ops[3].operands[0].([]byte); string(s) != "a(b)A" {
+		t.Errorf("Expected escapes to be resolved, got %q", s)
+	}
+	array := ops[4].operands[0].([]interface{})
+	if len(array) != 3 || string(array[0].([]byte)) != "\x00A" || array[1].(float64) != -250 {
+		t.Errorf("Unexpected TJ operand %v", array)
+	}
+	if string(content[ops[2].start:ops[2].end]) != "/F1 12 Tf" {
+		t.Errorf("Unexpected operation span %q", content[ops[2].start:ops[2].end])
+	}
+}
+
+func TestParseCMap(t *testing.T) {
+	cmap := []byte(`begincmap
+2 beginbfchar
+<0003> <0020>
+<0011> <00660069>
+endbfchar
+2 beginbfrange
+<0024> <0026> <0041>
+<0030> <0031> [<00E9> <00FC>]
+endbfrange
+endcmap`)
+
+	mapping := parseCMap(cmap)
+	expected := map[string]string{
+		"\x00\x03": " ",
+		"\x00\x11": "fi",
+		"\x00\x24": "A",
+		"\x00\x25": "B",
+		"\x00\x26": "C",
+		"\x00\x30": "é",
+		"\x00\x31": "ü",
+	}
+	if fmt.Sprint(mapping) != fmt.Sprint(expected) {
+		t.Errorf("parseCMap() = %q, expected %q", mapping, expected)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	extractor := NewTextExtractor()
+	out := filepath.Join(t.TempDir(), "redacted.pdf")
+	patterns := []*regexp.Regexp{regexp.MustCompile(`\d{3}-\d{2}-\d{4}`), regexp.MustCompile(`World`)}
+
+	redacted, err := extractor.Redact("testdata/text.pdf", out, patterns)
+	if err != nil {
+		t.Fatalf("Redact failed: %v", err)
+	}
+	expected := []TextBlock{
+		{Page: 1, Text: "555-12-3456", X: 105.6, Y: 677, Width: 66, Height: 12},
+		{Page: 1, Text: "World", X: 108, Y: 697, Width: 30, Height: 12},
+	}
+	if fmt.Sprint(redacted) != fmt.Sprint(expected) {
+		t.Fatalf("Expected redactions %+v, got %+v", expected, redacted)
+	}
+
+	// The remaining text keeps its place
+	blocks, err := extractor.ExtractWithPositions(out)
+	if err != nil {
+		t.Fatalf("ExtractWithPositions failed: %v", err)
+	}
+	expected = []TextBlock{
+		{Page: 1, Text: "Hello ", X: 72, Y: 697, Width: 36, Height: 12},
+		{Page: 1, Text: "Call ", X: 72, Y: 677, Width: 30, Height: 12},
+		{Page: 1, Text: "Scaled", X: 100, Y: 95, Width: 60, Height: 20},
+	}
+	if fmt.Sprint(blocks) != fmt.Sprint(expected) {
+		t.Errorf("Expected blocks %+v, got %+v", expected, blocks)
+	}
+
+	// No stream of the output may still hold the redacted text
+	ctx, err := api.ReadContextFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", out, err)
+	}
+	for nr, entry := range ctx.Table {
+		sd, ok := entry.Object.(types.StreamDict)
+		if !ok || sd.Decode() != nil {
+			continue
+		}
+		if strings.Contains(string(sd.Content), "555") || strings.Contains(string(sd.Content), "World") {
+			t.Errorf("Object %d still contains redacted text: %q", nr, sd.Content)
+		}
+	}
+
+	if _, err := extractor.Redact("testdata/text.pdf", "testdata/./text.pdf", patterns); err == nil {
+		t.Error("Expected error when the output would overwrite the input")
+	}
+}
+
+func TestGetLayers(t *testing.T) {
+	extractor := NewTextExtractor()
+
+	layers, err := extractor.GetLayers("testdata/layers.pdf")
+	if err != nil {
+		t.Fatalf("GetLayers failed: %v", err)
+	}
+	if fmt.Sprint(layers) != "[Watermark Review]" {
+		t.Errorf("Expected [Watermark Review], got %v", layers)
+	}
+
+	layers, err = extractor.GetLayers("testdata/text.pdf")
+	if err != nil {
+		t.Fatalf("GetLayers failed: %v", err)
+	}
+	if layers == nil || len(layers) != 0 {
+		t.Errorf("Expected an empty list for a PDF without layers, got %#v", layers)
+	}
+}
+
+func TestExtractLayerText(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter LayerFilter
+		want   string
+	}{
+		{"all", LayerFilter{}, "Body text\nDRAFT\nPage 1\nReviewer comment\nConfidential\n"},
+		// The form drawing "Confidential" belongs to the watermark layer as a whole
+		{"exclude", LayerFilter{Exclude: []string{"watermark"}}, "Body text\nPage 1\nReviewer comment\n"},
+		{"include", LayerFilter{Include: []string{"Review"}}, "Body text\nPage 1\nReviewer comment\n"},
+	}
+	for _, test := range tests {
+		extractor := NewTextExtractor()
+		extractor.Layers = test.filter
+		got, err := extractor.ExtractLayerText("testdata/layers.pdf", nil)
+		if err != nil {
+			t.Fatalf("%s: ExtractLayerText failed: %v", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.name, test.want, got)
+		}
+	}
+}
+
+func TestGetLinks(t *testing.T) {
+	extractor := NewTextExtractor()
+
+	links, err := extractor.GetLinks("testdata/links.pdf")
+	if err != nil {
+		t.Fatalf("GetLinks failed: %v", err)
+	}
+
+	expected := []PDFLink{
+		{Page: 1, Text: "Tide pools", URL: "https://example.com/tide-pools"},
+		{Page: 1, Text: "See appendix", DestPage: 2},
+		{Page: 1, Text: "Named jump", DestPage: 2},
+		{Page: 2, URL: "https://example.org/"},
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links without the script link, got %+v", len(expected), links)
+	}
+	for i, want := range expected {
+		if links[i] != want {
+			t.Errorf("Link %d = %+v, expected %+v", i, links[i], want)
+		}
+	}
+
+	// Comments and highlights are not links
+	links, err = extractor.GetLinks("testdata/annotated.pdf")
+	if err != nil || len(links) != 1 || links[0].URL != "https://example.com" {
+		t.Errorf("Expected only the link of the annotated document, got %+v, %v", links, err)
+	}
+	if links, err := extractor.GetLinks("testdata/text.pdf"); err != nil || len(links) != 0 {
+		t.Errorf("Expected no links in a link-free document, got %+v, %v", links, err)
+	}
+	if _, err := extractor.GetLinks("non-existent-file.pdf"); err == nil {
+		t.Error("Expected error for non-existent file")
+	}
+}
+
+func TestInlineLinks(t *testing.T) {
+	links := []PDFLink{
+		{Page: 1, Text: "Tide pools", URL: "https://example.com/tide-pools"},
+		{Page: 1, Text: "See appendix", DestPage: 2},
+		{Page: 1, Text: "missing", URL: "https://example.com/missing"},
+		{Page: 2, Text: "Tide pools", URL: "https://example.com/second"},
+	}
+	text := "Tide pools\nSee appendix\nMore on Tide pools"
+	expected := "[Tide pools](https://example.com/tide-pools)\nSee appendix\nMore on [Tide pools](https://example.com/second)"
+	if result := InlineLinks(text, links); result != expected {
+		t.Errorf("InlineLinks = %q, expected %q", result, expected)
+	}
+}
+
+// damagedPDFs returns copies of testdata/text.pdf with the damage RepairFile
+// fixes: every object moved away from its cross-reference offset, and the
+// cross-reference table and trailer cut off
+func damagedPDFs(t *testing.T) map[string][]byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/text.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := bytes.Index(data, []byte("\n1 0 obj"))
+	xref := bytes.LastIndex(data, []byte("\nxref"))
+	if first < 0 || xref < 0 {
+		t.Fatal("testdata/text.pdf has no classic cross-reference table")
+	}
+
+	shifted := append([]byte{}, data[:first]...)
+	shifted = append(shifted, "\n% inserted by an editor, moving every object"...)
+	shifted = append(shifted, data[first:]...)
+
+	truncated := append([]byte{}, data[:xref]...)
+	return map[string][]byte{"shifted": shifted, "truncated": truncated}
+}
+
+func TestRepairFile(t *testing.T) {
+	extractor := NewTextExtractor()
+	dir := t.TempDir()
+
+	for name, data := range damagedPDFs(t) {
+		in := filepath.Join(dir, name+".pdf")
+		if err := os.WriteFile(in, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := api.ReadContext(bytes.NewReader(data), extractor.Config); err == nil {
+			t.Fatalf("%s: expected the damaged PDF not to open", name)
+		}
+
+		out := filepath.Join(dir, name+"-fixed.pdf")
+		report, err := extractor.RepairFile(in, out)
+		if err != nil {
+			t.Fatalf("%s: RepairFile failed: %v", name, err)
+		}
+		if len(report.Problems) == 0 || !report.Repaired() || !strings.Contains(report.Repairs[0], "rebuilt the cross-reference table") {
+			t.Errorf("%s: unexpected report %+v", name, report)
+		}
+		if count, err := api.PageCountFile(out); err != nil || count != report.Pages || count == 0 {
+			t.Errorf("%s: repaired PDF has %d pages (%v), report says %d", name, count, err, report.Pages)
+		}
+		if _, err := extractor.ExtractWithPositions(out); err != nil {
+			t.Errorf("%s: expected text to be extracted from the repaired PDF, got %v", name, err)
+		}
+	}
+
+	// A valid PDF needs no repair but is still written
+	out := filepath.Join(dir, "valid.pdf")
+	report, err := extractor.RepairFile("testdata/text.pdf", out)
+	if err != nil || report.Repaired() || len(report.Problems) != 0 {
+		t.Errorf("Expected a valid PDF to need no repair, got %+v, %v", report, err)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("Expected the valid PDF to be written: %v", err)
+	}
+
+	// Files that are not PDFs at all cannot be repaired
+	junk := filepath.Join(dir, "junk.pdf")
+	if err := os.WriteFile(junk, []byte("<html>not found</html>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := extractor.RepairFile(junk, filepath.Join(dir, "junk-fixed.pdf")); err == nil {
+		t.Error("Expected a file without PDF objects not to be repaired")
+	}
+}
+
+func TestExtractTables(t *testing.T) {
+	tables, err := NewTextExtractor().ExtractTables("testdata/table.pdf", nil)
+	if err != nil {
+		t.Fatalf("ExtractTables failed: %v", err)
+	}
+
+	// The title and the closing sentence stand alone on their lines and are
+	// left out; the South row is drawn by one TJ operator and the spanning
+	// row lands in the first column
+	expected := []Table{{Page: 1, Rows: [][]string{
+		{"Region", "Units", "Revenue"},
+		{"North", "120", "4,800"},
+		{"South", "95", "3,990"},
+		{"East and West combined", "", ""},
+		{"Total", "215", "8,790"},
+	}}}
+	if !reflect.DeepEqual(tables, expected) {
+		t.Errorf("ExtractTables() = %q, expected %q", tables, expected)
+	}
+
+	if tables, err := NewTextExtractor().ExtractTables("testdata/table.pdf", []int{2}); err != nil || len(tables) != 0 {
+		t.Errorf("ExtractTables() on a page without tables = %v, %v", tables, err)
+	}
+	if tables, err := NewTextExtractor().ExtractTables("testdata/text.pdf", nil); err != nil || len(tables) != 0 {
+		t.Errorf("ExtractTables() on prose = %v, %v", tables, err)
+	}
+}
+
+func TestDetectTables(t *testing.T) {
+	at := func(text string, x, y, width float64) cell {
+		return cell{text: text, box: types.Rectangle{LL: types.Point{X: x, Y: y}, UR: types.Point{X: x + width, Y: y + 10}}}
+	}
+	cells := []cell{
+		// A table whose second row has a cell spanning both columns
+		at("Name", 50, 700, 40), at("Score", 150, 700, 40),
+		at("Team results for the season", 50, 686, 150),
+		at("Ada", 50, 672, 30), at("9", 160, 672, 10),
+		// A second table far below, given out of order
+		at("B2", 120, 400, 20), at("A1", 50, 414, 20), at("B1", 120, 414, 20), at("A2", 50, 400, 20),
+	}
+
+	expected := [][][]string{
+		{{"Name", "Score"}, {"Team results for the season", ""}, {"Ada", "9"}},
+		{{"A1", "B1"}, {"A2", "B2"}},
+	}
+	if got := detectTables(cells); !reflect.DeepEqual(got, expected) {
+		t.Errorf("detectTables() = %q, expected %q", got, expected)
+	}
+}
+
+func TestExtractFromFileLeavesNoFiles(t *testing.T) {
+	path, err := filepath.Abs("testdata/text.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if _, err := NewTextExtractor().ExtractFromFile(path); err != nil {
+		t.Fatalf("ExtractFromFile failed: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("ExtractFromFile left %d files in the working directory", len(entries))
+	}
+}
+
+// largePDF merges copies of the fixtures into a PDF of many pages
+func largePDF(tb testing.TB, copies int) string {
+	tb.Helper()
+	var files []string
+	for range copies {
+		files = append(files, "testdata/text.pdf", "testdata/links.pdf", "testdata/table.pdf")
+	}
+	path := filepath.Join(tb.TempDir(), "large.pdf")
+	if err := api.MergeCreateFile(files, path, false, nil); err != nil {
+		tb.Fatalf("MergeCreateFile failed: %v", err)
+	}
+	return path
+}
+
+func TestExtractPagesWorkers(t *testing.T) {
+	path := largePDF(t, 10)
+	serial, err := NewTextExtractor().ExtractFromFile(path)
+	if err != nil {
+		t.Fatalf("ExtractFromFile failed: %v", err)
+	}
+	if !strings.Contains(serial, "Hello World") {
+		t.Fatalf("Expected the text of the fixtures, got %q", serial)
+	}
+
+	for _, workers := range []int{2, 3, 8, 100} {
+		extractor := NewTextExtractor()
+		extractor.Workers = workers
+		got, err := extractor.ExtractFromFile(path)
+		if err != nil {
+			t.Fatalf("ExtractFromFile with %d workers failed: %v", workers, err)
+		}
+		if got != serial {
+			t.Errorf("ExtractFromFile with %d workers differs from the serial extraction", workers)
+		}
+
+		pages := []int{2, 5, 6, 30}
+		want, _ := NewTextExtractor().ExtractPages(path, pages)
+		if got, err := extractor.ExtractPages(path, pages); err != nil || got != want {
+			t.Errorf("ExtractPages with %d workers = %q, %v, expected %q", workers, got, err, want)
+		}
+	}
+}
+
+// BenchmarkExtractPages extracts a large PDF serially and with workers
+func BenchmarkExtractPages(b *testing.B) {
+	path := largePDF(b, 100)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			extractor := NewTextExtractor()
+			extractor.Workers = workers
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := extractor.ExtractFromFile(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	extractor := NewTextExtractor()
+	if !extractor.CanHandle("testdata/text.pdf") || extractor.CanHandle("https://example.com/text.pdf") {
+		t.Error("Expected only local PDF files to be handled")
+	}
+
+	result, err := extractor.Extract(context.Background(), "testdata/text.pdf")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if result.Title != "text" || result.Content != "Hello World\nCall 555-12-3456\nScaled\n" {
+		t.Errorf("Extract() = %q, %q, expected the text of the fixture", result.Title, result.Content)
+	}
+}
+
+func TestGetPageCount(t *testing.T) {
+	te := NewTextExtractor()
+	tests := map[string]int{
+		"testdata/text.pdf":  1,
+		"testdata/links.pdf": 2,
+		largePDF(t, 3):       12,
+	}
+	for path, expected := range tests {
+		count, err := te.GetPageCount(path)
+		if err != nil {
+			t.Fatalf("GetPageCount(%s) failed: %v", path, err)
+		}
+		if count != expected {
+			t.Errorf("GetPageCount(%s) = %d, expected %d", path, count, expected)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count, err := te.GetPageCountFromReader(bytes.NewReader(data)); err != nil || count != expected {
+			t.Errorf("GetPageCountFromReader(%s) = %d (err %v), expected %d", path, count, err, expected)
+		}
+	}
+
+	if _, err := te.GetPageCountFromBytes([]byte("not a pdf")); err == nil {
+		t.Error("Expected an error counting the pages of invalid data")
+	}
+}
@@ -0,0 +1,494 @@
+package extractors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxFormDepth limits how deeply form XObjects drawn by other forms are
+// followed, guarding against forms that draw themselves
+const maxFormDepth = 8
+
+// TextBlock is a piece of text shown by one text operator of a page, with its
+// bounding box in PDF user space: points from the lower-left page corner
+type TextBlock struct {
+	Page   int     `json:"page"`
+	Text   string  `json:"text"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// placedGlyph is a glyph with the box it covers on the page
+type placedGlyph struct {
+	glyph
+	box     types.Rectangle
+	element int     // index of the string in a TJ array, 0 for other operators
+	advance float64 // move to the next glyph in thousandths of the font size, spacing included
+}
+
+// textRun is the text shown by one text operator
+type textRun struct {
+	op     int // index of the operator in the page content, -1 inside forms
+	glyphs []placedGlyph
+	text   string
+	layers []string // optional content groups the text is drawn in, outermost first
+}
+
+// box returns the union of the glyph boxes of a run
+func (r textRun) box() types.Rectangle {
+	box := r.glyphs[0].box
+	for _, g := range r.glyphs[1:] {
+		box.LL.X = math.Min(box.LL.X, g.box.LL.X)
+		box.LL.Y = math.Min(box.LL.Y, g.box.LL.Y)
+		box.UR.X = math.Max(box.UR.X, g.box.UR.X)
+		box.UR.Y = math.Max(box.UR.Y, g.box.UR.Y)
+	}
+	return box
+}
+
+// ExtractWithPositions returns the text of a PDF file as blocks, one per
+// text-showing operator, in the order the pages draw them, together with
+// each block's page and bounding box. Boxes come from the font metrics the
+// PDF embeds and are approximate for fonts that describe themselves poorly.
+// Text in layers left out by te.Layers is skipped.
+func (te *TextExtractor) ExtractWithPositions(filePath string) ([]TextBlock, error) {
+	return te.pageBlocks(filePath, nil)
+}
+
+// pageBlocks returns the text blocks of the selected pages, or of all pages
+// when none are selected, in page order. With te.Workers above 1 the pages
+// are split into consecutive runs read concurrently. A pdfcpu context caches
+// the objects it dereferences and is not safe for concurrent use, so every
+// worker but the first reads the file into a context of its own.
+func (te *TextExtractor) pageBlocks(filePath string, pages []int) ([]TextBlock, error) {
+	ctx, err := te.readContext(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []int
+	for page := 1; page <= ctx.PageCount; page++ {
+		if len(pages) == 0 || slices.Contains(pages, page) {
+			selected = append(selected, page)
+		}
+	}
+
+	workers := min(te.Workers, len(selected))
+	if workers < 2 {
+		return te.blocksOf(ctx, selected)
+	}
+
+	results := make([][]TextBlock, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		run := selected[w*len(selected)/workers : (w+1)*len(selected)/workers]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workerCtx := ctx
+			if w > 0 {
+				if workerCtx, errs[w] = te.readContext(filePath); errs[w] != nil {
+					return
+				}
+			}
+			results[w], errs[w] = te.blocksOf(workerCtx, run)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return slices.Concat(results...), nil
+}
+
+// blocksOf returns the text blocks of the given pages of ctx, in page order
+func (te *TextExtractor) blocksOf(ctx *model.Context, pages []int) ([]TextBlock, error) {
+	var blocks []TextBlock
+	for _, page := range pages {
+		runs, _, err := pageText(ctx, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read text on page %d: %w", page, err)
+		}
+		for _, run := range runs {
+			if strings.TrimSpace(run.text) == "" || !te.Layers.keeps(run.layers) {
+				continue
+			}
+			box := run.box()
+			blocks = append(blocks, TextBlock{
+				Page:   page,
+				Text:   run.text,
+				X:      round2(box.LL.X),
+				Y:      round2(box.LL.Y),
+				Width:  round2(box.Width()),
+				Height: round2(box.Height()),
+			})
+		}
+	}
+	return blocks, nil
+}
+
+// readContext reads and validates a PDF file
+func (te *TextExtractor) readContext(filePath string) (*model.Context, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	return te.readContextFrom(f, filePath)
+}
+
+// readContextFrom reads and validates a PDF named name in error messages
+func (te *TextExtractor) readContextFrom(rs io.ReadSeeker, name string) (*model.Context, error) {
+	ctx, err := api.ReadContext(rs, te.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF %s: %w", name, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		return nil, fmt.Errorf("invalid PDF %s: %w", name, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, fmt.Errorf("failed to count pages of %s: %w", name, err)
+	}
+	return ctx, nil
+}
+
+// pageText interprets the content of a page and returns the text runs it
+// shows along with the parsed page content
+func pageText(ctx *model.Context, page int) ([]textRun, []operation, error) {
+	pageDict, _, inherited, err := ctx.PageDict(page, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := ctx.PageContent(pageDict)
+	if err == model.ErrNoContent {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	ops, err := parseContent(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	in := &interpreter{ctx: ctx, fonts: map[string]*pdfFont{}}
+	in.run(ops, inherited.Resources, matrix.IdentMatrix, true, 0)
+	return in.runs, ops, nil
+}
+
+// graphicsState is the part of the PDF graphics state that places text
+type graphicsState struct {
+	ctm       matrix.Matrix
+	font      *pdfFont
+	fontSize  float64
+	charSpace float64
+	wordSpace float64
+	scale     float64 // horizontal scaling as a fraction
+	leading   float64
+	rise      float64
+}
+
+// interpreter follows the text and graphics state operators of content
+// streams and records where text is shown
+type interpreter struct {
+	ctx    *model.Context
+	fonts  map[string]*pdfFont // fonts by the object number or name they were loaded from
+	runs   []textRun
+	layers []string // layers of the open optional content, outermost first
+}
+
+// run interprets the operations of a content stream drawn with the given
+// resources and initial transformation. top marks the page content itself,
+// whose operators text runs refer back to.
+func (in *interpreter) run(ops []operation, resources types.Dict, ctm matrix.Matrix, top bool, depth int) {
+	gs := graphicsState{ctm: ctm, scale: 1, font: loadFont(in.ctx, nil)}
+	var stack []graphicsState
+	tm, tlm := matrix.IdentMatrix, matrix.IdentMatrix
+
+	// Marked content nests; each open section records how many layers it
+	// added so EMC can close them. Sections a stream leaves open end with it.
+	var marked []int
+	defer func() {
+		for _, n := range marked {
+			in.layers = in.layers[:len(in.layers)-n]
+		}
+	}()
+
+	for i, op := range ops {
+		args := op.operands
+		switch op.operator {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if len(stack) > 0 {
+				gs = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			if m, ok := matrixOperands(args); ok {
+				gs.ctm = m.Multiply(gs.ctm)
+			}
+		case "BT":
+			tm, tlm = matrix.IdentMatrix, matrix.IdentMatrix
+		case "Tf":
+			if len(args) == 2 {
+				if name, ok := args[0].(pdfName); ok {
+					gs.font = in.font(resources, string(name))
+				}
+				gs.fontSize = numberOperand(args[1])
+			}
+		case "Tc":
+			gs.charSpace = lastNumber(args)
+		case "Tw":
+			gs.wordSpace = lastNumber(args)
+		case "Tz":
+			gs.scale = lastNumber(args) / 100
+		case "TL":
+			gs.leading = lastNumber(args)
+		case "Ts":
+			gs.rise = lastNumber(args)
+		case "Td", "TD":
+			if len(args) == 2 {
+				tx, ty := numberOperand(args[0]), numberOperand(args[1])
+				if op.operator == "TD" {
+					gs.leading = -ty
+				}
+				tlm = translation(tx, ty).Multiply(tlm)
+				tm = tlm
+			}
+		case "Tm":
+			if m, ok := matrixOperands(args); ok {
+				tm, tlm = m, m
+			}
+		case "T*":
+			tlm = translation(0, -gs.leading).Multiply(tlm)
+			tm = tlm
+		case "Tj", "'", "\"":
+			if op.operator != "Tj" {
+				if op.operator == "\"" && len(args) == 3 {
+					gs.wordSpace, gs.charSpace = numberOperand(args[0]), numberOperand(args[1])
+				}
+				tlm = translation(0, -gs.leading).Multiply(tlm)
+				tm = tlm
+			}
+			if len(args) > 0 {
+				if s, ok := args[len(args)-1].([]byte); ok {
+					in.show(&gs, &tm, []interface{}{s}, i, top)
+				}
+			}
+		case "TJ":
+			if len(args) == 1 {
+				if array, ok := args[0].([]interface{}); ok {
+					in.show(&gs, &tm, array, i, top)
+				}
+			}
+		case "BMC":
+			marked = append(marked, 0)
+		case "BDC":
+			layers := markedLayers(in.ctx, resources, args)
+			in.layers = append(in.layers, layers...)
+			marked = append(marked, len(layers))
+		case "EMC":
+			if len(marked) > 0 {
+				in.layers = in.layers[:len(in.layers)-marked[len(marked)-1]]
+				marked = marked[:len(marked)-1]
+			}
+		case "Do":
+			if len(args) == 1 && depth < maxFormDepth {
+				if name, ok := args[0].(pdfName); ok {
+					in.drawForm(resources, string(name), gs.ctm, depth)
+				}
+			}
+		}
+	}
+}
+
+// show places the glyphs of the strings in a Tj or TJ operand list, moving
+// the text matrix past them. Numbers in a TJ array move the next glyph left
+// by thousandths of the font size; large moves to the right separate words.
+func (in *interpreter) show(gs *graphicsState, tm *matrix.Matrix, elements []interface{}, op int, top bool) {
+	run := textRun{op: -1}
+	if top {
+		run.op = op
+	}
+	if len(in.layers) > 0 {
+		run.layers = append([]string(nil), in.layers...)
+	}
+
+	var text strings.Builder
+	for e, element := range elements {
+		switch element := element.(type) {
+		case float64:
+			tx := -element / 1000 * gs.fontSize * gs.scale
+			*tm = translation(tx, 0).Multiply(*tm)
+			if element < -200 && text.Len() > 0 && !strings.HasSuffix(text.String(), " ") {
+				text.WriteString(" ")
+			}
+		case []byte:
+			for _, g := range gs.font.glyphs(element) {
+				trm := matrix.Matrix{{gs.fontSize * gs.scale, 0, 0}, {0, gs.fontSize, 0}, {0, gs.rise, 1}}.
+					Multiply(*tm).Multiply(gs.ctm)
+				w := g.width / 1000
+				box := transformBox(trm, w, gs.font.ascent/1000, gs.font.descent/1000)
+				spacing := gs.charSpace
+				if g.space {
+					spacing += gs.wordSpace
+				}
+				advance := g.width
+				if gs.fontSize != 0 {
+					advance += spacing * 1000 / gs.fontSize
+				}
+				run.glyphs = append(run.glyphs, placedGlyph{glyph: g, box: box, element: e, advance: advance})
+				text.WriteString(g.text)
+
+				tx := (w*gs.fontSize + spacing) * gs.scale
+				*tm = translation(tx, 0).Multiply(*tm)
+			}
+		}
+	}
+
+	if len(run.glyphs) > 0 {
+		run.text = text.String()
+		in.runs = append(in.runs, run)
+	}
+}
+
+// font returns the font a Tf operator names, loading it on first use
+func (in *interpreter) font(resources types.Dict, name string) *pdfFont {
+	fonts, err := in.ctx.DereferenceDict(resources["Font"])
+	if err != nil || fonts == nil {
+		return loadFont(in.ctx, nil)
+	}
+	obj := fonts[name]
+
+	key := "name:" + name
+	if ref, ok := obj.(types.IndirectRef); ok {
+		key = fmt.Sprintf("obj:%d", ref.ObjectNumber.Value())
+	}
+	if f, ok := in.fonts[key]; ok {
+		return f
+	}
+
+	d, err := in.ctx.DereferenceDict(obj)
+	if err != nil {
+		d = nil
+	}
+	f := loadFont(in.ctx, d)
+	in.fonts[key] = f
+	return f
+}
+
+// drawForm interprets a form XObject drawn with Do, ignoring images
+func (in *interpreter) drawForm(resources types.Dict, name string, ctm matrix.Matrix, depth int) {
+	xobjects, err := in.ctx.DereferenceDict(resources["XObject"])
+	if err != nil || xobjects == nil {
+		return
+	}
+	sd, _, err := in.ctx.DereferenceStreamDict(xobjects[name])
+	if err != nil || sd == nil {
+		return
+	}
+	if subtype := sd.Dict.NameEntry("Subtype"); subtype == nil || *subtype != "Form" {
+		return
+	}
+	if err := sd.Decode(); err != nil {
+		return
+	}
+	ops, err := parseContent(sd.Content)
+	if err != nil {
+		return
+	}
+
+	formResources := resources
+	if d, err := in.ctx.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		formResources = d
+	}
+	if array, err := in.ctx.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(array) == 6 {
+		var operands []interface{}
+		for _, v := range array {
+			n, _ := number(in.ctx, v)
+			operands = append(operands, n)
+		}
+		if m, ok := matrixOperands(operands); ok {
+			ctm = m.Multiply(ctm)
+		}
+	}
+	// A form can belong to a layer as a whole
+	layers := contentLayers(in.ctx, sd.Dict["OC"])
+	in.layers = append(in.layers, layers...)
+	in.run(ops, formResources, ctm, false, depth+1)
+	in.layers = in.layers[:len(in.layers)-len(layers)]
+}
+
+// transformBox maps the glyph box from (0, descent) to (width, ascent) in
+// text space to the page, returning the axis-aligned box around it
+func transformBox(m matrix.Matrix, width, ascent, descent float64) types.Rectangle {
+	corners := []types.Point{
+		m.Transform(types.Point{X: 0, Y: descent}),
+		m.Transform(types.Point{X: width, Y: descent}),
+		m.Transform(types.Point{X: 0, Y: ascent}),
+		m.Transform(types.Point{X: width, Y: ascent}),
+	}
+	box := types.Rectangle{LL: corners[0], UR: corners[0]}
+	for _, p := range corners[1:] {
+		box.LL.X, box.LL.Y = math.Min(box.LL.X, p.X), math.Min(box.LL.Y, p.Y)
+		box.UR.X, box.UR.Y = math.Max(box.UR.X, p.X), math.Max(box.UR.Y, p.Y)
+	}
+	return box
+}
+
+// translation returns the matrix moving points by tx, ty
+func translation(tx, ty float64) matrix.Matrix {
+	return matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, ty, 1}}
+}
+
+// matrixOperands reads the six operands of cm or Tm
+func matrixOperands(args []interface{}) (matrix.Matrix, bool) {
+	if len(args) != 6 {
+		return matrix.Matrix{}, false
+	}
+	var n [6]float64
+	for i, arg := range args {
+		v, ok := arg.(float64)
+		if !ok {
+			return matrix.Matrix{}, false
+		}
+		n[i] = v
+	}
+	return matrix.Matrix{{n[0], n[1], 0}, {n[2], n[3], 0}, {n[4], n[5], 1}}, true
+}
+
+// numberOperand returns a numeric operand, or 0 for any other value
+func numberOperand(arg interface{}) float64 {
+	n, _ := arg.(float64)
+	return n
+}
+
+// lastNumber returns the last operand as a number
+func lastNumber(args []interface{}) float64 {
+	if len(args) == 0 {
+		return 0
+	}
+	return numberOperand(args[len(args)-1])
+}
+
+// round2 rounds to two decimals, well below the precision of glyph metrics
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
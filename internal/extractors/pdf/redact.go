@@ -0,0 +1,303 @@
+package extractors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// glyphRef locates a glyph of a page by its run and its index in the run
+type glyphRef struct {
+	run   int
+	glyph int
+}
+
+// textPiece maps a byte range of the searchable page text to the glyph it
+// came from; separators between runs and words map to no glyph
+type textPiece struct {
+	start, end int
+	ref        glyphRef
+}
+
+// Redact removes the text matching any of the patterns from the pages of a
+// PDF file and covers the places it was shown with black boxes, writing the
+// result to outFile. Matched glyphs are taken out of the content stream, not
+// just painted over, so the text can no longer be selected or extracted;
+// the surrounding text keeps its position. It returns the redacted matches
+// with their boxes. Text drawn inside form XObjects cannot be redacted and
+// is reported as an error rather than left readable under a box.
+func (te *TextExtractor) Redact(input, outFile string, patterns []*regexp.Regexp) ([]TextBlock, error) {
+	if filepath.Clean(input) == filepath.Clean(outFile) {
+		return nil, fmt.Errorf("output %s would overwrite the input", outFile)
+	}
+	ctx, err := te.readContext(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var redacted []TextBlock
+	for page := 1; page <= ctx.PageCount; page++ {
+		blocks, err := redactPage(ctx, page, patterns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to redact page %d: %w", page, err)
+		}
+		redacted = append(redacted, blocks...)
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", outFile, err)
+	}
+	return redacted, nil
+}
+
+// redactPage replaces the content of a page with one that no longer shows
+// the matched text and boxes over where it was. Pages without matches are
+// left untouched.
+func redactPage(ctx *model.Context, page int, patterns []*regexp.Regexp) ([]TextBlock, error) {
+	runs, ops, err := pageText(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	text, pieces := searchableText(runs, ops)
+
+	removed := map[int]map[int]bool{} // glyph indexes by run
+	var blocks []TextBlock
+	var boxes []types.Rectangle
+	for _, pattern := range patterns {
+		for _, loc := range pattern.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			refs := glyphsInRange(pieces, loc[0], loc[1])
+			if len(refs) == 0 {
+				continue
+			}
+
+			var matched textRun
+			byRun := map[int]*textRun{}
+			var order []int
+			for _, ref := range refs {
+				run := runs[ref.run]
+				if run.op < 0 {
+					return nil, fmt.Errorf("%q is drawn by a form XObject, which cannot be redacted", text[loc[0]:loc[1]])
+				}
+				if removed[ref.run] == nil {
+					removed[ref.run] = map[int]bool{}
+				}
+				removed[ref.run][ref.glyph] = true
+
+				g := run.glyphs[ref.glyph]
+				matched.glyphs = append(matched.glyphs, g)
+				if byRun[ref.run] == nil {
+					byRun[ref.run] = &textRun{}
+					order = append(order, ref.run)
+				}
+				byRun[ref.run].glyphs = append(byRun[ref.run].glyphs, g)
+			}
+
+			// One box per run keeps matches wrapping onto the next line from
+			// blacking out the space between the lines
+			for _, r := range order {
+				boxes = append(boxes, byRun[r].box())
+			}
+			box := matched.box()
+			blocks = append(blocks, TextBlock{
+				Page:   page,
+				Text:   text[loc[0]:loc[1]],
+				X:      round2(box.LL.X),
+				Y:      round2(box.LL.Y),
+				Width:  round2(box.Width()),
+				Height: round2(box.Height()),
+			})
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
+	pageDict, _, _, err := ctx.PageDict(page, false)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ctx.PageContent(pageDict)
+	if err != nil {
+		return nil, err
+	}
+	sd, err := ctx.NewStreamDictForBuf(redactedContent(content, runs, ops, removed, boxes))
+	if err != nil {
+		return nil, err
+	}
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	ref, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return nil, err
+	}
+	pageDict["Contents"] = *ref
+	return blocks, nil
+}
+
+// searchableText joins the text of the runs of a page the way a reader
+// would see it: runs on separate lines and words split by large TJ moves
+// separated by spaces. pieces map the text back to glyphs.
+func searchableText(runs []textRun, ops []operation) (string, []textPiece) {
+	var text strings.Builder
+	var pieces []textPiece
+	for r, run := range runs {
+		if r > 0 {
+			text.WriteString("\n")
+		}
+		var elements []interface{}
+		if run.op >= 0 && ops[run.op].operator == "TJ" && len(ops[run.op].operands) == 1 {
+			elements, _ = ops[run.op].operands[0].([]interface{})
+		}
+		for i, g := range run.glyphs {
+			if i > 0 && wordGap(elements, run.glyphs[i-1].element, g.element) {
+				text.WriteString(" ")
+			}
+			start := text.Len()
+			text.WriteString(g.text)
+			pieces = append(pieces, textPiece{start: start, end: text.Len(), ref: glyphRef{run: r, glyph: i}})
+		}
+	}
+	return text.String(), pieces
+}
+
+// wordGap reports whether the TJ elements between two strings move the
+// text far enough to separate words, as show does
+func wordGap(elements []interface{}, from, to int) bool {
+	for e := from + 1; e < to && e < len(elements); e++ {
+		if n, ok := elements[e].(float64); ok && n < -200 {
+			return true
+		}
+	}
+	return false
+}
+
+// glyphsInRange returns the glyphs whose text overlaps the byte range
+// [start, end), including glyphs without text inside it
+func glyphsInRange(pieces []textPiece, start, end int) []glyphRef {
+	var refs []glyphRef
+	for _, p := range pieces {
+		overlaps := p.start < end && p.end > start
+		if p.start == p.end {
+			overlaps = p.start > start && p.start < end
+		}
+		if overlaps {
+			refs = append(refs, p.ref)
+		}
+	}
+	return refs
+}
+
+// redactedContent rebuilds the content of a page with the removed glyphs of
+// each run replaced by moves of the same width, then paints the boxes. The
+// original content is wrapped in q/Q so the boxes are drawn in default user
+// space whatever graphics state it leaves behind.
+func redactedContent(content []byte, runs []textRun, ops []operation, removed map[int]map[int]bool, boxes []types.Rectangle) []byte {
+	changed := make([]int, 0, len(removed))
+	for r := range removed {
+		changed = append(changed, r)
+	}
+	sort.Slice(changed, func(i, j int) bool { return runs[changed[i]].op < runs[changed[j]].op })
+
+	var buf bytes.Buffer
+	buf.WriteString("q\n")
+	pos := 0
+	for _, r := range changed {
+		op := ops[runs[r].op]
+		buf.Write(content[pos:op.start])
+		buf.WriteString(redactedOperation(op, runs[r], removed[r]))
+		pos = op.end
+	}
+	buf.Write(content[pos:])
+
+	buf.WriteString("\nQ\nq 0 g\n")
+	for _, box := range boxes {
+		fmt.Fprintf(&buf, "%s %s %s %s re f\n", formatNumber(box.LL.X), formatNumber(box.LL.Y), formatNumber(box.Width()), formatNumber(box.Height()))
+	}
+	buf.WriteString("Q\n")
+	return buf.Bytes()
+}
+
+// redactedOperation rewrites a text-showing operator as a TJ array without
+// the removed glyphs, each replaced by a move of its advance so the glyphs
+// after it stay in place. ' and " become the operators they abbreviate.
+func redactedOperation(op operation, run textRun, removed map[int]bool) string {
+	var prefix string
+	var elements []interface{}
+	switch op.operator {
+	case "TJ":
+		elements, _ = op.operands[0].([]interface{})
+	case "\"":
+		prefix = formatNumber(numberOperand(op.operands[0])) + " Tw " + formatNumber(numberOperand(op.operands[1])) + " Tc T* "
+		elements = op.operands[len(op.operands)-1:]
+	case "'":
+		prefix = "T* "
+		elements = op.operands[len(op.operands)-1:]
+	default:
+		elements = op.operands[len(op.operands)-1:]
+	}
+
+	var parts []string
+	var code []byte
+	move := 0.0
+	flush := func() {
+		if len(code) > 0 {
+			parts = append(parts, "<"+hex.EncodeToString(code)+">")
+			code = nil
+		}
+		if move != 0 {
+			parts = append(parts, formatNumber(move))
+			move = 0
+		}
+	}
+
+	g := 0
+	for e, element := range elements {
+		switch element := element.(type) {
+		case float64:
+			if len(code) > 0 {
+				flush()
+			}
+			move += element
+		case []byte:
+			for ; g < len(run.glyphs) && run.glyphs[g].element == e; g++ {
+				if removed[g] {
+					if len(code) > 0 {
+						flush()
+					}
+					move -= run.glyphs[g].advance
+					continue
+				}
+				if move != 0 {
+					flush()
+				}
+				code = append(code, run.glyphs[g].code...)
+			}
+		}
+	}
+	flush()
+	return prefix + "[" + strings.Join(parts, " ") + "] TJ"
+}
+
+// formatNumber writes a number for a content stream with up to three
+// decimals
+func formatNumber(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
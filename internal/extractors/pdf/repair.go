@@ -0,0 +1,202 @@
+package extractors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// RepairReport describes what RepairFile found wrong with a PDF and what it
+// did about it
+type RepairReport struct {
+	Problems []string // what was wrong with the input, empty for a valid PDF
+	Repairs  []string // what was done to fix it
+	Pages    int      // pages in the repaired document
+}
+
+// Repaired reports whether anything had to be fixed
+func (r *RepairReport) Repaired() bool {
+	return len(r.Repairs) > 0
+}
+
+var (
+	// objectPattern matches the start of an indirect object, "12 0 obj"
+	objectPattern = regexp.MustCompile(`(?m)(?:^|[\r\n\s])(\d+)\s+(\d+)\s+obj\b`)
+
+	// rootPattern matches the catalog reference of a trailer
+	rootPattern = regexp.MustCompile(`/Root\s+(\d+)\s+(\d+)\s+R`)
+
+	// infoPattern matches the document information reference of a trailer
+	infoPattern = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+
+	// catalogPattern matches the type entry of a document catalog
+	catalogPattern = regexp.MustCompile(`/Type\s*/Catalog\b`)
+)
+
+// RepairFile reads a PDF that may be damaged and writes a repaired copy to
+// outFile. Cross-reference tables that are missing or point to the wrong
+// offsets, the most common damage from truncated downloads and careless
+// editing, are rebuilt by locating every object in the file. The document
+// is then validated leniently, stripped of unused and duplicate objects and
+// written with a fresh cross-reference table. A PDF that needs no repair is
+// still rewritten, so outFile can always be used in its place.
+func (te *TextExtractor) RepairFile(inFile, outFile string) (*RepairReport, error) {
+	data, err := os.ReadFile(inFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF %s: %w", inFile, err)
+	}
+
+	report := &RepairReport{}
+	if err := checkStrict(data); err != nil {
+		report.Problems = append(report.Problems, err.Error())
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(data), te.relaxed())
+	if err != nil {
+		rebuilt, objects, rebuildErr := rebuildXRef(data)
+		if rebuildErr != nil {
+			return nil, fmt.Errorf("cannot repair %s: %v (%w)", inFile, err, rebuildErr)
+		}
+		if ctx, err = api.ReadContext(bytes.NewReader(rebuilt), te.relaxed()); err != nil {
+			return nil, fmt.Errorf("cannot repair %s: %w", inFile, err)
+		}
+		report.Repairs = append(report.Repairs, fmt.Sprintf("rebuilt the cross-reference table from %d objects found in the file", objects))
+	}
+
+	if err := api.ValidateContext(ctx); err != nil {
+		return nil, fmt.Errorf("cannot repair %s: %w", inFile, err)
+	}
+	if err := api.OptimizeContext(ctx); err != nil {
+		return nil, fmt.Errorf("cannot repair %s: %w", inFile, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, fmt.Errorf("cannot repair %s: %w", inFile, err)
+	}
+	report.Pages = ctx.PageCount
+
+	if len(report.Problems) > 0 && !report.Repaired() {
+		report.Repairs = append(report.Repairs, "rewrote the document with the problems corrected")
+	}
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", outFile, err)
+	}
+	return report, nil
+}
+
+// NeedsRepair reports whether an extraction error may be fixed by
+// RepairFile, that is whether it is not about the file being missing or
+// unreadable
+func NeedsRepair(err error) bool {
+	return err != nil && !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, fs.ErrPermission)
+}
+
+// relaxed returns the extractor configuration with lenient validation,
+// which tolerates the deviations common in real-world PDFs
+func (te *TextExtractor) relaxed() *model.Configuration {
+	conf := *model.NewDefaultConfiguration()
+	if te.Config != nil {
+		conf = *te.Config
+	}
+	conf.ValidationMode = model.ValidationRelaxed
+	return &conf
+}
+
+// checkStrict reads and validates a PDF strictly, returning what is wrong
+// with it
+func checkStrict(data []byte) error {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationStrict
+	ctx, err := api.ReadContext(bytes.NewReader(data), conf)
+	if err != nil {
+		return err
+	}
+	return api.ValidateContext(ctx)
+}
+
+// rebuildXRef appends a cross-reference table and trailer listing every
+// object found in data, returning the result and how many objects it lists.
+// A later definition of an object replaces an earlier one, as with
+// incremental updates. The catalog is taken from the last trailer naming
+// one, or else from the object declaring itself a catalog.
+func rebuildXRef(data []byte) ([]byte, int, error) {
+	offsets := map[int]int{}
+	generations := map[int]int{}
+	for _, m := range objectPattern.FindAllSubmatchIndex(data, -1) {
+		nr, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		gen, _ := strconv.Atoi(string(data[m[4]:m[5]]))
+		offsets[nr] = m[2]
+		generations[nr] = gen
+	}
+	if len(offsets) == 0 {
+		return nil, 0, errors.New("no objects found")
+	}
+
+	root := lastMatch(rootPattern, data)
+	if root == "" {
+		root = catalogRef(data, offsets, generations)
+	}
+	if root == "" {
+		return nil, 0, errors.New("no document catalog found")
+	}
+
+	numbers := make([]int, 0, len(offsets))
+	for nr := range offsets {
+		numbers = append(numbers, nr)
+	}
+	sort.Ints(numbers)
+	size := numbers[len(numbers)-1] + 1
+
+	var b bytes.Buffer
+	b.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n", size)
+	for nr := 0; nr < size; nr++ {
+		if offset, ok := offsets[nr]; ok {
+			fmt.Fprintf(&b, "%010d %05d n \n", offset, generations[nr])
+		} else {
+			b.WriteString("0000000000 65535 f \n")
+		}
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %s", size, root)
+	if info := lastMatch(infoPattern, data); info != "" {
+		fmt.Fprintf(&b, " /Info %s", info)
+	}
+	fmt.Fprintf(&b, " >>\nstartxref\n%d\n%%%%EOF\n", xref)
+	return b.Bytes(), len(offsets), nil
+}
+
+// lastMatch returns the last "N G R" reference pattern matches in data
+func lastMatch(pattern *regexp.Regexp, data []byte) string {
+	matches := pattern.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	m := matches[len(matches)-1]
+	return fmt.Sprintf("%s %s R", m[1], m[2])
+}
+
+// catalogRef returns a reference to the object declaring itself the
+// document catalog, or "" when there is none
+func catalogRef(data []byte, offsets, generations map[int]int) string {
+	for nr, offset := range offsets {
+		end := bytes.Index(data[offset:], []byte("endobj"))
+		if end < 0 {
+			continue
+		}
+		if catalogPattern.Match(data[offset : offset+end]) {
+			return fmt.Sprintf("%d %d R", nr, generations[nr])
+		}
+	}
+	return ""
+}
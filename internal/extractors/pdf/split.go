@@ -0,0 +1,111 @@
+package extractors
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"maai.solutions/gengo/internal/output"
+)
+
+// PageRange is an inclusive range of 1-based page numbers
+type PageRange struct {
+	From int
+	Thru int
+}
+
+// String formats the range the way ParsePageRanges reads it
+func (r PageRange) String() string {
+	if r.From == r.Thru {
+		return strconv.Itoa(r.From)
+	}
+	return fmt.Sprintf("%d-%d", r.From, r.Thru)
+}
+
+// ParsePageRanges parses a comma-separated list of pages and page ranges
+// such as "1-3,4-6,9"
+func ParsePageRanges(value string) ([]PageRange, error) {
+	var ranges []PageRange
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, thru, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		end := start
+		if err == nil && isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(thru))
+		}
+		if err != nil || start < 1 || end < start {
+			return nil, fmt.Errorf("invalid page range %q", part)
+		}
+		ranges = append(ranges, PageRange{From: start, Thru: end})
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no page ranges given")
+	}
+	return ranges, nil
+}
+
+// SplitFile writes every page of a PDF file to its own PDF in outDir, named
+// after the input with the page number appended (report_1.pdf, report_2.pdf,
+// ...), and returns the written paths in page order
+func (te *TextExtractor) SplitFile(input, outDir string) ([]string, error) {
+	pageCount, err := api.PageCountFile(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF %s: %w", input, err)
+	}
+	if err := output.EnsureDir(outDir); err != nil {
+		return nil, err
+	}
+
+	if err := api.SplitFile(input, outDir, 1, te.Config); err != nil {
+		return nil, fmt.Errorf("failed to split %s: %w", input, err)
+	}
+
+	paths := make([]string, 0, pageCount)
+	for page := 1; page <= pageCount; page++ {
+		paths = append(paths, splitPath(input, outDir, PageRange{From: page, Thru: page}))
+	}
+	return paths, nil
+}
+
+// SplitRanges writes each page range of a PDF file to its own PDF in outDir,
+// named after the input with the range appended (report_1-3.pdf), and
+// returns the written paths in range order
+func (te *TextExtractor) SplitRanges(input, outDir string, ranges []PageRange) ([]string, error) {
+	pageCount, err := api.PageCountFile(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF %s: %w", input, err)
+	}
+	for _, r := range ranges {
+		if r.Thru > pageCount {
+			return nil, fmt.Errorf("page range %s is outside the document (%d pages)", r, pageCount)
+		}
+	}
+	if err := output.EnsureDir(outDir); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		path := splitPath(input, outDir, r)
+		if err := api.TrimFile(input, path, []string{r.String()}, te.Config); err != nil {
+			return paths, fmt.Errorf("failed to write pages %s: %w", r, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// splitPath names the file holding a page range of input, the way pdfcpu
+// names split files
+func splitPath(input, outDir string, r PageRange) string {
+	name := strings.TrimSuffix(filepath.Base(input), ".pdf")
+	return filepath.Join(outDir, name+"_"+r.String()+".pdf")
+}
@@ -0,0 +1,269 @@
+package extractors
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const (
+	// cellGap is the horizontal gap, as a share of the text height, that
+	// separates two cells drawn by one text operator; word spaces are
+	// narrower
+	cellGap = 0.6
+
+	// spaceGap is the gap, as a share of the text height, read as a space
+	// between words when the PDF draws none
+	spaceGap = 0.15
+
+	// rowSpacing is the largest vertical gap between rows of one table, as
+	// a multiple of the row height
+	rowSpacing = 2.0
+)
+
+// Table is a grid of text detected on a page, with rows from top to bottom
+// and cells from left to right. The text of a cell spanning several columns
+// is kept in the first of them, leaving the others empty.
+type Table struct {
+	Page int        `json:"page"`
+	Rows [][]string `json:"rows"`
+}
+
+// cell is a piece of text standing apart from its neighbours on a line
+type cell struct {
+	text string
+	box  types.Rectangle
+}
+
+// line is a row of cells sharing a baseline, ordered left to right
+type line struct {
+	cells       []cell
+	top, bottom float64
+}
+
+// ExtractTables detects the tables on the selected pages of a PDF file, or
+// on every page when none are selected. Tables are found from the position
+// of the text alone: lines split into several cells whose columns line up
+// from one line to the next. Ruling lines and cell backgrounds are not
+// looked at, so tables without gaps between their columns are missed.
+func (te *TextExtractor) ExtractTables(filePath string, pages []int) ([]Table, error) {
+	ctx, err := te.readContext(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []Table
+	for page := 1; page <= ctx.PageCount; page++ {
+		if len(pages) > 0 && !slices.Contains(pages, page) {
+			continue
+		}
+		runs, _, err := pageText(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+
+		var cells []cell
+		for _, run := range runs {
+			if te.Layers.keeps(run.layers) {
+				cells = append(cells, splitRun(run)...)
+			}
+		}
+		for _, rows := range detectTables(cells) {
+			tables = append(tables, Table{Page: page, Rows: rows})
+		}
+	}
+	return tables, nil
+}
+
+// splitRun splits the text of a run into cells wherever its glyphs leave a
+// gap wider than cellGap, as tables drawn one row at a time do
+func splitRun(run textRun) []cell {
+	var cells []cell
+	var current *cell
+	var text strings.Builder
+	flush := func() {
+		if current != nil {
+			current.text = strings.Join(strings.Fields(text.String()), " ")
+			cells = append(cells, *current)
+		}
+		current = nil
+		text.Reset()
+	}
+
+	for _, g := range run.glyphs {
+		if strings.TrimSpace(g.text) == "" {
+			text.WriteString(" ")
+			continue
+		}
+		if current != nil {
+			gap := g.box.LL.X - current.box.UR.X
+			height := current.box.Height()
+			if gap > cellGap*height {
+				flush()
+			} else if gap > spaceGap*height {
+				text.WriteString(" ")
+			}
+		}
+		if current == nil {
+			current = &cell{box: g.box}
+		}
+		current.box.LL.X = math.Min(current.box.LL.X, g.box.LL.X)
+		current.box.LL.Y = math.Min(current.box.LL.Y, g.box.LL.Y)
+		current.box.UR.X = math.Max(current.box.UR.X, g.box.UR.X)
+		current.box.UR.Y = math.Max(current.box.UR.Y, g.box.UR.Y)
+		text.WriteString(g.text)
+	}
+	flush()
+	return cells
+}
+
+// groupLines sorts cells into lines from the top of the page down, putting
+// cells whose vertical centers are within half a line height together
+func groupLines(cells []cell) []line {
+	sorted := slices.Clone(cells)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return center(sorted[i].box) > center(sorted[j].box)
+	})
+
+	var lines []line
+	for _, c := range sorted {
+		if n := len(lines); n > 0 {
+			l := &lines[n-1]
+			mid := (l.top + l.bottom) / 2
+			if math.Abs(center(c.box)-mid) < math.Max(l.top-l.bottom, c.box.Height())/2 {
+				l.cells = append(l.cells, c)
+				l.top = math.Max(l.top, c.box.UR.Y)
+				l.bottom = math.Min(l.bottom, c.box.LL.Y)
+				continue
+			}
+		}
+		lines = append(lines, line{cells: []cell{c}, top: c.box.UR.Y, bottom: c.box.LL.Y})
+	}
+	for i := range lines {
+		sort.SliceStable(lines[i].cells, func(a, b int) bool {
+			return lines[i].cells[a].box.LL.X < lines[i].cells[b].box.LL.X
+		})
+	}
+	return lines
+}
+
+// detectTables finds runs of consecutive lines with two or more cells and
+// returns the rows of each. A line with a single cell between two such
+// lines, such as a title spanning the columns or a wrapped cell, stays in
+// the table.
+func detectTables(cells []cell) [][][]string {
+	lines := groupLines(cells)
+	var tables [][][]string
+	for start := 0; start < len(lines); {
+		if len(lines[start].cells) < 2 {
+			start++
+			continue
+		}
+
+		end := start + 1
+		for end < len(lines) && closeBelow(lines[end-1], lines[end]) {
+			if len(lines[end].cells) >= 2 {
+				end++
+			} else if end+1 < len(lines) && len(lines[end+1].cells) >= 2 && closeBelow(lines[end], lines[end+1]) {
+				end += 2
+			} else {
+				break
+			}
+		}
+
+		if rows := tableRows(lines[start:end]); rows != nil {
+			tables = append(tables, rows)
+		}
+		start = end
+	}
+	return tables
+}
+
+// closeBelow reports whether next follows l closely enough to be the next
+// row of the same table
+func closeBelow(l, next line) bool {
+	return l.bottom-next.top < rowSpacing*(l.top-l.bottom)
+}
+
+// tableRows lays the cells of lines out in columns, or returns nil when they
+// do not form a table of at least two rows and two columns. Columns are
+// taken from the lines with the most cells, where no cell spans columns;
+// every other cell goes to the first column it overlaps, or the nearest.
+func tableRows(lines []line) [][]string {
+	full := 0
+	multi := 0
+	for _, l := range lines {
+		full = max(full, len(l.cells))
+		if len(l.cells) >= 2 {
+			multi++
+		}
+	}
+	if multi < 2 {
+		return nil
+	}
+
+	var spans [][2]float64
+	for _, l := range lines {
+		if len(l.cells) == full {
+			for _, c := range l.cells {
+				spans = append(spans, [2]float64{c.box.LL.X, c.box.UR.X})
+			}
+		}
+	}
+	columns := mergeSpans(spans)
+	if len(columns) < 2 {
+		return nil
+	}
+
+	rows := make([][]string, 0, len(lines))
+	for _, l := range lines {
+		row := make([]string, len(columns))
+		for _, c := range l.cells {
+			col := column(columns, c.box)
+			if row[col] != "" {
+				row[col] += " "
+			}
+			row[col] += c.text
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// mergeSpans merges overlapping horizontal spans into column extents,
+// ordered left to right
+func mergeSpans(spans [][2]float64) [][2]float64 {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var merged [][2]float64
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s[0] <= merged[n-1][1] {
+			merged[n-1][1] = math.Max(merged[n-1][1], s[1])
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// column returns the first column box overlaps, or the one nearest to it
+func column(columns [][2]float64, box types.Rectangle) int {
+	nearest, distance := 0, math.Inf(1)
+	mid := (box.LL.X + box.UR.X) / 2
+	for i, col := range columns {
+		if box.LL.X < col[1] && box.UR.X > col[0] {
+			return i
+		}
+		if d := math.Abs(mid - (col[0]+col[1])/2); d < distance {
+			nearest, distance = i, d
+		}
+	}
+	return nearest
+}
+
+// center returns the vertical center of a box
+func center(box types.Rectangle) float64 {
+	return (box.LL.Y + box.UR.Y) / 2
+}
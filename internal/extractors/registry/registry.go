@@ -0,0 +1,150 @@
+// Package registry lets extractors for different source types register
+// behind one interface, so commands dispatch a source without knowing which
+// formats exist.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"maai.solutions/gengo/internal/output"
+)
+
+// Extractor turns a source, a URL or a file path, into a normalized result
+type Extractor interface {
+	// Name identifies the extractor, e.g. "pdf" or "web". It names the
+	// source kind in metrics and messages.
+	Name() string
+	// CanHandle reports whether the extractor understands the source. It
+	// only inspects the source string and does no I/O.
+	CanHandle(source string) bool
+	// Extract extracts the content of the source
+	Extract(ctx context.Context, source string) (output.Result, error)
+}
+
+// Registry holds extractors in the order they were registered
+type Registry struct {
+	extractors []Extractor
+}
+
+// New returns a registry with the given extractors registered
+func New(extractors ...Extractor) *Registry {
+	r := &Registry{}
+	for _, e := range extractors {
+		r.Register(e)
+	}
+	return r
+}
+
+// Register adds an extractor. Sources are offered to extractors in
+// registration order, so specific extractors go before general ones, e.g.
+// YouTube before web pages. An extractor replaces one already registered
+// under its name, keeping that one's place.
+func (r *Registry) Register(e Extractor) {
+	for i, existing := range r.extractors {
+		if existing.Name() == e.Name() {
+			r.extractors[i] = e
+			return
+		}
+	}
+	r.extractors = append(r.extractors, e)
+}
+
+// Lookup returns the first extractor that can handle the source, or nil when
+// none can
+func (r *Registry) Lookup(source string) Extractor {
+	for _, e := range r.extractors {
+		if e.CanHandle(source) {
+			return e
+		}
+	}
+	return nil
+}
+
+// Get returns the extractor registered under name, or nil
+func (r *Registry) Get(name string) Extractor {
+	for _, e := range r.extractors {
+		if e.Name() == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the registered extractors in registration order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.extractors))
+	for _, e := range r.extractors {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// Extract extracts a source with the first extractor that can handle it
+func (r *Registry) Extract(ctx context.Context, source string) (output.Result, error) {
+	e := r.Lookup(source)
+	if e == nil {
+		return output.Result{}, fmt.Errorf("unsupported source: %s", source)
+	}
+	return e.Extract(ctx, source)
+}
+
+// IsLocalPath reports whether a source names a local file rather than a URL
+func IsLocalPath(source string) bool {
+	return source != "" && !strings.Contains(source, "://")
+}
+
+// HasExtension reports whether a local source path ends in one of the
+// extensions, compared case-insensitively
+func HasExtension(source string, extensions ...string) bool {
+	if !IsLocalPath(source) {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(source))
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// fileExtractor adapts a function extracting the title and content of a
+// file to the Extractor interface
+type fileExtractor struct {
+	name       string
+	extensions []string
+	extract    func(path string) (string, string, error)
+}
+
+// FileExtractor returns an extractor for local files with one of the
+// extensions, given with their dot, that extracts them with fn
+func FileExtractor(name string, fn func(path string) (title, content string, err error), extensions ...string) Extractor {
+	return &fileExtractor{name: name, extensions: extensions, extract: fn}
+}
+
+// Name returns the name the extractor was created with
+func (f *fileExtractor) Name() string {
+	return f.name
+}
+
+// CanHandle reports whether the source is a local file with one of the
+// extractor's extensions
+func (f *fileExtractor) CanHandle(source string) bool {
+	return HasExtension(source, f.extensions...)
+}
+
+// Extract reads the file, failing early when it does not exist
+func (f *fileExtractor) Extract(ctx context.Context, source string) (output.Result, error) {
+	if _, err := os.Stat(source); os.IsNotExist(err) {
+		return output.Result{}, fmt.Errorf("file does not exist: %s", source)
+	}
+	title, content, err := f.extract(source)
+	if err != nil {
+		return output.Result{}, err
+	}
+	return output.NewResult(f.name, source, title, content), nil
+}
@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"maai.solutions/gengo/internal/output"
+)
+
+// mockExtractor handles sources starting with its prefix
+type mockExtractor struct {
+	name   string
+	prefix string
+}
+
+func (m *mockExtractor) Name() string { return m.name }
+
+func (m *mockExtractor) CanHandle(source string) bool { return strings.HasPrefix(source, m.prefix) }
+
+func (m *mockExtractor) Extract(ctx context.Context, source string) (output.Result, error) {
+	return output.Result{Title: m.name, Source: source}, nil
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r := New(
+		&mockExtractor{name: "video", prefix: "https://video."},
+		&mockExtractor{name: "web", prefix: "https://"},
+	)
+
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"https://video.example.com/1", "video"},
+		{"https://example.com", "web"},
+		{"notes.txt", ""},
+	}
+	for _, test := range tests {
+		name := ""
+		if e := r.Lookup(test.source); e != nil {
+			name = e.Name()
+		}
+		if name != test.expected {
+			t.Errorf("Lookup(%q) = %q, expected %q", test.source, name, test.expected)
+		}
+	}
+
+	result, err := r.Extract(context.Background(), "https://video.example.com/1")
+	if err != nil || result.Title != "video" {
+		t.Errorf("Extract used %q (err %v), expected the video extractor", result.Title, err)
+	}
+	if _, err := r.Extract(context.Background(), "notes.txt"); err == nil {
+		t.Error("Expected an error for a source no extractor handles")
+	}
+}
+
+func TestRegistryRegisterReplaces(t *testing.T) {
+	r := New(
+		&mockExtractor{name: "a", prefix: "a"},
+		&mockExtractor{name: "b", prefix: "b"},
+	)
+	r.Register(&mockExtractor{name: "a", prefix: "x"})
+
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("Names() = %v, expected [a b]", names)
+	}
+	if r.Lookup("x1") != r.Get("a") {
+		t.Error("Expected the replacement to handle its sources under the old name")
+	}
+	if r.Get("missing") != nil {
+		t.Error("Expected nil for an unregistered name")
+	}
+}
+
+func TestFileExtractor(t *testing.T) {
+	e := FileExtractor("text", func(path string) (string, string, error) {
+		data, err := os.ReadFile(path)
+		return "Notes", string(data), err
+	}, ".txt")
+
+	for source, expected := range map[string]bool{
+		"notes.txt":                 true,
+		"./dir/NOTES.TXT":           true,
+		"notes.md":                  false,
+		"https://example.com/a.txt": false,
+		"":                          false,
+	} {
+		if got := e.CanHandle(source); got != expected {
+			t.Errorf("CanHandle(%q) = %v, expected %v", source, got, expected)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := e.Extract(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if result.Title != "Notes" || result.Content != "hello" || result.Source != path {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if _, err := e.Extract(context.Background(), filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
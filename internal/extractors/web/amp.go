@@ -0,0 +1,85 @@
+package extractors
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PageMeta is what an HTML page declares about itself besides its content
+type PageMeta struct {
+	Structured *StructuredData // JSON-LD article data, nil when the page has none
+	Canonical  string          // URL of <link rel="canonical">
+	AMP        string          // URL of <link rel="amphtml">, the page's AMP version
+	Coverage   Coverage        // how much of the visible text was extracted
+}
+
+// FollowAMP returns the AMP version of an HTML page when opts.PreferAMP is
+// set and the page links one. AMP pages carry little beyond the article, so
+// they extract more cleanly. The page itself is returned when it has no AMP
+// version or the AMP version cannot be fetched.
+func FollowAMP(ctx context.Context, page *Page, opts Options) *Page {
+	if !opts.PreferAMP || page.IsPDF() {
+		return page
+	}
+	doc, err := html.Parse(strings.NewReader(string(page.Body)))
+	if err != nil {
+		return page
+	}
+	base, _ := url.Parse(page.URL)
+	_, amp := declaredLinks(doc, base)
+	if amp == "" || amp == page.URL {
+		return page
+	}
+
+	ampPage, err := fetchPage(ctx, amp, opts, nil)
+	if err != nil || ampPage.IsPDF() {
+		return page
+	}
+	return ampPage
+}
+
+// declaredLinks returns the absolute canonical and AMP URLs the <link>
+// elements of a document declare, "" for those it does not
+func declaredLinks(doc *html.Node, base *url.URL) (canonical, amp string) {
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "link" {
+			for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+				switch {
+				case rel == "canonical" && canonical == "":
+					canonical = absoluteURL(base, attr(n, "href"))
+				case rel == "amphtml" && amp == "":
+					amp = absoluteURL(base, attr(n, "href"))
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return canonical, amp
+}
+
+// absoluteURL resolves an http(s) link against base, returning "" for
+// empty links and other schemes
+func absoluteURL(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
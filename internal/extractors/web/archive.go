@@ -0,0 +1,256 @@
+package extractors
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"maai.solutions/gengo/internal/httpclient"
+)
+
+// cssURLPattern matches url(...) references inside stylesheets
+var cssURLPattern = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+
+// ArchiveHTML fetches a page and returns a self-contained HTML snapshot with
+// stylesheets and images inlined
+func ArchiveHTML(url string) ([]byte, error) {
+	return ArchiveHTMLContext(context.Background(), url, Options{})
+}
+
+// ArchiveHTMLContext is like ArchiveHTML but honours ctx and the size options.
+// The page and all inlined assets together stay within MaxBytes; assets that
+// do not fit are left as absolute links instead of being inlined. Scripts are
+// removed so the snapshot renders the same offline. With StripMetadata,
+// inlined JPEG and PNG images lose their EXIF and other metadata.
+func ArchiveHTMLContext(ctx context.Context, pageURL string, opts Options) ([]byte, error) {
+	page, err := FetchPageContext(ctx, pageURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	if page.IsPDF() {
+		return nil, fmt.Errorf("cannot archive a PDF document as HTML")
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(page.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %v", err)
+	}
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	a := &archiver{ctx: ctx, budget: maxBytes - int64(len(page.Body)), stripMetadata: opts.StripMetadata}
+	a.inline(doc, base)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// archiver inlines assets into a parsed document while tracking the byte budget
+type archiver struct {
+	ctx           context.Context
+	budget        int64 // bytes still available for inlined assets
+	stripMetadata bool  // remove metadata from inlined images
+}
+
+// inline walks the document, replacing stylesheet links and image sources
+// with embedded content and dropping scripts
+func (a *archiver) inline(n *html.Node, base *url.URL) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode {
+			switch c.Data {
+			case "script", "noscript":
+				n.RemoveChild(c)
+				c = next
+				continue
+			case "link":
+				if strings.EqualFold(attr(c, "rel"), "stylesheet") {
+					if style := a.stylesheet(c, base); style != nil {
+						n.InsertBefore(style, c)
+						n.RemoveChild(c)
+						c = next
+						continue
+					}
+				}
+				a.absolutize(c, "href", base)
+			case "img":
+				a.inlineAttr(c, "src", base)
+				removeAttr(c, "srcset")
+			case "style":
+				if c.FirstChild != nil && c.FirstChild.Type == html.TextNode {
+					c.FirstChild.Data = a.inlineCSS(c.FirstChild.Data, base)
+				}
+			case "a":
+				a.absolutize(c, "href", base)
+			}
+			if style := attr(c, "style"); style != "" {
+				setAttr(c, "style", a.inlineCSS(style, base))
+			}
+		}
+		a.inline(c, base)
+		c = next
+	}
+}
+
+// stylesheet fetches a linked stylesheet and returns it as a <style> element
+func (a *archiver) stylesheet(link *html.Node, base *url.URL) *html.Node {
+	ref, err := base.Parse(attr(link, "href"))
+	if err != nil {
+		return nil
+	}
+	data, _, ok := a.fetch(ref.String())
+	if !ok {
+		return nil
+	}
+
+	style := &html.Node{Type: html.ElementNode, Data: "style"}
+	style.AppendChild(&html.Node{Type: html.TextNode, Data: a.inlineCSS(string(data), ref)})
+	return style
+}
+
+// inlineCSS replaces url(...) references in a stylesheet with data URIs,
+// resolving them against the stylesheet's own location
+func (a *archiver) inlineCSS(css string, base *url.URL) string {
+	return cssURLPattern.ReplaceAllStringFunc(css, func(match string) string {
+		ref := cssURLPattern.FindStringSubmatch(match)[1]
+		if strings.HasPrefix(ref, "data:") {
+			return match
+		}
+		if uri, ok := a.dataURI(ref, base); ok {
+			return fmt.Sprintf("url(%q)", uri)
+		}
+		return match
+	})
+}
+
+// inlineAttr replaces a URL attribute with a data URI, or makes it absolute
+// when the asset cannot be inlined
+func (a *archiver) inlineAttr(n *html.Node, key string, base *url.URL) {
+	ref := attr(n, key)
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return
+	}
+	if uri, ok := a.dataURI(ref, base); ok {
+		setAttr(n, key, uri)
+		return
+	}
+	a.absolutize(n, key, base)
+}
+
+// absolutize rewrites a relative URL attribute against the page URL
+func (a *archiver) absolutize(n *html.Node, key string, base *url.URL) {
+	ref := attr(n, key)
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "data:") {
+		return
+	}
+	if abs, err := base.Parse(ref); err == nil {
+		setAttr(n, key, abs.String())
+	}
+}
+
+// dataURI fetches an asset and encodes it as a data URI
+func (a *archiver) dataURI(ref string, base *url.URL) (string, bool) {
+	abs, err := base.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+	data, contentType, ok := a.fetch(abs.String())
+	if !ok {
+		return "", false
+	}
+	if a.stripMetadata {
+		data = StripImageMetadata(data)
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(abs.Path))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), true
+}
+
+// fetch downloads an asset if it fits in the remaining budget. Base64
+// encoding grows data by a third, which is counted against the budget.
+func (a *archiver) fetch(assetURL string) ([]byte, string, bool) {
+	if a.budget <= 0 {
+		return nil, "", false
+	}
+
+	req, err := http.NewRequestWithContext(a.ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, "", false
+	}
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		return nil, "", false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, a.budget+1))
+	if err != nil {
+		return nil, "", false
+	}
+	size := int64(base64.StdEncoding.EncodedLen(len(data)))
+	if size > a.budget {
+		return nil, "", false
+	}
+	a.budget -= size
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return data, contentType, true
+}
+
+// attr returns the value of an element attribute
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// setAttr sets an element attribute, adding it if missing
+func setAttr(n *html.Node, key, val string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+// removeAttr deletes an element attribute
+func removeAttr(n *html.Node, key string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+			return
+		}
+	}
+}
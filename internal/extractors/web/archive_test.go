@@ -0,0 +1,80 @@
+package extractors
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// archivePage links a stylesheet, a script and an image
+const archivePage = `<html><head><title>Archive Me</title>
+<link rel="stylesheet" href="/css/site.css">
+<script src="/app.js"></script>
+</head><body><h1>Hello</h1><img src="img/logo.png" srcset="img/logo@2x.png 2x"><a href="/other">Other</a></body></html>`
+
+func newArchiveServer(t *testing.T) *httptest.Server {
+	png := []byte("\x89PNG\r\n\x1a\nfake-image")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(archivePage))
+	})
+	mux.HandleFunc("/css/site.css", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		w.Write([]byte(`body { background: url('../img/logo.png'); }`))
+	})
+	mux.HandleFunc("/img/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(png)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestArchiveHTML(t *testing.T) {
+	server := newArchiveServer(t)
+
+	data, err := ArchiveHTML(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("ArchiveHTML failed: %v", err)
+	}
+	archive := string(data)
+
+	imageURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\nfake-image"))
+	for _, want := range []string{
+		"<title>Archive Me</title>",
+		`<style>body { background: url("` + imageURI + `"); }</style>`,
+		`<img src="` + imageURI + `"/>`,
+		`<a href="` + server.URL + `/other">`,
+	} {
+		if !strings.Contains(archive, want) {
+			t.Errorf("Expected archive to contain %q, got:\n%s", want, archive)
+		}
+	}
+	for _, unwanted := range []string{"<script", "<link", "srcset"} {
+		if strings.Contains(archive, unwanted) {
+			t.Errorf("Expected archive not to contain %q, got:\n%s", unwanted, archive)
+		}
+	}
+}
+
+func TestArchiveHTMLMaxBytes(t *testing.T) {
+	server := newArchiveServer(t)
+
+	// Room for the page but not its assets
+	data, err := ArchiveHTMLContext(t.Context(), server.URL+"/page", Options{MaxBytes: int64(len(archivePage)) + 8})
+	if err != nil {
+		t.Fatalf("ArchiveHTMLContext failed: %v", err)
+	}
+	archive := string(data)
+
+	if strings.Contains(archive, "data:") {
+		t.Errorf("Expected no assets to be inlined over budget, got:\n%s", archive)
+	}
+	if !strings.Contains(archive, `<img src="`+server.URL+`/img/logo.png"/>`) {
+		t.Errorf("Expected image to fall back to an absolute URL, got:\n%s", archive)
+	}
+}
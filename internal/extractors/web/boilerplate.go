@@ -0,0 +1,106 @@
+package extractors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultStripPhrases are the patterns of boilerplate lines removed from
+// extracted content when Options.StripPhrases is nil: newsletter calls to
+// action, cookie banners and share buttons that sit in ordinary paragraphs
+// and so get past the skipped tags. They match case-insensitively.
+var DefaultStripPhrases = []string{
+	`\b(subscribe|sign up) (to|for) (our|the|my) (free |weekly |daily )?newsletter\b`,
+	`\b(get|receive) (our|the) (latest )?(news|stories|articles|updates) (in|delivered to|straight to) your inbox\b`,
+	`\bshare (this|on|via)\b.*\b(facebook|twitter|x|linkedin|reddit|whatsapp|email|pinterest)\b`,
+	`^share( this( article| story| post| page)?)?:?$`,
+	`\bfollow us on\b`,
+	`\b(we|this (web)?site) uses? cookies\b`,
+	`\baccept (all )?cookies\b`,
+	`\bcookie (policy|settings|preferences)\b`,
+	`^advertisement$`,
+	`^(click|tap) here to subscribe\b`,
+}
+
+// boilerplateMaxWords is the longest line, in words, removed for matching a
+// strip phrase. Longer lines are prose that happens to mention the phrase.
+const boilerplateMaxWords = 25
+
+// defaultStripPatterns are DefaultStripPhrases compiled once
+var defaultStripPatterns = mustCompileStripPhrases(DefaultStripPhrases)
+
+// CompileStripPhrases compiles strip phrase patterns for case-insensitive
+// matching. Empty patterns are dropped, so a list of only empty patterns
+// strips nothing.
+func CompileStripPhrases(phrases []string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, phrase := range phrases {
+		if strings.TrimSpace(phrase) == "" {
+			continue
+		}
+		pattern, err := regexp.Compile("(?i)" + phrase)
+		if err != nil {
+			return nil, fmt.Errorf("invalid strip phrase %q: %w", phrase, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// mustCompileStripPhrases is CompileStripPhrases for the built-in list
+func mustCompileStripPhrases(phrases []string) []*regexp.Regexp {
+	patterns, err := CompileStripPhrases(phrases)
+	if err != nil {
+		panic(err)
+	}
+	return patterns
+}
+
+// stripPhrases returns the strip phrases in effect for the options
+func stripPhrases(opts Options) []string {
+	if opts.StripPhrases == nil {
+		return DefaultStripPhrases
+	}
+	return opts.StripPhrases
+}
+
+// stripPatterns compiles the strip phrases in effect for the options
+func stripPatterns(opts Options) ([]*regexp.Regexp, error) {
+	if opts.StripPhrases == nil {
+		return defaultStripPatterns, nil
+	}
+	return CompileStripPhrases(opts.StripPhrases)
+}
+
+// stripBoilerplate removes the short lines of the content that match one of
+// the patterns. Lines are matched without their heading, quote and emphasis
+// markers, so the patterns can anchor on the text.
+func stripBoilerplate(content string, patterns []*regexp.Regexp) string {
+	if len(patterns) == 0 {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !isBoilerplate(line, patterns) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// isBoilerplate reports whether a content line is short and matches one of
+// the patterns
+func isBoilerplate(line string, patterns []*regexp.Regexp) bool {
+	text := strings.Trim(line, "#>*_ \t")
+	if text == "" || len(strings.Fields(text)) > boilerplateMaxWords {
+		return false
+	}
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+package extractors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"maai.solutions/gengo/internal/output"
+)
+
+// DefaultCacheTTL is how long cached extractions are used without checking
+// the page again
+const DefaultCacheTTL = 24 * time.Hour
+
+// MaxSnapshots is how many earlier versions of a page the cache keeps for
+// diffing; older ones are removed
+const MaxSnapshots = 20
+
+// Cache stores extracted pages on disk so repeated extractions of the same
+// URL skip the download. Stale entries are revalidated with conditional
+// requests when the server sent an ETag or Last-Modified header. When a page
+// changes, the replaced entry is kept as a snapshot so versions can be diffed.
+type Cache struct {
+	Dir string        // directory holding one JSON file per cached page
+	TTL time.Duration // age after which an entry is revalidated
+}
+
+// CacheEntry is a cached extraction
+type CacheEntry struct {
+	URL          string          `json:"url"`
+	Title        string          `json:"title"`
+	Content      string          `json:"content"`
+	Structured   *StructuredData `json:"structured,omitempty"`
+	Canonical    string          `json:"canonical,omitempty"`
+	AMP          string          `json:"amp,omitempty"` // AMP version the content was extracted from
+	Coverage     Coverage        `json:"coverage,omitzero"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	FetchedAt    time.Time       `json:"fetched_at"`
+}
+
+// Cached is the outcome of an extraction through the cache
+type Cached struct {
+	Title      string
+	Content    string
+	Structured *StructuredData // JSON-LD article metadata, nil when the page has none
+	Canonical  string          // canonical URL the page declares
+	AMP        string          // AMP version the content was extracted from, "" for the page itself
+	Coverage   Coverage        // how much of the visible text was extracted, zero when unknown
+	Truncated  bool            // the page was cut off at MaxBytes; such pages are not cached
+	Hit        bool            // served from the cache without downloading the page
+
+	// Raw is the body the content was extracted from and RawType its media
+	// type. The cache does not keep bodies, so both are empty for a Hit.
+	Raw     []byte
+	RawType string
+}
+
+// RawExt returns the file extension of the raw body, ".pdf" for PDF
+// documents and ".html" for anything else
+func (c *Cached) RawExt() string {
+	if isPDFContentType(c.RawType) {
+		return ".pdf"
+	}
+	return ".html"
+}
+
+// DefaultCacheDir returns the per-user cache directory for web extractions,
+// ~/.cache/gengo/web on Linux
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "gengo", "web")
+}
+
+// NewCache returns a cache in DefaultCacheDir with the given TTL
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{Dir: DefaultCacheDir(), TTL: ttl}
+}
+
+// Extract extracts a page, using the cached copy while it is fresh and
+// revalidating it once stale. With refresh set the page is always downloaded
+// and the cache entry replaced.
+func (c *Cache) Extract(ctx context.Context, url string, opts Options, refresh bool) (*Cached, error) {
+	key := c.key(url, opts)
+
+	var entry *CacheEntry
+	if !refresh {
+		entry, _ = c.load(key)
+	}
+	if entry != nil && time.Since(entry.FetchedAt) < c.TTL {
+		return entry.cached(), nil
+	}
+
+	var header http.Header
+	if entry != nil {
+		header = conditionalHeader(entry)
+	}
+
+	page, err := fetchPage(ctx, url, opts, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.NotModified {
+		entry.FetchedAt = time.Now()
+		if page.ETag != "" {
+			entry.ETag = page.ETag
+		}
+		if page.LastModified != "" {
+			entry.LastModified = page.LastModified
+		}
+		c.save(key, entry)
+		return entry.cached(), nil
+	}
+
+	source := FollowAMP(ctx, page, opts)
+	title, content, meta, err := ExtractPageData(source, opts)
+	if err != nil {
+		return nil, err
+	}
+	cached := &Cached{Title: title, Content: content, Structured: meta.Structured, Canonical: meta.Canonical, Coverage: meta.Coverage, Truncated: source.Truncated, Raw: source.Body, RawType: source.ContentType}
+	if source != page {
+		cached.AMP = source.URL
+	}
+
+	if !cached.Truncated {
+		c.archive(key, content)
+		c.save(key, &CacheEntry{
+			URL:          url,
+			Title:        title,
+			Content:      content,
+			Structured:   cached.Structured,
+			Canonical:    cached.Canonical,
+			AMP:          cached.AMP,
+			Coverage:     cached.Coverage,
+			ETag:         page.ETag,
+			LastModified: page.LastModified,
+			FetchedAt:    time.Now(),
+		})
+	}
+	return cached, nil
+}
+
+// cached returns a cache hit for the entry
+func (e *CacheEntry) cached() *Cached {
+	return &Cached{Title: e.Title, Content: e.Content, Structured: e.Structured, Canonical: e.Canonical, AMP: e.AMP, Coverage: e.Coverage, Hit: true}
+}
+
+// Snapshots returns the stored versions of a page, oldest first, ending with
+// the current cache entry. It returns nil when the page was never cached.
+func (c *Cache) Snapshots(url string, opts Options) ([]CacheEntry, error) {
+	key := c.key(url, opts)
+
+	var snapshots []CacheEntry
+	paths, err := filepath.Glob(filepath.Join(c.snapshotDir(key), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if entry, err := readEntry(path); err == nil {
+			snapshots = append(snapshots, *entry)
+		}
+	}
+	if entry, err := c.load(key); err == nil {
+		snapshots = append(snapshots, *entry)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].FetchedAt.Before(snapshots[j].FetchedAt)
+	})
+	return snapshots, nil
+}
+
+// conditionalHeader builds the validators for revalidating an entry, or nil
+// when the server gave none
+func conditionalHeader(entry *CacheEntry) http.Header {
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+
+	header := http.Header{}
+	if entry.ETag != "" {
+		header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		header.Set("If-Modified-Since", entry.LastModified)
+	}
+	return header
+}
+
+// key names the cache file for a URL. Extraction options are part of the key
+// since they change the extracted content.
+func (c *Cache) key(url string, opts Options) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%t\n%t\n%s\n%t\n%t\n%q\n%d\n%t", url, strings.Join(opts.SkipTags, ","), strings.Join(opts.ContentTags, ","), opts.AllowStatus, opts.AltTextOnly, opts.Links, opts.ArticleBody, opts.PreferAMP, stripPhrases(opts), opts.MaxDepth, opts.Math)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// snapshotDir is the directory holding earlier versions of a cached page
+func (c *Cache) snapshotDir(key string) string {
+	return filepath.Join(c.Dir, key+".d")
+}
+
+// load reads a cache entry, treating unreadable entries as missing
+func (c *Cache) load(key string) (*CacheEntry, error) {
+	return readEntry(filepath.Join(c.Dir, key+".json"))
+}
+
+// readEntry reads a cache entry or snapshot file
+func readEntry(path string) (*CacheEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// archive keeps the current entry as a snapshot when content replaces it
+// with something different, dropping the oldest snapshots beyond
+// MaxSnapshots. Like save, failures are ignored.
+func (c *Cache) archive(key, content string) {
+	entry, err := c.load(key)
+	if err != nil || entry.Content == content {
+		return
+	}
+
+	dir := c.snapshotDir(key)
+	if err := os.MkdirAll(dir, output.DirMode); err != nil {
+		return
+	}
+	name := entry.FetchedAt.UTC().Format("20060102T150405.000000000Z")
+	writeEntry(dir, name, entry)
+
+	// Names sort chronologically, so the oldest come first
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for len(paths) > MaxSnapshots {
+		os.Remove(paths[0])
+		paths = paths[1:]
+	}
+}
+
+// save writes a cache entry. Failures are ignored since the cache is only an
+// optimization and the extraction itself succeeded.
+func (c *Cache) save(key string, entry *CacheEntry) {
+	if err := os.MkdirAll(c.Dir, output.DirMode); err != nil {
+		return
+	}
+	writeEntry(c.Dir, key, entry)
+}
+
+// writeEntry atomically writes entry to dir/name.json
+func writeEntry(dir, name string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && os.Chmod(tmp.Name(), output.FileMode) == nil {
+		os.Rename(tmp.Name(), filepath.Join(dir, name+".json"))
+	}
+}
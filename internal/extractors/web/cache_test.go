@@ -0,0 +1,132 @@
+package extractors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cacheServer serves a page with an ETag, answering matching conditional
+// requests with 304 and counting full downloads
+func cacheServer(t *testing.T, body string) (*httptest.Server, *int, *int) {
+	t.Helper()
+	full, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &full, &notModified
+}
+
+func TestCacheExtract(t *testing.T) {
+	server, full, notModified := cacheServer(t, "<html><head><title>Cached</title></head><body><p>Hello cache</p></body></html>")
+	cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	ctx := context.Background()
+
+	first, err := cache.Extract(ctx, server.URL, Options{}, false)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if first.Hit || first.Title != "Cached" || !strings.Contains(first.Content, "Hello cache") {
+		t.Errorf("first extraction = %+v", first)
+	}
+
+	second, err := cache.Extract(ctx, server.URL, Options{}, false)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if !second.Hit || second.Content != first.Content {
+		t.Errorf("second extraction = %+v, want a cache hit", second)
+	}
+	if *full != 1 || *notModified != 0 {
+		t.Errorf("fresh entry made requests: %d full, %d conditional", *full, *notModified)
+	}
+
+	if _, err := cache.Extract(ctx, server.URL, Options{}, true); err != nil {
+		t.Fatalf("Extract with refresh failed: %v", err)
+	}
+	if *full != 2 {
+		t.Errorf("refresh made %d full downloads, want 2", *full)
+	}
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	server, full, notModified := cacheServer(t, "<html><head><title>Stale</title></head><body><p>Still current</p></body></html>")
+	cache := &Cache{Dir: t.TempDir(), TTL: time.Nanosecond}
+	ctx := context.Background()
+
+	if _, err := cache.Extract(ctx, server.URL, Options{}, false); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	got, err := cache.Extract(ctx, server.URL, Options{}, false)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if !got.Hit || got.Title != "Stale" || !strings.Contains(got.Content, "Still current") {
+		t.Errorf("revalidated extraction = %+v", got)
+	}
+	if *full != 1 || *notModified != 1 {
+		t.Errorf("got %d full and %d conditional requests, want 1 and 1", *full, *notModified)
+	}
+}
+
+func TestCacheKeyIncludesOptions(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	a := cache.key("https://example.com", Options{})
+	b := cache.key("https://example.com", Options{SkipTags: []string{"nav"}})
+	if a == b {
+		t.Error("cache key should depend on extraction options")
+	}
+}
+
+func TestCacheSnapshots(t *testing.T) {
+	version := "one"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body><p>Version " + version + "</p></body></html>"))
+	}))
+	defer server.Close()
+
+	cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	ctx := context.Background()
+
+	snapshots, err := cache.Snapshots(server.URL, Options{})
+	if err != nil || len(snapshots) != 0 {
+		t.Fatalf("Snapshots of an uncached page = %v, %v", snapshots, err)
+	}
+
+	// Unchanged content is not stored twice
+	for _, v := range []string{"one", "one", "two"} {
+		version = v
+		if _, err := cache.Extract(ctx, server.URL, Options{}, true); err != nil {
+			t.Fatalf("Extract failed: %v", err)
+		}
+	}
+
+	snapshots, err = cache.Snapshots(server.URL, Options{})
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(snapshots))
+	}
+	if !strings.Contains(snapshots[0].Content, "Version one") || !strings.Contains(snapshots[1].Content, "Version two") {
+		t.Errorf("snapshots = %q, %q", snapshots[0].Content, snapshots[1].Content)
+	}
+	if snapshots[1].FetchedAt.Before(snapshots[0].FetchedAt) {
+		t.Error("snapshots are not in chronological order")
+	}
+}
@@ -0,0 +1,107 @@
+package extractors
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// Coverage compares how much of a page's visible text the extraction kept,
+// to tell whether the content heuristic found the main content. Characters
+// are counted without whitespace, so layout and markdown line breaks do not
+// skew the comparison.
+type Coverage struct {
+	Extracted int `json:"extracted_chars"` // text characters in the extracted content
+	Visible   int `json:"visible_chars"`   // text characters in the page body
+}
+
+// invisibleTags hold text that is never shown on the page
+var invisibleTags = map[string]bool{"script": true, "style": true, "noscript": true, "template": true, "svg": true}
+
+// lowCoverage is the share of visible text below which the extraction
+// probably missed the main content
+const lowCoverage = 0.25
+
+// Ratio returns the share of the visible text that was extracted, 0 when
+// the page has no visible text
+func (c Coverage) Ratio() float64 {
+	if c.Visible == 0 {
+		return 0
+	}
+	return float64(c.Extracted) / float64(c.Visible)
+}
+
+// Low reports whether so little of the page was extracted that the main
+// content was probably missed
+func (c Coverage) Low() bool {
+	return c.Visible > 0 && c.Ratio() < lowCoverage
+}
+
+// String summarizes the coverage, e.g.
+// "extracted 4,200 of 9,800 visible characters (43%)"
+func (c Coverage) String() string {
+	return fmt.Sprintf("extracted %s of %s visible characters (%.0f%%)", thousands(c.Extracted), thousands(c.Visible), c.Ratio()*100)
+}
+
+// scaled returns the extracted count reduced in proportion when content
+// of before characters is cut down to after, such as by removing
+// boilerplate. The content carries markdown markup the count leaves out, so
+// the share removed is used rather than the characters.
+func (c Coverage) scaled(after, before int) int {
+	if before == 0 || after >= before {
+		return c.Extracted
+	}
+	return int(math.Round(float64(c.Extracted) * float64(after) / float64(before)))
+}
+
+// visibleChars counts the text characters in the body of a document,
+// leaving out scripts, styles and other text that is never displayed
+func visibleChars(doc *html.Node) int {
+	count := 0
+	var walk func(n *html.Node, inBody bool)
+	walk = func(n *html.Node, inBody bool) {
+		switch n.Type {
+		case html.ElementNode:
+			if invisibleTags[n.Data] {
+				return
+			}
+			inBody = inBody || n.Data == "body"
+		case html.TextNode:
+			if inBody {
+				count += textChars(normalizeText(n.Data))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inBody)
+		}
+	}
+	walk(doc, false)
+	return count
+}
+
+// textChars counts the characters of text other than whitespace
+func textChars(text string) int {
+	count := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// thousands formats n with comma digit grouping
+func thousands(n int) string {
+	s := fmt.Sprint(n)
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteRune(',')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package extractors
+
+import "testing"
+
+func TestExtractCoverage(t *testing.T) {
+	page := `<html><head><title>Tides</title><style>p { color: red }</style></head><body>
+<nav>Home About Contact</nav>
+<article><h1>Tide pools</h1><p>Tide pools form on <b>rocky</b> shores.</p></article>
+<script>var tracking = "ignored";</script>
+<div>Subscribe to our newsletter</div>
+</body></html>`
+
+	_, _, meta, err := extractHTML(page, "", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tidepools and Tidepoolsformonrockyshores. are extracted; the nav and the
+	// newsletter div are visible but not content; title, style and script are not shown
+	expected := Coverage{Extracted: 36, Visible: 36 + 16 + 24}
+	if meta.Coverage != expected {
+		t.Errorf("Coverage = %+v, expected %+v", meta.Coverage, expected)
+	}
+	if got := meta.Coverage.String(); got != "extracted 36 of 76 visible characters (47%)" {
+		t.Errorf("String() = %q", got)
+	}
+	if meta.Coverage.Low() {
+		t.Error("Expected half of the page not to count as low coverage")
+	}
+
+	// Removed boilerplate lines do not count as extracted
+	_, _, meta, err = extractHTML(page, "", Options{StripPhrases: []string{"^Tide pools form"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Coverage.Extracted != 9 {
+		t.Errorf("Expected only the heading to count after stripping, got %+v", meta.Coverage)
+	}
+}
+
+func TestCoverageLow(t *testing.T) {
+	if !(Coverage{Extracted: 200, Visible: 9800}).Low() {
+		t.Error("Expected 2% coverage to be low")
+	}
+	if (Coverage{}).Low() {
+		t.Error("Expected a page without visible text not to be low")
+	}
+	if got := (Coverage{Extracted: 4200, Visible: 1234567}).String(); got != "extracted 4,200 of 1,234,567 visible characters (0%)" {
+		t.Errorf("String() = %q", got)
+	}
+}
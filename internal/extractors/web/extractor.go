@@ -0,0 +1,39 @@
+package extractors
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"maai.solutions/gengo/internal/output"
+)
+
+// PageExtractor extracts web pages for the extractor registry. It accepts
+// any http(s) URL, so it is registered after extractors for specific sites.
+type PageExtractor struct {
+	Options Options
+}
+
+// Name identifies the web extractor in the extractor registry
+func (e *PageExtractor) Name() string {
+	return "web"
+}
+
+// CanHandle reports whether source is an absolute http(s) URL with a host
+func (e *PageExtractor) CanHandle(source string) bool {
+	u, err := url.Parse(strings.TrimSpace(source))
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return (scheme == "http" || scheme == "https") && u.Hostname() != ""
+}
+
+// Extract downloads the page and extracts its main content
+func (e *PageExtractor) Extract(ctx context.Context, source string) (output.Result, error) {
+	title, content, err := DownloadContentContext(ctx, source, e.Options)
+	if err != nil {
+		return output.Result{}, err
+	}
+	return output.NewResult(output.SourceWeb, source, title, content), nil
+}
@@ -0,0 +1,295 @@
+package extractors
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	pdfextractors "maai.solutions/gengo/internal/extractors/pdf"
+	"maai.solutions/gengo/internal/httpclient"
+)
+
+// DefaultMaxBytes is the largest response body read when Options.MaxBytes is unset
+const DefaultMaxBytes int64 = 10 << 20 // 10MB
+
+// ErrContentTooLarge is returned when a response exceeds the configured size limit
+var ErrContentTooLarge = errors.New("content too large")
+
+// Page is a downloaded web page
+type Page struct {
+	URL          string
+	ContentType  string
+	Body         []byte
+	Truncated    bool   // body was cut off at the size limit
+	ETag         string // validators for conditional requests, when the server sent them
+	LastModified string
+	NotModified  bool // a conditional request found the cached copy current; Body is empty
+}
+
+// FetchPage downloads a web page, enforcing the size limit and rejecting
+// responses that are neither HTML nor PDF
+func FetchPage(url string, opts Options) (*Page, error) {
+	return FetchPageContext(context.Background(), url, opts)
+}
+
+// FetchPageContext is like FetchPage but aborts the request when ctx is done
+func FetchPageContext(ctx context.Context, url string, opts Options) (*Page, error) {
+	return fetchPage(ctx, url, opts, nil)
+}
+
+// fetchPage downloads a page, sending any extra request headers. A 304 Not
+// Modified answer to a conditional request returns a page marked NotModified.
+func fetchPage(ctx context.Context, url string, opts Options, header http.Header) (*Page, error) {
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %v", err)
+	}
+	// Setting Accept-Encoding ourselves disables the transport's transparent
+	// gzip handling, so decodeBody handles every encoding in one place
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && len(header) > 0 {
+		return &Page{URL: url, NotModified: true, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}, nil
+	}
+
+	if (resp.StatusCode < 200 || resp.StatusCode > 299) && !opts.AllowStatus {
+		// The status is the error; the body only adds a hint, so one that
+		// cannot be decoded is left out rather than reported instead
+		var snippet io.Reader = strings.NewReader("")
+		if decoded, err := decodeBody(resp); err == nil {
+			defer decoded.Close()
+			snippet = decoded
+		}
+		return nil, statusError(resp.Status, snippet)
+	}
+
+	decoded, err := decodeBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer decoded.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if !isHTMLContentType(contentType) && !isPDFContentType(contentType) {
+		return nil, fmt.Errorf("unsupported content type %q: only HTML pages and PDF documents can be extracted", contentType)
+	}
+
+	if resp.ContentLength > maxBytes && !opts.Truncate {
+		return nil, fmt.Errorf("%w: response is %d bytes, limit is %d bytes", ErrContentTooLarge, resp.ContentLength, maxBytes)
+	}
+
+	page := &Page{
+		URL:          url,
+		ContentType:  contentType,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if err := page.readBody(decoded, maxBytes, opts.Truncate); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// ReadPage reads a page from r instead of downloading it, e.g. HTML piped
+// into the command, enforcing the same size limit as FetchPage. PDF documents
+// are recognized by their content; anything else is treated as HTML. url,
+// which may be empty, resolves relative links.
+func ReadPage(r io.Reader, url string, opts Options) (*Page, error) {
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	page := &Page{URL: url}
+	if err := page.readBody(r, maxBytes, opts.Truncate); err != nil {
+		return nil, err
+	}
+	if isPDFContentType(http.DetectContentType(page.Body)) {
+		page.ContentType = "application/pdf"
+	}
+	return page, nil
+}
+
+// readBody reads the page body from r, failing or truncating it when it is
+// longer than maxBytes
+func (p *Page) readBody(r io.Reader, maxBytes int64, truncate bool) error {
+	// Read one byte past the limit so oversized bodies can be detected
+	body, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	p.Body = body
+	if int64(len(body)) > maxBytes {
+		if !truncate {
+			return fmt.Errorf("%w: response exceeds limit of %d bytes", ErrContentTooLarge, maxBytes)
+		}
+		p.Body = body[:maxBytes]
+		p.Truncated = true
+	}
+	return nil
+}
+
+// decodeBody wraps the response body in a decompressor matching its Content-Encoding
+func decodeBody(resp *http.Response) (io.ReadCloser, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+		return io.NopCloser(resp.Body), nil
+
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip response: %v", err)
+		}
+		return reader, nil
+
+	case "deflate":
+		// "deflate" is specified as zlib-wrapped, but some servers send raw
+		// deflate data, so peek at the header to pick the right reader
+		buffered := bufio.NewReader(resp.Body)
+		header, err := buffered.Peek(2)
+		if err == nil && isZlibHeader(header) {
+			reader, err := zlib.NewReader(buffered)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decompress deflate response: %v", err)
+			}
+			return reader, nil
+		}
+		return flate.NewReader(buffered), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// isZlibHeader reports whether two bytes form a valid zlib stream header
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// statusError describes a non-2xx response, including the start of its body
+func statusError(status string, body io.Reader) error {
+	snippet, _ := io.ReadAll(io.LimitReader(body, 512))
+	text := normalizeText(strings.ToValidUTF8(string(snippet), ""))
+	if len(text) > 200 {
+		// Cut at the start of a rune, not inside one
+		cut := 200
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "..."
+	}
+
+	if text == "" {
+		return fmt.Errorf("unexpected HTTP status %s", status)
+	}
+	return fmt.Errorf("unexpected HTTP status %s: %s", status, text)
+}
+
+// IsPDF reports whether the page is a PDF document rather than HTML
+func (p *Page) IsPDF() bool {
+	return isPDFContentType(p.ContentType)
+}
+
+// ExtractPage extracts the raw title and body text from a fetched page,
+// routing PDF documents to the PDF extractor
+func ExtractPage(page *Page, opts Options) (string, string, error) {
+	title, content, _, err := ExtractPageData(page, opts)
+	return title, content, err
+}
+
+// ExtractPageData is like ExtractPage but also returns what an HTML page
+// declares about itself, which is empty for PDF documents
+func ExtractPageData(page *Page, opts Options) (string, string, PageMeta, error) {
+	if !page.IsPDF() {
+		return extractHTML(string(page.Body), page.URL, opts)
+	}
+
+	text, err := pdfText(page.Body)
+	if err != nil {
+		return "", "", PageMeta{}, fmt.Errorf("failed to extract PDF: %v", err)
+	}
+	return pdfTitle(page.URL), text, PageMeta{}, nil
+}
+
+// pdfText extracts the text of a downloaded PDF document. The PDF extractor
+// reads from a file, so the body is written to a temporary one.
+func pdfText(body []byte) (string, error) {
+	tmp, err := os.CreateTemp("", "gengo-web-*.pdf")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return pdfextractors.NewTextExtractor().ExtractLayerText(tmp.Name(), nil)
+}
+
+// pdfTitle derives a title for a PDF document from the last segment of its URL
+func pdfTitle(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if name := path.Base(u.Path); name != "/" && name != "." {
+			return strings.TrimSuffix(name, path.Ext(name))
+		}
+	}
+	return ""
+}
+
+// isPDFContentType reports whether a Content-Type header describes a PDF document
+func isPDFContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/pdf"
+}
+
+// isHTMLContentType reports whether a Content-Type header describes an HTML
+// document. A missing header is accepted since many servers omit it.
+func isHTMLContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return true
+	default:
+		return false
+	}
+}
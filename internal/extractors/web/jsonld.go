@@ -0,0 +1,140 @@
+package extractors
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// articleTypes are the schema.org types whose JSON-LD describes the main
+// content of a page
+var articleTypes = map[string]bool{
+	"Article":          true,
+	"NewsArticle":      true,
+	"BlogPosting":      true,
+	"TechArticle":      true,
+	"ScholarlyArticle": true,
+	"Report":           true,
+	"Review":           true,
+	"HowTo":            true,
+	"Recipe":           true,
+}
+
+// StructuredData is the article metadata a page embeds as JSON-LD in
+// <script type="application/ld+json"> blocks
+type StructuredData struct {
+	Type          string   `json:"type"`
+	Headline      string   `json:"headline,omitempty"`
+	Authors       []string `json:"authors,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	ArticleBody   string   `json:"article_body,omitempty"`
+}
+
+// isJSONLD reports whether a script type attribute marks JSON-LD
+func isJSONLD(scriptType string) bool {
+	mediaType, _, _ := strings.Cut(scriptType, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), "application/ld+json")
+}
+
+// parseJSONLD reads the JSON-LD blocks of a page and returns the first
+// article node, or nil when there is none. Blocks may hold a single node, an
+// array of nodes or an @graph of them; invalid blocks are ignored.
+func parseJSONLD(blocks []string) *StructuredData {
+	var nodes []map[string]interface{}
+	for _, block := range blocks {
+		var v interface{}
+		if err := json.Unmarshal([]byte(block), &v); err != nil {
+			continue
+		}
+		nodes = appendNodes(nodes, v)
+	}
+
+	for _, node := range nodes {
+		if nodeType := articleType(node["@type"]); nodeType != "" {
+			return structuredData(nodeType, node)
+		}
+	}
+	return nil
+}
+
+// appendNodes collects the objects of a JSON-LD value, descending into
+// arrays and @graph
+func appendNodes(nodes []map[string]interface{}, v interface{}) []map[string]interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, item := range v {
+			nodes = appendNodes(nodes, item)
+		}
+	case map[string]interface{}:
+		nodes = append(nodes, v)
+		if graph, ok := v["@graph"]; ok {
+			nodes = appendNodes(nodes, graph)
+		}
+	}
+	return nodes
+}
+
+// articleType returns the article type a node's @type names, which may be a
+// string or a list, or "" for other types
+func articleType(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		if articleTypes[v] {
+			return v
+		}
+	case []interface{}:
+		for _, item := range v {
+			if t := articleType(item); t != "" {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// structuredData reads the fields of an article node
+func structuredData(nodeType string, node map[string]interface{}) *StructuredData {
+	data := &StructuredData{
+		Type:          nodeType,
+		Headline:      normalizeText(stringField(node, "headline")),
+		Authors:       names(node["author"]),
+		DatePublished: stringField(node, "datePublished"),
+		DateModified:  stringField(node, "dateModified"),
+		Description:   normalizeText(stringField(node, "description")),
+		ArticleBody:   strings.TrimSpace(stringField(node, "articleBody")),
+	}
+	if data.Headline == "" {
+		data.Headline = normalizeText(stringField(node, "name"))
+	}
+	return data
+}
+
+// stringField returns a string property of a node, or "" when it is missing
+// or not a string
+func stringField(node map[string]interface{}, key string) string {
+	s, _ := node[key].(string)
+	return strings.TrimSpace(s)
+}
+
+// names reads an author property given as a name, a Person or Organization
+// node, or a list of either
+func names(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		if name := normalizeText(v); name != "" {
+			return []string{name}
+		}
+	case map[string]interface{}:
+		if name := normalizeText(stringField(v, "name")); name != "" {
+			return []string{name}
+		}
+	case []interface{}:
+		var all []string
+		for _, item := range v {
+			all = append(all, names(item)...)
+		}
+		return all
+	}
+	return nil
+}
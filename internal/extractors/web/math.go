@@ -0,0 +1,306 @@
+package extractors
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// texAnnotations are the annotation encodings that hold the LaTeX source a
+// MathML expression was rendered from, as written by MathJax, KaTeX and
+// MediaWiki
+var texAnnotations = map[string]bool{"application/x-tex": true, "tex": true, "latex": true, "text/x-latex": true}
+
+// mathSymbols spells the operators and letters MathML holds as Unicode
+// characters as LaTeX commands
+var mathSymbols = map[rune]string{
+	'α': `\alpha`, 'β': `\beta`, 'γ': `\gamma`, 'δ': `\delta`, 'ε': `\epsilon`, 'ζ': `\zeta`,
+	'η': `\eta`, 'θ': `\theta`, 'ι': `\iota`, 'κ': `\kappa`, 'λ': `\lambda`, 'μ': `\mu`,
+	'ν': `\nu`, 'ξ': `\xi`, 'π': `\pi`, 'ρ': `\rho`, 'σ': `\sigma`, 'τ': `\tau`,
+	'υ': `\upsilon`, 'φ': `\phi`, 'χ': `\chi`, 'ψ': `\psi`, 'ω': `\omega`,
+	'Γ': `\Gamma`, 'Δ': `\Delta`, 'Θ': `\Theta`, 'Λ': `\Lambda`, 'Ξ': `\Xi`, 'Π': `\Pi`,
+	'Σ': `\Sigma`, 'Φ': `\Phi`, 'Ψ': `\Psi`, 'Ω': `\Omega`,
+	'×': `\times`, '·': `\cdot`, '⋅': `\cdot`, '÷': `\div`, '±': `\pm`, '∓': `\mp`, '−': `-`,
+	'≤': `\leq`, '≥': `\geq`, '≠': `\neq`, '≈': `\approx`, '≡': `\equiv`, '∼': `\sim`, '∝': `\propto`,
+	'→': `\to`, '←': `\leftarrow`, '⇒': `\Rightarrow`, '⇔': `\Leftrightarrow`, '↦': `\mapsto`,
+	'∞': `\infty`, '∂': `\partial`, '∇': `\nabla`, '∑': `\sum`, '∏': `\prod`, '∫': `\int`, '∮': `\oint`,
+	'∈': `\in`, '∉': `\notin`, '⊂': `\subset`, '⊆': `\subseteq`, '∪': `\cup`, '∩': `\cap`, '∅': `\emptyset`,
+	'∀': `\forall`, '∃': `\exists`, '¬': `\neg`, '∧': `\wedge`, '∨': `\vee`,
+	'…': `\ldots`, '⋯': `\cdots`, '′': `'`, '⁡': ``, '⁢': ``, '⁣': ``,
+}
+
+// mathFunctions are the multi-letter identifiers LaTeX sets as operators
+var mathFunctions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+	"sinh": true, "cosh": true, "tanh": true, "arcsin": true, "arccos": true, "arctan": true,
+	"log": true, "ln": true, "exp": true, "lim": true, "max": true, "min": true, "sup": true,
+	"inf": true, "det": true, "gcd": true, "deg": true, "dim": true, "arg": true,
+}
+
+// mathAccents maps the marks an accented <mover> places over its base to
+// LaTeX accent commands
+var mathAccents = map[string]string{
+	"¯": `\overline`, "‾": `\overline`, "^": `\hat`, "ˆ": `\hat`, "~": `\tilde`, "˜": `\tilde`,
+	"→": `\vec`, "⃗": `\vec`, "˙": `\dot`, "¨": `\ddot`,
+}
+
+// handleMath writes a <math> element in the content as LaTeX, $...$ inline
+// or $$...$$ on a line of its own for display math. Display math often sits
+// between paragraphs, so any math inside a content element is kept.
+func (ce *ContentExtractor) handleMath(n *html.Node) {
+	if ce.isInAnySkipTag() || !(ce.inBody || ce.rootDepth >= 0 || ce.figureDepth > 0 || len(ce.captionAt) > 0) {
+		return
+	}
+	tex := MathMLToLaTeX(n)
+	if tex == "" {
+		return
+	}
+	if attr(n, "display") == "block" {
+		ce.Content = append(ce.Content, "\n$$"+tex+"$$\n")
+		return
+	}
+	ce.Content = append(ce.Content, "$"+tex+"$ ")
+}
+
+// MathMLToLaTeX converts a MathML <math> element to LaTeX. The LaTeX source
+// the page annotated the expression with is used when there is one, so the
+// result matches what the author wrote; otherwise the presentation markup
+// is converted.
+func MathMLToLaTeX(n *html.Node) string {
+	if tex := texAnnotation(n); tex != "" {
+		return tex
+	}
+	if tex := unwrapDisplayStyle(attr(n, "alttext")); tex != "" {
+		return tex
+	}
+	return strings.TrimSpace(mathChildren(n))
+}
+
+// texAnnotation returns the LaTeX source annotating a MathML expression, or ""
+func texAnnotation(n *html.Node) string {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.Data == "annotation" && texAnnotations[strings.ToLower(attr(c, "encoding"))] {
+			return unwrapDisplayStyle(nodeText(c))
+		}
+		if tex := texAnnotation(c); tex != "" {
+			return tex
+		}
+	}
+	return ""
+}
+
+// mathNode converts a MathML element and its children to LaTeX
+func mathNode(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return ""
+	}
+	args := mathArgs(n)
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+
+	switch n.Data {
+	case "mi":
+		name := strings.TrimSpace(nodeText(n))
+		if mathFunctions[name] {
+			return `\` + name
+		}
+		return mathToken(name)
+	case "mn", "mo":
+		return mathToken(strings.TrimSpace(nodeText(n)))
+	case "mtext", "ms":
+		if text := normalizeText(nodeText(n)); text != "" {
+			return `\text{` + text + `}`
+		}
+		return ""
+	case "mspace":
+		return `\ `
+	case "mfrac":
+		return `\frac{` + arg(0) + `}{` + arg(1) + `}`
+	case "msqrt":
+		return `\sqrt{` + mathChildren(n) + `}`
+	case "mroot":
+		return `\sqrt[` + arg(1) + `]{` + arg(0) + `}`
+	case "msup":
+		return mathGroup(arg(0)) + `^` + mathGroup(arg(1))
+	case "msub":
+		return mathGroup(arg(0)) + `_` + mathGroup(arg(1))
+	case "msubsup", "munderover":
+		return mathGroup(arg(0)) + `_` + mathGroup(arg(1)) + `^` + mathGroup(arg(2))
+	case "munder":
+		return mathGroup(arg(0)) + `_` + mathGroup(arg(1))
+	case "mover":
+		if accent, ok := mathAccents[strings.TrimSpace(lastElementText(n))]; ok {
+			return accent + `{` + arg(0) + `}`
+		}
+		return mathGroup(arg(0)) + `^` + mathGroup(arg(1))
+	case "mfenced":
+		open, close := "(", ")"
+		if v, ok := attrValue(n, "open"); ok {
+			open = v
+		}
+		if v, ok := attrValue(n, "close"); ok {
+			close = v
+		}
+		return mathToken(open) + strings.Join(args, ",") + mathToken(close)
+	case "mtable":
+		return `\begin{matrix}` + strings.Join(args, ` \\ `) + `\end{matrix}`
+	case "mtr", "mlabeledtr":
+		return strings.Join(args, " & ")
+	case "annotation", "annotation-xml", "mphantom":
+		return ""
+	case "semantics":
+		// The first child is the presentation markup, the rest annotate it
+		return arg(0)
+	default:
+		// mrow, mstyle, mpadded, mtd and anything unknown group their children
+		return mathChildren(n)
+	}
+}
+
+// mathArgs converts the element children of a MathML element, which are
+// the arguments of layout elements such as <mfrac>
+func mathArgs(n *html.Node) []string {
+	var args []string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			args = append(args, mathNode(c))
+		}
+	}
+	return args
+}
+
+// commandEnd matches a LaTeX command at the end of converted math, which
+// needs a space before a following letter
+var commandEnd = regexp.MustCompile(`\\[a-zA-Z]+$`)
+
+// mathChildren converts and joins the element children of a MathML element
+func mathChildren(n *html.Node) string {
+	var b strings.Builder
+	for _, part := range mathArgs(n) {
+		if part == "" {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(part); unicode.IsLetter(r) && commandEnd.MatchString(b.String()) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// mathToken spells the Unicode symbols of a token element as LaTeX
+func mathToken(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if cmd, ok := mathSymbols[r]; ok {
+			if strings.HasPrefix(cmd, `\`) && commandEnd.MatchString(b.String()) {
+				b.WriteByte(' ')
+			}
+			b.WriteString(cmd)
+			continue
+		}
+		if unicode.IsLetter(r) && commandEnd.MatchString(b.String()) {
+			b.WriteByte(' ')
+		}
+		switch r {
+		case '{', '}', '%', '#', '&':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// mathGroup braces a sub- or superscript operand unless it is a single
+// character or command
+func mathGroup(s string) string {
+	if utf8.RuneCountInString(s) == 1 || (commandEnd.MatchString(s) && strings.LastIndex(s, `\`) == 0) {
+		return s
+	}
+	return "{" + s + "}"
+}
+
+// lastElementText returns the text of the last element child, the accent
+// of a <mover>
+func lastElementText(n *html.Node) string {
+	for c := n.LastChild; c != nil; c = c.PrevSibling {
+		if c.Type == html.ElementNode {
+			return nodeText(c)
+		}
+	}
+	return ""
+}
+
+// attrValue returns an attribute and whether the element has it, telling an
+// empty value apart from a missing one
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// nodeText returns the text inside an element
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// displayStyle matches the {\displaystyle ...} wrapper MediaWiki puts around
+// the LaTeX of its formulas
+var displayStyle = regexp.MustCompile(`^\{\s*\\displaystyle\s+(.*)\}$`)
+
+// unwrapDisplayStyle trims LaTeX and removes the MediaWiki display style
+// wrapper and any $ delimiters around it
+func unwrapDisplayStyle(tex string) string {
+	tex = strings.TrimSpace(tex)
+	if m := displayStyle.FindStringSubmatch(tex); m != nil {
+		tex = strings.TrimSpace(m[1])
+	}
+	for _, delim := range []string{"$$", "$"} {
+		if len(tex) > 2*len(delim) && strings.HasPrefix(tex, delim) && strings.HasSuffix(tex, delim) {
+			tex = strings.TrimSpace(tex[len(delim) : len(tex)-len(delim)])
+			break
+		}
+	}
+	return tex
+}
+
+// latexAlt matches image alt text written in LaTeX: a command such as \frac
+// or a braced sub- or superscript
+var latexAlt = regexp.MustCompile(`\\[a-zA-Z]+|[_^]\{`)
+
+// isEquationImage reports whether an image shows an equation whose alt text
+// holds its LaTeX source, like the formula images of MediaWiki and blogs
+// rendering LaTeX server-side
+func isEquationImage(n *html.Node, alt string) bool {
+	class := strings.ToLower(attr(n, "class"))
+	for _, hint := range []string{"math", "tex", "latex", "equation"} {
+		if strings.Contains(class, hint) {
+			return true
+		}
+	}
+	return latexAlt.MatchString(alt)
+}
@@ -0,0 +1,52 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// findElement returns the first element named tag in a parsed document
+func findElement(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestMathMLToLaTeX(t *testing.T) {
+	tests := []struct {
+		name string
+		math string
+		want string
+	}{
+		{"greek and operators", `<math><mi>α</mi><mo>≤</mo><mi>β</mi><mo>×</mo><mn>2</mn></math>`, `\alpha\leq\beta\times2`},
+		{"function names", `<math><mi>sin</mi><mi>x</mi></math>`, `\sin x`},
+		{"command before letter", `<math><mi>π</mi><mi>r</mi></math>`, `\pi r`},
+		{"subscript group", `<math><msub><mi>x</mi><mrow><mi>i</mi><mi>j</mi></mrow></msub></math>`, `x_{ij}`},
+		{"root", `<math><mroot><mi>x</mi><mn>3</mn></mroot></math>`, `\sqrt[3]{x}`},
+		{"accent", `<math><mover accent="true"><mi>v</mi><mo>→</mo></mover></math>`, `\vec{v}`},
+		{"fenced", `<math><mfenced><mi>a</mi><mi>b</mi></mfenced></math>`, `(a,b)`},
+		{"matrix", `<math><mtable><mtr><mtd><mn>1</mn></mtd><mtd><mn>0</mn></mtd></mtr><mtr><mtd><mn>0</mn></mtd><mtd><mn>1</mn></mtd></mtr></mtable></math>`, `\begin{matrix}1 & 0 \\ 0 & 1\end{matrix}`},
+		{"text", `<math><mtext>if</mtext><mi>x</mi></math>`, `\text{if}x`},
+		{"alttext", `<math alttext="{\displaystyle a^2}"><mi>ignored</mi></math>`, `a^2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader("<p>" + tt.math + "</p>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := MathMLToLaTeX(findElement(doc, "math")); got != tt.want {
+				t.Errorf("MathMLToLaTeX() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package extractors
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// jpegMetadataMarkers are the JPEG segments that carry metadata rather than
+// image data: APP1 (EXIF, including GPS location, and XMP), APP13 (IPTC)
+// and comments
+var jpegMetadataMarkers = map[byte]bool{0xE1: true, 0xED: true, 0xFE: true}
+
+// pngMetadataChunks are the PNG chunks that carry metadata rather than image data
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// StripImageMetadata removes EXIF, XMP, IPTC, text and timestamp metadata
+// from a JPEG or PNG image without re-encoding it, so the pixels are left
+// exactly as they were. Since the EXIF orientation goes too, photos that
+// relied on it may show rotated. Other formats, and images that cannot be
+// parsed, are returned unchanged.
+func StripImageMetadata(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		if stripped, ok := stripJPEG(data); ok {
+			return stripped
+		}
+	case bytes.HasPrefix(data, pngSignature):
+		if stripped, ok := stripPNG(data); ok {
+			return stripped
+		}
+	}
+	return data
+}
+
+// stripJPEG copies the segments of a JPEG up to the image data, leaving out
+// metadata segments. It reports false for a malformed file.
+func stripJPEG(data []byte) ([]byte, bool) {
+	out := append([]byte(nil), data[:2]...)
+	i := 2
+	for i < len(data) {
+		if data[i] != 0xFF {
+			return nil, false
+		}
+		// Markers may be padded with any number of fill bytes
+		start := i
+		for i < len(data) && data[i] == 0xFF {
+			i++
+		}
+		if i >= len(data) {
+			return nil, false
+		}
+		marker := data[i]
+		i++
+
+		switch {
+		case marker == 0xD9 || marker == 0xDA:
+			// End of image, or the start of the compressed image data that
+			// runs to the end; nothing after it is metadata
+			return append(out, data[start:]...), true
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// Markers without a length
+			out = append(out, data[start:i]...)
+			continue
+		}
+
+		if i+2 > len(data) {
+			return nil, false
+		}
+		end := i + int(binary.BigEndian.Uint16(data[i:]))
+		if end < i+2 || end > len(data) {
+			return nil, false
+		}
+		if !jpegMetadataMarkers[marker] {
+			out = append(out, data[start:end]...)
+		}
+		i = end
+	}
+	return out, true
+}
+
+// stripPNG copies the chunks of a PNG, leaving out metadata chunks. It
+// reports false for a malformed file.
+func stripPNG(data []byte) ([]byte, bool) {
+	out := append([]byte(nil), pngSignature...)
+	i := len(pngSignature)
+	for i < len(data) {
+		// Length, type, data and CRC
+		if i+8 > len(data) {
+			return nil, false
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, false
+		}
+		chunkType := string(data[i+4 : i+8])
+		if !pngMetadataChunks[chunkType] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,85 @@
+package extractors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// jpegSegment builds a JPEG marker segment with its length
+func jpegSegment(marker byte, payload string) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// pngChunk builds a PNG chunk with a zero CRC, which stripping does not check
+func pngChunk(chunkType, data string) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return append(chunk, 0, 0, 0, 0)
+}
+
+func TestStripImageMetadataJPEG(t *testing.T) {
+	jfif := jpegSegment(0xE0, "JFIF\x00\x01\x01")
+	exif := jpegSegment(0xE1, "Exif\x00\x00GPS 52.37N 4.89E")
+	comment := jpegSegment(0xFE, "shot by Alice")
+	quant := jpegSegment(0xDB, "\x00quant")
+	scan := append(jpegSegment(0xDA, "\x01scan"), "\x12\x34\xFF\x00\x56\xFF\xD9"...)
+
+	var image []byte
+	for _, part := range [][]byte{{0xFF, 0xD8}, jfif, exif, comment, quant, scan} {
+		image = append(image, part...)
+	}
+
+	var want []byte
+	for _, part := range [][]byte{{0xFF, 0xD8}, jfif, quant, scan} {
+		want = append(want, part...)
+	}
+
+	got := StripImageMetadata(image)
+	if !bytes.Equal(got, want) {
+		t.Errorf("StripImageMetadata() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestStripImageMetadataPNG(t *testing.T) {
+	ihdr := pngChunk("IHDR", "\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+	idat := pngChunk("IDAT", "pixels")
+	iend := pngChunk("IEND", "")
+
+	var image []byte
+	for _, part := range [][]byte{pngSignature, ihdr, pngChunk("tEXt", "Author\x00Alice"), pngChunk("eXIf", "MM\x00*"), idat, pngChunk("tIME", "\x07\xe8\x05\x01\x0c\x00\x00"), iend} {
+		image = append(image, part...)
+	}
+
+	var want []byte
+	for _, part := range [][]byte{pngSignature, ihdr, idat, iend} {
+		want = append(want, part...)
+	}
+
+	got := StripImageMetadata(image)
+	if !bytes.Equal(got, want) {
+		t.Errorf("StripImageMetadata() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestStripImageMetadataUnchanged(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"gif", []byte("GIF89a\x01\x00\x01\x00")},
+		{"truncated jpeg", append([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x40}, "Exif"...)},
+		{"truncated png", append(append([]byte(nil), pngSignature...), 0, 0, 1, 0, 'I')},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripImageMetadata(tt.data); !bytes.Equal(got, tt.data) {
+				t.Errorf("StripImageMetadata() = %q, want the input unchanged", got)
+			}
+		})
+	}
+}
@@ -1,54 +1,177 @@
 package extractors
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/net/html"
+	"maai.solutions/gengo/internal/output"
+	"maai.solutions/gengo/internal/project"
 )
 
+// DefaultSkipTags are the elements whose text is never extracted
+var DefaultSkipTags = []string{"script", "style", "nav", "header", "footer", "aside"}
+
+// DefaultContentTags are the elements whose text is extracted as page content
+var DefaultContentTags = []string{"p", "h1", "h2", "h3", "h4", "h5", "h6", "article", "section", "main", "blockquote"}
+
+// LinkStyle selects how links in the page content are written
+type LinkStyle string
+
+// Link styles accepted by Options.Links
+const (
+	LinksNone       LinkStyle = ""           // keep only the link text
+	LinksInline     LinkStyle = "inline"     // write links as [text](url)
+	LinksReferences LinkStyle = "references" // mark link text with [n] and list the targets at the end
+)
+
+// Options customizes how pages are fetched and which elements the extractor
+// skips and treats as content. A nil slice keeps the corresponding default set.
+type Options struct {
+	SkipTags    []string
+	ContentTags []string
+	MaxBytes    int64     // maximum response body size (default: DefaultMaxBytes)
+	Truncate    bool      // truncate oversized responses instead of failing
+	AllowStatus bool      // extract non-2xx responses instead of failing
+	BodyOnly    bool      // omit the title and source header from markdown output
+	AltTextOnly bool      // write image descriptions as plain text instead of markdown image links
+	Links       LinkStyle // how links in the content are written (default: text only)
+	ArticleBody bool      // use the JSON-LD articleBody as the content when the page has one
+	PreferAMP   bool      // extract the AMP version a page links instead, see FollowAMP
+	MaxDepth    int       // element levels extracted below the outermost content element (0: no limit)
+	Math        bool      // write MathML and equation images as $...$ or $$...$$ LaTeX
+
+	// StripMetadata removes EXIF, location and other metadata from the
+	// images inlined into an HTML archive, see StripImageMetadata
+	StripMetadata bool
+
+	// StripPhrases are patterns of boilerplate lines removed from the content
+	// (default: DefaultStripPhrases; empty to keep every line)
+	StripPhrases []string
+}
+
 type ContentExtractor struct {
-	Title     string
-	Content   []string
-	inTitle   bool
-	inBody    bool
-	inSkip    map[string]bool
-	currTag   string
-	skipTags  map[string]bool
+	Title       string
+	Content     []string
+	inTitle     bool
+	inBody      bool
+	inSkip      map[string]int // open-element depth per skip tag
+	currTag     string         // immediate parent element of the text being handled
+	tagStack    []string       // open elements from the document root down
+	skipTags    map[string]bool
+	contentTags map[string]bool
+	boldDepth   int
+	italicDepth int
+	quoteStarts []int // indexes into Content where open blockquotes began
+	figureDepth int
+	captionAt   []int    // indexes into Content where open figcaptions began
+	baseURL     *url.URL // page address that relative image sources and links resolve against
+	altTextOnly bool
+	linkStyle   LinkStyle
+	linkStarts  []int          // indexes into Content where open links began
+	linkHrefs   []string       // targets of the open links, "" when they cannot be linked
+	references  []string       // link targets in order of first use, for LinksReferences
+	refNumbers  map[string]int // reference number per link target
+	jsonLD      []string       // contents of the JSON-LD script blocks
+	maxDepth    int            // see Options.MaxDepth
+	rootDepth   int            // tagStack index of the outermost open content element, -1 outside content
+	math        bool           // see Options.Math
+	extracted   int            // text characters added to Content, see Coverage
 }
 
 func NewContentExtractor() *ContentExtractor {
+	return NewContentExtractorWithOptions(Options{})
+}
+
+// NewContentExtractorWithOptions creates an extractor with custom skip and content tag sets
+func NewContentExtractorWithOptions(opts Options) *ContentExtractor {
+	skipTags := opts.SkipTags
+	if skipTags == nil {
+		skipTags = DefaultSkipTags
+	}
+	contentTags := opts.ContentTags
+	if contentTags == nil {
+		contentTags = DefaultContentTags
+	}
+
 	return &ContentExtractor{
-		skipTags: map[string]bool{
-			"script": true,
-			"style":  true,
-			"nav":    true,
-			"header": true,
-			"footer": true,
-			"aside":  true,
-		},
-		inSkip: make(map[string]bool),
+		skipTags:    tagSet(skipTags),
+		contentTags: tagSet(contentTags),
+		inSkip:      make(map[string]int),
+		altTextOnly: opts.AltTextOnly,
+		linkStyle:   opts.Links,
+		refNumbers:  make(map[string]int),
+		maxDepth:    opts.MaxDepth,
+		rootDepth:   -1,
+		math:        opts.Math,
+	}
+}
+
+// tagSet builds a lookup set from a list of tag names
+func tagSet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			set[tag] = true
+		}
 	}
+	return set
 }
 
 func (ce *ContentExtractor) traverse(n *html.Node) {
+	// Leave out elements nested too deep inside the content, with everything in them
+	if n.Type == html.ElementNode && ce.maxDepth > 0 && ce.rootDepth >= 0 && len(ce.tagStack)-ce.rootDepth > ce.maxDepth {
+		return
+	}
+
 	switch n.Type {
 	case html.ElementNode:
+		if n.Data == "math" && ce.math {
+			// The whole expression becomes one piece of LaTeX
+			ce.handleMath(n)
+			return
+		}
+		if ce.contentTags[n.Data] && ce.rootDepth < 0 {
+			ce.rootDepth = len(ce.tagStack)
+		}
+		ce.tagStack = append(ce.tagStack, n.Data)
 		ce.currTag = n.Data
 		if n.Data == "title" {
 			ce.inTitle = true
 		}
 		if ce.skipTags[n.Data] {
-			ce.inSkip[n.Data] = true
+			ce.inSkip[n.Data]++
 		}
-		if isContentTag(n.Data) {
+		if ce.contentTags[n.Data] {
 			ce.inBody = true
 		}
+		switch n.Data {
+		case "strong", "b":
+			ce.boldDepth++
+		case "em", "i":
+			ce.italicDepth++
+		case "blockquote":
+			ce.quoteStarts = append(ce.quoteStarts, len(ce.Content))
+		case "figure":
+			ce.figureDepth++
+		case "figcaption":
+			ce.captionAt = append(ce.captionAt, len(ce.Content))
+		case "img":
+			ce.handleImage(n)
+		case "script":
+			if isJSONLD(attr(n, "type")) && n.FirstChild != nil {
+				ce.jsonLD = append(ce.jsonLD, n.FirstChild.Data)
+			}
+		case "a":
+			ce.linkStarts = append(ce.linkStarts, len(ce.Content))
+			ce.linkHrefs = append(ce.linkHrefs, ce.linkURL(attr(n, "href")))
+		}
 	case html.TextNode:
 		ce.handleData(n.Data)
 	}
@@ -60,55 +183,286 @@ func (ce *ContentExtractor) traverse(n *html.Node) {
 	// Handle end tags
 	switch n.Type {
 	case html.ElementNode:
+		// Restore the parent as the current tag so trailing text is attributed correctly
+		ce.tagStack = ce.tagStack[:len(ce.tagStack)-1]
+		if len(ce.tagStack) == ce.rootDepth {
+			ce.rootDepth = -1
+		}
+		ce.currTag = ""
+		if len(ce.tagStack) > 0 {
+			ce.currTag = ce.tagStack[len(ce.tagStack)-1]
+		}
 		if n.Data == "title" {
 			ce.inTitle = false
 		}
-		if ce.skipTags[n.Data] {
-			ce.inSkip[n.Data] = false
+		if ce.skipTags[n.Data] && ce.inSkip[n.Data] > 0 {
+			ce.inSkip[n.Data]--
 		}
-		if isContentTag(n.Data) {
+		if ce.contentTags[n.Data] {
 			if ce.inBody {
 				ce.Content = append(ce.Content, "\n")
 			}
 			ce.inBody = false
 		}
+		switch n.Data {
+		case "strong", "b":
+			ce.boldDepth--
+		case "em", "i":
+			ce.italicDepth--
+		case "blockquote":
+			ce.closeBlockquote()
+		case "figure":
+			ce.figureDepth--
+		case "figcaption":
+			ce.closeCaption()
+		case "a":
+			ce.closeLink()
+		}
 	}
 }
 
+// handleImage writes the alt text of an image in the content or a figure as
+// a markdown image, or as plain text with AltTextOnly or when the source
+// cannot be linked. Images without alt text are decorative and skipped. With
+// Math, equation images are written as the LaTeX in their alt text.
+func (ce *ContentExtractor) handleImage(n *html.Node) {
+	alt := normalizeText(attr(n, "alt"))
+	if alt == "" || ce.isInAnySkipTag() || !(ce.inBody || ce.figureDepth > 0 || len(ce.captionAt) > 0) {
+		return
+	}
+
+	if ce.math && isEquationImage(n, alt) {
+		tex := unwrapDisplayStyle(alt)
+		if strings.Contains(strings.ToLower(attr(n, "class")), "display") {
+			ce.Content = append(ce.Content, "\n$$"+tex+"$$\n")
+		} else {
+			ce.Content = append(ce.Content, "$"+tex+"$ ")
+		}
+		return
+	}
+
+	src := ce.resolveURL(attr(n, "src"))
+	text := alt
+	if !ce.altTextOnly && src != "" {
+		text = fmt.Sprintf("![%s](%s)", escapeAlt(alt), src)
+	}
+
+	// Figures hold the image on a line of its own, above the caption
+	if ce.figureDepth > 0 && len(ce.captionAt) == 0 {
+		ce.Content = append(ce.Content, "\n"+text+"\n")
+		return
+	}
+	ce.Content = append(ce.Content, text+" ")
+}
+
+// resolveURL resolves an image source or link target against the page
+// address, returning "" for inline data and references that cannot be linked
+func (ce *ContentExtractor) resolveURL(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if ce.baseURL != nil {
+		u = ce.baseURL.ResolveReference(u)
+	}
+
+	return urlEscaper.Replace(u.String())
+}
+
+// urlEscaper encodes parentheses and spaces, which would end a markdown link
+// early. Like the other replacers and patterns here it is built once and is
+// safe to share between extractors running concurrently.
+var urlEscaper = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20")
+
+// linkURL returns the target of a link, or "" for links within the page and
+// scripts, which lead nowhere once the content is extracted
+func (ce *ContentExtractor) linkURL(href string) string {
+	href = strings.TrimSpace(href)
+	if ce.linkStyle == LinksNone || href == "" || strings.HasPrefix(href, "#") {
+		return ""
+	}
+	target := ce.resolveURL(href)
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return target
+	}
+	return ""
+}
+
+// closeLink rewrites the text collected since the matching <a> opened as an
+// inline link or marks it with its reference number. Links spanning several
+// blocks, such as a heading, are left as plain text.
+func (ce *ContentExtractor) closeLink() {
+	start := ce.linkStarts[len(ce.linkStarts)-1]
+	href := ce.linkHrefs[len(ce.linkHrefs)-1]
+	ce.linkStarts = ce.linkStarts[:len(ce.linkStarts)-1]
+	ce.linkHrefs = ce.linkHrefs[:len(ce.linkHrefs)-1]
+
+	text := strings.TrimSpace(strings.Join(ce.Content[start:], ""))
+	if href == "" || text == "" || strings.Contains(text, "\n") {
+		return
+	}
+
+	switch ce.linkStyle {
+	case LinksInline:
+		text = fmt.Sprintf("[%s](%s)", escapeAlt(text), href)
+	case LinksReferences:
+		n, ok := ce.refNumbers[href]
+		if !ok {
+			ce.references = append(ce.references, href)
+			n = len(ce.references)
+			ce.refNumbers[href] = n
+		}
+		text = fmt.Sprintf("%s[%d]", text, n)
+	}
+	ce.Content = append(ce.Content[:start], text+" ")
+}
+
+// referenceList renders the numbered targets of LinksReferences, or "" when
+// the content has no links
+func (ce *ContentExtractor) referenceList() string {
+	if len(ce.references) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n## References\n\n")
+	for i, ref := range ce.references {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, ref)
+	}
+	return b.String()
+}
+
+// escapeAlt escapes brackets that would end the alt text of a markdown image
+// or the text of a link
+func escapeAlt(alt string) string {
+	return bracketEscaper.Replace(alt)
+}
+
+var bracketEscaper = strings.NewReplacer("[", "\\[", "]", "\\]")
+
+// spaceBeforePunct matches a space left before punctuation where an inline
+// element ended
+var spaceBeforePunct = regexp.MustCompile(`\s+([.,;:!?])`)
+
+// closeCaption rewrites the content collected since the matching
+// <figcaption> opened as an italic caption line
+func (ce *ContentExtractor) closeCaption() {
+	start := ce.captionAt[len(ce.captionAt)-1]
+	ce.captionAt = ce.captionAt[:len(ce.captionAt)-1]
+
+	caption := strings.Join(strings.Fields(strings.Join(ce.Content[start:], "")), " ")
+	ce.Content = ce.Content[:start]
+	if caption == "" {
+		return
+	}
+
+	// Emphasis inside the caption is dropped so the markers do not nest, and
+	// the spaces joining text pieces are removed before punctuation
+	caption = strings.ReplaceAll(caption, "*", "")
+	caption = spaceBeforePunct.ReplaceAllString(caption, "$1")
+	ce.Content = append(ce.Content, "\n*"+caption+"*\n\n")
+}
+
+// closeBlockquote rewrites the content collected since the matching
+// <blockquote> opened as "> " prefixed markdown lines
+func (ce *ContentExtractor) closeBlockquote() {
+	start := ce.quoteStarts[len(ce.quoteStarts)-1]
+	ce.quoteStarts = ce.quoteStarts[:len(ce.quoteStarts)-1]
+
+	quoted := strings.TrimSpace(strings.Join(ce.Content[start:], ""))
+	ce.Content = ce.Content[:start]
+	if quoted == "" {
+		return
+	}
+
+	var lines []string
+	for _, line := range strings.Split(quoted, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(lines) > 0 && lines[len(lines)-1] != ">" {
+				lines = append(lines, ">")
+			}
+			continue
+		}
+		lines = append(lines, "> "+line)
+	}
+
+	ce.Content = append(ce.Content, "\n"+strings.Join(lines, "\n")+"\n\n")
+}
+
 func (ce *ContentExtractor) handleData(data string) {
-	cleaned := strings.TrimSpace(data)
+	cleaned := normalizeText(data)
 	if cleaned == "" {
 		return
 	}
 
 	if ce.inTitle {
 		ce.Title += cleaned
-	} else if ce.inBody && !ce.isInAnySkipTag() {
-		if isHeaderTag(ce.currTag) {
-			level := ce.currTag[1:] // h1, h2, etc.
-			ce.Content = append(ce.Content, fmt.Sprintf("\n%s %s\n", strings.Repeat("#", int(level[0]-'0')), cleaned))
+	} else if (ce.inBody || len(ce.captionAt) > 0) && !ce.isInAnySkipTag() {
+		ce.extracted += textChars(cleaned)
+		if header := ce.headerTag(); header != "" {
+			level := int(header[1] - '0') // h1, h2, etc.
+			ce.Content = append(ce.Content, fmt.Sprintf("\n%s %s\n", strings.Repeat("#", level), cleaned))
 		} else {
-			ce.Content = append(ce.Content, cleaned+" ")
+			ce.Content = append(ce.Content, ce.emphasize(cleaned)+" ")
 		}
 	}
 }
 
+// headerTag returns the h1-h6 element the current text belongs to, looking
+// through inline elements such as <em> or <a> inside the heading
+func (ce *ContentExtractor) headerTag() string {
+	if isHeaderTag(ce.currTag) {
+		return ce.currTag
+	}
+	for i := len(ce.tagStack) - 1; i >= 0; i-- {
+		tag := ce.tagStack[i]
+		if isHeaderTag(tag) {
+			return tag
+		}
+		if ce.contentTags[tag] {
+			break
+		}
+	}
+	return ""
+}
+
+// emphasize wraps text in markdown bold/italic markers for the enclosing
+// <strong>/<b> and <em>/<i> elements
+func (ce *ContentExtractor) emphasize(text string) string {
+	if ce.italicDepth > 0 {
+		text = "*" + text + "*"
+	}
+	if ce.boldDepth > 0 {
+		text = "**" + text + "**"
+	}
+	return text
+}
+
 func (ce *ContentExtractor) isInAnySkipTag() bool {
-	for _, in := range ce.inSkip {
-		if in {
+	for _, depth := range ce.inSkip {
+		if depth > 0 {
 			return true
 		}
 	}
 	return false
 }
 
-func isContentTag(tag string) bool {
-	switch tag {
-	case "p", "h1", "h2", "h3", "h4", "h5", "h6", "article", "section", "main":
-		return true
-	default:
-		return false
-	}
+// normalizeText decodes any HTML entities left in the text (for example from
+// double-encoded markup), turns non-breaking spaces into regular spaces and
+// collapses runs of whitespace
+func normalizeText(text string) string {
+	text = html.UnescapeString(text)
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+	return strings.Join(strings.Fields(text), " ")
 }
 
 func isHeaderTag(tag string) bool {
@@ -116,68 +470,194 @@ func isHeaderTag(tag string) bool {
 }
 
 func sanitizeFilename(name string) string {
-	re := regexp.MustCompile(`[<>:"/\\|?*]`)
-	return strings.TrimSpace(re.ReplaceAllString(name, "-"))
+	return output.SanitizeFilename(name)
+}
+
+// ExtractContent parses an HTML string and returns the raw page title and the
+// extracted body text, without the markdown header block
+func ExtractContent(htmlContent string) (string, string, error) {
+	return ExtractContentWithOptions(htmlContent, Options{})
+}
+
+// ExtractContentWithOptions is like ExtractContent but uses custom tag sets
+func ExtractContentWithOptions(htmlContent string, opts Options) (string, string, error) {
+	return extractContent(htmlContent, "", opts)
+}
+
+// extract traverses a parsed document, turning a panic on markup the
+// extractor does not expect into an error so one bad page cannot end a batch
+func (ce *ContentExtractor) extract(doc *html.Node) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to extract HTML content: %v", r)
+		}
+	}()
+	ce.traverse(doc)
+	return nil
+}
+
+// blankLines matches runs of blank lines, collapsed to one in the content
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// extractContent extracts a page, resolving relative image sources and links
+// against pageURL when it is set
+func extractContent(htmlContent, pageURL string, opts Options) (string, string, error) {
+	title, content, _, err := extractHTML(htmlContent, pageURL, opts)
+	return title, content, err
 }
 
-// ExtractFromHTML extracts content from HTML string
-func ExtractFromHTML(htmlContent string, url string) (string, string) {
+// extractHTML is like extractContent but also returns what the page
+// declares about itself: its JSON-LD structured data, its canonical and AMP
+// URLs and how much of its visible text was extracted. The headline of the
+// structured data replaces the page title, which often carries the site
+// name, and with Options.ArticleBody its article body replaces the content
+// taken from the markup. Boilerplate lines matching the strip phrases are
+// removed from the assembled content.
+func extractHTML(htmlContent, pageURL string, opts Options) (string, string, PageMeta, error) {
+	patterns, err := stripPatterns(opts)
+	if err != nil {
+		return "", "", PageMeta{}, err
+	}
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
-		return "", ""
+		return "", "", PageMeta{}, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
-	parser := NewContentExtractor()
-	parser.traverse(doc)
+	parser := NewContentExtractorWithOptions(opts)
+	if pageURL != "" {
+		parser.baseURL, _ = url.Parse(pageURL)
+	}
+	if err := parser.extract(doc); err != nil {
+		return "", "", PageMeta{}, err
+	}
 
+	var meta PageMeta
+	meta.Canonical, meta.AMP = declaredLinks(doc, parser.baseURL)
+	meta.Coverage = Coverage{Extracted: parser.extracted, Visible: visibleChars(doc)}
 	title := parser.Title
+	content := strings.Join(parser.Content, "") + parser.referenceList()
+	data := parseJSONLD(parser.jsonLD)
+	meta.Structured = data
+	if data != nil {
+		if data.Headline != "" {
+			title = data.Headline
+		}
+		if opts.ArticleBody && data.ArticleBody != "" {
+			content = strings.ReplaceAll(data.ArticleBody, "\r\n", "\n") + "\n"
+			meta.Coverage.Extracted = textChars(content)
+		}
+	}
+	stripped := stripBoilerplate(content, patterns)
+	meta.Coverage.Extracted = meta.Coverage.scaled(textChars(stripped), textChars(content))
+	content = stripped
+	content = blankLines.ReplaceAllString(content, "\n\n")
+
+	return title, content, meta, nil
+}
+
+// ExtractFromHTML extracts content from HTML string. It fails when the
+// markup cannot be parsed or traversed, so an error is never mistaken for an
+// empty page.
+func ExtractFromHTML(htmlContent string, url string) (string, string, error) {
+	return ExtractFromHTMLWithOptions(htmlContent, url, Options{})
+}
+
+// ExtractFromHTMLWithOptions is like ExtractFromHTML but uses custom tag sets
+// and, with BodyOnly, returns the content without the markdown header
+func ExtractFromHTMLWithOptions(htmlContent string, url string, opts Options) (string, string, error) {
+	title, content, err := ExtractContentWithOptions(htmlContent, opts)
+	if err != nil {
+		return "", "", err
+	}
+
 	if title == "" {
 		title = "Untitled"
 	}
 	sanitizedTitle := sanitizeFilename(title)
 
-	content := strings.Join(parser.Content, "")
-	content = regexp.MustCompile(`\n{3,}`).ReplaceAllString(content, "\n\n")
+	if opts.BodyOnly {
+		return sanitizedTitle, strings.TrimLeft(content, "\n"), nil
+	}
 
 	markdown := fmt.Sprintf("# %s\n\nSource: %s\n\n---\n\n%s", title, url, content)
 
-	return sanitizedTitle, markdown
+	return sanitizedTitle, markdown, nil
 }
 
 // DownloadAndExtract downloads a webpage and extracts its content
 func DownloadAndExtract(url string) (string, string, error) {
-	resp, err := http.Get(url)
+	page, err := FetchPage(url, Options{})
 	if err != nil {
-		return "", "", fmt.Errorf("failed to fetch URL: %v", err)
+		return "", "", err
 	}
-	defer resp.Body.Close()
 
-	htmlContent, err := io.ReadAll(resp.Body)
+	if page.IsPDF() {
+		title, text, err := ExtractPage(page, Options{})
+		if err != nil {
+			return "", "", err
+		}
+		if title == "" {
+			title = "Untitled"
+		}
+		markdown := fmt.Sprintf("# %s\n\nSource: %s\n\n---\n\n%s", title, url, text)
+		return sanitizeFilename(title), markdown, nil
+	}
+
+	return ExtractFromHTML(string(page.Body), url)
+}
+
+// DownloadContent downloads a webpage and returns its raw title and body
+// text, leaving formatting of the result to the caller
+func DownloadContent(url string) (string, string, error) {
+	return DownloadContentWithOptions(url, Options{})
+}
+
+// DownloadContentWithOptions is like DownloadContent but uses custom tag sets
+func DownloadContentWithOptions(url string, opts Options) (string, string, error) {
+	return DownloadContentContext(context.Background(), url, opts)
+}
+
+// DownloadContentContext is like DownloadContentWithOptions but aborts the
+// download when ctx is done
+func DownloadContentContext(ctx context.Context, url string, opts Options) (string, string, error) {
+	page, err := FetchPageContext(ctx, url, opts)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read response body: %v", err)
+		return "", "", err
 	}
 
-	title, content := ExtractFromHTML(string(htmlContent), url)
-	return title, content, nil
+	return ExtractPage(page, opts)
 }
 
 // SaveToProject saves content to a project folder structure
 func SaveToProject(title, content, projectName string) error {
+	if err := output.CheckOptions(output.OutputOptions{ProjectName: projectName, ProjectRoot: "."}); err != nil {
+		return err
+	}
 	projectDir := filepath.Join(".", projectName)
-	
+
 	// Create project directory if it doesn't exist
-	if err := os.MkdirAll(projectDir, 0755); err != nil {
+	if err := os.MkdirAll(projectDir, output.DirMode); err != nil {
 		return fmt.Errorf("failed to create project directory: %v", err)
 	}
 
 	// Create filename from title
-	filename := fmt.Sprintf("%s.md", title)
+	name := sanitizeFilename(title)
+	if name == "" {
+		name = "Untitled"
+	}
+	filename := fmt.Sprintf("%s.md", name)
 	filepath := filepath.Join(projectDir, filename)
 
 	// Write content to file
-	if err := os.WriteFile(filepath, []byte(content), 0644); err != nil {
+	if err := os.WriteFile(filepath, []byte(content), output.FileMode); err != nil {
 		return fmt.Errorf("failed to write file: %v", err)
 	}
 
-	return nil
+	return project.Record(projectDir, project.Entry{
+		Title:       title,
+		File:        filename,
+		Format:      "markdown",
+		Hash:        project.HashContent(content),
+		ExtractedAt: time.Now(),
+	}, output.FileMode)
 }
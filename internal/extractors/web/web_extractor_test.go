@@ -1,12 +1,23 @@
 package extractors
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"maai.solutions/gengo/internal/output"
 )
 
 func TestNewContentExtractor(t *testing.T) {
@@ -33,35 +44,197 @@ func TestNewContentExtractor(t *testing.T) {
 	}
 }
 
-func TestIsContentTag(t *testing.T) {
+func TestNewContentExtractorWithOptions(t *testing.T) {
+	extractor := NewContentExtractorWithOptions(Options{
+		SkipTags:    []string{"form", " Script "},
+		ContentTags: []string{"blockquote"},
+	})
+
+	if !extractor.skipTags["form"] || !extractor.skipTags["script"] {
+		t.Error("Expected custom skip tags to be set")
+	}
+	if extractor.skipTags["nav"] {
+		t.Error("Expected custom skip tags to replace the defaults")
+	}
+	if !extractor.contentTags["blockquote"] || extractor.contentTags["p"] {
+		t.Error("Expected custom content tags to replace the defaults")
+	}
+
+	// Empty options keep the defaults
+	defaults := NewContentExtractorWithOptions(Options{})
+	for _, tag := range DefaultSkipTags {
+		if !defaults.skipTags[tag] {
+			t.Errorf("Expected default skip tag %s", tag)
+		}
+	}
+}
+
+func TestExtractContentWithCustomSkipTags(t *testing.T) {
+	htmlContent := `<html><head><title>Form Page</title></head><body>
+<p>Keep this paragraph.</p>
+<form><p>Subscribe to our newsletter</p></form>
+<blockquote>Quoted wisdom</blockquote>
+</body></html>`
+
+	// No strip phrases, so only the skip tags can remove the form
+	_, content, err := ExtractContentWithOptions(htmlContent, Options{StripPhrases: []string{}})
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+	if !strings.Contains(content, "Subscribe") {
+		t.Error("Expected form content with default options")
+	}
+
+	opts := Options{
+		SkipTags:     append(append([]string{}, DefaultSkipTags...), "form"),
+		ContentTags:  append(append([]string{}, DefaultContentTags...), "blockquote"),
+		StripPhrases: []string{},
+	}
+	_, content, err = ExtractContentWithOptions(htmlContent, opts)
+	if err != nil {
+		t.Fatalf("ExtractContentWithOptions failed: %v", err)
+	}
+	if strings.Contains(content, "Subscribe") {
+		t.Error("Expected form content to be skipped")
+	}
+	if !strings.Contains(content, "Keep this paragraph.") {
+		t.Error("Expected paragraph content to be kept")
+	}
+	if !strings.Contains(content, "Quoted wisdom") {
+		t.Error("Expected blockquote content to be included")
+	}
+}
+
+func TestExtractContentBlockquotesAndEmphasis(t *testing.T) {
+	htmlContent := `<html><head><title>Quotes</title></head><body>
+<p>This is <strong>very <em>important</em></strong> and <i>subtle</i>.</p>
+<blockquote><p>First quoted line.</p><p>Second <b>quoted</b> line.</p></blockquote>
+<p>After the quote.</p>
+</body></html>`
+
+	_, content, err := ExtractContent(htmlContent)
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+
+	expected := []string{
+		"**very**",
+		"***important***",
+		"*subtle*",
+		"> First quoted line.",
+		"> Second **quoted** line.",
+		"After the quote.",
+	}
+	for _, want := range expected {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected content to contain %q, got:\n%s", want, content)
+		}
+	}
+
+	if strings.Contains(content, "> After the quote.") {
+		t.Error("Expected text after the blockquote not to be quoted")
+	}
+}
+
+func TestExtractContentHeaderAttribution(t *testing.T) {
+	htmlContent := `<html><head><title>Headers</title></head><body>
+<section>
+<h2>Section <em>Heading</em></h2>
+<p>Paragraph after the <a href="#">heading</a> text.</p>
+<h10>Not a heading</h10>
+</section>
+</body></html>`
+
+	opts := Options{ContentTags: append(append([]string{}, DefaultContentTags...), "h10")}
+	_, content, err := ExtractContentWithOptions(htmlContent, opts)
+	if err != nil {
+		t.Fatalf("ExtractContentWithOptions failed: %v", err)
+	}
+
+	if !strings.Contains(content, "## Section") || !strings.Contains(content, "## Heading") {
+		t.Errorf("Expected heading text to be rendered as level 2, got:\n%s", content)
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "#") && (strings.Contains(line, "Paragraph") || strings.Contains(line, "text.")) {
+			t.Errorf("Paragraph following a header rendered as heading: %q", line)
+		}
+		if strings.HasPrefix(line, "#") && strings.Contains(line, "Not a heading") {
+			t.Errorf("h10 element rendered as heading: %q", line)
+		}
+	}
+
+	if !strings.Contains(content, "Not a heading") {
+		t.Error("Expected h10 content to be kept as text")
+	}
+}
+
+func TestExtractContentNestedSkipTags(t *testing.T) {
+	htmlContent := `<html><head><title>Nested</title></head><body>
+<aside>
+  <aside><p>Inner sidebar</p></aside>
+  <p>Outer sidebar continues</p>
+</aside>
+<p>Main content</p>
+</body></html>`
+
+	_, content, err := ExtractContent(htmlContent)
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+
+	if strings.Contains(content, "Inner sidebar") || strings.Contains(content, "Outer sidebar") {
+		t.Errorf("Expected nested aside content to be skipped, got:\n%s", content)
+	}
+	if !strings.Contains(content, "Main content") {
+		t.Error("Expected main content after the aside to be kept")
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
 	tests := []struct {
-		tag      string
-		expected bool
+		input    string
+		expected string
 	}{
-		{"p", true},
-		{"h1", true},
-		{"h2", true},
-		{"h3", true},
-		{"h4", true},
-		{"h5", true},
-		{"h6", true},
-		{"article", true},
-		{"section", true},
-		{"main", true},
-		{"div", false},
-		{"span", false},
-		{"script", false},
-		{"style", false},
+		{"Tom &amp; Jerry", "Tom & Jerry"},
+		{"it&#8217;s", "it\u2019s"},
+		{"non\u00a0breaking&nbsp;space", "non breaking space"},
+		{"  lots \n\t of   space  ", "lots of space"},
+		{"", ""},
 	}
 
 	for _, test := range tests {
-		result := isContentTag(test.tag)
+		result := normalizeText(test.input)
 		if result != test.expected {
-			t.Errorf("isContentTag(%s) = %v, expected %v", test.tag, result, test.expected)
+			t.Errorf("normalizeText(%q) = %q, expected %q", test.input, result, test.expected)
 		}
 	}
 }
 
+func TestExtractContentEntities(t *testing.T) {
+	htmlContent := `<html><head><title>Fish &amp;amp; Chips</title></head><body>
+<p>Fish&nbsp;&amp;&nbsp;chips &amp;amp; peas,   it&#8217;s
+   a &quot;classic&quot;.</p>
+</body></html>`
+
+	title, content, err := ExtractContent(htmlContent)
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+
+	if title != "Fish & Chips" {
+		t.Errorf("Expected decoded title, got %q", title)
+	}
+
+	expected := "Fish & chips & peas, it\u2019s a \"classic\"."
+	if !strings.Contains(content, expected) {
+		t.Errorf("Expected content to contain %q, got %q", expected, content)
+	}
+	if strings.Contains(content, "&amp;") || strings.Contains(content, "\u00a0") {
+		t.Errorf("Expected no raw entities or non-breaking spaces, got %q", content)
+	}
+}
+
 func TestIsHeaderTag(t *testing.T) {
 	tests := []struct {
 		tag      string
@@ -101,6 +274,18 @@ func TestSanitizeFilename(t *testing.T) {
 		{"Asterisk*File", "Asterisk-File"},
 		{"   Spaced   ", "Spaced"},
 		{"", ""},
+		{"Tab\tand\nnewline", "Tab-and-newline"},
+		{"a<>:b", "a-b"},
+		{"Already -- dashed", "Already - dashed"},
+		{".hidden", "hidden"},
+		{"...dots...", "dots"},
+		{"CON", "CON_"},
+		{"nul.txt", "nul.txt_"},
+		{"Console", "Console"},
+		{"COM10", "COM10"},
+		{"Launch 🚀 Day", "Launch Day"},
+		{"👍🏽", ""},
+		{"Café ☕ résumé", "Café résumé"},
 	}
 
 	for _, test := range tests {
@@ -111,6 +296,26 @@ func TestSanitizeFilename(t *testing.T) {
 	}
 }
 
+func TestSanitizeFilenameLength(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	result := sanitizeFilename(long)
+	if len(result) > output.MaxFilenameLength {
+		t.Errorf("Expected at most %d bytes, got %d", output.MaxFilenameLength, len(result))
+	}
+
+	// Titles that only differ past the cut-off must not collide
+	other := sanitizeFilename(long + "different ending")
+	if result == other {
+		t.Errorf("Expected distinct names for distinct long titles, both got %q", result)
+	}
+
+	// Multi-byte runes are never split
+	accented := sanitizeFilename(strings.Repeat("é", 300))
+	if !utf8.ValidString(accented) {
+		t.Errorf("Expected valid UTF-8, got %q", accented)
+	}
+}
+
 func TestExtractFromHTML(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -189,7 +394,10 @@ func TestExtractFromHTML(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			title, content := ExtractFromHTML(test.html, test.url)
+			title, content, err := ExtractFromHTML(test.html, test.url)
+			if err != nil {
+				t.Fatalf("ExtractFromHTML failed: %v", err)
+			}
 
 			if title != test.expectTitle {
 				t.Errorf("Expected title %q, got %q", test.expectTitle, title)
@@ -266,6 +474,248 @@ func TestDownloadAndExtractInvalidURL(t *testing.T) {
 	}
 }
 
+func TestFetchPageMaxBytes(t *testing.T) {
+	body := "<html><body><p>" + strings.Repeat("a", 2048) + "</p></body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	_, err := FetchPage(server.URL, Options{MaxBytes: 1024})
+	if !errors.Is(err, ErrContentTooLarge) {
+		t.Errorf("Expected ErrContentTooLarge, got: %v", err)
+	}
+
+	page, err := FetchPage(server.URL, Options{MaxBytes: 1024, Truncate: true})
+	if err != nil {
+		t.Fatalf("FetchPage with truncation failed: %v", err)
+	}
+	if !page.Truncated || len(page.Body) != 1024 {
+		t.Errorf("Expected truncated 1024 byte body, got truncated=%v len=%d", page.Truncated, len(page.Body))
+	}
+
+	page, err = FetchPage(server.URL, Options{})
+	if err != nil {
+		t.Fatalf("FetchPage with default limit failed: %v", err)
+	}
+	if page.Truncated || string(page.Body) != body {
+		t.Error("Expected full body under the default limit")
+	}
+}
+
+func TestReadPage(t *testing.T) {
+	body := "<html><head><title>Piped</title></head><body><p>" + strings.Repeat("a", 2048) + "</p></body></html>"
+
+	page, err := ReadPage(strings.NewReader(body), "", Options{})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	if page.IsPDF() || string(page.Body) != body {
+		t.Errorf("Expected the HTML body, got pdf=%v len=%d", page.IsPDF(), len(page.Body))
+	}
+	if title, _, err := ExtractPage(page, Options{}); err != nil || title != "Piped" {
+		t.Errorf("Expected title Piped, got %q (%v)", title, err)
+	}
+
+	if _, err := ReadPage(strings.NewReader(body), "", Options{MaxBytes: 1024}); !errors.Is(err, ErrContentTooLarge) {
+		t.Errorf("Expected ErrContentTooLarge, got: %v", err)
+	}
+	page, err = ReadPage(strings.NewReader(body), "", Options{MaxBytes: 1024, Truncate: true})
+	if err != nil || !page.Truncated || len(page.Body) != 1024 {
+		t.Errorf("Expected a truncated 1024 byte body, got %v", err)
+	}
+
+	page, err = ReadPage(strings.NewReader("%PDF-1.4 test document"), "", Options{})
+	if err != nil || !page.IsPDF() {
+		t.Errorf("Expected a PDF document to be recognized, got %+v (%v)", page, err)
+	}
+}
+
+func TestFetchPageRejectsNonHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	}))
+	defer server.Close()
+
+	_, err := FetchPage(server.URL, Options{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported content type") {
+		t.Errorf("Expected unsupported content type error, got: %v", err)
+	}
+}
+
+func TestDownloadContentNonHTML(t *testing.T) {
+	pdf, err := os.ReadFile("../pdf/testdata/text.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"key": "value"}`))
+		case "/docs/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write(pdf)
+		case "/docs/broken.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4 test document"))
+		}
+	}))
+	defer server.Close()
+
+	_, _, err = DownloadContent(server.URL + "/data.json")
+	if err == nil {
+		t.Fatal("Expected error for JSON response")
+	}
+	if !strings.Contains(err.Error(), "application/json") {
+		t.Errorf("Expected error to mention the content type, got: %v", err)
+	}
+
+	title, content, err := DownloadContent(server.URL + "/docs/report.pdf")
+	if err != nil {
+		t.Fatalf("Expected PDF response to be extracted, got: %v", err)
+	}
+	if title != "report" {
+		t.Errorf("Expected title 'report', got %q", title)
+	}
+	if !strings.Contains(content, "Hello World") || !strings.Contains(content, "Call 555-12-3456") {
+		t.Errorf("Expected the text of the PDF, got %q", content)
+	}
+
+	if _, _, err := DownloadContent(server.URL + "/docs/broken.pdf"); err == nil || !strings.Contains(err.Error(), "failed to extract PDF") {
+		t.Errorf("Expected an unreadable PDF to fail, got: %v", err)
+	}
+
+	title, markdown, err := DownloadAndExtract(server.URL + "/docs/report.pdf")
+	if err != nil {
+		t.Fatalf("DownloadAndExtract failed for PDF: %v", err)
+	}
+	if title != "report" || !strings.Contains(markdown, "# report") || !strings.Contains(markdown, "Hello World") {
+		t.Errorf("Expected PDF markdown with title, got title %q and:\n%s", title, markdown)
+	}
+}
+
+func TestDownloadAndExtractStatusCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<html><head><title>Not Found</title></head><body><p>Page not found</p></body></html>"))
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Internal failure"))
+		case "/bad-gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("not gzip"))
+		case "/long":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("x" + strings.Repeat("é", 150)))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status string
+	}{
+		{"/missing", "404"},
+		{"/broken", "500"},
+		{"/bad-gzip", "502"},
+		{"/long", "503"},
+	}
+
+	for _, test := range tests {
+		_, _, err := DownloadAndExtract(server.URL + test.path)
+		if err == nil {
+			t.Errorf("Expected error for %s", test.path)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.status) {
+			t.Errorf("Expected error to include status %s, got: %v", test.status, err)
+		}
+	}
+
+	_, _, err := DownloadAndExtract(server.URL + "/broken")
+	if err == nil || !strings.Contains(err.Error(), "Internal failure") {
+		t.Errorf("Expected error to include a body snippet, got: %v", err)
+	}
+
+	// Long snippets are cut between characters
+	_, _, err = DownloadAndExtract(server.URL + "/long")
+	if err == nil || !utf8.ValidString(err.Error()) || !strings.HasSuffix(err.Error(), "é...") {
+		t.Errorf("Expected a snippet cut at a character boundary, got: %q", err)
+	}
+
+	title, content, err := DownloadContentWithOptions(server.URL+"/missing", Options{AllowStatus: true})
+	if err != nil {
+		t.Fatalf("Expected error page to be extracted with AllowStatus, got: %v", err)
+	}
+	if title != "Not Found" || !strings.Contains(content, "Page not found") {
+		t.Errorf("Unexpected error page extraction: title %q content %q", title, content)
+	}
+}
+
+func TestDownloadAndExtractCompressed(t *testing.T) {
+	page := "<html><head><title>Compressed Page</title></head><body><p>Decompressed content</p></body></html>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		switch r.URL.Path {
+		case "/gzip":
+			zw := gzip.NewWriter(&buf)
+			zw.Write([]byte(page))
+			zw.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+		case "/deflate":
+			zw := zlib.NewWriter(&buf)
+			zw.Write([]byte(page))
+			zw.Close()
+			w.Header().Set("Content-Encoding", "deflate")
+		case "/raw-deflate":
+			fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+			fw.Write([]byte(page))
+			fw.Close()
+			w.Header().Set("Content-Encoding", "deflate")
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/gzip", "/deflate", "/raw-deflate"} {
+		title, content, err := DownloadAndExtract(server.URL + path)
+		if err != nil {
+			t.Errorf("%s: DownloadAndExtract failed: %v", path, err)
+			continue
+		}
+		if title != "Compressed Page" {
+			t.Errorf("%s: expected title 'Compressed Page', got %q", path, title)
+		}
+		if !strings.Contains(content, "Decompressed content") {
+			t.Errorf("%s: expected decompressed content, got:\n%s", path, content)
+		}
+	}
+}
+
+func TestFetchPageContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body><p>Hello</p></body></html>"))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := FetchPageContext(ctx, server.URL, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
 func TestSaveToProject(t *testing.T) {
 	// Create a temporary directory for testing
 	tempDir := t.TempDir()
@@ -307,6 +757,22 @@ func TestSaveToProject(t *testing.T) {
 	}
 }
 
+func TestSaveToProjectUnsafeTitle(t *testing.T) {
+	tempDir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	if err := SaveToProject("../Q&A: what/why? 🚀", "content", "proj"); err != nil {
+		t.Fatalf("SaveToProject failed: %v", err)
+	}
+
+	expectedFile := filepath.Join(tempDir, "proj", "-Q&A- what-why-.md")
+	if _, err := os.Stat(expectedFile); err != nil {
+		t.Errorf("Expected sanitized file %s: %v", expectedFile, err)
+	}
+}
+
 func TestSaveToProjectInvalidPath(t *testing.T) {
 	// Try to save to a path that should fail (using invalid characters in different OS)
 	err := SaveToProject("test", "content", "/invalid\x00path")
@@ -369,14 +835,14 @@ func TestContentExtractorIsInAnySkipTag(t *testing.T) {
 		t.Error("Expected not to be in any skip tag initially")
 	}
 
-	// Set one skip tag to true
-	extractor.inSkip["script"] = true
+	// Enter one skip tag
+	extractor.inSkip["script"] = 1
 	if !extractor.isInAnySkipTag() {
 		t.Error("Expected to be in a skip tag")
 	}
 
-	// Set it back to false
-	extractor.inSkip["script"] = false
+	// Leave it again
+	extractor.inSkip["script"] = 0
 	if extractor.isInAnySkipTag() {
 		t.Error("Expected not to be in any skip tag after resetting")
 	}
@@ -396,7 +862,11 @@ func ExampleExtractFromHTML() {
 </body>
 </html>`
 
-	title, content := ExtractFromHTML(html, "https://example.com")
+	title, content, err := ExtractFromHTML(html, "https://example.com")
+	if err != nil {
+		// handle error
+		_ = err
+	}
 
 	// Print title (sanitized for filename use)
 	_ = title // Example Page
@@ -427,3 +897,379 @@ func ExampleSaveToProject() {
 	}
 	// Creates: ./my-project/My Document.md
 }
+
+func TestExtractFromHTMLMalformed(t *testing.T) {
+	// Unclosed and stray tags, a broken attribute and an unterminated comment
+	page := `<html><head><title>Broken</title><body><p>First <b>bold <i>both</p></b> after</i>
+<div class="x><p>Lost</div></span></table><p>Last paragraph<!-- never closed`
+
+	title, content, err := ExtractFromHTML(page, "https://example.com")
+	if err != nil {
+		t.Fatalf("ExtractFromHTML failed on broken markup: %v", err)
+	}
+	if title != "Broken" || !strings.Contains(content, "First") {
+		t.Errorf("Expected the readable parts of the page, got %q: %q", title, content)
+	}
+
+	// A panic while traversing is reported as an error
+	doc, err := html.Parse(strings.NewReader("<html><body><script>x</script></body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := &ContentExtractor{skipTags: tagSet([]string{"script"})} // inSkip is nil
+	if err := broken.extract(doc); err == nil || !strings.Contains(err.Error(), "failed to extract HTML content") {
+		t.Errorf("Expected the panic to become an error, got %v", err)
+	}
+}
+
+func TestExtractFromHTMLBodyOnly(t *testing.T) {
+	page := `<html><head><title>Body Only</title></head><body><p>Just the text.</p></body></html>`
+
+	title, content, err := ExtractFromHTMLWithOptions(page, "https://example.com", Options{BodyOnly: true})
+	if err != nil {
+		t.Fatalf("ExtractFromHTMLWithOptions failed: %v", err)
+	}
+	if title != sanitizeFilename("Body Only") {
+		t.Errorf("Unexpected title %q", title)
+	}
+	if strings.Contains(content, "Source:") || strings.Contains(content, "# Body Only") {
+		t.Errorf("Expected no header block, got %q", content)
+	}
+	if !strings.HasPrefix(content, "Just the text.") {
+		t.Errorf("Expected content to start with the body text, got %q", content)
+	}
+
+	_, full, err := ExtractFromHTML(page, "https://example.com")
+	if err != nil {
+		t.Fatalf("ExtractFromHTML failed: %v", err)
+	}
+	if !strings.HasPrefix(full, "# Body Only\n\nSource: https://example.com\n\n---\n\n") {
+		t.Errorf("Expected the full markdown by default, got %q", full)
+	}
+}
+
+func TestExtractFigures(t *testing.T) {
+	data, err := os.ReadFile("testdata/figure.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := &Page{URL: "https://example.com/articles/reefs.html", ContentType: "text/html", Body: data}
+	_, content, err := ExtractPage(page, Options{})
+	if err != nil {
+		t.Fatalf("ExtractPage failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"\n![A \\[healthy\\] reef with fish](https://example.com/articles/images/reef%20%281%29.jpg)\n",
+		"\n*Figure 1: A reef off the coast of Belize.*\n",
+		"white ![warning](https://example.com/icons/warn.svg) when",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected content to contain %q, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "Site logo") || strings.Contains(content, "spacer") {
+		t.Errorf("Expected skipped and decorative images to be left out, got:\n%s", content)
+	}
+
+	_, plain, err := ExtractPage(page, Options{AltTextOnly: true})
+	if err != nil {
+		t.Fatalf("ExtractPage failed: %v", err)
+	}
+	if !strings.Contains(plain, "\nA [healthy] reef with fish\n") || strings.Contains(plain, "![") {
+		t.Errorf("Expected plain alt text, got:\n%s", plain)
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	data, err := os.ReadFile("testdata/links.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &Page{URL: "https://example.com/coast/visit.html", ContentType: "text/html", Body: data}
+
+	_, plain, err := ExtractPage(page, Options{})
+	if err != nil {
+		t.Fatalf("ExtractPage failed: %v", err)
+	}
+	if strings.Contains(plain, "](") || strings.Contains(plain, "References") {
+		t.Errorf("Expected link text only by default, got:\n%s", plain)
+	}
+
+	_, inline, err := ExtractPage(page, Options{Links: LinksInline})
+	if err != nil {
+		t.Fatalf("ExtractPage failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Tide pools\n",
+		"Read the [tide guide](https://example.com/guides/tides) before",
+		"[safety tips](https://example.org/safety?lang=en)",
+		"lists closures ,",
+		"[the rangers](mailto:rangers@example.com)",
+		"Subscribe to updates",
+	} {
+		if !strings.Contains(inline, want) {
+			t.Errorf("Expected inline links to contain %q, got:\n%s", want, inline)
+		}
+	}
+
+	_, refs, err := ExtractPage(page, Options{Links: LinksReferences})
+	if err != nil {
+		t.Fatalf("ExtractPage failed: %v", err)
+	}
+	for _, want := range []string{
+		"Read the tide guide[1] before",
+		"safety tips[2]",
+		"The guide[1] also",
+		"the rangers[3]",
+		"\n\n## References\n\n1. https://example.com/guides/tides\n2. https://example.org/safety?lang=en\n3. mailto:rangers@example.com\n",
+	} {
+		if !strings.Contains(refs, want) {
+			t.Errorf("Expected references to contain %q, got:\n%s", want, refs)
+		}
+	}
+	if strings.Contains(refs, "Home") {
+		t.Errorf("Expected links in skipped elements to be left out, got:\n%s", refs)
+	}
+}
+
+// BenchmarkExtractFromHTML extracts a batch of pages the way a crawl does,
+// one extractor per page
+func BenchmarkExtractFromHTML(b *testing.B) {
+	var pages []string
+	for _, name := range []string{"testdata/figure.html", "testdata/links.html"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		pages = append(pages, string(data))
+	}
+	var article strings.Builder
+	article.WriteString("<html><head><title>Long read</title></head><body><article>")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&article, "<h2>Part %d</h2><p>Some <em>text</em> with a <a href=\"/p/%d\">link</a>.</p>\n\n\n", i, i)
+	}
+	article.WriteString("</article></body></html>")
+	pages = append(pages, article.String())
+
+	opts := Options{Links: LinksInline}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, page := range pages {
+			if _, _, err := ExtractFromHTMLWithOptions(page, "https://example.com/", opts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestExtractJSONLD(t *testing.T) {
+	data, err := os.ReadFile("testdata/jsonld.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &Page{URL: "https://example.com/tides", Body: data, ContentType: "text/html"}
+
+	title, content, meta, err := ExtractPageData(page, Options{})
+	if err != nil {
+		t.Fatalf("ExtractPageData failed: %v", err)
+	}
+	if title != "Tide pools explained" {
+		t.Errorf("Expected the headline as title, got %q", title)
+	}
+	if !strings.Contains(content, "Subscribe now!") {
+		t.Errorf("Expected the content from the markup by default, got %q", content)
+	}
+	expected := &StructuredData{
+		Type:          "NewsArticle",
+		Headline:      "Tide pools explained",
+		Authors:       []string{"Ana Ruiz", "Ben Okafor"},
+		DatePublished: "2024-05-01T08:00:00Z",
+		Description:   "What lives between the tides.",
+		ArticleBody:   "Tide pools form where rock holds water.\r\n\r\nAnemones live there.",
+	}
+	if fmt.Sprintf("%+v", meta.Structured) != fmt.Sprintf("%+v", expected) {
+		t.Errorf("Expected %+v, got %+v", expected, meta.Structured)
+	}
+
+	_, body, _, err := ExtractPageData(page, Options{ArticleBody: true})
+	if err != nil {
+		t.Fatalf("ExtractPageData failed: %v", err)
+	}
+	if body != "Tide pools form where rock holds water.\n\nAnemones live there.\n" {
+		t.Errorf("Expected the article body as content, got %q", body)
+	}
+
+	// Pages without an article node fall back to the markup
+	plain := []byte(`<html><head><title>Plain</title><script type="application/ld+json">{"@type": "WebSite"}</script>
+<script type="application/ld+json">{not json</script></head><body><p>Text</p></body></html>`)
+	title, _, meta, err = ExtractPageData(&Page{Body: plain, ContentType: "text/html"}, Options{ArticleBody: true})
+	if err != nil || title != "Plain" || meta.Structured != nil {
+		t.Errorf("Expected no structured data, got %q %+v (err %v)", title, meta.Structured, err)
+	}
+}
+
+func TestFollowAMP(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/story", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Story</title><link rel="canonical" href="/story">
+<link rel="amphtml" href="/story/amp"></head><body><div>Ads everywhere</div><p>Story text</p></body></html>`)
+	})
+	mux.HandleFunc("/story/amp", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Story</title><link rel="canonical" href="/story"></head><body><p>Clean story text</p></body></html>`)
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><link rel="amphtml" href="/missing"></head><body><p>Plain text</p></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := context.Background()
+
+	page, err := FetchPageContext(ctx, server.URL+"/story", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FollowAMP(ctx, page, Options{}); got != page {
+		t.Error("Expected the page itself without PreferAMP")
+	}
+	amp := FollowAMP(ctx, page, Options{PreferAMP: true})
+	if amp.URL != server.URL+"/story/amp" {
+		t.Fatalf("Expected the AMP version, got %s", amp.URL)
+	}
+	_, content, meta, err := ExtractPageData(amp, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "Clean story text") || meta.Canonical != server.URL+"/story" {
+		t.Errorf("Unexpected AMP extraction %q, canonical %q", content, meta.Canonical)
+	}
+
+	// An AMP link that cannot be fetched falls back to the page
+	page, err = FetchPageContext(ctx, server.URL+"/plain", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FollowAMP(ctx, page, Options{PreferAMP: true}); got != page {
+		t.Errorf("Expected the page itself when its AMP version is missing, got %s", got.URL)
+	}
+}
+
+func TestExtractStripsBoilerplate(t *testing.T) {
+	data, err := os.ReadFile("testdata/boilerplate.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, content, err := ExtractContent(string(data))
+	if err != nil {
+		t.Fatalf("ExtractContent failed: %v", err)
+	}
+	for _, cruft := range []string{"Subscribe to our newsletter", "Share on Twitter", "We use cookies", "Advertisement"} {
+		if strings.Contains(content, cruft) {
+			t.Errorf("Expected %q to be stripped, got %q", cruft, content)
+		}
+	}
+	for _, prose := range []string{
+		"# Mangrove forests",
+		"Mangroves grow in the salty water of tropical coasts.",
+		"Their roots shelter young fish",
+		"Readers of our newsletter asked", // long enough to be prose
+	} {
+		if !strings.Contains(content, prose) {
+			t.Errorf("Expected %q to be kept, got %q", prose, content)
+		}
+	}
+	if strings.Contains(content, "\n\n\n") {
+		t.Errorf("Expected stripped lines not to leave runs of blank lines, got %q", content)
+	}
+
+	// An empty list keeps everything, a custom list replaces the defaults
+	_, content, err = ExtractContentWithOptions(string(data), Options{StripPhrases: []string{""}})
+	if err != nil {
+		t.Fatalf("ExtractContentWithOptions failed: %v", err)
+	}
+	if !strings.Contains(content, "Subscribe to our newsletter") {
+		t.Errorf("Expected no stripping with an empty list, got %q", content)
+	}
+	_, content, err = ExtractContentWithOptions(string(data), Options{StripPhrases: []string{`^their roots`}})
+	if err != nil {
+		t.Fatalf("ExtractContentWithOptions failed: %v", err)
+	}
+	if strings.Contains(content, "Their roots") || !strings.Contains(content, "Advertisement") {
+		t.Errorf("Expected only the custom phrase to be stripped, got %q", content)
+	}
+
+	if _, _, err := ExtractContentWithOptions(string(data), Options{StripPhrases: []string{"("}}); err == nil {
+		t.Error("Expected an error for an invalid strip phrase")
+	}
+}
+
+func TestExtractMaxDepth(t *testing.T) {
+	data, err := os.ReadFile("testdata/nested.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, content, err := ExtractContentWithOptions(string(data), Options{})
+	if err != nil {
+		t.Fatalf("ExtractContentWithOptions failed: %v", err)
+	}
+	for _, deep := range []string{"ten beaches", "waterproof boots"} {
+		if !strings.Contains(content, deep) {
+			t.Errorf("Expected %q without a depth limit, got %q", deep, content)
+		}
+	}
+
+	// The paragraphs three levels below article and section are the deepest kept
+	_, content, err = ExtractContentWithOptions(string(data), Options{MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("ExtractContentWithOptions failed: %v", err)
+	}
+	for _, kept := range []string{"# Tide pools", "Tide pools form where", "Anemones and crabs", "Visit at low tide"} {
+		if !strings.Contains(content, kept) {
+			t.Errorf("Expected %q to be kept, got %q", kept, content)
+		}
+	}
+	for _, cut := range []string{"ten beaches", "waterproof boots"} {
+		if strings.Contains(content, cut) {
+			t.Errorf("Expected %q past the depth limit to be left out, got %q", cut, content)
+		}
+	}
+}
+
+func TestExtractMath(t *testing.T) {
+	data, err := os.ReadFile("testdata/math.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, content, err := ExtractContentWithOptions(string(data), Options{Math: true})
+	if err != nil {
+		t.Fatalf("ExtractContentWithOptions failed: %v", err)
+	}
+	for _, want := range []string{
+		"is $E=mc^2$ for every mass",
+		"\n$$x=\\frac{-b\\pm\\sqrt{b^2-4ac}}{2a}$$\n",
+		"states, $\\sum_{i=1}^{n} p_i = 1$ as expected",
+		"satisfies $\\sin ^{2}\\theta +\\cos ^{2}\\theta =1$ always",
+		"![The lab](/lab.jpg)",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in content, got %q", want, content)
+		}
+	}
+
+	// Without Math the equations are left as loose text
+	_, content, err = ExtractContentWithOptions(string(data), Options{})
+	if err != nil {
+		t.Fatalf("ExtractContentWithOptions failed: %v", err)
+	}
+	if strings.Contains(content, "$") {
+		t.Errorf("Expected no LaTeX without Math, got %q", content)
+	}
+}
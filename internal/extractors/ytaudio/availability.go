@@ -0,0 +1,74 @@
+package ytaudio
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// Reasons YouTube will not serve a video's audio, wrapped in a *VideoError
+var (
+	ErrLiveStream       = errors.New("video is a live stream")
+	ErrVideoUnavailable = errors.New("video is unavailable")
+	ErrPrivate          = errors.New("video is private")
+)
+
+// VideoError reports why a video cannot be downloaded. Retrying does not
+// help, unlike with network failures.
+type VideoError struct {
+	Err    error  // ErrLiveStream, ErrVideoUnavailable or ErrPrivate
+	Reason string // YouTube's explanation, when it gave one
+}
+
+func (e *VideoError) Error() string {
+	if e.Reason == "" {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + ": " + e.Reason
+}
+
+func (e *VideoError) Unwrap() error { return e.Err }
+
+// unavailableStatuses are the playability statuses of videos that are gone
+// or blocked, as opposed to ones that need a login
+var unavailableStatuses = map[string]bool{
+	"ERROR":      true, // removed, terminated account or bad id
+	"UNPLAYABLE": true, // blocked in the country or by the uploader
+}
+
+// videoInfo fetches a video's metadata with client, reporting private,
+// unavailable and live videos as a *VideoError
+func videoInfo(ctx context.Context, client *youtube.Client, videoURL string) (*youtube.Video, error) {
+	video, err := client.GetVideoContext(ctx, videoURL)
+	if err != nil {
+		if videoErr := classifyVideoError(err); videoErr != nil {
+			return nil, videoErr
+		}
+		return nil, err
+	}
+	// Running streams have no length yet and are only served as HLS
+	if video.Duration == 0 && video.HLSManifestURL != "" {
+		return nil, &VideoError{Err: ErrLiveStream, Reason: "it can be transcribed once the stream has ended"}
+	}
+	return video, nil
+}
+
+// classifyVideoError returns the *VideoError a client error stands for, or
+// nil when it is some other failure
+func classifyVideoError(err error) *VideoError {
+	if errors.Is(err, youtube.ErrVideoPrivate) {
+		return &VideoError{Err: ErrPrivate}
+	}
+	var status *youtube.ErrPlayabiltyStatus
+	if !errors.As(err, &status) {
+		return nil
+	}
+	switch {
+	case status.Status == "LIVE_STREAM_OFFLINE":
+		return &VideoError{Err: ErrLiveStream, Reason: status.Reason}
+	case unavailableStatuses[status.Status]:
+		return &VideoError{Err: ErrVideoUnavailable, Reason: status.Reason}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package ytaudio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// cannedTransport answers YouTube's player API with a canned player response
+// and the watch page with that response embedded, as a real page carries it
+type cannedTransport struct {
+	player string
+}
+
+func (c cannedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := c.player
+	if req.URL.Path == "/watch" {
+		body = "<script>var ytInitialPlayerResponse = " + c.player + ";</script>"
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+const audioFormats = `"adaptiveFormats": [{"itag": 140, "url": "https://example.com/audio", "mimeType": "audio/mp4", "bitrate": 128000, "audioChannels": 2}]`
+
+func TestVideoInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		player   string
+		expected error // nil for a downloadable video
+		reason   string
+	}{
+		{
+			name:     "private",
+			player:   `{"playabilityStatus": {"status": "LOGIN_REQUIRED", "reason": "This video is private"}}`,
+			expected: ErrPrivate,
+		},
+		{
+			// Without playableInEmbed the client reads the watch page as well
+			name:     "removed",
+			player:   `{"playabilityStatus": {"status": "ERROR", "reason": "This video has been removed by the uploader"}}`,
+			expected: ErrVideoUnavailable,
+			reason:   "This video has been removed by the uploader",
+		},
+		{
+			name:     "blocked",
+			player:   `{"playabilityStatus": {"status": "UNPLAYABLE", "reason": "The uploader has not made this video available in your country", "playableInEmbed": true}}`,
+			expected: ErrVideoUnavailable,
+			reason:   "The uploader has not made this video available in your country",
+		},
+		{
+			name:     "upcoming live stream",
+			player:   `{"playabilityStatus": {"status": "LIVE_STREAM_OFFLINE", "reason": "This live event will begin in 3 hours.", "playableInEmbed": true}}`,
+			expected: ErrLiveStream,
+			reason:   "This live event will begin in 3 hours.",
+		},
+		{
+			name:     "running live stream",
+			player:   `{"playabilityStatus": {"status": "OK", "playableInEmbed": true}, "videoDetails": {"lengthSeconds": "0"}, "streamingData": {"hlsManifestUrl": "https://example.com/live.m3u8", ` + audioFormats + `}}`,
+			expected: ErrLiveStream,
+		},
+		{
+			name:   "video",
+			player: `{"playabilityStatus": {"status": "OK", "playableInEmbed": true}, "videoDetails": {"title": "Talk", "lengthSeconds": "212"}, "streamingData": {` + audioFormats + `}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &youtube.Client{HTTPClient: &http.Client{Transport: cannedTransport{player: test.player}}}
+			video, err := videoInfo(context.Background(), client, "https://youtu.be/dQw4w9WgXcQ")
+
+			if test.expected == nil {
+				if err != nil {
+					t.Fatalf("Expected the video, got %v", err)
+				}
+				if video.Title != "Talk" {
+					t.Errorf("Expected title Talk, got %q", video.Title)
+				}
+				return
+			}
+
+			var videoErr *VideoError
+			if !errors.As(err, &videoErr) || !errors.Is(err, test.expected) {
+				t.Fatalf("Expected a VideoError for %v, got %v", test.expected, err)
+			}
+			if test.reason != "" && videoErr.Reason != test.reason {
+				t.Errorf("Expected reason %q, got %q", test.reason, videoErr.Reason)
+			}
+		})
+	}
+}
+
+func TestVideoInfoOtherErrors(t *testing.T) {
+	// Responses that say nothing about the video stay ordinary errors
+	client := &youtube.Client{HTTPClient: &http.Client{Transport: cannedTransport{player: "not json"}}}
+	_, err := videoInfo(context.Background(), client, "https://youtu.be/dQw4w9WgXcQ")
+	var videoErr *VideoError
+	if err == nil || errors.As(err, &videoErr) {
+		t.Errorf("Expected a plain error, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package ytaudio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"maai.solutions/gengo/internal/httpclient"
+)
+
+// WhisperModels lists the whisper model sizes the CLI knows about
+var WhisperModels = []string{"tiny", "base", "small", "medium", "large"}
+
+// youTubeCheckURL is requested to confirm that YouTube is reachable
+var youTubeCheckURL = "https://www.youtube.com/"
+
+// DependencyStatus is the outcome of checking a single dependency
+type DependencyStatus struct {
+	Name   string
+	OK     bool
+	Fatal  bool   // transcription cannot run when this check fails
+	Detail string // version, location or reason for failure
+}
+
+// ModelStatus reports where a whisper model was found, if anywhere
+type ModelStatus struct {
+	Name string
+	Path string // empty when the model is not installed
+}
+
+// DependencyReport collects the results of all dependency checks
+type DependencyReport struct {
+	Checks []DependencyStatus
+	Models []ModelStatus
+}
+
+// OK reports whether every fatal dependency is available
+func (r *DependencyReport) OK() bool {
+	for _, check := range r.Checks {
+		if check.Fatal && !check.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckDependencyReport checks ffmpeg, the installed whisper models and
+// network access to YouTube. Missing ffmpeg or models are fatal; an
+// unreachable YouTube is reported but not fatal.
+func CheckDependencyReport(ctx context.Context) *DependencyReport {
+	report := &DependencyReport{}
+	report.Checks = append(report.Checks, checkFFmpeg(ctx))
+
+	found := 0
+	for _, name := range WhisperModels {
+		path := FindWhisperModel(name)
+		if path != "" {
+			found++
+		}
+		report.Models = append(report.Models, ModelStatus{Name: name, Path: path})
+	}
+	models := DependencyStatus{Name: "whisper model", OK: found > 0, Fatal: true}
+	if found > 0 {
+		models.Detail = fmt.Sprintf("%d of %d models installed", found, len(WhisperModels))
+	} else {
+		models.Detail = "no models found; download one from https://huggingface.co/ggerganov/whisper.cpp"
+	}
+	report.Checks = append(report.Checks, models)
+
+	report.Checks = append(report.Checks, checkYouTube(ctx, youTubeCheckURL))
+	return report
+}
+
+// checkFFmpeg verifies ffmpeg is installed and reports its version
+func checkFFmpeg(ctx context.Context) DependencyStatus {
+	status := DependencyStatus{Name: "ffmpeg", Fatal: true}
+
+	if err := CheckDependencies(); err != nil {
+		status.Detail = err.Error()
+		return status
+	}
+
+	out, err := exec.CommandContext(ctx, "ffmpeg", "-version").Output()
+	if err != nil {
+		status.Detail = fmt.Sprintf("failed to run ffmpeg: %v", err)
+		return status
+	}
+
+	status.OK = true
+	status.Detail = strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	return status
+}
+
+// checkYouTube verifies that url can be reached over the network
+func checkYouTube(ctx context.Context, url string) DependencyStatus {
+	status := DependencyStatus{Name: "youtube access"}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		status.Detail = err.Error()
+		return status
+	}
+
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		status.Detail = fmt.Sprintf("cannot reach %s: %v", url, err)
+		return status
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		status.Detail = fmt.Sprintf("%s returned HTTP %d", url, resp.StatusCode)
+		return status
+	}
+
+	status.OK = true
+	status.Detail = fmt.Sprintf("%s reachable", url)
+	return status
+}
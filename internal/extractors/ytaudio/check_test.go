@@ -0,0 +1,62 @@
+package ytaudio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDependencyReportOK(t *testing.T) {
+	report := &DependencyReport{Checks: []DependencyStatus{
+		{Name: "ffmpeg", OK: true, Fatal: true},
+		{Name: "youtube access", OK: false},
+	}}
+	if !report.OK() {
+		t.Error("Expected report to be OK when only non-fatal checks fail")
+	}
+
+	report.Checks = append(report.Checks, DependencyStatus{Name: "whisper model", Fatal: true})
+	if report.OK() {
+		t.Error("Expected report to fail when a fatal check fails")
+	}
+}
+
+func TestCheckYouTube(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/blocked" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	if status := checkYouTube(context.Background(), server.URL); !status.OK {
+		t.Errorf("Expected reachable server to pass, got %q", status.Detail)
+	}
+	if status := checkYouTube(context.Background(), server.URL+"/blocked"); status.OK {
+		t.Error("Expected HTTP 403 to fail the check")
+	}
+	if status := checkYouTube(context.Background(), "http://127.0.0.1:1"); status.OK || status.Fatal {
+		t.Errorf("Expected unreachable host to fail without being fatal, got %+v", status)
+	}
+}
+
+func TestCheckDependencyReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	orig := youTubeCheckURL
+	youTubeCheckURL = server.URL
+	defer func() { youTubeCheckURL = orig }()
+
+	report := CheckDependencyReport(context.Background())
+	if len(report.Checks) != 3 {
+		t.Fatalf("Expected 3 checks, got %d", len(report.Checks))
+	}
+	if len(report.Models) != len(WhisperModels) {
+		t.Errorf("Expected %d model entries, got %d", len(WhisperModels), len(report.Models))
+	}
+	if !report.Checks[2].OK {
+		t.Errorf("Expected youtube access check to pass, got %q", report.Checks[2].Detail)
+	}
+}
@@ -0,0 +1,121 @@
+package ytaudio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kkdai/youtube/v2"
+	"maai.solutions/gengo/internal/httpclient"
+)
+
+// retryBackoff is the delay before the first retry; it doubles on each
+// attempt up to maxRetryBackoff
+var retryBackoff = 2 * time.Second
+
+// maxRetryBackoff bounds the delay between retries, however many are allowed
+const maxRetryBackoff = time.Minute
+
+// streamOpener opens a download stream starting at offset. It returns the
+// offset the stream actually starts at, which is 0 when resuming is not possible.
+type streamOpener func(ctx context.Context, offset int64) (io.ReadCloser, int64, error)
+
+// openStream opens the stream for a format, using a range request to resume
+// from offset and falling back to the full stream if the range is refused
+func openStream(ctx context.Context, client *youtube.Client, video *youtube.Video, format *youtube.Format, offset int64) (io.ReadCloser, int64, error) {
+	if offset > 0 {
+		if stream, err := openRange(ctx, client, video, format, offset); err == nil {
+			return stream, offset, nil
+		}
+	}
+
+	stream, _, err := client.GetStreamContext(ctx, video, format)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get video stream: %w", err)
+	}
+	return stream, 0, nil
+}
+
+// openRange requests the remainder of a stream from offset onwards
+func openRange(ctx context.Context, client *youtube.Client, video *youtube.Video, format *youtube.Format, offset int64) (io.ReadCloser, error) {
+	url, err := client.GetStreamURLContext(ctx, video, format)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = httpclient.Default
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("range request not supported: HTTP %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// copyWithRetry copies a stream into file, reopening it up to retries times
+// with exponential backoff when the copy fails. Streams that resume at the
+// current offset are appended; streams that restart overwrite the file.
+func copyWithRetry(ctx context.Context, file *os.File, open streamOpener, retries int) error {
+	var written int64
+	for attempt := 0; ; attempt++ {
+		err := func() error {
+			stream, offset, err := open(ctx, written)
+			if err != nil {
+				return err
+			}
+			defer stream.Close()
+
+			if offset != written {
+				if err := file.Truncate(offset); err != nil {
+					return err
+				}
+				if _, err := file.Seek(offset, io.SeekStart); err != nil {
+					return err
+				}
+				written = offset
+			}
+
+			n, err := io.Copy(file, &contextReader{ctx: ctx, r: stream})
+			written += n
+			return err
+		}()
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= retries {
+			return fmt.Errorf("download failed after %d attempts: %w", attempt+1, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay(attempt)):
+		}
+	}
+}
+
+// retryDelay returns the delay before retrying after the given attempt,
+// counted from 0. The shift is bounded so large attempt counts cannot
+// overflow into a negative or zero delay.
+func retryDelay(attempt int) time.Duration {
+	return min(retryBackoff<<min(attempt, 6), maxRetryBackoff)
+}
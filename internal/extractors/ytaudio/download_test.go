@@ -0,0 +1,134 @@
+package ytaudio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingReader returns data and then fails like a throttled stream
+type failingReader struct {
+	r io.Reader
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func TestCopyWithRetry(t *testing.T) {
+	retryBackoff = 0
+	const content = "0123456789"
+
+	tests := []struct {
+		name    string
+		resume  bool
+		retries int
+		wantErr bool
+		opens   int
+	}{
+		{"resume from offset", true, 2, false, 2},
+		{"restart without range support", false, 2, false, 2},
+		{"out of retries", true, 0, true, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := os.Create(filepath.Join(t.TempDir(), "video.mp4"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			var offsets []int64
+			open := func(ctx context.Context, offset int64) (io.ReadCloser, int64, error) {
+				offsets = append(offsets, offset)
+				if len(offsets) == 1 {
+					// First attempt breaks off after four bytes
+					return io.NopCloser(&failingReader{r: strings.NewReader(content[:4])}), 0, nil
+				}
+				if !test.resume {
+					offset = 0
+				}
+				return io.NopCloser(strings.NewReader(content[offset:])), offset, nil
+			}
+
+			err = copyWithRetry(context.Background(), file, open, test.retries)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("copyWithRetry() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if len(offsets) != test.opens {
+				t.Errorf("Expected %d stream opens, got %d", test.opens, len(offsets))
+			}
+			if test.wantErr {
+				return
+			}
+
+			if offsets[1] != 4 {
+				t.Errorf("Expected retry to request offset 4, got %d", offsets[1])
+			}
+			data, err := os.ReadFile(file.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != content {
+				t.Errorf("Expected file content %q, got %q", content, data)
+			}
+		})
+	}
+}
+
+func TestCopyWithRetryCancelled(t *testing.T) {
+	retryBackoff = 0
+	ctx, cancel := context.WithCancel(context.Background())
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "video.mp4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	opens := 0
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, int64, error) {
+		opens++
+		cancel()
+		return nil, 0, errors.New("connection reset")
+	}
+
+	if err := copyWithRetry(ctx, file, open, 5); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if opens != 1 {
+		t.Errorf("Expected no retries after cancellation, got %d opens", opens)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	defer func(backoff time.Duration) { retryBackoff = backoff }(retryBackoff)
+	retryBackoff = 2 * time.Second
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{0, 2 * time.Second},
+		{1, 4 * time.Second},
+		{4, 32 * time.Second},
+		{5, time.Minute},
+		{70, time.Minute},
+		{1 << 30, time.Minute},
+	}
+	for _, test := range tests {
+		if got := retryDelay(test.attempt); got != test.expected {
+			t.Errorf("retryDelay(%d) = %v, expected %v", test.attempt, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package ytaudio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kkdai/youtube/v2"
+	"maai.solutions/gengo/internal/httpclient"
+)
+
+// Playlist lists the videos of a YouTube playlist in playlist order
+type Playlist struct {
+	ID     string
+	Title  string
+	Videos []PlaylistVideo
+}
+
+// PlaylistVideo is a video of a playlist
+type PlaylistVideo struct {
+	URL   string
+	Title string
+}
+
+// IsPlaylistURL reports whether rawURL points at a YouTube playlist page,
+// https://www.youtube.com/playlist?list=...
+func IsPlaylistURL(rawURL string) bool {
+	u := parseVideoURL(rawURL)
+	if u == nil || !youTubeHosts[strings.ToLower(u.Hostname())] {
+		return false
+	}
+	return u.Path == "/playlist" && u.Query().Get("list") != ""
+}
+
+// Playlist fetches the title and videos of a playlist
+func (s *Service) Playlist(ctx context.Context, playlistURL string) (*Playlist, error) {
+	httpClient := s.httpClient
+	if httpClient == nil {
+		httpClient = httpclient.Default
+	}
+	client := youtube.Client{HTTPClient: httpClient}
+
+	playlist, err := client.GetPlaylistContext(ctx, playlistURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist: %w", err)
+	}
+
+	result := &Playlist{ID: playlist.ID, Title: playlist.Title}
+	for _, entry := range playlist.Videos {
+		result.Videos = append(result.Videos, PlaylistVideo{
+			URL:   "https://www.youtube.com/watch?v=" + entry.ID,
+			Title: entry.Title,
+		})
+	}
+	return result, nil
+}
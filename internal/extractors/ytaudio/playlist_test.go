@@ -0,0 +1,24 @@
+package ytaudio
+
+import "testing"
+
+func TestIsPlaylistURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://www.youtube.com/playlist?list=PL590L5WQmH8fJ54F369BLDSqIwcs-TCfs", true},
+		{"https://youtube.com/playlist?list=PLabc", true},
+		{"https://m.youtube.com/playlist?list=PLabc", true},
+		{"https://www.youtube.com/playlist", false},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PLabc", false},
+		{"https://example.com/playlist?list=PLabc", false},
+		{"not a url", false},
+	}
+
+	for _, test := range tests {
+		if result := IsPlaylistURL(test.url); result != test.expected {
+			t.Errorf("IsPlaylistURL(%q) = %v, expected %v", test.url, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package ytaudio
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"maai.solutions/gengo/internal/extractors/asr"
+	"maai.solutions/gengo/internal/text"
+)
+
+// Templates are the built-in transcript templates, selected by name
+var Templates = map[string]string{
+	// default follows the markdown layout of transcripts without a template
+	"default": `# {{.Title}}
+
+**Source:** {{.URL}}  
+{{- if .Language}}
+**Language:** {{.Language}}  
+{{- end}}
+**Length:** {{timestamp .Duration}}  
+{{- if .Partial}}
+**Partial:** transcription timed out  
+{{- end}}
+
+---
+
+{{.Text}}
+`,
+	// timestamps writes every segment on its own line after its start time
+	"timestamps": `# {{.Title}}
+
+**Source:** {{.URL}}  
+**Length:** {{timestamp .Duration}}  
+
+---
+
+{{range .Segments}}[{{timestamp .Start}}] {{.Text}}
+{{end}}`,
+	// paragraphs heads each paragraph with the time it starts at
+	"paragraphs": `# {{.Title}}
+
+**Source:** {{.URL}}  
+**Length:** {{timestamp .Duration}}  
+
+---
+{{range .Paragraphs}}
+**[{{timestamp .Start}}]** {{.Text}}
+{{end}}`,
+}
+
+// TranscriptData is what a transcript template renders
+type TranscriptData struct {
+	Title       string
+	URL         string
+	VideoID     string
+	Language    string
+	Duration    time.Duration // length of the transcribed audio
+	Transcribed time.Time
+	Partial     bool // the transcription timed out and stops early
+	Text        string
+	Segments    []asr.Segment
+	Paragraphs  []Paragraph // segments grouped at pauses
+}
+
+// Paragraph is a run of transcript segments between pauses
+type Paragraph struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// NewTranscriptData collects the template fields of a transcript. Segments
+// are grouped into paragraphs at pauses of at least pause.
+func NewTranscriptData(title, videoURL string, result *TranscriptionResult, pause time.Duration) TranscriptData {
+	data := TranscriptData{
+		Title:       title,
+		URL:         videoURL,
+		VideoID:     VideoID(videoURL),
+		Language:    result.Language,
+		Duration:    TranscribedUntil(result),
+		Transcribed: time.Now(),
+		Partial:     result.Partial,
+		Text:        result.Text,
+		Segments:    result.Segments,
+	}
+	for _, segments := range asr.Paragraphs(result.Segments, pause) {
+		texts := make([]string, 0, len(segments))
+		for _, segment := range segments {
+			texts = append(texts, segment.Text)
+		}
+		data.Paragraphs = append(data.Paragraphs, Paragraph{
+			Start: segments[0].Start,
+			End:   segments[len(segments)-1].End,
+			Text:  text.JoinText(texts),
+		})
+	}
+	return data
+}
+
+// templateFuncs are the functions available to transcript templates
+var templateFuncs = template.FuncMap{
+	"timestamp": timestamp,
+}
+
+// timestamp formats an audio position as M:SS, or H:MM:SS from an hour on
+func timestamp(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// TemplateNames returns the names of the built-in templates in order
+func TemplateNames() []string {
+	names := make([]string, 0, len(Templates))
+	for name := range Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTemplate parses a transcript template from a file, or the built-in
+// template of that name when no such file exists
+func LoadTemplate(nameOrPath string) (*template.Template, error) {
+	source, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		builtin, ok := Templates[nameOrPath]
+		if !ok {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("template %q is neither a file nor a built-in template (%s)", nameOrPath, strings.Join(TemplateNames(), ", "))
+			}
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		source = []byte(builtin)
+	}
+
+	tmpl, err := template.New(nameOrPath).Funcs(templateFuncs).Option("missingkey=error").Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", nameOrPath, err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate renders a transcript with a template
+func RenderTemplate(tmpl *template.Template, data TranscriptData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,91 @@
+package ytaudio
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"maai.solutions/gengo/internal/extractors/asr"
+)
+
+func templateResult() *TranscriptionResult {
+	return &TranscriptionResult{
+		Text:     "Hello there. Welcome back.",
+		Language: "en",
+		Segments: []asr.Segment{
+			{Start: 0, End: 2 * time.Second, Text: "Hello there."},
+			{Start: 65 * time.Second, End: 68 * time.Second, Text: "Welcome back."},
+		},
+	}
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	data := NewTranscriptData("Talk", "https://youtu.be/dQw4w9WgXcQ", templateResult(), asr.DefaultParagraphPause)
+
+	tests := map[string][]string{
+		"default":    {"# Talk\n", "**Source:** https://youtu.be/dQw4w9WgXcQ", "**Language:** en", "**Length:** 1:08", "Hello there. Welcome back."},
+		"timestamps": {"[0:00] Hello there.\n", "[1:05] Welcome back.\n"},
+		"paragraphs": {"**[0:00]** Hello there.\n", "**[1:05]** Welcome back.\n"},
+	}
+	for _, name := range TemplateNames() {
+		tmpl, err := LoadTemplate(name)
+		if err != nil {
+			t.Fatalf("LoadTemplate(%q) failed: %v", name, err)
+		}
+		out, err := RenderTemplate(tmpl, data)
+		if err != nil {
+			t.Fatalf("Rendering %s failed: %v", name, err)
+		}
+		for _, want := range tests[name] {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %s template output to contain %q, got:\n%s", name, want, out)
+			}
+		}
+	}
+}
+
+func TestLoadTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "house.tmpl")
+	source := "## {{.Title}} ({{.VideoID}})\n{{range .Paragraphs}}{{timestamp .Start}}-{{timestamp .End}}: {{.Text}}\n{{end}}"
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate failed: %v", err)
+	}
+	out, err := RenderTemplate(tmpl, NewTranscriptData("Talk", "https://youtu.be/dQw4w9WgXcQ", templateResult(), asr.DefaultParagraphPause))
+	if err != nil {
+		t.Fatalf("RenderTemplate failed: %v", err)
+	}
+	expected := "## Talk (dQw4w9WgXcQ)\n0:00-0:02: Hello there.\n1:05-1:08: Welcome back.\n"
+	if out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	if _, err := LoadTemplate("no-such-template"); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+	bad := filepath.Join(t.TempDir(), "bad.tmpl")
+	os.WriteFile(bad, []byte("{{.Title"), 0644)
+	if _, err := LoadTemplate(bad); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                "0:00",
+		65 * time.Second: "1:05",
+		time.Hour + 2*time.Minute + 3*time.Second: "1:02:03",
+		1500 * time.Millisecond:                   "0:02",
+	}
+	for d, expected := range tests {
+		if got := timestamp(d); got != expected {
+			t.Errorf("timestamp(%s) = %q, expected %q", d, got, expected)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package ytaudio
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"maai.solutions/gengo/internal/extractors/asr"
+	"maai.solutions/gengo/internal/output"
+)
+
+// youTubeHosts lists the hosts serving YouTube watch, embed, shorts and live pages
+var youTubeHosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"music.youtube.com":        true,
+	"youtube-nocookie.com":     true,
+	"www.youtube-nocookie.com": true,
+}
+
+// parseVideoURL parses an absolute http(s) URL, returning nil for anything else
+func parseVideoURL(rawURL string) *url.URL {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if (scheme != "http" && scheme != "https") || u.Hostname() == "" {
+		return nil
+	}
+	return u
+}
+
+// IsVideoURL reports whether rawURL points at a single YouTube video
+func IsVideoURL(rawURL string) bool {
+	u := parseVideoURL(rawURL)
+	if u == nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "youtu.be" {
+		return strings.Trim(u.Path, "/") != ""
+	}
+	if !youTubeHosts[host] {
+		return false
+	}
+
+	if u.Path == "/watch" {
+		return u.Query().Get("v") != ""
+	}
+	for _, prefix := range []string{"/embed/", "/v/", "/shorts/", "/live/"} {
+		if strings.HasPrefix(u.Path, prefix) && strings.Trim(strings.TrimPrefix(u.Path, prefix), "/") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// VideoID extracts the video ID from a YouTube URL. It understands
+// watch?v=ID, youtu.be/ID and the /embed/, /v/, /shorts/ and /live/ path forms.
+func VideoID(rawURL string) string {
+	u := parseVideoURL(rawURL)
+	if u == nil {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	if host == "youtu.be" {
+		return segments[0]
+	}
+	if !youTubeHosts[host] {
+		return ""
+	}
+
+	if u.Path == "/watch" {
+		return u.Query().Get("v")
+	}
+	if len(segments) >= 2 {
+		switch segments[0] {
+		case "embed", "v", "shorts", "live":
+			return segments[1]
+		}
+	}
+	return ""
+}
+
+// TranscriptResult converts a transcription result into a normalized output result
+func TranscriptResult(videoURL string, result *TranscriptionResult) output.Result {
+	videoID := VideoID(videoURL)
+	title := "YouTube Video Transcript"
+	if videoID != "" {
+		title = fmt.Sprintf("YouTube Video Transcript (%s)", videoID)
+	}
+
+	metadata := map[string]string{
+		"Transcribed": time.Now().Format("2006-01-02 15:04:05"),
+		"Duration":    result.Duration.String(),
+	}
+	if result.Language != "" {
+		metadata["Language"] = result.Language
+	}
+	if languages := asr.SegmentLanguages(result.Segments); len(languages) > 1 {
+		metadata["Languages"] = strings.Join(languages, ", ")
+	}
+	if result.Partial {
+		metadata["Partial"] = "transcription timed out after " + TranscribedUntil(result).String()
+	}
+	if result.Channel != "" {
+		metadata["Channel"] = result.Channel
+	}
+
+	transcript := output.NewResult(output.SourceTranscript, videoURL, title, result.Text)
+	transcript.Metadata = metadata
+	return transcript
+}
+
+// TranscribedUntil returns the audio position the transcript reaches, the
+// end of its last segment
+func TranscribedUntil(result *TranscriptionResult) time.Duration {
+	if len(result.Segments) == 0 {
+		return 0
+	}
+	return result.Segments[len(result.Segments)-1].End
+}
+
+// Extractor transcribes YouTube videos for the extractor registry
+type Extractor struct {
+	Config *Config // nil for DefaultConfig
+	Model  string  // whisper model name overriding Config's model, "" to keep it
+}
+
+// Name identifies the YouTube extractor in the extractor registry
+func (e *Extractor) Name() string {
+	return "youtube"
+}
+
+// CanHandle reports whether source is a YouTube video URL
+func (e *Extractor) CanHandle(source string) bool {
+	return IsVideoURL(source)
+}
+
+// Extract downloads the video's audio and transcribes it
+func (e *Extractor) Extract(ctx context.Context, source string) (output.Result, error) {
+	config := DefaultConfig()
+	if e.Config != nil {
+		copied := *e.Config
+		config = &copied
+	}
+	if e.Model != "" {
+		modelPath := FindWhisperModel(e.Model)
+		if modelPath == "" {
+			return output.Result{}, asr.ModelNotFound(e.Model)
+		}
+		asrConfig := asr.DefaultConfig()
+		if config.ASRConfig != nil {
+			*asrConfig = *config.ASRConfig
+		}
+		asrConfig.WhisperModel = modelPath
+		config.ASRConfig = asrConfig
+	}
+
+	result, err := NewService(config).TranscribeYouTubeVideo(ctx, source)
+	if err != nil {
+		return output.Result{}, err
+	}
+	return TranscriptResult(source, result), nil
+}
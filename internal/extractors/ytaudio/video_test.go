@@ -0,0 +1,134 @@
+package ytaudio
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"maai.solutions/gengo/internal/extractors/asr"
+)
+
+func TestIsVideoURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://youtube.com/watch?v=dQw4w9WgXcQ", true},
+		{"https://youtu.be/dQw4w9WgXcQ", true},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", true},
+		{"https://m.youtube.com/watch?v=dQw4w9WgXcQ", true},
+		{"https://youtube.com/embed/dQw4w9WgXcQ", true},
+		{"https://youtube.com/v/dQw4w9WgXcQ", true},
+		{"https://youtube.com/shorts/dQw4w9WgXcQ", true},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ?feature=share", true},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ", true},
+		{"https://music.youtube.com/watch?v=dQw4w9WgXcQ", true},
+		{"https://youtube.com/shorts/", false},
+		{"https://youtube.com/watch", false},
+		{"https://notyoutube.com/watch?v=dQw4w9WgXcQ", false},
+		{"https://example.com/?next=youtube.com/watch?v=abc", false},
+		{"https://", false},
+		{"invalid-url", false},
+		{"https://example.com", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		result := IsVideoURL(test.url)
+		if result != test.expected {
+			t.Errorf("IsVideoURL(%q) = %v, expected %v", test.url, result, test.expected)
+		}
+	}
+}
+
+func TestVideoID(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://youtube.com/watch?v=dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://youtube.com/watch?v=dQw4w9WgXcQ&t=30s", "dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ?t=30", "dQw4w9WgXcQ"},
+		{"https://youtube.com/shorts/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ?feature=share", "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ?si=abc", "dQw4w9WgXcQ"},
+		{"https://youtube.com/embed/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://youtube.com/v/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://music.youtube.com/watch?list=PL1&v=dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://example.com/watch?v=dQw4w9WgXcQ", ""},
+		{"invalid-url", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		result := VideoID(test.url)
+		if result != test.expected {
+			t.Errorf("VideoID(%q) = %q, expected %q", test.url, result, test.expected)
+		}
+	}
+}
+
+func TestTranscriptResultLanguage(t *testing.T) {
+	url := "https://youtu.be/dQw4w9WgXcQ"
+
+	result := TranscriptResult(url, &TranscriptionResult{Text: "hallo", Language: "de"})
+	if result.Metadata["Language"] != "de" {
+		t.Errorf("Expected Language metadata 'de', got %q", result.Metadata["Language"])
+	}
+
+	result = TranscriptResult(url, &TranscriptionResult{Text: "hello"})
+	if _, ok := result.Metadata["Language"]; ok {
+		t.Error("Expected no Language metadata when the language is unknown")
+	}
+}
+
+func TestTranscriptResultLanguages(t *testing.T) {
+	url := "https://youtu.be/dQw4w9WgXcQ"
+
+	result := TranscriptResult(url, &TranscriptionResult{
+		Text:     "hello hallo",
+		Language: "en",
+		Segments: []asr.Segment{{Text: "hello", Language: "en"}, {Text: "hallo", Language: "de"}},
+	})
+	if result.Metadata["Languages"] != "en, de" {
+		t.Errorf("Expected Languages metadata 'en, de', got %q", result.Metadata["Languages"])
+	}
+
+	result = TranscriptResult(url, &TranscriptionResult{
+		Text:     "hello",
+		Language: "en",
+		Segments: []asr.Segment{{Text: "hello", Language: "en"}},
+	})
+	if _, ok := result.Metadata["Languages"]; ok {
+		t.Error("Expected no Languages metadata for a single language")
+	}
+}
+
+func TestTranscriptResultPartial(t *testing.T) {
+	url := "https://youtu.be/dQw4w9WgXcQ"
+
+	result := TranscriptResult(url, &TranscriptionResult{Text: "hello"})
+	if _, ok := result.Metadata["Partial"]; ok {
+		t.Error("Expected no Partial metadata for a complete transcript")
+	}
+
+	partial := &TranscriptionResult{
+		Text: "hello world",
+		Segments: []asr.Segment{
+			{Start: 0, End: 30 * time.Second, Text: "hello"},
+			{Start: 30 * time.Second, End: 42 * time.Second, Text: "world"},
+		},
+		Partial: true,
+	}
+	if until := TranscribedUntil(partial); until != 42*time.Second {
+		t.Errorf("TranscribedUntil = %s, expected 42s", until)
+	}
+	result = TranscriptResult(url, partial)
+	if !strings.Contains(result.Metadata["Partial"], "42s") {
+		t.Errorf("Expected Partial metadata with the transcribed length, got %q", result.Metadata["Partial"])
+	}
+	if result.Content != "hello world" {
+		t.Errorf("Expected the partial text to be kept, got %q", result.Content)
+	}
+}
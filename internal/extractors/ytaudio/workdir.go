@@ -0,0 +1,88 @@
+package ytaudio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"maai.solutions/gengo/internal/output"
+)
+
+// WorkDirPrefix starts the name of every per-run work directory, so that
+// stale ones left by killed runs can be found and removed
+const WorkDirPrefix = "gengo-ytaudio-"
+
+// StaleWorkDirs lists the work directories in dir that were last modified
+// more than olderThan ago, oldest first. An empty dir means os.TempDir().
+func StaleWorkDirs(dir string, olderThan time.Duration) ([]string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	type stale struct {
+		path    string
+		modTime time.Time
+	}
+	var found []stale
+	cutoff := time.Now().Add(-olderThan)
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), WorkDirPrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was read
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			found = append(found, stale{filepath.Join(dir, entry.Name()), info.ModTime()})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.Before(found[j].modTime) })
+	paths := make([]string, len(found))
+	for i, s := range found {
+		paths[i] = s.path
+	}
+	return paths, nil
+}
+
+// moveFile moves a file into dir, copying it when a rename is not possible
+// because dir is on another filesystem, and gives it output.FileMode. It
+// returns the new path.
+func moveFile(path, dir string) (string, error) {
+	dest := filepath.Join(dir, filepath.Base(path))
+	if err := os.Rename(path, dest); err == nil {
+		return dest, os.Chmod(dest, output.FileMode)
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, output.FileMode)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dest)
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dest)
+		return "", err
+	}
+	os.Remove(path)
+	return dest, nil
+}
@@ -0,0 +1,76 @@
+package ytaudio
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"maai.solutions/gengo/internal/output"
+)
+
+func TestStaleWorkDirs(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	mkdir := func(name string, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatalf("Failed to age %s: %v", name, err)
+		}
+		return path
+	}
+
+	oldest := mkdir(WorkDirPrefix+"1", 72*time.Hour)
+	old := mkdir(WorkDirPrefix+"2", 48*time.Hour)
+	mkdir(WorkDirPrefix+"3", time.Minute) // still running
+	mkdir("unrelated", 72*time.Hour)
+	if err := os.WriteFile(filepath.Join(dir, WorkDirPrefix+"file"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	stale, err := StaleWorkDirs(dir, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("StaleWorkDirs failed: %v", err)
+	}
+	if want := []string{oldest, old}; !reflect.DeepEqual(stale, want) {
+		t.Errorf("Expected %v, got %v", want, stale)
+	}
+
+	if _, err := StaleWorkDirs(filepath.Join(dir, "missing"), time.Hour); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	path := filepath.Join(src, "video_1.mp4")
+	if err := os.WriteFile(path, []byte("video"), 0600); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	moved, err := moveFile(path, dst)
+	if err != nil {
+		t.Fatalf("moveFile failed: %v", err)
+	}
+	if moved != filepath.Join(dst, "video_1.mp4") {
+		t.Errorf("Unexpected destination: %s", moved)
+	}
+	if data, err := os.ReadFile(moved); err != nil || string(data) != "video" {
+		t.Errorf("Expected the moved content, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the source to be gone, got %v", err)
+	}
+	info, err := os.Stat(moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != output.FileMode {
+		t.Errorf("Expected the moved file to have mode %o, got %o", output.FileMode, info.Mode().Perm())
+	}
+}
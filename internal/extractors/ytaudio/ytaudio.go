@@ -2,8 +2,10 @@ package ytaudio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,13 +13,18 @@ import (
 
 	"github.com/kkdai/youtube/v2"
 	"maai.solutions/gengo/internal/extractors/asr"
+	"maai.solutions/gengo/internal/httpclient"
+	"maai.solutions/gengo/internal/output"
 )
 
 // Config holds configuration for the YouTube transcription service
 type Config struct {
 	OutputDir    string
-	ASRConfig    *asr.Config // ASR configuration
-	CleanupFiles bool        // whether to delete temporary files
+	TempDir      string           // where per-run work directories are created (default: os.TempDir())
+	ASRConfig    *asr.Config      // ASR configuration
+	ASRPool      *asr.ServicePool // shared services to transcribe with instead of one from ASRConfig
+	CleanupFiles bool             // whether to delete temporary files
+	Retries      int              // extra download attempts after a failed stream
 }
 
 // DefaultConfig returns a default configuration
@@ -26,20 +33,35 @@ func DefaultConfig() *Config {
 		OutputDir:    "/tmp/ytaudio",
 		ASRConfig:    asr.DefaultConfig(),
 		CleanupFiles: true,
+		Retries:      3,
 	}
 }
 
 // TranscriptionResult holds the result of transcription
 type TranscriptionResult struct {
 	Text     string
+	Language string // requested or detected language code
+	Segments []asr.Segment
+	WAVPath  string // converted audio kept with asr.Config.KeepWAV, in OutputDir
+	Video    string // downloaded video kept when CleanupFiles is disabled
 	Duration time.Duration
+	Partial  bool   // ctx ended during transcription; the transcript stops early
+	Channel  string // name of the channel that published the video, when known
 	Error    error
 }
 
 // Service handles YouTube audio transcription
 type Service struct {
 	config     *Config
-	asrService *asr.Service
+	asrService transcriber
+	download   func(ctx context.Context, videoURL, outputPath string) (*youtube.Video, error)
+	httpClient *http.Client // client for YouTube requests, nil for httpclient.Default
+}
+
+// transcriber transcribes an audio or video file, an *asr.Service or a
+// shared *asr.ServicePool
+type transcriber interface {
+	TranscribeAudio(ctx context.Context, inputPath, tempDir string) (*asr.Result, error)
 }
 
 // NewService creates a new transcription service
@@ -47,62 +69,108 @@ func NewService(config *Config) *Service {
 	if config == nil {
 		config = DefaultConfig()
 	}
-	return &Service{
-		config:     config,
-		asrService: asr.NewService(config.ASRConfig),
+	s := &Service{config: config}
+	if config.ASRPool != nil {
+		s.asrService = config.ASRPool
+	} else {
+		s.asrService = asr.NewService(config.ASRConfig)
 	}
+	s.download = s.downloadVideo
+	return s
 }
 
 // TranscribeYouTubeVideo downloads a YouTube video, extracts audio, and transcribes it
-func (s *Service) TranscribeYouTubeVideo(ctx context.Context, videoURL string) (*TranscriptionResult, error) {
+// Each run works in its own directory under TempDir, so concurrent runs never
+// collide, and the directory is always removed. On success the video is moved
+// to OutputDir when CleanupFiles is disabled, as is a WAV kept with
+// asr.Config.KeepWAV; on failure nothing is kept. When ctx ends during
+// transcription, the transcript up to that point is returned with Partial set
+// instead of an error, so long videos that narrowly time out are not lost.
+func (s *Service) TranscribeYouTubeVideo(ctx context.Context, videoURL string) (_ *TranscriptionResult, err error) {
 	start := time.Now()
 
 	// Ensure output directory exists
-	if err := os.MkdirAll(s.config.OutputDir, 0755); err != nil {
+	if err := os.MkdirAll(s.config.OutputDir, output.DirMode); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	workDir, err := os.MkdirTemp(s.config.TempDir, WorkDirPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
 	// Generate unique filename
 	timestamp := time.Now().Unix()
 	baseFilename := fmt.Sprintf("video_%d", timestamp)
-	videoPath := filepath.Join(s.config.OutputDir, baseFilename+".mp4") // Default to mp4
+	videoPath := filepath.Join(workDir, baseFilename+".mp4") // Default to mp4
 
 	// Download video using github.com/kkdai/youtube
-	if err := s.downloadVideo(ctx, videoURL, videoPath); err != nil {
+	video, err := s.download(ctx, videoURL, videoPath)
+	if err != nil {
+		// The reason the video cannot be had is the whole story
+		var videoErr *VideoError
+		if errors.As(err, &videoErr) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to download video: %w", err)
 	}
 
 	// Transcribe audio using ASR service (handles conversion automatically)
-	result, err := s.asrService.TranscribeAudio(ctx, videoPath, s.config.OutputDir)
+	result, err := s.asrService.TranscribeAudio(ctx, videoPath, workDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
 	}
 
-	// Cleanup temporary files if configured
-	if s.config.CleanupFiles {
-		os.Remove(videoPath)
+	transcription := &TranscriptionResult{
+		Text:     strings.TrimSpace(result.Text),
+		Language: result.Language,
+		Segments: result.Segments,
+		Partial:  result.Partial,
+	}
+	if video != nil {
+		transcription.Channel = video.Author
 	}
 
-	duration := time.Since(start)
-	return &TranscriptionResult{
-		Text:     strings.TrimSpace(result.Text),
-		Duration: duration,
-	}, nil
+	// Move what should outlive the work directory to the output directory
+	if !s.config.CleanupFiles {
+		if transcription.Video, err = moveFile(videoPath, s.config.OutputDir); err != nil {
+			return nil, fmt.Errorf("failed to keep downloaded video: %w", err)
+		}
+	}
+	if result.WAVPath != "" {
+		if transcription.WAVPath, err = moveFile(result.WAVPath, s.config.OutputDir); err != nil {
+			return nil, fmt.Errorf("failed to keep converted audio: %w", err)
+		}
+	}
+
+	transcription.Duration = time.Since(start)
+	return transcription, nil
 }
 
-// downloadVideo downloads a YouTube video using github.com/kkdai/youtube library
-func (s *Service) downloadVideo(ctx context.Context, videoURL, outputPath string) error {
-	client := youtube.Client{}
+// downloadVideo downloads a YouTube video using github.com/kkdai/youtube library.
+// It returns the video's details. A partially written file is removed if the
+// download fails or is cancelled.
+func (s *Service) downloadVideo(ctx context.Context, videoURL, outputPath string) (_ *youtube.Video, err error) {
+	httpClient := s.httpClient
+	if httpClient == nil {
+		httpClient = httpclient.Default
+	}
+	client := youtube.Client{HTTPClient: httpClient}
 
-	video, err := client.GetVideo(videoURL)
+	video, err := videoInfo(ctx, &client, videoURL)
 	if err != nil {
-		return fmt.Errorf("failed to get video info: %w", err)
+		var videoErr *VideoError
+		if errors.As(err, &videoErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get video info: %w", err)
 	}
 
 	// Find the best audio format
 	formats := video.Formats.WithAudioChannels()
 	if len(formats) == 0 {
-		return fmt.Errorf("no audio formats found for video")
+		return nil, fmt.Errorf("no audio formats found for video")
 	}
 
 	// Select the best audio format (prefer highest bitrate)
@@ -115,30 +183,43 @@ func (s *Service) downloadVideo(ctx context.Context, videoURL, outputPath string
 	}
 
 	if bestFormat == nil {
-		return fmt.Errorf("no suitable audio format found")
+		return nil, fmt.Errorf("no suitable audio format found")
 	}
 
-	// Download the video/audio stream
-	stream, _, err := client.GetStream(video, bestFormat)
-	if err != nil {
-		return fmt.Errorf("failed to get video stream: %w", err)
-	}
-	defer stream.Close()
-
 	// Create the output file
 	file, err := os.Create(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return nil, fmt.Errorf("failed to create output file: %w", err)
 	}
-	defer file.Close()
+	defer func() {
+		file.Close()
+		if err != nil {
+			os.Remove(outputPath)
+		}
+	}()
 
-	// Copy the stream to the file
-	_, err = io.Copy(file, stream)
-	if err != nil {
-		return fmt.Errorf("failed to copy video: %w", err)
+	// Download the video/audio stream, resuming or restarting it when it breaks off
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, int64, error) {
+		return openStream(ctx, &client, video, bestFormat, offset)
+	}
+	if err := copyWithRetry(ctx, file, open, s.config.Retries); err != nil {
+		return nil, fmt.Errorf("failed to copy video: %w", err)
 	}
 
-	return nil
+	return video, nil
+}
+
+// contextReader stops reading once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
 }
 
 // FindWhisperModel tries to find the whisper model in common locations
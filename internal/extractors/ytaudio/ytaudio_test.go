@@ -1,8 +1,14 @@
 package ytaudio
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/kkdai/youtube/v2"
 	"maai.solutions/gengo/internal/extractors/asr"
 )
 
@@ -24,6 +30,10 @@ func TestDefaultConfig(t *testing.T) {
 	if !config.CleanupFiles {
 		t.Error("Expected default CleanupFiles to be true")
 	}
+
+	if config.Retries != 3 {
+		t.Errorf("Expected default Retries to be 3, got %d", config.Retries)
+	}
 }
 
 func TestNewService(t *testing.T) {
@@ -67,6 +77,64 @@ func TestCheckDependencies(t *testing.T) {
 	}
 }
 
+func TestTranscribeYouTubeVideoCleansUpOnFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		download func(ctx context.Context, videoURL, outputPath string) (*youtube.Video, error)
+	}{
+		{
+			name: "download failure",
+			download: func(ctx context.Context, videoURL, outputPath string) (*youtube.Video, error) {
+				os.WriteFile(outputPath, []byte("partial"), 0644)
+				return nil, errors.New("unexpected EOF")
+			},
+		},
+		{
+			name: "transcription failure",
+			download: func(ctx context.Context, videoURL, outputPath string) (*youtube.Video, error) {
+				return nil, os.WriteFile(outputPath, []byte("not a video"), 0644)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, tempDir := t.TempDir(), t.TempDir()
+			service := NewService(&Config{
+				OutputDir:    dir,
+				TempDir:      tempDir,
+				ASRConfig:    &asr.Config{WhisperModel: filepath.Join(dir, "missing.bin")},
+				CleanupFiles: false,
+			})
+			service.download = test.download
+
+			if _, err := service.TranscribeYouTubeVideo(context.Background(), "https://youtu.be/test"); err == nil {
+				t.Fatal("Expected transcription to fail")
+			}
+
+			for _, d := range []string{dir, tempDir} {
+				entries, err := os.ReadDir(d)
+				if err != nil {
+					t.Fatalf("Failed to read directory: %v", err)
+				}
+				for _, entry := range entries {
+					t.Errorf("Unexpected leftover file: %s", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestContextReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reader := &contextReader{ctx: ctx, r: strings.NewReader("data")}
+	if _, err := reader.Read(make([]byte, 4)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
 // Example of how to test the transcription with a mock or test video
 // This is commented out since it requires actual dependencies and network access
 /*
@@ -0,0 +1,157 @@
+// Package httpclient provides the HTTP client shared by every network
+// feature, so timeouts, the proxy, the user agent and the number of requests
+// in flight are configured once.
+//
+// Commands replace Default from their flags before any request is made;
+// packages send their requests with Default instead of http.DefaultClient,
+// which never times out.
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Defaults applied by DefaultOptions
+const (
+	DefaultTimeout       = 30 * time.Second
+	DefaultUserAgent     = "gengo/0.0.0"
+	DefaultMaxConcurrent = 16
+)
+
+// Options configures a client
+type Options struct {
+	// Timeout bounds connecting to a server and waiting for its response
+	// headers (0: no limit). Reading the body is only bounded by the request
+	// context, so long downloads are not cut off.
+	Timeout time.Duration
+	// Proxy is the URL of an http, https or socks5 proxy, "" to use the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	Proxy string
+	// UserAgent is sent with requests that do not set their own
+	UserAgent string
+	// MaxConcurrent limits the requests in flight at once, across all hosts,
+	// until their response body is closed (0: no limit)
+	MaxConcurrent int
+}
+
+// DefaultOptions returns the options of the Default client
+func DefaultOptions() Options {
+	return Options{
+		Timeout:       DefaultTimeout,
+		UserAgent:     DefaultUserAgent,
+		MaxConcurrent: DefaultMaxConcurrent,
+	}
+}
+
+// Default is the client used for every request
+var Default = mustNew(DefaultOptions())
+
+// NewHTTPClient returns a client with pooled connections configured by opts
+func NewHTTPClient(opts Options) (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", opts.Proxy)
+		}
+		proxy = http.ProxyURL(u)
+	}
+	if opts.Timeout < 0 {
+		return nil, fmt.Errorf("invalid timeout %s: must be 0 or more", opts.Timeout)
+	}
+	if opts.MaxConcurrent < 0 {
+		return nil, fmt.Errorf("invalid concurrency limit %d: must be 0 or more", opts.MaxConcurrent)
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = proxy
+	base.DialContext = (&net.Dialer{Timeout: opts.Timeout, KeepAlive: 30 * time.Second}).DialContext
+	base.TLSHandshakeTimeout = opts.Timeout
+	base.ResponseHeaderTimeout = opts.Timeout
+
+	t := &transport{base: base, userAgent: opts.UserAgent}
+	if opts.MaxConcurrent > 0 {
+		t.slots = make(chan struct{}, opts.MaxConcurrent)
+	}
+	return &http.Client{Transport: t}, nil
+}
+
+// mustNew returns a client for options known to be valid
+func mustNew(opts Options) *http.Client {
+	client, err := NewHTTPClient(opts)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// transport sets the user agent and limits the requests in flight
+type transport struct {
+	base      http.RoundTripper
+	userAgent string
+	slots     chan struct{} // one entry per request in flight, nil for no limit
+}
+
+// RoundTrip waits for a free slot, holding it until the response body is
+// closed, and sends the request
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	if t.slots == nil {
+		return t.base.RoundTrip(req)
+	}
+	if err := t.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.release}
+	return resp, nil
+}
+
+// acquire takes a slot, or fails when ctx ends first
+func (t *transport) acquire(ctx context.Context) error {
+	select {
+	case t.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot
+func (t *transport) release() {
+	<-t.slots
+}
+
+// releasingBody frees its request's slot when closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// Close closes the body and frees the slot, once
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
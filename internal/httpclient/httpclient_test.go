@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClientUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.UserAgent())
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(Options{UserAgent: "tester/1.0"})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+
+	get := func(userAgent string) string {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		if userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if got := get(""); got != "tester/1.0" {
+		t.Errorf("User-Agent = %q, want the configured one", got)
+	}
+	if got := get("custom"); got != "custom" {
+		t.Errorf("User-Agent = %q, want the request's own", got)
+	}
+}
+
+func TestNewHTTPClientMaxConcurrent(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		<-release
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(Options{MaxConcurrent: 2})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+
+	done := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			done <- err
+		}()
+	}
+
+	// Give the requests time to pile up, then let them all finish
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	for i := 0; i < 4; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("Request failed: %v", err)
+		}
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("Peak requests in flight = %d, want 2", got)
+	}
+}
+
+func TestNewHTTPClientWaitHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewHTTPClient(Options{MaxConcurrent: 1})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+	go client.Get(server.URL) // holds the only slot
+
+	time.Sleep(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait for a slot to time out, got %v", err)
+	}
+}
+
+func TestNewHTTPClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewHTTPClient(Options{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Error("Expected a server that never answers to time out")
+	}
+}
+
+func TestNewHTTPClientInvalid(t *testing.T) {
+	for _, opts := range []Options{
+		{Proxy: "not a url"},
+		{Proxy: "ftp://proxy.example.com"},
+		{Timeout: -time.Second},
+		{MaxConcurrent: -1},
+	} {
+		if _, err := NewHTTPClient(opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+
+	if _, err := NewHTTPClient(Options{Proxy: "socks5://127.0.0.1:1080"}); err != nil {
+		t.Errorf("Expected a socks5 proxy to be accepted, got %v", err)
+	}
+}
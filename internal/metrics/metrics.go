@@ -0,0 +1,132 @@
+// Package metrics records extraction counts, failures, sizes and durations.
+//
+// Every build keeps in-process totals for batch summaries. Builds with the
+// "metrics" tag also export them in Prometheus format, which keeps the
+// Prometheus client out of the plain CLI:
+//
+//	go build -tags metrics
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source types used to label extractions
+const (
+	SourceWeb      = "web"
+	SourcePDF      = "pdf"
+	SourceYouTube  = "youtube"
+	SourceAudio    = "audio"
+	SourceDocx     = "docx"
+	SourceEpub     = "epub"
+	SourceMarkdown = "markdown"
+)
+
+// Totals summarizes the extractions of one source type
+type Totals struct {
+	Extractions int
+	Failures    int
+	Bytes       int64
+	Duration    time.Duration
+}
+
+// Recorder accumulates extraction totals by source type
+type Recorder struct {
+	mu     sync.Mutex
+	totals map[string]*Totals
+}
+
+// NewRecorder returns an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{totals: make(map[string]*Totals)}
+}
+
+// Observe records one extraction of the given source type. bytes is the size
+// of the extracted content and err the failure, if any.
+func (r *Recorder) Observe(source string, bytes int, duration time.Duration, err error) {
+	r.mu.Lock()
+	t, ok := r.totals[source]
+	if !ok {
+		t = &Totals{}
+		r.totals[source] = t
+	}
+	t.Extractions++
+	if err != nil {
+		t.Failures++
+	}
+	t.Bytes += int64(bytes)
+	t.Duration += duration
+	r.mu.Unlock()
+
+	export(source, bytes, duration, err)
+}
+
+// Totals returns a copy of the totals for each source type
+func (r *Recorder) Totals() map[string]Totals {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	totals := make(map[string]Totals, len(r.totals))
+	for source, t := range r.totals {
+		totals[source] = *t
+	}
+	return totals
+}
+
+// Summary describes the recorded extractions in one line per source type,
+// followed by an overall total when there is more than one type
+func (r *Recorder) Summary() string {
+	totals := r.Totals()
+	sources := make([]string, 0, len(totals))
+	for source := range totals {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	var b strings.Builder
+	var all Totals
+	for _, source := range sources {
+		t := totals[source]
+		fmt.Fprintf(&b, "  %-9s %s\n", source+":", formatTotals(t))
+		all.Extractions += t.Extractions
+		all.Failures += t.Failures
+		all.Bytes += t.Bytes
+		all.Duration += t.Duration
+	}
+	if len(sources) > 1 {
+		fmt.Fprintf(&b, "  %-9s %s\n", "total:", formatTotals(all))
+	}
+	return b.String()
+}
+
+// formatTotals renders totals as "3 extracted, 1 failed, 12.5 KB in 4.2s"
+func formatTotals(t Totals) string {
+	return fmt.Sprintf("%d extracted, %d failed, %s in %s",
+		t.Extractions-t.Failures, t.Failures, FormatBytes(t.Bytes), t.Duration.Round(100*time.Millisecond))
+}
+
+// FormatBytes renders a byte count with a binary unit, e.g. 12.5 KB
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Default is the process-wide recorder used by the server and batch commands
+var Default = NewRecorder()
+
+// Observe records an extraction on the Default recorder
+func Observe(source string, bytes int, duration time.Duration, err error) {
+	Default.Observe(source, bytes, duration, err)
+}
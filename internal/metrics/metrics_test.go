@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+	r.Observe(SourcePDF, 2048, time.Second, nil)
+	r.Observe(SourcePDF, 0, 500*time.Millisecond, errors.New("broken"))
+	r.Observe(SourceWeb, 100, 200*time.Millisecond, nil)
+
+	totals := r.Totals()
+	pdf := totals[SourcePDF]
+	if pdf.Extractions != 2 || pdf.Failures != 1 || pdf.Bytes != 2048 || pdf.Duration != 1500*time.Millisecond {
+		t.Errorf("Unexpected pdf totals: %+v", pdf)
+	}
+	if web := totals[SourceWeb]; web.Extractions != 1 || web.Failures != 0 {
+		t.Errorf("Unexpected web totals: %+v", web)
+	}
+
+	expected := "" +
+		"  pdf:      1 extracted, 1 failed, 2.0 KB in 1.5s\n" +
+		"  web:      1 extracted, 0 failed, 100 B in 200ms\n" +
+		"  total:    2 extracted, 1 failed, 2.1 KB in 1.7s\n"
+	if summary := r.Summary(); summary != expected {
+		t.Errorf("Unexpected summary:\n%s\nexpected:\n%s", summary, expected)
+	}
+}
+
+func TestSummarySingleSource(t *testing.T) {
+	r := NewRecorder()
+	r.Observe(SourceYouTube, 10, time.Minute, nil)
+
+	if summary := r.Summary(); strings.Contains(summary, "total:") {
+		t.Errorf("Expected no total line for a single source, got:\n%s", summary)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KB",
+		5 * 1024 * 1024: "5.0 MB",
+	}
+	for n, expected := range tests {
+		if got := FormatBytes(n); got != expected {
+			t.Errorf("FormatBytes(%d) = %q, expected %q", n, got, expected)
+		}
+	}
+}
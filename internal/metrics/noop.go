@@ -0,0 +1,19 @@
+//go:build !metrics
+
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// Enabled reports whether metrics are exported in Prometheus format
+const Enabled = false
+
+// export is a no-op without the metrics build tag
+func export(source string, bytes int, duration time.Duration, err error) {}
+
+// Handler returns nil without the metrics build tag
+func Handler() http.Handler {
+	return nil
+}
@@ -0,0 +1,57 @@
+//go:build metrics
+
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	extractionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gengo_extractions_total",
+		Help: "Extractions attempted, by source type.",
+	}, []string{"source"})
+
+	failuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gengo_extraction_failures_total",
+		Help: "Extractions that failed, by source type.",
+	}, []string{"source"})
+
+	bytesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gengo_extracted_bytes_total",
+		Help: "Bytes of extracted content, by source type.",
+	}, []string{"source"})
+
+	// Buckets span quick page fetches up to long transcriptions
+	durationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gengo_extraction_duration_seconds",
+		Help:    "Time taken by extractions and transcriptions, by source type.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 15, 60, 300, 900, 1800, 3600},
+	}, []string{"source"})
+)
+
+func init() {
+	prometheus.MustRegister(extractionsTotal, failuresTotal, bytesTotal, durationSeconds)
+}
+
+// Enabled reports whether metrics are exported in Prometheus format
+const Enabled = true
+
+// export adds an extraction to the Prometheus metrics
+func export(source string, bytes int, duration time.Duration, err error) {
+	extractionsTotal.WithLabelValues(source).Inc()
+	if err != nil {
+		failuresTotal.WithLabelValues(source).Inc()
+	}
+	bytesTotal.WithLabelValues(source).Add(float64(bytes))
+	durationSeconds.WithLabelValues(source).Observe(duration.Seconds())
+}
+
+// Handler serves the metrics in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,28 @@
+//go:build metrics
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	NewRecorder().Observe("handler-test", 512, 2*time.Second, nil)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`gengo_extractions_total{source="handler-test"} 1`,
+		`gengo_extracted_bytes_total{source="handler-test"} 512`,
+		`gengo_extraction_duration_seconds_count{source="handler-test"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in metrics output", want)
+		}
+	}
+}
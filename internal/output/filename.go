@@ -0,0 +1,92 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxFilenameLength is the longest sanitized file name in bytes, leaving room
+// for an extension and the parent path within common filesystem limits
+const MaxFilenameLength = 200
+
+var (
+	invalidFilenameChars = regexp.MustCompile(`[<>:"/\\|?*]`)
+	repeatedDashes       = regexp.MustCompile(`-{2,}`)
+	repeatedSpaces       = regexp.MustCompile(` {2,}`)
+)
+
+// reservedNames are device names Windows refuses to use as file names
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SanitizeFilename turns a title into a file name that is safe on common
+// filesystems. Invalid and control characters become dashes, emoji and other
+// symbols are dropped, runs of dashes and spaces collapse, leading dots and trailing
+// dots are removed, reserved device names get a suffix, and long names are
+// truncated with a short hash of the original so distinct titles stay distinct.
+func SanitizeFilename(name string) string {
+	original := name
+
+	name = invalidFilenameChars.ReplaceAllString(name, "-")
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r):
+			return '-'
+		case isEmoji(r):
+			return -1
+		}
+		return r
+	}, name)
+	name = repeatedDashes.ReplaceAllString(name, "-")
+	name = repeatedSpaces.ReplaceAllString(name, " ")
+	name = strings.TrimSpace(name)
+	name = strings.TrimLeft(name, ". ")
+	name = strings.TrimRight(name, ". ")
+
+	// "CON" and "con.txt" are both reserved, so compare the part before any dot
+	base, _, _ := strings.Cut(name, ".")
+	if reservedNames[strings.ToUpper(strings.TrimSpace(base))] {
+		name += "_"
+	}
+
+	if len(name) > MaxFilenameLength {
+		sum := sha256.Sum256([]byte(original))
+		suffix := "-" + hex.EncodeToString(sum[:])[:8]
+		name = truncateUTF8(name, MaxFilenameLength-len(suffix))
+		name = strings.TrimRight(name, ". -") + suffix
+	}
+
+	return name
+}
+
+// isEmoji reports whether r is an emoji or one of the invisible runes that
+// combine emoji: joiners, variation selectors and skin tone modifiers
+func isEmoji(r rune) bool {
+	switch {
+	case unicode.Is(unicode.So, r), unicode.Is(unicode.Cf, r):
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F, r >= 0x1F3FB && r <= 0x1F3FF:
+		return true
+	}
+	return false
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
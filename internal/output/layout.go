@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Subfolders used by Layout.BySource for the built-in extractors
+const (
+	SourceWeb        = "web"
+	SourcePDF        = "pdf"
+	SourceTranscript = "transcripts"
+)
+
+// Layout arranges the files saved inside a project folder
+type Layout struct {
+	BySource bool // group files by source type, e.g. web/ or transcripts/
+	ByDate   bool // group files by extraction date, e.g. 2024-05-01/
+}
+
+// ProjectsDir is the directory holding project folders when
+// OutputOptions.ProjectRoot is empty
+var ProjectsDir = "."
+
+// ProjectLayout arranges files saved to a project when OutputOptions.Subdir
+// is empty. The default keeps every file directly in the project folder.
+var ProjectLayout Layout
+
+// Subdir returns the folder inside a project, relative to it, for a file of
+// the given source type saved at t. It returns "" for a flat layout.
+func (l Layout) Subdir(sourceType string, t time.Time) string {
+	var parts []string
+	if l.BySource && sourceType != "" {
+		parts = append(parts, sourceType)
+	}
+	if l.ByDate {
+		parts = append(parts, t.Format("2006-01-02"))
+	}
+	return filepath.Join(parts...)
+}
+
+// projectDir returns the project folder a result is saved in
+func projectDir(opts OutputOptions) string {
+	root := opts.ProjectRoot
+	if root == "" {
+		root = ProjectsDir
+	}
+	if root == "" {
+		root = "."
+	}
+	return filepath.Join(root, opts.ProjectName)
+}
+
+// BatchDir returns the folder that keeps the batch state of results written
+// with opts, next to the project manifest: the project folder, or else the
+// output directory. It returns "" for an output file or stdout.
+func BatchDir(opts OutputOptions) string {
+	if opts.ProjectName != "" {
+		return projectDir(opts)
+	}
+	return opts.OutputDir
+}
+
+// projectSubdir returns the folder inside the project for a result
+func projectSubdir(opts OutputOptions, now time.Time) string {
+	if opts.Subdir != "" {
+		return opts.Subdir
+	}
+	return ProjectLayout.Subdir(opts.SourceType, now)
+}
+
+// ParseLayout parses a project layout given as a comma-separated list of
+// "source" and "date". Source folders always contain the date folders.
+// "flat" or an empty string selects the flat layout.
+func ParseLayout(value string) (Layout, error) {
+	var layout Layout
+	for _, part := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "", "flat":
+		case "source":
+			layout.BySource = true
+		case "date":
+			layout.ByDate = true
+		default:
+			return Layout{}, fmt.Errorf("unknown project layout %q (expected flat, source, date or source,date)", part)
+		}
+	}
+	return layout, nil
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"maai.solutions/gengo/internal/project"
+)
+
+func TestLayoutSubdir(t *testing.T) {
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		layout     Layout
+		sourceType string
+		want       string
+	}{
+		{Layout{}, SourceWeb, ""},
+		{Layout{BySource: true}, SourceWeb, "web"},
+		{Layout{BySource: true}, "", ""},
+		{Layout{ByDate: true}, SourcePDF, "2024-05-01"},
+		{Layout{BySource: true, ByDate: true}, SourceTranscript, filepath.Join("transcripts", "2024-05-01")},
+	}
+
+	for _, tt := range tests {
+		if got := tt.layout.Subdir(tt.sourceType, day); got != tt.want {
+			t.Errorf("%+v.Subdir(%q) = %q, want %q", tt.layout, tt.sourceType, got, tt.want)
+		}
+	}
+}
+
+func TestWriteProjectLayout(t *testing.T) {
+	defer func(dir string, layout Layout) { ProjectsDir, ProjectLayout = dir, layout }(ProjectsDir, ProjectLayout)
+	ProjectsDir = t.TempDir()
+	ProjectLayout = Layout{BySource: true, ByDate: true}
+
+	opts := OutputOptions{ProjectName: "proj", SourceType: SourceWeb}
+	path, err := Write(Result{Title: "Page", Content: "body"}, opts)
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	date := time.Now().Format("2006-01-02")
+	want := filepath.Join(ProjectsDir, "proj", "web", date, "Page.md")
+	if path != want {
+		t.Errorf("Write returned %q, want %q", path, want)
+	}
+
+	// The manifest stays at the top of the project and lists the subfolder
+	manifest, err := project.Load(filepath.Join(ProjectsDir, "proj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Entries) != 1 || manifest.Entries[0].File != "web/"+date+"/Page.md" {
+		t.Errorf("Unexpected manifest entries: %+v", manifest.Entries)
+	}
+
+	// Duplicates are found across subfolders
+	opts.SourceType = SourcePDF
+	_, err = Write(Result{Title: "Copy", Content: "body"}, opts)
+	var dup *DuplicateError
+	if !errors.As(err, &dup) || dup.Path != want {
+		t.Errorf("Expected duplicate of %s, got %v", want, err)
+	}
+
+	// An explicit Subdir overrides the layout
+	path, err = Write(Result{Title: "Top", Content: "other"}, OutputOptions{ProjectName: "proj", Subdir: "."})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if path != filepath.Join(ProjectsDir, "proj", "Top.md") {
+		t.Errorf("Write with Subdir returned %q", path)
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Layout
+	}{
+		{"", Layout{}},
+		{"flat", Layout{}},
+		{"source", Layout{BySource: true}},
+		{"date", Layout{ByDate: true}},
+		{"Source, date", Layout{BySource: true, ByDate: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLayout(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLayout(%q) = %+v, %v, want %+v", tt.value, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseLayout("month"); err == nil {
+		t.Error("Expected an error for an unknown layout")
+	}
+}
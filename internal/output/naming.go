@@ -0,0 +1,153 @@
+package output
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// maxNameCounter bounds the search for an unused file name
+const maxNameCounter = 10000
+
+// NameFields are the values a NameTemplate can use, e.g.
+// "{{.Date}}-{{.Title}}" or "{{.Domain}}-{{.ID}}-{{.N}}"
+type NameFields struct {
+	Title  string // result title
+	Date   string // extraction date, 2006-01-02
+	Time   string // extraction time, 15-04-05
+	Type   string // source type, e.g. web, pdf or transcripts
+	ID     string // source id: a YouTube video id, the last URL path segment or the file name without extension
+	Domain string // host of a URL source without www., "" for files
+	N      int    // counter, the lowest number from 1 giving an unused name
+}
+
+// NameTemplate renders file names for saved results from NameFields
+type NameTemplate struct {
+	text string
+	tmpl *template.Template
+}
+
+// NameTemplates name the files saved to a project or output directory when
+// OutputOptions.Filename is empty, keyed by source type with "" applying to
+// every source without a template of its own. Without any, files are named
+// after the result title.
+var NameTemplates map[string]*NameTemplate
+
+// ParseNameTemplate parses a Go template over NameFields, rejecting unknown
+// fields up front rather than when the first file is saved
+func ParseNameTemplate(text string) (*NameTemplate, error) {
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name template %q: %w", text, err)
+	}
+	if err := tmpl.Execute(new(strings.Builder), NameFields{}); err != nil {
+		return nil, fmt.Errorf("invalid name template %q: %w", text, err)
+	}
+	return &NameTemplate{text: text, tmpl: tmpl}, nil
+}
+
+// String returns the template text
+func (t *NameTemplate) String() string {
+	return t.text
+}
+
+// NameTemplateFor returns the template naming files of the given source
+// type, or nil when they are named after their title
+func NameTemplateFor(sourceType string) *NameTemplate {
+	if t, ok := NameTemplates[sourceType]; ok {
+		return t
+	}
+	return NameTemplates[""]
+}
+
+// usesCounter reports whether the template renders .N
+func (t *NameTemplate) usesCounter() bool {
+	a := t.render(NameFields{N: 1})
+	b := t.render(NameFields{N: 2})
+	return a != b
+}
+
+// render executes the template and sanitizes the result into a file name.
+// Errors cannot happen for templates accepted by ParseNameTemplate.
+func (t *NameTemplate) render(fields NameFields) string {
+	var b strings.Builder
+	t.tmpl.Execute(&b, fields)
+	return SanitizeFilename(b.String())
+}
+
+// Name renders the file name, with ext, for a result saved in dir at now.
+// The name is made unique in dir: the counter .N is raised until the name is
+// unused, and a template without .N gets "-2", "-3", ... appended instead.
+// Appending results reuse the name as rendered, since they extend a file.
+func (t *NameTemplate) Name(result Result, dir, ext string, now time.Time, appending bool) string {
+	fields := nameFields(result, now)
+	fields.N = 1
+	name := t.render(fields)
+	if name == "" {
+		return ""
+	}
+	if appending {
+		return name + ext
+	}
+
+	counter := t.usesCounter()
+	for n := 2; n <= maxNameCounter && exists(filepath.Join(dir, name+ext)); n++ {
+		if counter {
+			fields.N = n
+			name = t.render(fields)
+		} else {
+			name = fmt.Sprintf("%s-%d", t.render(fields), n)
+		}
+	}
+	return name + ext
+}
+
+// nameFields describes a result saved at now
+func nameFields(result Result, now time.Time) NameFields {
+	if !result.ExtractedAt.IsZero() {
+		now = result.ExtractedAt
+	}
+	fields := NameFields{
+		Title: strings.TrimSpace(result.Title),
+		Date:  now.Format("2006-01-02"),
+		Time:  now.Format("15-04-05"),
+		Type:  result.SourceType,
+	}
+	if fields.Title == "" {
+		fields.Title = "Untitled"
+	}
+
+	u, err := url.Parse(result.Source)
+	if err == nil && u.Hostname() != "" {
+		fields.Domain = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		switch {
+		case u.Query().Get("v") != "":
+			fields.ID = u.Query().Get("v")
+		case fields.Domain == "youtu.be":
+			fields.ID = strings.Trim(u.Path, "/")
+		default:
+			fields.ID = path.Base(strings.TrimRight(u.Path, "/"))
+			if fields.ID == "." || fields.ID == "/" {
+				fields.ID = ""
+			}
+		}
+		if fields.ID == "" {
+			fields.ID = fields.Domain
+		}
+	} else if result.Source != "" {
+		base := filepath.Base(result.Source)
+		fields.ID = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return fields
+}
+
+// exists reports whether something is at path
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
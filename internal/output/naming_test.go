@@ -0,0 +1,83 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseNameTemplate(t *testing.T) {
+	if _, err := ParseNameTemplate("{{.Date}}-{{.Title}}"); err != nil {
+		t.Errorf("ParseNameTemplate() = %v", err)
+	}
+	for _, text := range []string{"{{.Date", "{{.Author}}-{{.Title}}"} {
+		if _, err := ParseNameTemplate(text); err == nil {
+			t.Errorf("ParseNameTemplate(%q) succeeded, expected an error", text)
+		}
+	}
+}
+
+func TestNameFields(t *testing.T) {
+	extracted := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		source, id, domain string
+	}{
+		{"https://www.youtube.com/watch?v=abc123", "abc123", "youtube.com"},
+		{"https://youtu.be/abc123", "abc123", "youtu.be"},
+		{"https://example.com/blog/post-1/", "post-1", "example.com"},
+		{"https://example.com", "example.com", "example.com"},
+		{"docs/report.pdf", "report", ""},
+	}
+	for _, test := range tests {
+		fields := nameFields(Result{Title: "Report", Source: test.source, SourceType: SourceWeb, ExtractedAt: extracted}, time.Now())
+		if fields.ID != test.id || fields.Domain != test.domain {
+			t.Errorf("nameFields(%q) ID, Domain = %q, %q, expected %q, %q", test.source, fields.ID, fields.Domain, test.id, test.domain)
+		}
+		if fields.Date != "2024-05-01" || fields.Time != "09-30-00" || fields.Type != SourceWeb {
+			t.Errorf("nameFields(%q) = %+v, expected the extraction time and type", test.source, fields)
+		}
+	}
+}
+
+func TestWriteNameTemplate(t *testing.T) {
+	defer func() { NameTemplates = nil }()
+	dated, _ := ParseNameTemplate("{{.Date}}-{{.Title}}")
+	counted, _ := ParseNameTemplate("{{.Domain}}-{{.N}}")
+	NameTemplates = map[string]*NameTemplate{"": dated, SourceWeb: counted}
+
+	dir := t.TempDir()
+	extracted := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	pdf := Result{Title: "Q1: Report", Source: "q1.pdf", SourceType: SourcePDF, Content: "Text", ExtractedAt: extracted}
+	web := Result{Title: "Home", Source: "https://example.com/", SourceType: SourceWeb, Content: "Text", ExtractedAt: extracted}
+
+	tests := []struct {
+		result   Result
+		expected string
+	}{
+		{pdf, "2024-05-01-Q1- Report.md"},
+		{pdf, "2024-05-01-Q1- Report-2.md"},
+		{web, "example.com-1.md"},
+		{web, "example.com-2.md"},
+	}
+	for _, test := range tests {
+		path, err := Write(test.result, OutputOptions{OutputDir: dir})
+		if err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+		if got := filepath.Base(path); got != test.expected {
+			t.Errorf("Write() saved %s, expected %s", got, test.expected)
+		}
+	}
+
+	// An explicit file name still wins
+	path, err := Write(web, OutputOptions{OutputDir: dir, Filename: "home"})
+	if err != nil || filepath.Base(path) != "home.md" {
+		t.Errorf("Write() with a file name = %s, %v", path, err)
+	}
+
+	// Appending extends the file the template names
+	path, err = Write(pdf, OutputOptions{OutputDir: dir, Append: true})
+	if err != nil || filepath.Base(path) != "2024-05-01-Q1- Report.md" {
+		t.Errorf("Write() appending = %s, %v", path, err)
+	}
+}
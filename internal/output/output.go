@@ -0,0 +1,599 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"maai.solutions/gengo/internal/project"
+)
+
+// Format selects how a Result is rendered
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatMarkdown Format = "markdown"
+	FormatJSON     Format = "json"
+
+	// FormatHTML writes Content unchanged as an HTML document. It is only
+	// offered by commands that produce HTML, so ParseFormat does not accept it.
+	FormatHTML Format = "html"
+)
+
+// Result is the normalized content produced by an extractor. Every
+// extractor returns one, so commands, the server and the project manifest
+// handle PDFs, web pages and transcripts alike.
+type Result struct {
+	Title    string            `json:"title"`
+	Source   string            `json:"source"`
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Tags     []string          `json:"tags,omitempty"` // written as front matter in markdown
+
+	// Provenance: the kind of source, e.g. SourceWeb, and when it was
+	// extracted. Both are set by NewResult.
+	SourceType  string    `json:"source_type,omitempty"`
+	ExtractedAt time.Time `json:"extracted_at,omitzero"`
+
+	// Data, when set, is encoded in place of the result for JSON output so
+	// commands can expose a richer, source-specific structure
+	Data interface{} `json:"-"`
+
+	// Raw is the document the content was extracted from, such as the HTML
+	// of a web page, saved next to the output with OutputOptions.SaveRaw
+	// under the extension RawExt, e.g. ".html"
+	Raw    []byte `json:"-"`
+	RawExt string `json:"-"`
+}
+
+// NewResult returns the result of extracting content from source now
+func NewResult(sourceType, source, title, content string) Result {
+	return Result{
+		Title:       title,
+		Source:      source,
+		Content:     content,
+		SourceType:  sourceType,
+		ExtractedAt: time.Now(),
+	}
+}
+
+// OutputOptions describes where and how a Result is written.
+// Destinations are checked in order: ProjectName, OutputFile, OutputDir, stdout.
+type OutputOptions struct {
+	OutputFile  string    // write to this exact file path
+	OutputDir   string    // write to a title-based file inside this directory
+	ProjectName string    // write to a title-based file inside ProjectRoot/ProjectName
+	ProjectRoot string    // parent directory for project folders (default: ProjectsDir)
+	Subdir      string    // folder inside the project, "." for its top level (default: from ProjectLayout)
+	SourceType  string    // kind of source, naming the project subfolder with Layout.BySource (default: the result's)
+	Filename    string    // file name without extension (default: from NameTemplates, or the sanitized title)
+	Format      Format    // output format (default: markdown)
+	Stdout      io.Writer // destination when no file option is set (default: os.Stdout)
+	Append      bool      // append to an existing file after a timestamped delimiter
+	Force       bool      // save to a project even if identical content is already there
+	BodyOnly    bool      // write markdown content without the title and source header
+	SaveRaw     bool      // also save Result.Raw next to the output file, named after it
+	GzipRaw     bool      // gzip the raw document saved with SaveRaw
+
+	// Extraction is recorded with the project manifest entry, together with
+	// BodyOnly, so the entry can be refreshed with the same options
+	Extraction project.Options
+}
+
+// DuplicateError reports that a project already holds identical content
+type DuplicateError struct {
+	File string // existing project file with the same content, relative to the project
+	Path string // path of the existing file
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("identical content is already saved as %s", e.File)
+}
+
+// Plan describes the work a command would do when run with --dry-run
+type Plan struct {
+	Action  string   // what would be done, e.g. "fetch and extract web page"
+	Source  string   // the URL or file the command would read
+	Title   string   // result title when known up front, used to name the output file
+	Details []string // additional lines such as selected pages or models
+}
+
+// ParseFormat validates a format name given on the command line
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case FormatText, "txt":
+		return FormatText, nil
+	case FormatMarkdown, "md", "":
+		return FormatMarkdown, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported output format: %s (expected text, markdown or json)", name)
+	}
+}
+
+// Extension returns the file extension used for a format
+func (f Format) Extension() string {
+	switch f {
+	case FormatText:
+		return ".txt"
+	case FormatJSON:
+		return ".json"
+	case FormatHTML:
+		return ".html"
+	default:
+		return ".md"
+	}
+}
+
+// Tagger, when set, derives tags for results saved to a project that have
+// none of their own
+var Tagger func(content string) []string
+
+// Write renders a result and writes it to the destination selected by opts.
+// It returns the path written, or an empty string for stdout, so callers can
+// report it without recomputing the destination. When opts names several
+// destinations only the first of project, file and directory is written;
+// WriteAll writes to each of them.
+func Write(result Result, opts OutputOptions) (string, error) {
+	if opts.Append && opts.Format == FormatJSON {
+		return "", fmt.Errorf("appending is not supported for json output")
+	}
+	if err := CheckOptions(opts); err != nil {
+		return "", err
+	}
+
+	if opts.ProjectName != "" && len(result.Tags) == 0 && Tagger != nil {
+		result.Tags = Tagger(result.Content)
+	}
+
+	format := opts.Format
+	if opts.BodyOnly && (format == FormatMarkdown || format == "") {
+		// The content is already markdown, only the header block is dropped
+		format = FormatText
+		result.Content = strings.TrimLeft(result.Content, "\n")
+	}
+
+	data, err := Render(result, format)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	path := destination(result, opts, now)
+	if path == "" {
+		stdout := opts.Stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		if _, err := stdout.Write(data); err != nil {
+			return "", fmt.Errorf("failed to write output: %w", err)
+		}
+		return "", nil
+	}
+
+	if opts.ProjectName != "" && !opts.Force && !opts.Append {
+		dir := projectDir(opts)
+		if file, ok := findDuplicate(dir, result.Content); ok {
+			return "", &DuplicateError{File: file, Path: filepath.Join(dir, filepath.FromSlash(file))}
+		}
+	}
+
+	if err := CheckPath(path); err != nil {
+		return "", err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := EnsureDir(dir); err != nil {
+			return "", err
+		}
+	}
+
+	if opts.Append {
+		err = appendFile(path, data, opts.Format, now)
+	} else {
+		err = writeFileAtomic(path, data)
+	}
+	if err != nil {
+		return "", pathError("write file", path, err)
+	}
+
+	rawPath, err := writeRaw(result, opts, path)
+	if err != nil {
+		return "", err
+	}
+
+	// Keep the project manifest in step with the files saved into it. The
+	// manifest sits at the top of the project, with files listed relative to it.
+	if opts.ProjectName != "" {
+		format := opts.Format
+		if format == "" {
+			format = FormatMarkdown
+		}
+		dir := projectDir(opts)
+		file, err := filepath.Rel(dir, path)
+		if err != nil {
+			file = filepath.Base(path)
+		}
+		extractedAt := result.ExtractedAt
+		if extractedAt.IsZero() {
+			extractedAt = now
+		}
+		entry := project.Entry{
+			Source:      result.Source,
+			SourceType:  result.SourceType,
+			Title:       result.Title,
+			File:        filepath.ToSlash(file),
+			Format:      string(format),
+			Hash:        project.HashContent(result.Content),
+			Tags:        result.Tags,
+			ExtractedAt: extractedAt,
+		}
+		if rawPath != "" {
+			if raw, err := filepath.Rel(dir, rawPath); err == nil {
+				entry.Raw = filepath.ToSlash(raw)
+			}
+		}
+		extraction := opts.Extraction
+		extraction.BodyOnly = opts.BodyOnly
+		if extraction != (project.Options{}) {
+			entry.Options = &extraction
+		}
+		if err := project.Record(dir, entry, FileMode); err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
+}
+
+// RawPath returns where the raw document of a result written to path is
+// saved with OutputOptions.SaveRaw: next to it, with the extension replaced
+// by the result's RawExt and ".gz" added when it is gzipped
+func RawPath(result Result, opts OutputOptions, path string) string {
+	raw := strings.TrimSuffix(path, filepath.Ext(path)) + result.RawExt
+	if opts.GzipRaw {
+		raw += ".gz"
+	}
+	return raw
+}
+
+// writeRaw saves the raw document of a result written to path when opts ask
+// for it, returning the path saved or "" when there is nothing to save
+func writeRaw(result Result, opts OutputOptions, path string) (string, error) {
+	if !opts.SaveRaw || len(result.Raw) == 0 {
+		return "", nil
+	}
+	rawPath := RawPath(result, opts, path)
+	if rawPath == path {
+		return "", fmt.Errorf("the raw document would overwrite %s; choose another output format", path)
+	}
+	if err := CheckPath(rawPath); err != nil {
+		return "", err
+	}
+
+	data := result.Raw
+	if opts.GzipRaw {
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		if _, err := zw.Write(data); err != nil {
+			return "", err
+		}
+		if err := zw.Close(); err != nil {
+			return "", err
+		}
+		data = b.Bytes()
+	}
+	if err := writeFileAtomic(rawPath, data); err != nil {
+		return "", pathError("write file", rawPath, err)
+	}
+	return rawPath, nil
+}
+
+// Targets splits options naming several destinations, a project, an output
+// file and an output directory, into one set of options per destination, in
+// that order. Options naming none yield a single stdout target.
+func Targets(opts OutputOptions) []OutputOptions {
+	var targets []OutputOptions
+	if opts.ProjectName != "" {
+		target := opts
+		target.OutputFile, target.OutputDir = "", ""
+		targets = append(targets, target)
+	}
+	if opts.OutputFile != "" {
+		target := opts
+		target.ProjectName, target.OutputDir = "", ""
+		targets = append(targets, target)
+	}
+	if opts.OutputDir != "" {
+		target := opts
+		target.ProjectName, target.OutputFile = "", ""
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		targets = append(targets, opts)
+	}
+	return targets
+}
+
+// WriteAll writes a result to every destination opts names, see Targets,
+// and returns the paths written. A project that already holds the content
+// does not stop the other destinations from being written; its
+// DuplicateError is returned once they are. Any other error stops at the
+// failing destination, returning the paths written before it.
+func WriteAll(result Result, opts OutputOptions) ([]string, error) {
+	var paths []string
+	var dupErr error
+	for _, target := range Targets(opts) {
+		path, err := Write(result, target)
+		var dup *DuplicateError
+		if errors.As(err, &dup) {
+			dupErr = err
+			continue
+		}
+		if err != nil {
+			return paths, err
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, dupErr
+}
+
+// findDuplicate looks up content in a project's manifest and returns the
+// existing file holding it, ignoring entries whose file has been removed
+func findDuplicate(dir, content string) (string, bool) {
+	manifest, err := project.Load(dir)
+	if err != nil {
+		return "", false
+	}
+
+	entry, ok := manifest.FindHash(project.HashContent(content))
+	if !ok {
+		return "", false
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(entry.File))); err != nil {
+		return "", false
+	}
+	return entry.File, true
+}
+
+// appendFile appends data to path, creating it if needed. When the file
+// already has content a timestamped delimiter separates the new entry.
+func appendFile(path string, data []byte, format Format, now time.Time) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, FileMode)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err == nil && info.Size() > 0 {
+		_, err = io.WriteString(f, appendDelimiter(format, now))
+	}
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// appendDelimiter returns the separator written between appended entries
+func appendDelimiter(format Format, now time.Time) string {
+	stamp := now.Format("2006-01-02 15:04:05")
+	if format == FormatText {
+		return fmt.Sprintf("\n===== Appended %s =====\n\n", stamp)
+	}
+	return fmt.Sprintf("\n---\n\n<!-- Appended %s -->\n\n", stamp)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so an interrupted write never leaves a half-written output
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(FileMode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Destination returns the file path a result will be written to, or an
+// empty string when it goes to stdout. Project files in a date-based layout
+// are placed by the current date; Write returns the path actually used.
+func Destination(result Result, opts OutputOptions) string {
+	return destination(result, opts, time.Now())
+}
+
+// Destinations returns the file paths WriteAll writes a result to, or none
+// when it goes to stdout
+func Destinations(result Result, opts OutputOptions) []string {
+	now := time.Now()
+	var paths []string
+	for _, target := range Targets(opts) {
+		if path := destination(result, target, now); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// destination is Destination for a result saved at now
+func destination(result Result, opts OutputOptions, now time.Time) string {
+	if opts.SourceType == "" {
+		opts.SourceType = result.SourceType
+	}
+
+	var dir string
+	switch {
+	case opts.ProjectName != "":
+		dir = filepath.Join(projectDir(opts), projectSubdir(opts, now))
+	case opts.OutputFile != "":
+		return opts.OutputFile
+	case opts.OutputDir != "":
+		dir = opts.OutputDir
+	default:
+		return ""
+	}
+	return filepath.Join(dir, filename(result, opts, dir, now))
+}
+
+// filename names the file a result is saved as in dir: opts.Filename, the
+// name template for its source type, or its sanitized title
+func filename(result Result, opts OutputOptions, dir string, now time.Time) string {
+	ext := opts.Format.Extension()
+	if opts.Filename != "" {
+		return opts.Filename + ext
+	}
+	if t := NameTemplateFor(opts.SourceType); t != nil {
+		result.SourceType = opts.SourceType
+		if name := t.Name(result, dir, ext, now, opts.Append); name != "" {
+			return name
+		}
+	}
+
+	name := SanitizeFilename(result.Title)
+	if name == "" {
+		name = "Untitled"
+	}
+	return name + ext
+}
+
+// WritePlan prints a plan and the destinations it would be written to,
+// without fetching, extracting or writing anything
+func WritePlan(plan Plan, opts OutputOptions) error {
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if err := CheckOptions(opts); err != nil {
+		return err
+	}
+
+	// Titles usually come from the content itself, so show a placeholder,
+	// or the name template that will name the file
+	if plan.Title == "" && opts.Filename == "" {
+		opts.Filename = "<title>"
+		if t := NameTemplateFor(opts.SourceType); t != nil {
+			opts.Filename = t.String()
+		}
+	}
+	format := opts.Format
+	if format == "" {
+		format = FormatMarkdown
+	}
+	dest := strings.Join(Destinations(Result{Title: plan.Title}, opts), ", ")
+	if dest == "" {
+		dest = "stdout"
+	}
+
+	var b strings.Builder
+	b.WriteString("Dry run: nothing will be downloaded or written\n")
+	fmt.Fprintf(&b, "  Action: %s\n", plan.Action)
+	fmt.Fprintf(&b, "  Source: %s\n", plan.Source)
+	for _, detail := range plan.Details {
+		fmt.Fprintf(&b, "  %s\n", detail)
+	}
+	fmt.Fprintf(&b, "  Output: %s (%s)\n", dest, format)
+
+	if _, err := io.WriteString(stdout, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// Render converts a result to bytes in the given format
+func Render(result Result, format Format) ([]byte, error) {
+	switch format {
+	case FormatText:
+		return []byte(ensureNewline(result.Content)), nil
+
+	case FormatJSON:
+		var v interface{} = result
+		if result.Data != nil {
+			v = result.Data
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return append(data, '\n'), nil
+
+	case FormatHTML:
+		return []byte(result.Content), nil
+
+	case FormatMarkdown, "":
+		return []byte(renderMarkdown(result)), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// renderMarkdown formats a result as a markdown document with a header block,
+// preceded by YAML front matter when the result has tags
+func renderMarkdown(result Result) string {
+	title := result.Title
+	if title == "" {
+		title = "Untitled"
+	}
+
+	var b strings.Builder
+	if len(result.Tags) > 0 {
+		// JSON strings are valid YAML flow scalars and escape anything unusual
+		tags := make([]string, 0, len(result.Tags))
+		for _, tag := range result.Tags {
+			quoted, _ := json.Marshal(tag)
+			tags = append(tags, string(quoted))
+		}
+		fmt.Fprintf(&b, "---\ntags: [%s]\n---\n\n", strings.Join(tags, ", "))
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if result.Source != "" {
+		fmt.Fprintf(&b, "**Source:** %s  \n", result.Source)
+	}
+
+	keys := make([]string, 0, len(result.Metadata))
+	for key := range result.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "**%s:** %s  \n", key, result.Metadata[key])
+	}
+
+	b.WriteString("\n---\n\n")
+	b.WriteString(ensureNewline(strings.TrimLeft(result.Content, "\n")))
+
+	return b.String()
+}
+
+// ensureNewline makes sure non-empty output ends with a newline
+func ensureNewline(s string) string {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		return s + "\n"
+	}
+	return s
+}
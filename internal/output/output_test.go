@@ -0,0 +1,498 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"maai.solutions/gengo/internal/project"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+		wantErr  bool
+	}{
+		{"text", FormatText, false},
+		{"txt", FormatText, false},
+		{"markdown", FormatMarkdown, false},
+		{"MD", FormatMarkdown, false},
+		{"", FormatMarkdown, false},
+		{"json", FormatJSON, false},
+		{"xml", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := ParseFormat(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+		}
+		if result != test.expected {
+			t.Errorf("ParseFormat(%q) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestDestination(t *testing.T) {
+	result := Result{Title: "My: Page"}
+
+	tests := []struct {
+		name     string
+		opts     OutputOptions
+		expected string
+	}{
+		{"stdout", OutputOptions{}, ""},
+		{"output file", OutputOptions{OutputFile: "out.txt", OutputDir: "dir"}, "out.txt"},
+		{"output dir", OutputOptions{OutputDir: "dir", Format: FormatJSON}, filepath.Join("dir", "My- Page.json")},
+		{"project", OutputOptions{ProjectName: "proj", OutputFile: "out.txt"}, filepath.Join(".", "proj", "My- Page.md")},
+		{"project root", OutputOptions{ProjectName: "proj", ProjectRoot: "root", Filename: "custom", Format: FormatText}, filepath.Join("root", "proj", "custom.txt")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if path := Destination(result, test.opts); path != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, path)
+			}
+		})
+	}
+}
+
+func TestWriteDuplicate(t *testing.T) {
+	root := t.TempDir()
+	opts := OutputOptions{ProjectName: "proj", ProjectRoot: root}
+
+	if _, err := Write(Result{Title: "First", Content: "same"}, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	_, err := Write(Result{Title: "Second", Content: "same"}, opts)
+	var dup *DuplicateError
+	if !errors.As(err, &dup) || dup.File != "First.md" {
+		t.Fatalf("Expected duplicate of First.md, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "proj", "Second.md")); !os.IsNotExist(err) {
+		t.Error("Expected duplicate not to be written")
+	}
+
+	opts.Force = true
+	if _, err := Write(Result{Title: "Second", Content: "same"}, opts); err != nil {
+		t.Fatalf("Forced write failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "proj", "Second.md")); err != nil {
+		t.Errorf("Expected forced duplicate to be written: %v", err)
+	}
+
+	// Content whose file was deleted is no longer a duplicate
+	os.Remove(filepath.Join(root, "proj", "First.md"))
+	os.Remove(filepath.Join(root, "proj", "Second.md"))
+	opts.Force = false
+	if _, err := Write(Result{Title: "Third", Content: "same"}, opts); err != nil {
+		t.Errorf("Expected write after removing duplicates to succeed: %v", err)
+	}
+}
+
+func TestWriteAll(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "projects")
+	opts := OutputOptions{
+		OutputFile:  filepath.Join(dir, "a.md"),
+		OutputDir:   filepath.Join(dir, "out"),
+		ProjectName: "corpus",
+		ProjectRoot: root,
+	}
+	result := Result{Title: "Page", Source: "https://example.com", Content: "body"}
+
+	expected := []string{
+		filepath.Join(root, "corpus", "Page.md"),
+		filepath.Join(dir, "a.md"),
+		filepath.Join(dir, "out", "Page.md"),
+	}
+	if dests := Destinations(result, opts); !reflect.DeepEqual(dests, expected) {
+		t.Errorf("Destinations = %v, expected %v", dests, expected)
+	}
+
+	paths, err := WriteAll(result, opts)
+	if err != nil {
+		t.Fatalf("WriteAll failed: %v", err)
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("WriteAll wrote %v, expected %v", paths, expected)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be written: %v", path, err)
+		}
+	}
+
+	// A duplicate in the project still lets the file be written
+	os.Remove(opts.OutputFile)
+	opts.OutputDir = ""
+	paths, err = WriteAll(Result{Title: "Again", Content: "body"}, opts)
+	var dup *DuplicateError
+	if !errors.As(err, &dup) {
+		t.Fatalf("Expected a duplicate error, got %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{opts.OutputFile}) {
+		t.Errorf("Expected only the output file to be written, got %v", paths)
+	}
+
+	// Without destinations the result goes to stdout
+	var buf bytes.Buffer
+	paths, err = WriteAll(result, OutputOptions{Stdout: &buf})
+	if err != nil || len(paths) != 0 || !strings.Contains(buf.String(), "body") {
+		t.Errorf("Expected stdout output only, got paths %v, err %v, output %q", paths, err, buf.String())
+	}
+}
+
+func TestWriteAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes", "notes.md")
+	opts := OutputOptions{OutputFile: path, Format: FormatMarkdown, Append: true}
+
+	for _, title := range []string{"First", "Second"} {
+		if _, err := Write(Result{Title: title, Content: title + " body"}, opts); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected appended file to exist: %v", err)
+	}
+	content := string(data)
+
+	if !strings.HasPrefix(content, "# First\n") {
+		t.Errorf("Expected file to start with the first entry, got:\n%s", content)
+	}
+	if strings.Count(content, "<!-- Appended ") != 1 {
+		t.Errorf("Expected one delimiter between two entries, got:\n%s", content)
+	}
+	if first, second := strings.Index(content, "First body"), strings.Index(content, "# Second"); first < 0 || second < first {
+		t.Errorf("Expected entries in order, got:\n%s", content)
+	}
+
+	if _, err := Write(Result{Title: "JSON"}, OutputOptions{OutputFile: path, Format: FormatJSON, Append: true}); err == nil {
+		t.Error("Expected appending JSON to fail")
+	}
+}
+
+func TestAppendDelimiter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	if got := appendDelimiter(FormatText, now); got != "\n===== Appended 2024-05-01 09:30:00 =====\n\n" {
+		t.Errorf("Unexpected text delimiter: %q", got)
+	}
+	if got := appendDelimiter(FormatMarkdown, now); got != "\n---\n\n<!-- Appended 2024-05-01 09:30:00 -->\n\n" {
+		t.Errorf("Unexpected markdown delimiter: %q", got)
+	}
+}
+
+func TestWritePlan(t *testing.T) {
+	tests := []struct {
+		name string
+		plan Plan
+		opts OutputOptions
+		want []string
+	}{
+		{
+			"stdout",
+			Plan{Action: "fetch and extract web page", Source: "https://example.com"},
+			OutputOptions{},
+			[]string{"Dry run", "Action: fetch and extract web page", "Source: https://example.com", "Output: stdout (markdown)"},
+		},
+		{
+			"project with unknown title",
+			Plan{Action: "extract", Source: "https://example.com"},
+			OutputOptions{ProjectName: "proj", Format: FormatJSON},
+			[]string{"Output: " + filepath.Join(".", "proj", "<title>.json") + " (json)"},
+		},
+		{
+			"several destinations",
+			Plan{Action: "extract", Source: "doc.pdf", Title: "doc"},
+			OutputOptions{ProjectName: "proj", OutputFile: "a.md"},
+			[]string{"Output: " + filepath.Join(".", "proj", "doc.md") + ", a.md (markdown)"},
+		},
+		{
+			"known title and details",
+			Plan{Action: "extract PDF text", Source: "doc.pdf", Title: "doc", Details: []string{"Pages: 1, 2"}},
+			OutputOptions{OutputDir: "out", Format: FormatText},
+			[]string{"Pages: 1, 2", "Output: " + filepath.Join("out", "doc.txt") + " (text)"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			test.opts.Stdout = &buf
+			if err := WritePlan(test.plan, test.opts); err != nil {
+				t.Fatalf("WritePlan failed: %v", err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Expected plan to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	result := Result{
+		Title:    "Test",
+		Source:   "https://example.com",
+		Content:  "Body text",
+		Metadata: map[string]string{"Duration": "1s"},
+	}
+
+	text, err := Render(result, FormatText)
+	if err != nil {
+		t.Fatalf("Render text failed: %v", err)
+	}
+	if string(text) != "Body text\n" {
+		t.Errorf("Unexpected text output: %q", text)
+	}
+
+	markdown, err := Render(result, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Render markdown failed: %v", err)
+	}
+	for _, want := range []string{"# Test\n", "**Source:** https://example.com", "**Duration:** 1s", "---", "Body text"} {
+		if !strings.Contains(string(markdown), want) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+
+	data, err := Render(result, FormatJSON)
+	if err != nil {
+		t.Fatalf("Render json failed: %v", err)
+	}
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if decoded.Title != "Test" || decoded.Content != "Body text" || decoded.Metadata["Duration"] != "1s" {
+		t.Errorf("Unexpected decoded result: %+v", decoded)
+	}
+}
+
+func TestRenderJSONData(t *testing.T) {
+	result := Result{Title: "Test", Content: "Body", Data: map[string]int{"count": 2}}
+
+	data, err := Render(result, FormatJSON)
+	if err != nil {
+		t.Fatalf("Render json failed: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "{\n  \"count\": 2\n}" {
+		t.Errorf("Expected Data to be encoded, got:\n%s", data)
+	}
+
+	markdown, err := Render(result, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Render markdown failed: %v", err)
+	}
+	if !strings.Contains(string(markdown), "Body") {
+		t.Errorf("Expected markdown to ignore Data, got:\n%s", markdown)
+	}
+}
+
+func TestNewResultProvenance(t *testing.T) {
+	defer func(dir string, layout Layout) { ProjectsDir, ProjectLayout = dir, layout }(ProjectsDir, ProjectLayout)
+	ProjectsDir = t.TempDir()
+	ProjectLayout = Layout{BySource: true}
+
+	before := time.Now()
+	result := NewResult(SourcePDF, "report.pdf", "Report", "body")
+	if result.SourceType != SourcePDF || result.ExtractedAt.Before(before) {
+		t.Errorf("NewResult provenance = %q at %v", result.SourceType, result.ExtractedAt)
+	}
+
+	data, err := Render(result, FormatJSON)
+	if err != nil {
+		t.Fatalf("Render json failed: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["source_type"] != SourcePDF || decoded["extracted_at"] == nil {
+		t.Errorf("Expected provenance in JSON, got:\n%s", data)
+	}
+
+	// A result without provenance leaves it out of the JSON
+	data, err = Render(Result{Title: "Plain"}, FormatJSON)
+	if err != nil {
+		t.Fatalf("Render json failed: %v", err)
+	}
+	if strings.Contains(string(data), "source_type") || strings.Contains(string(data), "extracted_at") {
+		t.Errorf("Expected no provenance in JSON, got:\n%s", data)
+	}
+
+	// The result's source type places it in the layout and is recorded in
+	// the manifest when the options do not name one
+	path, err := Write(result, OutputOptions{ProjectName: "proj"})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if want := filepath.Join(ProjectsDir, "proj", "pdf", "Report.md"); path != want {
+		t.Errorf("Write returned %q, want %q", path, want)
+	}
+	manifest, err := project.Load(filepath.Join(ProjectsDir, "proj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Entries) != 1 {
+		t.Fatalf("Expected 1 manifest entry, got %+v", manifest.Entries)
+	}
+	if entry := manifest.Entries[0]; entry.SourceType != SourcePDF || !entry.ExtractedAt.Equal(result.ExtractedAt) {
+		t.Errorf("Unexpected manifest entry: %+v", entry)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	result := Result{Title: "Doc", Content: "hello"}
+
+	var buf bytes.Buffer
+	if _, err := Write(result, OutputOptions{Format: FormatText, Stdout: &buf}); err != nil {
+		t.Fatalf("Write to stdout failed: %v", err)
+	}
+	if buf.String() != "hello\n" {
+		t.Errorf("Unexpected stdout output: %q", buf.String())
+	}
+
+	root := t.TempDir()
+	opts := OutputOptions{ProjectName: "proj", ProjectRoot: root, Format: FormatText}
+	if _, err := Write(result, opts); err != nil {
+		t.Fatalf("Write to project failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "proj", "Doc.txt"))
+	if err != nil {
+		t.Fatalf("Expected project file to exist: %v", err)
+	}
+	if string(data) != "hello\n" {
+		t.Errorf("Unexpected file content: %q", data)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, "proj"))
+	if err != nil {
+		t.Fatalf("Failed to read project directory: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected the output file and manifest, found %d entries", len(entries))
+	}
+
+	manifest, err := project.Load(filepath.Join(root, "proj"))
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	if len(manifest.Entries) != 1 || manifest.Entries[0].File != "Doc.txt" || manifest.Entries[0].Format != "text" {
+		t.Errorf("Unexpected manifest entries: %+v", manifest.Entries)
+	}
+}
+
+func TestWriteRaw(t *testing.T) {
+	raw := []byte("<html><body><p>hello</p></body></html>")
+	result := Result{Title: "Doc", Content: "hello", Raw: raw, RawExt: ".html"}
+	root := t.TempDir()
+
+	opts := OutputOptions{ProjectName: "proj", ProjectRoot: root, SaveRaw: true}
+	path, err := Write(result, opts)
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "proj", "Doc.html")); err != nil || !bytes.Equal(data, raw) {
+		t.Errorf("Expected the raw HTML next to %s, got %q, %v", path, data, err)
+	}
+	manifest, err := project.Load(filepath.Join(root, "proj"))
+	if err != nil || len(manifest.Entries) != 1 || manifest.Entries[0].Raw != "Doc.html" {
+		t.Errorf("Expected the raw file in the manifest, got %+v, %v", manifest, err)
+	}
+
+	// Gzipped next to an output file
+	file := filepath.Join(root, "page.md")
+	if _, err := Write(result, OutputOptions{OutputFile: file, SaveRaw: true, GzipRaw: true}); err != nil {
+		t.Fatalf("Write gzipped failed: %v", err)
+	}
+	f, err := os.Open(filepath.Join(root, "page.html.gz"))
+	if err != nil {
+		t.Fatalf("Expected the gzipped raw HTML: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Expected gzip data: %v", err)
+	}
+	var unzipped bytes.Buffer
+	if _, err := unzipped.ReadFrom(zr); err != nil || !bytes.Equal(unzipped.Bytes(), raw) {
+		t.Errorf("Unexpected gzipped raw HTML: %q, %v", unzipped.Bytes(), err)
+	}
+
+	// The raw document must not replace the output itself
+	if _, err := Write(result, OutputOptions{OutputFile: filepath.Join(root, "page.html"), Format: FormatHTML, SaveRaw: true}); err == nil {
+		t.Error("Expected an error when the raw document would overwrite the output")
+	}
+}
+
+func TestWriteProjectTags(t *testing.T) {
+	root := t.TempDir()
+	defer func(tagger func(string) []string) { Tagger = tagger }(Tagger)
+	Tagger = func(content string) []string { return []string{"first tag", `say "hi"`} }
+
+	if _, err := Write(Result{Title: "Tagged", Content: "body"}, OutputOptions{ProjectName: "proj", ProjectRoot: root}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "proj", "Tagged.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ntags: [\"first tag\", \"say \\\"hi\\\"\"]\n---\n\n# Tagged\n"
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("Expected front matter %q, got:\n%s", want, data)
+	}
+
+	manifest, err := project.Load(filepath.Join(root, "proj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Entries) != 1 || len(manifest.Entries[0].Tags) != 2 {
+		t.Errorf("Expected tags in the manifest, got %+v", manifest.Entries)
+	}
+
+	// Results outside a project are not tagged
+	var b strings.Builder
+	if _, err := Write(Result{Title: "Plain", Content: "body"}, OutputOptions{Stdout: &b}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if strings.HasPrefix(b.String(), "---") {
+		t.Errorf("Expected no front matter on stdout, got:\n%s", b.String())
+	}
+}
+
+func TestWriteBodyOnly(t *testing.T) {
+	result := Result{Title: "Doc", Source: "https://example.com", Content: "\n## Intro\n\nhello"}
+
+	var b strings.Builder
+	if _, err := Write(result, OutputOptions{Stdout: &b, BodyOnly: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if b.String() != "## Intro\n\nhello\n" {
+		t.Errorf("Expected only the content, got %q", b.String())
+	}
+
+	// JSON keeps its fields
+	b.Reset()
+	if _, err := Write(result, OutputOptions{Stdout: &b, Format: FormatJSON, BodyOnly: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(b.String(), `"title": "Doc"`) {
+		t.Errorf("Expected JSON with the title, got:\n%s", b.String())
+	}
+}
@@ -0,0 +1,128 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BaseDir, when set, confines every path gengo writes to this directory.
+// Servers and automated batch runs set it from --base-dir so output options
+// taken from requests or job files cannot reach elsewhere, e.g. with
+// "../../etc". Interactive use leaves it empty and may write anywhere.
+var BaseDir string
+
+// ErrOutsideBase reports an output path that leaves BaseDir
+var ErrOutsideBase = errors.New("output path is outside the allowed directory")
+
+// CheckPath reports whether path may be written to: it must be non-empty,
+// free of NUL bytes and, when BaseDir is set, inside BaseDir once relative
+// segments and symbolic links are resolved.
+func CheckPath(path string) error {
+	if path == "" {
+		return errors.New("output path is empty")
+	}
+	if strings.ContainsRune(path, 0) {
+		return fmt.Errorf("output path %q contains a NUL byte", path)
+	}
+	if BaseDir == "" {
+		return nil
+	}
+
+	base, err := resolvePath(BaseDir)
+	if err != nil {
+		return err
+	}
+	target, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(base, target); err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w: %s is not inside %s", ErrOutsideBase, path, BaseDir)
+	}
+	return nil
+}
+
+// CheckOptions validates the destinations named by opts before anything is
+// extracted or written. Project names, project subfolders and file names
+// must stay inside the projects directory even without BaseDir, since they
+// only ever name folders and files within it.
+func CheckOptions(opts OutputOptions) error {
+	if opts.ProjectName != "" {
+		if !filepath.IsLocal(opts.ProjectName) {
+			return fmt.Errorf("invalid project name %q: it must name a folder inside the projects directory", opts.ProjectName)
+		}
+		if opts.Subdir != "" && !filepath.IsLocal(opts.Subdir) {
+			return fmt.Errorf("invalid project subfolder %q: it must be inside the project", opts.Subdir)
+		}
+	}
+	if opts.Filename != "" && (!filepath.IsLocal(opts.Filename) || strings.ContainsAny(opts.Filename, `/\`)) {
+		return fmt.Errorf("invalid file name %q: it must not contain path separators", opts.Filename)
+	}
+
+	for _, target := range Targets(opts) {
+		var path string
+		switch {
+		case target.ProjectName != "":
+			path = projectDir(target)
+		case target.OutputFile != "":
+			path = target.OutputFile
+		case target.OutputDir != "":
+			path = target.OutputDir
+		default:
+			continue
+		}
+		if err := CheckPath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureDir checks dir with CheckPath and creates it and its parents with
+// DirMode, explaining permission problems rather than passing on a bare
+// system error
+func EnsureDir(dir string) error {
+	if err := CheckPath(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, DirMode); err != nil {
+		return pathError("create output directory", dir, err)
+	}
+	return nil
+}
+
+// pathError describes a failure to act on path, with a hint for the
+// common permission case
+func pathError(action, path string, err error) error {
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("cannot %s %s: permission denied (check that you can write to %s)", action, path, filepath.Dir(filepath.Clean(path)))
+	}
+	return fmt.Errorf("failed to %s %s: %w", action, path, err)
+}
+
+// resolvePath returns path made absolute, with symbolic links resolved in the
+// part of it that already exists, so a link inside BaseDir pointing out of it
+// is caught before the rest of the path is created
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output path %s: %w", path, err)
+	}
+
+	existing, rest := abs, ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return abs, nil
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
@@ -0,0 +1,137 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withBaseDir(t *testing.T, dir string) {
+	t.Helper()
+	previous := BaseDir
+	BaseDir = dir
+	t.Cleanup(func() { BaseDir = previous })
+}
+
+func TestCheckPath(t *testing.T) {
+	base := t.TempDir()
+	withBaseDir(t, base)
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{filepath.Join(base, "out.md"), true},
+		{filepath.Join(base, "notes", "new", "out.md"), true},
+		{base, true},
+		{filepath.Join(base, "notes", "..", "out.md"), true},
+		{filepath.Join(base, "..", "out.md"), false},
+		{filepath.Join(base, "..", "..", "etc", "passwd"), false},
+		{filepath.Join(base+"-other", "out.md"), false},
+		{"/etc/passwd", false},
+	}
+	for _, test := range tests {
+		err := CheckPath(test.path)
+		if test.allowed && err != nil {
+			t.Errorf("CheckPath(%q) = %v, expected it to be allowed", test.path, err)
+		}
+		if !test.allowed && !errors.Is(err, ErrOutsideBase) {
+			t.Errorf("CheckPath(%q) = %v, expected ErrOutsideBase", test.path, err)
+		}
+	}
+
+	if err := CheckPath("out\x00.md"); err == nil {
+		t.Error("Expected an error for a path with a NUL byte")
+	}
+}
+
+func TestCheckPathSymlinkEscape(t *testing.T) {
+	base, outside := t.TempDir(), t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(base, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	withBaseDir(t, base)
+
+	if err := CheckPath(filepath.Join(base, "link", "out.md")); !errors.Is(err, ErrOutsideBase) {
+		t.Errorf("Expected a link out of the base directory to be rejected, got %v", err)
+	}
+}
+
+func TestCheckPathWithoutBase(t *testing.T) {
+	withBaseDir(t, "")
+
+	// Interactive use may write anywhere
+	if err := CheckPath(filepath.Join("..", "out.md")); err != nil {
+		t.Errorf("Expected paths to be allowed without a base directory, got %v", err)
+	}
+}
+
+func TestCheckOptions(t *testing.T) {
+	withBaseDir(t, "")
+	root := t.TempDir()
+
+	tests := []struct {
+		name    string
+		opts    OutputOptions
+		wantErr bool
+	}{
+		{"project", OutputOptions{ProjectName: "research", ProjectRoot: root}, false},
+		{"nested project", OutputOptions{ProjectName: "clients/acme", ProjectRoot: root}, false},
+		{"project traversal", OutputOptions{ProjectName: "../../etc", ProjectRoot: root}, true},
+		{"absolute project", OutputOptions{ProjectName: "/etc", ProjectRoot: root}, true},
+		{"subdir traversal", OutputOptions{ProjectName: "research", ProjectRoot: root, Subdir: "../.."}, true},
+		{"file name with separator", OutputOptions{OutputDir: root, Filename: "../passwd"}, true},
+		{"output file", OutputOptions{OutputFile: filepath.Join("..", "out.md")}, false},
+	}
+	for _, test := range tests {
+		if err := CheckOptions(test.opts); (err != nil) != test.wantErr {
+			t.Errorf("%s: CheckOptions() error = %v, wantErr %v", test.name, err, test.wantErr)
+		}
+	}
+
+	// With a base directory every destination must stay inside it
+	withBaseDir(t, root)
+	if err := CheckOptions(OutputOptions{OutputDir: filepath.Join(root, "..", "escape")}); !errors.Is(err, ErrOutsideBase) {
+		t.Errorf("Expected an output directory outside the base to be rejected, got %v", err)
+	}
+	if err := CheckOptions(OutputOptions{ProjectName: "research", ProjectRoot: root}); err != nil {
+		t.Errorf("Expected a project inside the base to be allowed, got %v", err)
+	}
+}
+
+func TestWriteRejectsTraversal(t *testing.T) {
+	base := t.TempDir()
+	withBaseDir(t, base)
+	escape := filepath.Join(base, "..", "escaped.md")
+
+	result := Result{Title: "Report", Content: "content"}
+	if _, err := Write(result, OutputOptions{OutputFile: escape, Format: FormatMarkdown}); !errors.Is(err, ErrOutsideBase) {
+		t.Fatalf("Expected Write to reject a path outside the base, got %v", err)
+	}
+	if _, err := os.Stat(escape); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be written outside the base, got %v", err)
+	}
+
+	path, err := Write(result, OutputOptions{OutputDir: filepath.Join(base, "docs"), Format: FormatMarkdown})
+	if err != nil || !strings.HasPrefix(path, base) {
+		t.Errorf("Write() inside the base = %q, %v", path, err)
+	}
+}
+
+func TestEnsureDirPermission(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	withBaseDir(t, "")
+	parent := filepath.Join(t.TempDir(), "locked")
+	if err := os.Mkdir(parent, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	err := EnsureDir(filepath.Join(parent, "out"))
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected a permission error, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Default permissions for files and directories gengo writes
+const (
+	DefaultFileMode os.FileMode = 0644
+	DefaultDirMode  os.FileMode = 0755
+)
+
+// FileMode and DirMode are the permissions used for saved results, project
+// manifests and the directories created for them. Commands set them from
+// --file-mode and --dir-mode; existing directories are left unchanged.
+var (
+	FileMode = DefaultFileMode
+	DirMode  = DefaultDirMode
+)
+
+// ParseMode parses an octal permission string such as "0600" or "755"
+func ParseMode(s string) (os.FileMode, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(s), "0o")
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || value == "" || mode > 0777 {
+		return 0, fmt.Errorf("invalid permission mode %q (expected octal such as 0644)", s)
+	}
+	return os.FileMode(mode), nil
+}
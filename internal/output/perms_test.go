@@ -0,0 +1,64 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected os.FileMode
+		wantErr  bool
+	}{
+		{"0644", 0644, false},
+		{"600", 0600, false},
+		{" 0o700 ", 0700, false},
+		{"", 0, true},
+		{"0888", 0, true},
+		{"1777", 0, true},
+		{"rw-r--r--", 0, true},
+	}
+
+	for _, test := range tests {
+		mode, err := ParseMode(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseMode(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+			continue
+		}
+		if mode != test.expected {
+			t.Errorf("ParseMode(%q) = %o, expected %o", test.input, mode, test.expected)
+		}
+	}
+}
+
+func TestWritePermissions(t *testing.T) {
+	defer func() {
+		FileMode = DefaultFileMode
+		DirMode = DefaultDirMode
+	}()
+	FileMode = 0600
+	DirMode = 0700
+
+	root := t.TempDir()
+	opts := OutputOptions{ProjectName: "private", ProjectRoot: root, Format: FormatText}
+	if _, err := Write(Result{Title: "Secret", Content: "hidden"}, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	checks := map[string]os.FileMode{
+		filepath.Join(root, "private"):                  0700,
+		filepath.Join(root, "private", "Secret.txt"):    0600,
+		filepath.Join(root, "private", "manifest.json"): 0600,
+	}
+	for path, expected := range checks {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat %s failed: %v", path, err)
+		}
+		if info.Mode().Perm() != expected {
+			t.Errorf("%s has mode %o, expected %o", path, info.Mode().Perm(), expected)
+		}
+	}
+}
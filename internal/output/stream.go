@@ -0,0 +1,65 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// FormatJSONL streams one JSON object per processed source. It is only
+// offered by commands that handle several sources, so ParseFormat does not
+// accept it.
+const FormatJSONL Format = "jsonl"
+
+// Item statuses reported in a JSON-lines stream
+const (
+	StatusOK      = "ok"
+	StatusSkipped = "skipped"
+	StatusError   = "error"
+)
+
+// Item is one line of a JSON-lines stream describing a processed source
+type Item struct {
+	Source  string   `json:"source"`
+	Status  string   `json:"status"`
+	Output  string   `json:"output,omitempty"`
+	Outputs []string `json:"outputs,omitempty"` // every file written, when there are several destinations
+	Error   string   `json:"error,omitempty"`
+	Title   string   `json:"title,omitempty"`
+	Content string   `json:"content,omitempty"` // set when results are not saved to files
+}
+
+// StreamWriter writes Items as JSON lines. It is safe for concurrent use, so
+// items can be reported by whichever worker finishes them.
+type StreamWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStreamWriter returns a StreamWriter that writes to w
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{w: w}
+}
+
+// Write encodes item as a single line and flushes it so consumers see each
+// item as soon as it completes
+func (s *StreamWriter) Write(item Item) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	if f, ok := s.w.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	// A buffered writer only shows lines that were flushed
+	stream := NewStreamWriter(bufio.NewWriter(&buf))
+
+	items := []Item{
+		{Source: "a.pdf", Status: StatusOK, Output: "out/a.md"},
+		{Source: "b.pdf", Status: StatusError, Error: "file does not exist"},
+	}
+	for i, item := range items {
+		if err := stream.Write(item); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != i+1 {
+			t.Fatalf("Expected %d flushed lines, got %d", i+1, lines)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, line := range lines {
+		var got Item
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("Line %d is not valid JSON: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, items[i]) {
+			t.Errorf("Line %d = %+v, expected %+v", i, got, items[i])
+		}
+	}
+	if strings.Contains(lines[0], `"error"`) || strings.Contains(lines[1], `"output"`) {
+		t.Errorf("Expected empty fields to be omitted, got:\n%s", buf.String())
+	}
+}
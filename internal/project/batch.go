@@ -0,0 +1,183 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BatchFile is the name of the batch state kept next to the manifest
+const BatchFile = "batch.jsonl"
+
+// BatchStatus is the state of one source of a batch
+type BatchStatus string
+
+const (
+	BatchPending BatchStatus = "pending"
+	BatchDone    BatchStatus = "done"
+	BatchFailed  BatchStatus = "failed"
+)
+
+// BatchItem records the state of one source of a batch
+type BatchItem struct {
+	Source    string      `json:"source"`
+	Status    BatchStatus `json:"status"`
+	Attempts  int         `json:"attempts,omitempty"` // times the source was extracted, failed or not
+	Error     string      `json:"error,omitempty"`    // why the last attempt failed
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Batch is the state of a batch of sources extracted into one folder, so an
+// interrupted run can be resumed. It is kept as a log with a line per
+// change, appended as each source finishes: a crash loses at most the line
+// being written, and large batches are not rewritten after every source.
+type Batch struct {
+	dir   string
+	perm  os.FileMode
+	items map[string]*BatchItem
+	order []string // sources in the order they were added
+	cut   bool     // the log ends in a line cut short
+}
+
+// LoadBatch reads the batch state of a folder, replaying its log. A folder
+// without state yields an empty batch. Changes are appended with perm.
+func LoadBatch(dir string, perm os.FileMode) (*Batch, error) {
+	b := &Batch{dir: dir, perm: perm, items: make(map[string]*BatchItem)}
+
+	data, err := os.ReadFile(filepath.Join(dir, BatchFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch state: %w", err)
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var item BatchItem
+		if err := json.Unmarshal(line, &item); err != nil || item.Source == "" {
+			// A line cut short by a crash is ignored
+			continue
+		}
+		b.set(item)
+	}
+	// Start the next change on a line of its own after a cut line
+	b.cut = len(data) > 0 && data[len(data)-1] != '\n'
+	return b, nil
+}
+
+// set replaces the state of a source in memory
+func (b *Batch) set(item BatchItem) {
+	if _, ok := b.items[item.Source]; !ok {
+		b.order = append(b.order, item.Source)
+	}
+	b.items[item.Source] = &item
+}
+
+// Status returns the state of a source, BatchPending when it is unknown
+func (b *Batch) Status(source string) BatchStatus {
+	if item, ok := b.items[source]; ok {
+		return item.Status
+	}
+	return BatchPending
+}
+
+// Item returns the state recorded for a source
+func (b *Batch) Item(source string) (BatchItem, bool) {
+	item, ok := b.items[source]
+	if !ok {
+		return BatchItem{}, false
+	}
+	return *item, true
+}
+
+// Add records the sources not yet in the batch as pending
+func (b *Batch) Add(sources []string) error {
+	var added []BatchItem
+	now := time.Now().UTC()
+	for _, source := range sources {
+		if _, ok := b.items[source]; ok {
+			continue
+		}
+		item := BatchItem{Source: source, Status: BatchPending, UpdatedAt: now}
+		b.set(item)
+		added = append(added, item)
+	}
+	return b.append(added...)
+}
+
+// Mark records the outcome of extracting a source: done when err is nil,
+// failed otherwise
+func (b *Batch) Mark(source string, err error) error {
+	item := BatchItem{Source: source, Status: BatchDone, UpdatedAt: time.Now().UTC()}
+	if previous, ok := b.items[source]; ok {
+		item.Attempts = previous.Attempts
+	}
+	item.Attempts++
+	if err != nil {
+		item.Status = BatchFailed
+		item.Error = err.Error()
+	}
+	b.set(item)
+	return b.append(item)
+}
+
+// Remaining returns the sources still to be extracted, in their order: the
+// failed ones with failedOnly, otherwise all that are not done
+func (b *Batch) Remaining(sources []string, failedOnly bool) []string {
+	var remaining []string
+	for _, source := range sources {
+		status := b.Status(source)
+		if status == BatchDone || (failedOnly && status != BatchFailed) {
+			continue
+		}
+		remaining = append(remaining, source)
+	}
+	return remaining
+}
+
+// Count returns the number of sources with the given state
+func (b *Batch) Count(status BatchStatus) int {
+	n := 0
+	for _, source := range b.order {
+		if b.items[source].Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// append writes items to the end of the log
+func (b *Batch) append(items ...BatchItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	var data []byte
+	if b.cut {
+		data = append(data, '\n')
+	}
+	for _, item := range items {
+		line, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to encode batch state: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	f, err := os.OpenFile(filepath.Join(b.dir, BatchFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, b.perm)
+	if err != nil {
+		return fmt.Errorf("failed to write batch state: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write batch state: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write batch state: %w", err)
+	}
+	b.cut = false
+	return nil
+}
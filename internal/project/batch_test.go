@@ -0,0 +1,82 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBatchResume(t *testing.T) {
+	dir := t.TempDir()
+	sources := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+
+	b, err := LoadBatch(dir, 0644)
+	if err != nil {
+		t.Fatalf("LoadBatch failed: %v", err)
+	}
+	if err := b.Add(sources); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := b.Mark(sources[0], nil); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if err := b.Mark(sources[1], errors.New("connection reset")); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+
+	// A second run sees the state of the first
+	b, err = LoadBatch(dir, 0644)
+	if err != nil {
+		t.Fatalf("LoadBatch failed: %v", err)
+	}
+	if b.Status(sources[0]) != BatchDone || b.Status(sources[1]) != BatchFailed || b.Status(sources[2]) != BatchPending {
+		t.Errorf("Unexpected states %s, %s, %s", b.Status(sources[0]), b.Status(sources[1]), b.Status(sources[2]))
+	}
+	if item, _ := b.Item(sources[1]); item.Error != "connection reset" || item.Attempts != 1 {
+		t.Errorf("Unexpected failed item %+v", item)
+	}
+	if got := b.Remaining(sources, false); !slices.Equal(got, sources[1:]) {
+		t.Errorf("Remaining = %v, expected %v", got, sources[1:])
+	}
+	if got := b.Remaining(sources, true); !slices.Equal(got, sources[1:2]) {
+		t.Errorf("Remaining failed only = %v, expected %v", got, sources[1:2])
+	}
+
+	// Retrying counts the attempts
+	if err := b.Mark(sources[1], nil); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if item, _ := b.Item(sources[1]); item.Status != BatchDone || item.Error != "" || item.Attempts != 2 {
+		t.Errorf("Unexpected retried item %+v", item)
+	}
+	if b.Count(BatchDone) != 2 || b.Count(BatchPending) != 1 || b.Count(BatchFailed) != 0 {
+		t.Errorf("Unexpected counts: %d done, %d pending, %d failed", b.Count(BatchDone), b.Count(BatchPending), b.Count(BatchFailed))
+	}
+}
+
+func TestLoadBatchIgnoresTruncatedLine(t *testing.T) {
+	dir := t.TempDir()
+	log := `{"source":"https://example.com/a","status":"done","updated_at":"2024-01-01T00:00:00Z"}
+{"source":"https://example.com/b","sta`
+	if err := os.WriteFile(filepath.Join(dir, BatchFile), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := LoadBatch(dir, 0644)
+	if err != nil {
+		t.Fatalf("LoadBatch failed: %v", err)
+	}
+	if b.Status("https://example.com/a") != BatchDone || b.Status("https://example.com/b") != BatchPending {
+		t.Errorf("Expected the complete line to count and the cut one to be ignored")
+	}
+
+	// Changes after the cut line are read back
+	if err := b.Mark("https://example.com/b", nil); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if b, err = LoadBatch(dir, 0644); err != nil || b.Status("https://example.com/b") != BatchDone {
+		t.Errorf("Expected the change after the cut line to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestFile is the name of the manifest kept in every project folder
+const ManifestFile = "manifest.json"
+
+// Entry records one extracted source saved in a project
+type Entry struct {
+	Source      string    `json:"source"`
+	SourceType  string    `json:"source_type,omitempty"` // kind of source, e.g. web or pdf
+	Title       string    `json:"title"`
+	File        string    `json:"file"`              // file name relative to the project folder
+	Format      string    `json:"format,omitempty"`  // output format the file was written in
+	Hash        string    `json:"hash,omitempty"`    // hash of the extracted content
+	Tags        []string  `json:"tags,omitempty"`    // keyphrases describing the content
+	Raw         string    `json:"raw,omitempty"`     // source document saved alongside, relative to the project folder
+	Options     *Options  `json:"options,omitempty"` // extraction options the content was saved with
+	ExtractedAt time.Time `json:"extracted_at"`
+}
+
+// Options records the options that shaped an entry's content, so refreshing
+// the entry extracts it the same way
+type Options struct {
+	TOC           bool   `json:"toc,omitempty"`            // table of contents inserted
+	HeadingOffset int    `json:"heading_offset,omitempty"` // levels headings were demoted by
+	Limit         int    `json:"limit,omitempty"`          // words or characters kept (0: all)
+	LimitUnit     string `json:"limit_unit,omitempty"`     // what Limit counts, words or chars
+	Links         string `json:"links,omitempty"`          // how links were written, e.g. inline
+	Math          bool   `json:"math,omitempty"`           // equations written as LaTeX
+	BodyOnly      bool   `json:"body_only,omitempty"`      // written without the title and source header
+}
+
+// Manifest lists the entries saved in a project folder
+type Manifest struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the manifest of a project folder. A folder without a manifest
+// yields an empty one.
+func Load(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse project manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Save writes the manifest into a project folder with the given permissions
+func (m *Manifest) Save(dir string, perm os.FileMode) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode project manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), perm); err != nil {
+		return fmt.Errorf("failed to write project manifest: %w", err)
+	}
+	return nil
+}
+
+// Put adds an entry, replacing any existing entry for the same file
+func (m *Manifest) Put(entry Entry) {
+	for i := range m.Entries {
+		if m.Entries[i].File == entry.File {
+			m.Entries[i] = entry
+			return
+		}
+	}
+	m.Entries = append(m.Entries, entry)
+}
+
+// FindHash returns the entry whose content has the given hash
+func (m *Manifest) FindHash(hash string) (Entry, bool) {
+	for _, entry := range m.Entries {
+		if entry.Hash == hash {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Record adds an entry to the manifest of a project folder, writing it with perm
+func Record(dir string, entry Entry, perm os.FileMode) error {
+	m, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	m.Put(entry)
+	return m.Save(dir, perm)
+}
+
+// HashContent returns the hash stored in the manifest for extracted content
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
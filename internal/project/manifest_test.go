@@ -0,0 +1,78 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingManifest(t *testing.T) {
+	m, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(m.Entries) != 0 {
+		t.Errorf("Expected empty manifest, got %d entries", len(m.Entries))
+	}
+}
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	entries := []Entry{
+		{Source: "https://example.com/a", Title: "A", File: "A.md", Hash: HashContent("a"), ExtractedAt: now},
+		{Source: "https://example.com/b", Title: "B", File: "B.md", ExtractedAt: now},
+		{Source: "https://example.com/a", Title: "A v2", File: "A.md", Hash: HashContent("a2"), ExtractedAt: now},
+	}
+	for _, entry := range entries {
+		if err := Record(dir, entry, 0644); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(m.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(m.Entries))
+	}
+	if m.Entries[0].Title != "A v2" || m.Entries[0].Hash != HashContent("a2") {
+		t.Errorf("Expected re-saved file to replace its entry, got %+v", m.Entries[0])
+	}
+	if !m.Entries[1].ExtractedAt.Equal(now) {
+		t.Errorf("Expected extraction time to round-trip, got %v", m.Entries[1].ExtractedAt)
+	}
+}
+
+func TestFindHash(t *testing.T) {
+	m := &Manifest{Entries: []Entry{{File: "A.md", Hash: HashContent("a")}, {File: "B.md"}}}
+
+	if entry, ok := m.FindHash(HashContent("a")); !ok || entry.File != "A.md" {
+		t.Errorf("Expected to find A.md, got %+v, %v", entry, ok)
+	}
+	if _, ok := m.FindHash(HashContent("b")); ok {
+		t.Error("Expected no match for unknown content")
+	}
+}
+
+func TestLoadInvalidManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected an error for an invalid manifest")
+	}
+}
+
+func TestHashContent(t *testing.T) {
+	if HashContent("same") != HashContent("same") {
+		t.Error("Expected identical content to hash identically")
+	}
+	if HashContent("one") == HashContent("two") {
+		t.Error("Expected different content to hash differently")
+	}
+}
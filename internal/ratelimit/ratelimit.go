@@ -0,0 +1,80 @@
+// Package ratelimit spaces out requests to the same host so batch
+// extractions stay polite and do not get the user blocked.
+//
+// Each host has its own limiter, so sources spread over several domains are
+// not serialized behind one another.
+package ratelimit
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultRate is the default number of requests per second sent to one host
+const DefaultRate = 1.0
+
+// HostLimiter limits the request rate per host
+type HostLimiter struct {
+	limit rate.Limit
+
+	mu    sync.Mutex
+	hosts map[string]*rate.Limiter
+}
+
+// New returns a limiter allowing perSecond requests per second to each host
+// and waiting at least delay between two requests to the same host. The
+// stricter of the two applies; zero disables either.
+func New(perSecond float64, delay time.Duration) *HostLimiter {
+	limit := rate.Inf
+	if perSecond > 0 {
+		limit = rate.Limit(perSecond)
+	}
+	if delay > 0 && rate.Every(delay) < limit {
+		limit = rate.Every(delay)
+	}
+	return &HostLimiter{limit: limit, hosts: make(map[string]*rate.Limiter)}
+}
+
+// Wait blocks until a request to the host of rawURL is allowed, or ctx is
+// done. Sources without a host, such as local files, pass immediately, as
+// does everything on a nil limiter.
+func (l *HostLimiter) Wait(ctx context.Context, rawURL string) error {
+	if l == nil || l.limit == rate.Inf {
+		return nil
+	}
+
+	host := Host(rawURL)
+	if host == "" {
+		return nil
+	}
+	return l.forHost(host).Wait(ctx)
+}
+
+// forHost returns the limiter for a host, creating it on first use. A burst
+// of one lets the first request through at once and spaces the rest.
+func (l *HostLimiter) forHost(host string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiter, ok := l.hosts[host]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, 1)
+		l.hosts[host] = limiter
+	}
+	return limiter
+}
+
+// Host returns the lowercased host name of an http(s) URL, or "" for
+// anything else
+func Host(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHost(t *testing.T) {
+	tests := map[string]string{
+		"https://Example.com/page":    "example.com",
+		"http://example.com:8080/a":   "example.com",
+		"report.pdf":                  "",
+		"/home/user/notes.md":         "",
+		"ftp://example.com/file.txt":  "",
+		" https://www.youtube.com/x ": "www.youtube.com",
+	}
+	for input, want := range tests {
+		if got := Host(input); got != want {
+			t.Errorf("Host(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestHostLimiterSpacesSameHost(t *testing.T) {
+	limiter := New(0, 50*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, "https://example.com/page"); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests to one host took %v, want at least 100ms", elapsed)
+	}
+}
+
+func TestHostLimiterSeparatesHosts(t *testing.T) {
+	limiter := New(0.1, 0)
+	ctx := context.Background()
+
+	start := time.Now()
+	for _, source := range []string{"https://a.example/1", "https://b.example/1", "https://c.example/1", "notes.md", "notes.md"} {
+		if err := limiter.Wait(ctx, source); err != nil {
+			t.Fatalf("Wait(%q) failed: %v", source, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("first requests to distinct hosts took %v, want no waiting", elapsed)
+	}
+}
+
+func TestHostLimiterCancelled(t *testing.T) {
+	limiter := New(0.1, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, "https://example.com/"); err != nil {
+		t.Fatalf("first Wait failed: %v", err)
+	}
+	if err := limiter.Wait(ctx, "https://example.com/"); err == nil {
+		t.Error("expected an error when the wait outlasts the context")
+	}
+}
+
+func TestNilAndUnlimited(t *testing.T) {
+	var nilLimiter *HostLimiter
+	if err := nilLimiter.Wait(context.Background(), "https://example.com/"); err != nil {
+		t.Errorf("nil limiter: %v", err)
+	}
+
+	unlimited := New(0, 0)
+	for i := 0; i < 100; i++ {
+		if err := unlimited.Wait(context.Background(), "https://example.com/"); err != nil {
+			t.Fatalf("unlimited limiter: %v", err)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"maai.solutions/gengo/internal/httpclient"
+)
+
+// Job statuses
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// jobRetention is how long finished jobs stay queryable
+const jobRetention = 24 * time.Hour
+
+// callbackTimeout bounds the POST delivering a finished job to its callback
+const callbackTimeout = 30 * time.Second
+
+// Job is an asynchronous transcription tracked by the server
+type Job struct {
+	ID            string     `json:"id"`
+	Status        string     `json:"status"`
+	Source        string     `json:"source"`
+	Callback      string     `json:"callback,omitempty"`
+	Result        *Response  `json:"result,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	CallbackError string     `json:"callback_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// jobStore keeps job state in memory
+type jobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+func newJobStore() *jobStore {
+	return &jobStore{jobs: make(map[string]*Job)}
+}
+
+// add registers a new queued job, dropping finished jobs past retention
+func (js *jobStore) add(source, callback string) (Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	now := time.Now()
+	for key, job := range js.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > jobRetention {
+			delete(js.jobs, key)
+		}
+	}
+
+	job := &Job{ID: id, Status: JobQueued, Source: source, Callback: callback, CreatedAt: now}
+	js.jobs[id] = job
+	return *job, nil
+}
+
+// get returns a copy of a job
+func (js *jobStore) get(id string) (Job, bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	job, ok := js.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// update applies fn to a job under the store lock and returns the result
+func (js *jobStore) update(id string, fn func(job *Job)) Job {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	job := js.jobs[id]
+	fn(job)
+	return *job
+}
+
+// newJobID returns a random job identifier
+func newJobID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// jobRequest is the JSON body accepted by /jobs/transcribe
+type jobRequest struct {
+	URL      string `json:"url"`
+	Callback string `json:"callback"`
+}
+
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes))
+	if err != nil {
+		writeError(w, limitError(err))
+		return
+	}
+
+	var req jobRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, badRequest("invalid JSON body: %v", err))
+		return
+	}
+	if err := CheckHTTPURL(req.URL); err != nil {
+		writeError(w, badRequest("invalid url: %v", err))
+		return
+	}
+	if req.Callback != "" {
+		if err := s.checkCallback(req.Callback); err != nil {
+			writeError(w, badRequest("invalid callback: %v", err))
+			return
+		}
+	}
+
+	if err := s.startJob(); err != nil {
+		writeError(w, err)
+		return
+	}
+	job, err := s.jobs.add(req.URL, req.Callback)
+	if err != nil {
+		s.finishJob()
+		writeError(w, err)
+		return
+	}
+	go s.runJob(job)
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleJobStatus(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, &requestError{status: http.StatusNotFound, err: fmt.Errorf("job not found")})
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// checkCallback accepts only http(s) URLs on one of Config.CallbackHosts, so
+// clients cannot make the server POST to arbitrary internal addresses
+func (s *Server) checkCallback(raw string) error {
+	if err := CheckHTTPURL(raw); err != nil {
+		return err
+	}
+	u, _ := url.Parse(strings.TrimSpace(raw))
+	for _, host := range s.config.CallbackHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	if len(s.config.CallbackHosts) == 0 {
+		return fmt.Errorf("callbacks are disabled on this server")
+	}
+	return fmt.Errorf("host %q is not an allowed callback host", u.Host)
+}
+
+// startJob takes a job slot, failing when the server is shutting down or
+// Config.MaxJobs jobs are already queued or running
+func (s *Server) startJob() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return &requestError{status: http.StatusServiceUnavailable, err: fmt.Errorf("server is shutting down")}
+	}
+	select {
+	case s.jobSlots <- struct{}{}:
+	default:
+		return &requestError{status: http.StatusServiceUnavailable, err: fmt.Errorf("too many jobs in progress (limit %d)", cap(s.jobSlots))}
+	}
+	s.jobsWG.Add(1)
+	return nil
+}
+
+// finishJob releases the slot taken by startJob
+func (s *Server) finishJob() {
+	<-s.jobSlots
+	s.jobsWG.Done()
+}
+
+// runJob transcribes a job's source and delivers the outcome to its callback
+func (s *Server) runJob(job Job) {
+	defer s.finishJob()
+	s.jobs.update(job.ID, func(j *Job) { j.Status = JobRunning })
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.config.Timeout)
+	resp, err := s.transcribe(ctx, job.Source)
+	cancel()
+
+	job = s.jobs.update(job.ID, func(j *Job) {
+		now := time.Now()
+		j.FinishedAt = &now
+		if err != nil {
+			j.Status = JobFailed
+			j.Error = err.Error()
+			return
+		}
+		j.Status = JobDone
+		j.Result = &resp
+	})
+
+	if job.Callback == "" {
+		return
+	}
+	// A job cut short by shutdown still reports its failure
+	ctx, cancel = context.WithTimeout(context.WithoutCancel(s.ctx), callbackTimeout)
+	defer cancel()
+	if err := postJSON(ctx, job.Callback, job); err != nil {
+		s.jobs.update(job.ID, func(j *Job) { j.CallbackError = err.Error() })
+	}
+}
+
+// postJSON sends v as a JSON POST and fails on a non-2xx response. Redirects
+// are not followed, so the callback host check cannot be bypassed.
+func postJSON(ctx context.Context, target string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := *httpclient.Default
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("callback failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return nil
+}
+
+// CheckHTTPURL rejects anything but an absolute http(s) URL
+func CheckHTTPURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("expected an http(s) URL, got %q", raw)
+	}
+	return nil
+}
+
+// SubmitTranscription asks the server at serverURL to transcribe videoURL in
+// the background, POSTing the finished job to callback when it is set
+func SubmitTranscription(ctx context.Context, serverURL, videoURL, callback string) (Job, error) {
+	data, err := json.Marshal(jobRequest{URL: videoURL, Callback: callback})
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(serverURL, "/")+"/jobs/transcribe", bytes.NewReader(data))
+	if err != nil {
+		return Job{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJobRequest(req, http.StatusAccepted)
+}
+
+// JobStatus fetches a job from the server at serverURL
+func JobStatus(ctx context.Context, serverURL, id string) (Job, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(serverURL, "/")+"/jobs/"+url.PathEscape(id), nil)
+	if err != nil {
+		return Job{}, err
+	}
+	return doJobRequest(req, http.StatusOK)
+}
+
+// doJobRequest sends a jobs API request and decodes the returned job
+func doJobRequest(req *http.Request, want int) (Job, error) {
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to reach gengo server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Error != "" {
+			return Job{}, fmt.Errorf("server returned %s: %s", resp.Status, body.Error)
+		}
+		return Job{}, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	var job Job
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return Job{}, fmt.Errorf("failed to decode job: %w", err)
+	}
+	return job, nil
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranscriptionJobCallback(t *testing.T) {
+	delivered := make(chan Job, 1)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var job Job
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+			t.Errorf("Callback body is not a job: %v", err)
+		}
+		delivered <- job
+	}))
+	defer callback.Close()
+
+	release := make(chan struct{})
+	config := DefaultConfig()
+	config.CallbackHosts = []string{"127.0.0.1"}
+	s := New(config)
+	s.transcribe = func(ctx context.Context, source string) (Response, error) {
+		<-release
+		resp := Response{}
+		resp.Source = source
+		resp.Content = "hello world"
+		return resp, nil
+	}
+	api := httptest.NewServer(s)
+	defer api.Close()
+
+	ctx := context.Background()
+	job, err := SubmitTranscription(ctx, api.URL, "https://youtu.be/abc123", callback.URL)
+	if err != nil {
+		t.Fatalf("SubmitTranscription failed: %v", err)
+	}
+	if job.ID == "" || job.Status != JobQueued {
+		t.Errorf("Expected a queued job with an id, got %+v", job)
+	}
+
+	// The submission returns before the transcription finishes
+	status, err := JobStatus(ctx, api.URL, job.ID)
+	if err != nil {
+		t.Fatalf("JobStatus failed: %v", err)
+	}
+	if status.Status == JobDone {
+		t.Errorf("Expected the job to be pending, got %+v", status)
+	}
+	close(release)
+
+	select {
+	case got := <-delivered:
+		if got.ID != job.ID || got.Status != JobDone || got.Result == nil || got.Result.Content != "hello world" {
+			t.Errorf("Unexpected callback payload: %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Callback was not delivered")
+	}
+
+	status, err = JobStatus(ctx, api.URL, job.ID)
+	if err != nil {
+		t.Fatalf("JobStatus failed: %v", err)
+	}
+	if status.Status != JobDone || status.FinishedAt == nil {
+		t.Errorf("Expected a finished job, got %+v", status)
+	}
+}
+
+func TestTranscriptionJobFailure(t *testing.T) {
+	s := New(DefaultConfig())
+	s.transcribe = func(ctx context.Context, source string) (Response, error) {
+		return Response{}, errors.New("video unavailable")
+	}
+	api := httptest.NewServer(s)
+	defer api.Close()
+
+	ctx := context.Background()
+	job, err := SubmitTranscription(ctx, api.URL, "https://youtu.be/abc123", "")
+	if err != nil {
+		t.Fatalf("SubmitTranscription failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := JobStatus(ctx, api.URL, job.ID)
+		if err != nil {
+			t.Fatalf("JobStatus failed: %v", err)
+		}
+		if status.Status == JobFailed {
+			if status.Error != "video unavailable" {
+				t.Errorf("Unexpected job error: %q", status.Error)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job did not fail in time: %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobRequestErrors(t *testing.T) {
+	api := httptest.NewServer(New(DefaultConfig()))
+	defer api.Close()
+
+	ctx := context.Background()
+	if _, err := JobStatus(ctx, api.URL, "missing"); err == nil || !strings.Contains(err.Error(), "job not found") {
+		t.Errorf("Expected a job not found error, got: %v", err)
+	}
+	if _, err := SubmitTranscription(ctx, api.URL, "https://youtu.be/abc123", "not a url"); err == nil || !strings.Contains(err.Error(), "invalid callback") {
+		t.Errorf("Expected an invalid callback error, got: %v", err)
+	}
+	if _, err := SubmitTranscription(ctx, api.URL, "", ""); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected a bad request for a missing url, got: %v", err)
+	}
+}
+
+func TestJobCallbackHosts(t *testing.T) {
+	api := httptest.NewServer(New(DefaultConfig()))
+	defer api.Close()
+
+	ctx := context.Background()
+	if _, err := SubmitTranscription(ctx, api.URL, "https://youtu.be/abc123", "http://127.0.0.1/done"); err == nil || !strings.Contains(err.Error(), "callbacks are disabled") {
+		t.Errorf("Expected callbacks to be disabled by default, got: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.CallbackHosts = []string{"hooks.example.com", "localhost:9000"}
+	s := New(config)
+	tests := map[string]bool{
+		"https://hooks.example.com/done":      true,
+		"http://HOOKS.example.com:8443/done":  true,
+		"http://localhost:9000/done":          true,
+		"http://localhost:9001/done":          false,
+		"http://169.254.169.254/latest":       false,
+		"file:///etc/passwd":                  false,
+		"https://hooks.example.com.evil.com/": false,
+	}
+	for callback, allowed := range tests {
+		if err := s.checkCallback(callback); (err == nil) != allowed {
+			t.Errorf("checkCallback(%q) = %v, expected allowed=%v", callback, err, allowed)
+		}
+	}
+}
+
+func TestJobLimitAndClose(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxJobs = 1
+	s := New(config)
+	started := make(chan struct{})
+	s.transcribe = func(ctx context.Context, source string) (Response, error) {
+		close(started)
+		<-ctx.Done()
+		return Response{}, ctx.Err()
+	}
+	api := httptest.NewServer(s)
+	defer api.Close()
+
+	ctx := context.Background()
+	job, err := SubmitTranscription(ctx, api.URL, "https://youtu.be/abc123", "")
+	if err != nil {
+		t.Fatalf("SubmitTranscription failed: %v", err)
+	}
+	<-started
+	if _, err := SubmitTranscription(ctx, api.URL, "https://youtu.be/def456", ""); err == nil || !strings.Contains(err.Error(), "too many jobs") {
+		t.Errorf("Expected the job limit to be enforced, got: %v", err)
+	}
+
+	// Close cancels the running job and waits for it
+	s.Close()
+	status, err := JobStatus(ctx, api.URL, job.ID)
+	if err != nil {
+		t.Fatalf("JobStatus failed: %v", err)
+	}
+	if status.Status != JobFailed || !strings.Contains(status.Error, "canceled") {
+		t.Errorf("Expected the job to be cancelled, got %+v", status)
+	}
+	if _, err := SubmitTranscription(ctx, api.URL, "https://youtu.be/abc123", ""); err == nil || !strings.Contains(err.Error(), "shutting down") {
+		t.Errorf("Expected submissions to be refused after Close, got: %v", err)
+	}
+}
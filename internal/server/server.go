@@ -0,0 +1,412 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"maai.solutions/gengo/internal/extractors/asr"
+	pdfextractors "maai.solutions/gengo/internal/extractors/pdf"
+	webextractors "maai.solutions/gengo/internal/extractors/web"
+	"maai.solutions/gengo/internal/extractors/ytaudio"
+	"maai.solutions/gengo/internal/metrics"
+	"maai.solutions/gengo/internal/output"
+)
+
+// Config holds configuration for the extraction API server
+type Config struct {
+	MaxBodyBytes   int64         // limit for JSON request bodies
+	MaxUploadBytes int64         // limit for multipart file uploads
+	Timeout        time.Duration // time allowed for a single extraction
+	TempDir        string        // where uploads and downloads are staged (default: os.TempDir())
+	ASRConfig      *asr.Config   // whisper configuration for /transcribe
+	// Transcribers is how many transcriptions run at once, each with its own
+	// loaded model; further requests wait their turn
+	Transcribers int
+	// MaxJobs is how many background jobs may be queued or running at once;
+	// further submissions are refused
+	MaxJobs int
+	// CallbackHosts are the hosts, optionally with a port, that jobs may POST
+	// their results to; with none, jobs cannot have a callback
+	CallbackHosts []string
+}
+
+// DefaultConfig returns a default server configuration
+func DefaultConfig() *Config {
+	return &Config{
+		MaxBodyBytes:   1 << 20,
+		MaxUploadBytes: 200 << 20,
+		Timeout:        30 * time.Minute,
+		ASRConfig:      asr.DefaultConfig(),
+		Transcribers:   1,
+		MaxJobs:        16,
+	}
+}
+
+// Server exposes the extractors over HTTP
+type Server struct {
+	config *Config
+	mux    *http.ServeMux
+	jobs   *jobStore
+	asr    *asr.ServicePool // whisper models shared by all transcriptions
+
+	// Background jobs run under ctx, which Close cancels before waiting for
+	// them; jobSlots holds one token per job queued or running
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	jobsWG   sync.WaitGroup
+	jobSlots chan struct{}
+
+	// transcribe runs background transcription jobs (default: transcribeURL)
+	transcribe func(ctx context.Context, source string) (Response, error)
+}
+
+// New creates a server with the extraction routes registered
+func New(config *Config) *Server {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	s := &Server{
+		config: config,
+		mux:    http.NewServeMux(),
+		jobs:   newJobStore(),
+		asr:    asr.NewServicePool(config.ASRConfig, config.Transcribers),
+
+		jobSlots: make(chan struct{}, max(config.MaxJobs, 1)),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.transcribe = s.transcribeURL
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("POST /extract/web", s.handleWeb)
+	s.mux.HandleFunc("POST /extract/pdf", s.handlePDF)
+	s.mux.HandleFunc("POST /transcribe", s.handleTranscribe)
+	s.mux.HandleFunc("POST /jobs/transcribe", s.handleSubmitJob)
+	s.mux.HandleFunc("GET /jobs/{id}", s.handleJobStatus)
+	if handler := metrics.Handler(); handler != nil {
+		s.mux.Handle("GET /metrics", handler)
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close cancels the background jobs, waits for them to stop and releases the
+// whisper models loaded for transcription
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+	s.jobsWG.Wait()
+	return s.asr.Close()
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, then lets in-flight
+// requests finish for a short grace period
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       5 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// urlRequest is the JSON body accepted by /extract/web and /transcribe
+type urlRequest struct {
+	URL string `json:"url"`
+}
+
+// Segment is a timed piece of a transcript in a /transcribe response
+type Segment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+	// Language is the language detected for the segment, with
+	// Config.ASRConfig.PerSegmentLanguage
+	Language string `json:"language,omitempty"`
+}
+
+// Response is the JSON body returned for a successful extraction
+type Response struct {
+	output.Result
+	Segments []Segment `json:"segments,omitempty"`
+	Partial  bool      `json:"partial,omitempty"` // the transcription timed out and stops early
+}
+
+// requestError is an error caused by the client's request
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+
+func badRequest(format string, a ...interface{}) error {
+	return &requestError{status: http.StatusBadRequest, err: fmt.Errorf(format, a...)}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleWeb(w http.ResponseWriter, r *http.Request) {
+	pageURL, err := s.readURL(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	title, content, err := webextractors.DownloadContentContext(ctx, pageURL, webextractors.Options{})
+	metrics.Observe(metrics.SourceWeb, len(content), time.Since(start), err)
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to extract web page: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Result: output.NewResult(output.SourceWeb, pageURL, title, content)})
+}
+
+func (s *Server) handlePDF(w http.ResponseWriter, r *http.Request) {
+	path, name, cleanup, err := s.saveUpload(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cleanup()
+
+	start := time.Now()
+	text, err := pdfextractors.NewTextExtractor().ExtractLayerText(path, nil)
+	metrics.Observe(metrics.SourcePDF, len(text), time.Since(start), err)
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to extract PDF: %w", err))
+		return
+	}
+	title := strings.TrimSuffix(name, filepath.Ext(name))
+	writeJSON(w, http.StatusOK, Response{Result: output.NewResult(output.SourcePDF, name, title, text)})
+}
+
+func (s *Server) handleTranscribe(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Timeout)
+	defer cancel()
+
+	if !isMultipart(r) {
+		source, err := s.readURL(w, r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		resp, err := s.transcribeURL(ctx, source)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	path, name, cleanup, err := s.saveUpload(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cleanup()
+
+	// Each job gets its own work directory, since conversions use fixed names
+	workDir, err := os.MkdirTemp(s.config.TempDir, "gengo-transcribe-*")
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to create work directory: %w", err))
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	start := time.Now()
+	result, err := s.asr.TranscribeAudio(ctx, path, workDir)
+	if err != nil {
+		metrics.Observe(metrics.SourceAudio, 0, time.Since(start), err)
+		writeError(w, fmt.Errorf("failed to transcribe audio: %w", err))
+		return
+	}
+	metrics.Observe(metrics.SourceAudio, len(result.Text), time.Since(start), nil)
+	writeJSON(w, http.StatusOK, transcriptResponse(name, result))
+}
+
+// transcribeURL downloads and transcribes a YouTube video
+func (s *Server) transcribeURL(ctx context.Context, source string) (resp Response, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.Observe(metrics.SourceYouTube, len(resp.Content), time.Since(start), err)
+	}()
+
+	workDir, err := os.MkdirTemp(s.config.TempDir, "gengo-transcribe-*")
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	config := ytaudio.DefaultConfig()
+	config.OutputDir = workDir
+	config.TempDir = workDir
+	config.ASRConfig = s.config.ASRConfig
+	config.ASRPool = s.asr
+	transcript, err := ytaudio.NewService(config).TranscribeYouTubeVideo(ctx, source)
+	if err != nil {
+		return Response{}, err
+	}
+	return transcriptResponse(source, &asr.Result{
+		Text:     transcript.Text,
+		Language: transcript.Language,
+		Segments: transcript.Segments,
+		Partial:  transcript.Partial,
+	}), nil
+}
+
+// transcriptResponse converts a transcription into a response body
+func transcriptResponse(source string, result *asr.Result) Response {
+	resp := Response{Result: output.NewResult(output.SourceTranscript, source, "Transcript", result.Text)}
+	if result.Language != "" {
+		resp.Metadata = map[string]string{"Language": result.Language}
+	}
+	for _, seg := range result.Segments {
+		resp.Segments = append(resp.Segments, Segment{Start: seg.Start.Seconds(), End: seg.End.Seconds(), Text: seg.Text, Language: seg.Language})
+	}
+	resp.Partial = result.Partial
+	return resp
+}
+
+// readURL reads the source URL from a JSON body ({"url": "..."}) or a plain
+// text body holding just the URL
+func (s *Server) readURL(w http.ResponseWriter, r *http.Request) (string, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes))
+	if err != nil {
+		return "", limitError(err)
+	}
+
+	raw := strings.TrimSpace(string(body))
+	if strings.HasPrefix(raw, "{") {
+		var req urlRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return "", badRequest("invalid JSON body: %v", err)
+		}
+		raw = strings.TrimSpace(req.URL)
+	}
+	if raw == "" {
+		return "", badRequest("missing url")
+	}
+
+	if err := CheckHTTPURL(raw); err != nil {
+		return "", badRequest("invalid url: %v", err)
+	}
+	return raw, nil
+}
+
+// saveUpload stores the "file" field of a multipart request in a temporary
+// file, keeping its extension so extractors can recognize the format
+func (s *Server) saveUpload(w http.ResponseWriter, r *http.Request) (path, name string, cleanup func(), err error) {
+	if !isMultipart(r) {
+		return "", "", nil, badRequest("expected a multipart/form-data upload with a \"file\" field")
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return "", "", nil, badRequest("invalid multipart body: %v", err)
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return "", "", nil, badRequest("missing \"file\" field")
+		}
+		if err != nil {
+			return "", "", nil, limitError(err)
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		name = filepath.Base(part.FileName())
+		tmp, err := os.CreateTemp(s.config.TempDir, "gengo-upload-*"+filepath.Ext(name))
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to store upload: %w", err)
+		}
+		cleanup = func() { os.Remove(tmp.Name()) }
+
+		_, err = io.Copy(tmp, part)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return "", "", nil, limitError(err)
+		}
+		return tmp.Name(), name, cleanup, nil
+	}
+}
+
+// isMultipart reports whether r carries a multipart/form-data body
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// limitError turns a body size limit violation into a 413 error
+func limitError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return &requestError{status: http.StatusRequestEntityTooLarge, err: fmt.Errorf("request body exceeds %d bytes", maxErr.Limit)}
+	}
+	return badRequest("failed to read request: %v", err)
+}
+
+// writeError reports err as a JSON error body with a matching status code
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var reqErr *requestError
+	var videoErr *ytaudio.VideoError
+	switch {
+	case errors.As(err, &reqErr):
+		status = reqErr.status
+	case errors.As(err, &videoErr):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeJSON encodes v as the response body
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
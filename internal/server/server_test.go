@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"maai.solutions/gengo/internal/metrics"
+)
+
+// do sends a request to a server and decodes the JSON response
+func do(t *testing.T, s *Server, req *http.Request) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Response is not JSON (%d): %q", rec.Code, rec.Body.String())
+	}
+	return rec.Code, body
+}
+
+// upload builds a multipart request with a single "file" field
+func upload(t *testing.T, target, name string, data []byte) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("CreateFormFile failed: %v", err)
+	}
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestHealth(t *testing.T) {
+	code, body := do(t, New(nil), httptest.NewRequest(http.MethodGet, "/health", nil))
+	if code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("Unexpected health response %d: %v", code, body)
+	}
+}
+
+func TestExtractWeb(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>Served Page</title></head><body><p>Served content here.</p></body></html>"))
+	}))
+	defer page.Close()
+
+	s := New(&Config{MaxBodyBytes: 1024, Timeout: DefaultConfig().Timeout})
+
+	bodies := []string{`{"url": "` + page.URL + `"}`, page.URL}
+	for _, reqBody := range bodies {
+		code, body := do(t, s, httptest.NewRequest(http.MethodPost, "/extract/web", strings.NewReader(reqBody)))
+		if code != http.StatusOK {
+			t.Fatalf("Expected 200 for %q, got %d: %v", reqBody, code, body)
+		}
+		if body["title"] != "Served Page" || !strings.Contains(body["content"].(string), "Served content here.") {
+			t.Errorf("Unexpected result: %v", body)
+		}
+	}
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{"", http.StatusBadRequest},
+		{`{"url": ""}`, http.StatusBadRequest},
+		{`{"url": `, http.StatusBadRequest},
+		{"ftp://example.com/file", http.StatusBadRequest},
+		{`{"url": "` + page.URL + strings.Repeat(" ", 2048) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		code, body := do(t, s, httptest.NewRequest(http.MethodPost, "/extract/web", strings.NewReader(test.body)))
+		if code != test.status || body["error"] == "" {
+			t.Errorf("Body %.30q: expected %d with an error, got %d: %v", test.body, test.status, code, body)
+		}
+	}
+}
+
+func TestExtractPDF(t *testing.T) {
+	s := New(&Config{MaxUploadBytes: 512, TempDir: t.TempDir()})
+
+	code, body := do(t, s, upload(t, "/extract/pdf", "report.pdf", []byte("not a pdf")))
+	if code != http.StatusInternalServerError || !strings.Contains(body["error"].(string), "failed to extract PDF") {
+		t.Errorf("Expected an extraction error, got %d: %v", code, body)
+	}
+
+	code, _ = do(t, s, upload(t, "/extract/pdf", "big.pdf", bytes.Repeat([]byte("x"), 4096)))
+	if code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized upload, got %d", code)
+	}
+
+	code, _ = do(t, s, httptest.NewRequest(http.MethodPost, "/extract/pdf", strings.NewReader("%PDF")))
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a multipart upload, got %d", code)
+	}
+
+	pdf, err := os.ReadFile("../extractors/pdf/testdata/text.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = New(&Config{MaxUploadBytes: 1 << 20, TempDir: t.TempDir()})
+	code, body = do(t, s, upload(t, "/extract/pdf", "text.pdf", pdf))
+	if code != http.StatusOK || body["title"] != "text" || !strings.Contains(body["content"].(string), "Hello World") {
+		t.Errorf("Expected the text of the uploaded PDF, got %d: %v", code, body)
+	}
+}
+
+func TestTranscribeRequiresSource(t *testing.T) {
+	s := New(&Config{MaxBodyBytes: 1024, TempDir: t.TempDir()})
+
+	code, body := do(t, s, httptest.NewRequest(http.MethodPost, "/transcribe", strings.NewReader(`{}`)))
+	if code != http.StatusBadRequest || body["error"] != "missing url" {
+		t.Errorf("Expected a missing url error, got %d: %v", code, body)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/extract/web", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := http.StatusNotFound
+	if metrics.Enabled {
+		want = http.StatusOK
+	}
+	if rec.Code != want {
+		t.Errorf("Expected %d from /metrics, got %d", want, rec.Code)
+	}
+}
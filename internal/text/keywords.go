@@ -0,0 +1,191 @@
+package text
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxPhraseWords is the longest candidate keyphrase, in words
+const maxPhraseWords = 3
+
+// Keywords returns the top n keyphrases of an English text. See
+// KeywordsWithStopwords.
+func Keywords(text string, n int) []string {
+	stopwords, _ := Stopwords("en")
+	return KeywordsWithStopwords(text, n, stopwords)
+}
+
+// KeywordsForLanguage returns the top n keyphrases using the built-in
+// stopwords of a language, detecting it for AutoLanguage. See StopwordsFor.
+func KeywordsForLanguage(text string, n int, language string) ([]string, error) {
+	stopwords, err := StopwordsFor(text, language)
+	if err != nil {
+		return nil, err
+	}
+	return KeywordsWithStopwords(text, n, stopwords), nil
+}
+
+// KeywordsWithStopwords ranks keyphrases with RAKE (Rapid Automatic Keyword
+// Extraction): candidates are runs of words between stopwords and
+// punctuation, each word scores its co-occurrence degree divided by its
+// frequency, and a phrase scores the sum of its words times the number of
+// times it occurs. Phrases are returned
+// in lower case, best first. Chinese and Japanese text, which has no spaces,
+// is split into words by script; see tokenize.
+func KeywordsWithStopwords(text string, n int, stopwords map[string]bool) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	phrases := candidatePhrases(text, stopwords)
+
+	freq := make(map[string]int)
+	degree := make(map[string]int)
+	for _, phrase := range phrases {
+		for _, word := range phrase {
+			freq[word]++
+			degree[word] += len(phrase)
+		}
+	}
+
+	scores := make(map[string]float64)
+	occurrences := make(map[string]int)
+	for _, phrase := range phrases {
+		key := JoinText(phrase)
+		if _, seen := scores[key]; !seen {
+			for _, word := range phrase {
+				scores[key] += float64(degree[word]) / float64(freq[word])
+			}
+			continue
+		}
+		// Repeated phrases are more central to the text; weighting by
+		// occurrences also lets single-word compounds, common in languages
+		// written without spaces, compete with longer phrases
+		occurrences[key]++
+	}
+	for key, n := range occurrences {
+		scores[key] *= float64(n + 1)
+	}
+
+	keys := make([]string, 0, len(scores))
+	for key := range scores {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if scores[keys[i]] != scores[keys[j]] {
+			return scores[keys[i]] > scores[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// candidatePhrases splits text into lower-cased runs of content words.
+// Stopwords, punctuation and line breaks end a run; runs longer than
+// maxPhraseWords are split, and words that are too short or numeric are
+// dropped.
+func candidatePhrases(text string, stopwords map[string]bool) [][]string {
+	var phrases [][]string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			phrases = append(phrases, current)
+			current = nil
+		}
+	}
+
+	for _, token := range tokenize(text, stopwords) {
+		if token == "" {
+			flush()
+			continue
+		}
+		word := strings.ToLower(token)
+		if stopwords[word] || !isKeyword(word) {
+			flush()
+			continue
+		}
+		current = append(current, word)
+		if len(current) == maxPhraseWords {
+			flush()
+		}
+	}
+	flush()
+	return phrases
+}
+
+// tokenize splits text into words, with an empty token marking every phrase
+// boundary such as punctuation. Apostrophes and hyphens inside words are
+// kept, and markdown syntax characters are treated as boundaries.
+//
+// Chinese and Japanese are written without spaces, so words are split where
+// the script changes between kanji and katakana instead. Hiragana, which
+// mostly spells particles and inflections, and single Han characters listed
+// as stopwords mark phrase boundaries like punctuation does.
+func tokenize(text string, stopwords map[string]bool) []string {
+	var tokens []string
+	var word strings.Builder
+	var wordScript *unicode.RangeTable
+	endWord := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, strings.Trim(word.String(), "'-’"))
+			word.Reset()
+		}
+		wordScript = nil
+	}
+	boundary := func() {
+		endWord()
+		tokens = append(tokens, "")
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r):
+			boundary()
+		case unicode.Is(unicode.Han, r) && stopwords[string(r)]:
+			boundary()
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Katakana, r) || (r == 'ー' && wordScript == unicode.Katakana):
+			script := unicode.Han
+			if !unicode.Is(unicode.Han, r) {
+				script = unicode.Katakana
+			}
+			if wordScript != script {
+				endWord()
+			}
+			word.WriteRune(r)
+			wordScript = script
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if wordScript != nil {
+				endWord()
+			}
+			word.WriteRune(r)
+		case (r == '\'' || r == '’' || r == '-') && word.Len() > 0 && wordScript == nil:
+			word.WriteRune(r)
+		case unicode.IsSpace(r) && r != '\n':
+			endWord()
+		default:
+			boundary()
+		}
+	}
+	endWord()
+	return tokens
+}
+
+// isKeyword rejects words too short or too numeric to describe content.
+// A CJK character carries more meaning than a letter, so two are enough.
+func isKeyword(word string) bool {
+	letters := 0
+	for _, r := range word {
+		switch {
+		case IsCJK(r):
+			letters += 2
+		case unicode.IsLetter(r):
+			letters++
+		}
+	}
+	return letters >= 3
+}
@@ -0,0 +1,60 @@
+package text
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKeywords(t *testing.T) {
+	text := `Compatibility of systems of linear constraints over the set of natural numbers.
+Criteria of compatibility of a system of linear Diophantine equations, strict inequations,
+and nonstrict inequations are considered. Upper bounds for components of a minimal set of
+solutions and algorithms of construction of minimal generating sets of solutions for all
+types of systems are given.`
+
+	got := Keywords(text, 3)
+	want := []string{"linear diophantine equations", "minimal generating sets", "linear constraints"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Keywords = %q, want %q", got, want)
+	}
+
+	if got := Keywords(text, 0); got != nil {
+		t.Errorf("Keywords with n=0 = %q, want nil", got)
+	}
+	if got := Keywords("the and of", 3); len(got) != 0 {
+		t.Errorf("Keywords of only stopwords = %q, want none", got)
+	}
+}
+
+func TestKeywordsWithStopwords(t *testing.T) {
+	stopwords, err := Stopwords("de")
+	if err != nil {
+		t.Fatalf("Stopwords failed: %v", err)
+	}
+	got := KeywordsWithStopwords("Die Katze schläft auf dem Sofa. Die Katze frisst.", 2, stopwords)
+	want := []string{"katze frisst", "katze schläft"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KeywordsWithStopwords = %q, want %q", got, want)
+	}
+
+	if _, err := Stopwords("xx"); err == nil {
+		t.Error("expected an error for a language without a stopword list")
+	}
+}
+
+func TestLoadStopwords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stopwords.txt")
+	if err := os.WriteFile(path, []byte("# custom list\nFoo\n\nbar\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stopwords, err := LoadStopwords(path)
+	if err != nil {
+		t.Fatalf("LoadStopwords failed: %v", err)
+	}
+	if !stopwords["foo"] || !stopwords["bar"] || len(stopwords) != 2 {
+		t.Errorf("LoadStopwords = %v", stopwords)
+	}
+}
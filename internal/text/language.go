@@ -0,0 +1,121 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AutoLanguage asks language-aware helpers to detect the language from the
+// text itself
+const AutoLanguage = "auto"
+
+// IsCJK reports whether r is a Chinese or Japanese character. These scripts
+// are written without spaces between words, so word and sentence rules based
+// on spaces do not apply to them.
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー'
+}
+
+// DetectLanguage guesses the language code of text from its script: "ja"
+// when it contains kana, "zh" when most letters are Han characters, "ko" for
+// Hangul, and "" for everything else, which is treated as space-separated.
+func DetectLanguage(text string) string {
+	var letters, han, kana, hangul int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case !unicode.IsLetter(r):
+			continue
+		}
+		letters++
+	}
+
+	switch {
+	case letters == 0:
+		return ""
+	case kana > 0 && (kana+han)*2 >= letters:
+		return "ja"
+	case han*2 >= letters:
+		return "zh"
+	case hangul*2 >= letters:
+		return "ko"
+	}
+	return ""
+}
+
+// usesSpaces reports whether words of a language are separated by spaces
+func usesSpaces(language string) bool {
+	switch language {
+	case "ja", "zh":
+		return false
+	}
+	return true
+}
+
+// JoinText joins pieces of running text, with a space between them except
+// where both sides are CJK characters or either is full-width punctuation
+func JoinText(parts []string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			last, _ := utf8.DecodeLastRuneInString(b.String())
+			first, _ := utf8.DecodeRuneInString(part)
+			joined := (IsCJK(last) && IsCJK(first)) || isCJKPunct(last) || isCJKPunct(first)
+			if !joined {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// isCJKPunct reports whether r is ideographic or full-width punctuation
+func isCJKPunct(r rune) bool {
+	return (r >= 0x3000 && r <= 0x303F) || (r >= 0xFF01 && r <= 0xFF65)
+}
+
+// isSentenceEnd reports whether the rune at text[i] ends a sentence. Full-width
+// terminators always do; ASCII ones only before whitespace or the end, so
+// decimals and abbreviations such as "2.5" stay intact.
+func isSentenceEnd(text string, i int, r rune) bool {
+	switch r {
+	case '。', '！', '？':
+		return true
+	case '.', '!', '?':
+		rest := text[i+1:]
+		return rest == "" || strings.HasPrefix(rest, " ") || strings.HasPrefix(rest, "\n")
+	}
+	return false
+}
+
+// Sentences splits text into sentences at ".", "!" and "?" followed by a
+// space and at the full-width "。", "！" and "？" used in Chinese and Japanese.
+// Sentences keep their terminator and are trimmed of surrounding space.
+func Sentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i, r := range text {
+		if isSentenceEnd(text, i, r) {
+			end := i + utf8.RuneLen(r)
+			if sentence := strings.TrimSpace(text[start:end]); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			start = end
+		}
+	}
+	if sentence := strings.TrimSpace(text[start:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
@@ -0,0 +1,88 @@
+package text
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"The quick brown fox": "",
+		"機械学習はデータから学習します":     "ja",
+		"ニューラルネットワーク":         "ja",
+		"机器学习是人工智能的一个分支":      "zh",
+		"기계 학습은 데이터에서 학습합니다":  "ko",
+		"":                        "",
+		"Machine learning (機械学習)": "",
+	}
+	for input, want := range tests {
+		if got := DetectLanguage(input); got != want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestSentences(t *testing.T) {
+	got := Sentences("First one. Version 2.5 is out! 日本語です。本当？終わり")
+	want := []string{"First one.", "Version 2.5 is out!", "日本語です。", "本当？", "終わり"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sentences = %q, want %q", got, want)
+	}
+}
+
+func TestJoinText(t *testing.T) {
+	got := JoinText([]string{"音声認識の", "精度は高い。", "Whisper", "model", "です"})
+	want := "音声認識の精度は高い。Whisper model です"
+	if got != want {
+		t.Errorf("JoinText = %q, want %q", got, want)
+	}
+}
+
+// TestJapaneseFixture checks the language-aware helpers against a Japanese
+// document, which has no spaces between words
+func TestJapaneseFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/japanese.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	if got := DetectLanguage(content); got != "ja" {
+		t.Fatalf("DetectLanguage = %q, want ja", got)
+	}
+
+	keywords, err := KeywordsForLanguage(content, 5, AutoLanguage)
+	if err != nil {
+		t.Fatalf("KeywordsForLanguage failed: %v", err)
+	}
+	for _, want := range []string{"ニューラルネットワーク", "機械学習", "音声認識"} {
+		found := false
+		for _, keyword := range keywords {
+			found = found || strings.Contains(keyword, want)
+		}
+		if !found {
+			t.Errorf("Expected a keyphrase containing %q, got %q", want, keywords)
+		}
+	}
+	for _, keyword := range keywords {
+		if strings.ContainsAny(keyword, " 、。はをのにで") || len([]rune(keyword)) > 20 {
+			t.Errorf("Keyphrase %q was not split into words", keyword)
+		}
+	}
+
+	sentences := Sentences(content)
+	if len(sentences) < 6 {
+		t.Errorf("Expected the fixture to split into at least 6 sentences, got %d: %q", len(sentences), sentences)
+	}
+
+	if got := DeriveTitle("機械学習は、データからパターンを学習する技術です。次の文。"); got != "機械学習は、データからパターンを学習する技術です" {
+		t.Errorf("DeriveTitle = %q", got)
+	}
+
+	restored := RestorePunctuation("機械学習は\nデータから学習する\n\n音声認識")
+	if want := "機械学習はデータから学習する。\n\n音声認識。"; restored != want {
+		t.Errorf("RestorePunctuation = %q, want %q", restored, want)
+	}
+}
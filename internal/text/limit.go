@@ -0,0 +1,129 @@
+package text
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LimitUnit selects what Limit counts
+type LimitUnit string
+
+const (
+	LimitWords LimitUnit = "words"
+	LimitChars LimitUnit = "chars"
+)
+
+// Ellipsis marks text cut short by Limit
+const Ellipsis = "…"
+
+// ParseLimitUnit validates a limit unit given on the command line
+func ParseLimitUnit(name string) (LimitUnit, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "words", "word", "":
+		return LimitWords, nil
+	case "chars", "char", "characters":
+		return LimitChars, nil
+	default:
+		return "", fmt.Errorf("unsupported limit unit: %s (expected words or chars)", name)
+	}
+}
+
+// Limit returns the start of text, at most n words or characters long, for
+// previews and to bound what is passed on. It is cut after the last sentence
+// or paragraph that fits and marked with Ellipsis; when none fits, at the
+// last word boundary. Text within the limit, and any text when n is 0 or
+// less, is returned unchanged.
+func Limit(text string, n int, unit LimitUnit) string {
+	if n <= 0 {
+		return text
+	}
+	cut := limitIndex(text, n, unit)
+	if strings.TrimSpace(text[cut:]) == "" {
+		return text
+	}
+
+	head := text[:cut]
+	if end, paragraph := lastSentenceEnd(head); end > 0 {
+		if paragraph {
+			return strings.TrimRightFunc(head[:end], unicode.IsSpace) + "\n\n" + Ellipsis
+		}
+		return head[:end] + " " + Ellipsis
+	}
+	return strings.TrimRightFunc(head, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';' || r == ':' || r == '-'
+	}) + Ellipsis
+}
+
+// limitIndex returns the byte offset in text after its first n words or
+// characters, or len(text) when text is shorter. A word the character limit
+// falls inside is left out, unless it is the first.
+func limitIndex(text string, n int, unit LimitUnit) int {
+	if unit == LimitChars {
+		count := 0
+		for i, r := range text {
+			if count < n {
+				count++
+				continue
+			}
+			if !unicode.IsSpace(r) {
+				if j := strings.LastIndexFunc(text[:i], unicode.IsSpace); j > 0 {
+					return j
+				}
+			}
+			return i
+		}
+		return len(text)
+	}
+
+	words := 0
+	inWord := false
+	for i, r := range text {
+		if !unicode.IsSpace(r) {
+			inWord = true
+			continue
+		}
+		if inWord {
+			if words++; words == n {
+				return i
+			}
+		}
+		inWord = false
+	}
+	return len(text)
+}
+
+// sentenceEnds are the marks that end a sentence; the ideographic ones need
+// no space after them
+const sentenceEnds = ".!?…。！？"
+
+// lastSentenceEnd returns the byte offset after the last complete sentence
+// or paragraph in text, and whether it ends a paragraph, or 0 when text holds
+// no complete sentence
+func lastSentenceEnd(text string) (end int, paragraph bool) {
+	for i, r := range text {
+		if r == '\n' && strings.HasPrefix(text[i:], "\n\n") && strings.TrimSpace(text[:i]) != "" {
+			end, paragraph = i, true
+			continue
+		}
+		if !strings.ContainsRune(sentenceEnds, r) {
+			continue
+		}
+
+		// Include closing quotes and brackets after the mark
+		j := i + utf8.RuneLen(r)
+		for j < len(text) {
+			next, size := utf8.DecodeRuneInString(text[j:])
+			if !strings.ContainsRune(`"')]」』`, next) {
+				break
+			}
+			j += size
+		}
+		next, _ := utf8.DecodeRuneInString(text[j:])
+		if j == len(text) || unicode.IsSpace(next) || r >= utf8.RuneSelf {
+			end, paragraph = j, false
+		}
+	}
+	return end, paragraph
+}
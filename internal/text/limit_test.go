@@ -0,0 +1,47 @@
+package text
+
+import "testing"
+
+func TestLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		n        int
+		unit     LimitUnit
+		expected string
+	}{
+		{"no limit", "One. Two. Three.", 0, LimitWords, "One. Two. Three."},
+		{"within limit", "One two three.", 3, LimitWords, "One two three."},
+		{"trailing space within limit", "One two three.\n", 3, LimitWords, "One two three.\n"},
+		{"cut at sentence", "The first sentence. The second one is longer than that.", 6, LimitWords, "The first sentence. …"},
+		{"cut at question", "Is it done? Not yet, still going.", 4, LimitWords, "Is it done? …"},
+		{"cut at quoted sentence", `He said "stop." Then he left the room.`, 5, LimitWords, `He said "stop." …`},
+		{"cut at word", "One long sentence without any end in sight, going on", 5, LimitWords, "One long sentence without any…"},
+		{"trailing comma dropped", "First, second, third, fourth", 2, LimitWords, "First, second…"},
+		{"cut at paragraph", "# Title\n\nIntro text runs on and on", 4, LimitWords, "# Title\n\n…"},
+		{"chars at sentence", "Short one. Then a much longer sentence.", 20, LimitChars, "Short one. …"},
+		{"chars inside word", "Supercalifragilistic expialidocious words", 25, LimitChars, "Supercalifragilistic…"},
+		{"chars in first word", "Supercalifragilistic", 5, LimitChars, "Super…"},
+		{"chars count runes", "Ünïcödé wörds hère", 13, LimitChars, "Ünïcödé wörds…"},
+		{"ideographic", "今日は晴れです。明日は雨です。", 10, LimitChars, "今日は晴れです。 …"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := Limit(test.text, test.n, test.unit); result != test.expected {
+				t.Errorf("Limit(%q, %d, %s) = %q, expected %q", test.text, test.n, test.unit, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseLimitUnit(t *testing.T) {
+	for name, expected := range map[string]LimitUnit{"words": LimitWords, "": LimitWords, "Chars": LimitChars, "characters": LimitChars} {
+		if unit, err := ParseLimitUnit(name); err != nil || unit != expected {
+			t.Errorf("ParseLimitUnit(%q) = %q, %v; expected %q", name, unit, err, expected)
+		}
+	}
+	if _, err := ParseLimitUnit("lines"); err == nil {
+		t.Error("Expected an error for an unknown unit")
+	}
+}
@@ -0,0 +1,160 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// spaceBeforePunct matches whitespace left in front of punctuation
+var spaceBeforePunct = regexp.MustCompile(`\s+([,.!?;:])`)
+
+// properNouns are always capitalized. Months that are also common words
+// ("may", "march") are left alone.
+var properNouns = map[string]bool{
+	"i": true, "i'm": true, "i've": true, "i'll": true, "i'd": true,
+	"monday": true, "tuesday": true, "wednesday": true, "thursday": true,
+	"friday": true, "saturday": true, "sunday": true,
+	"january": true, "february": true, "april": true, "june": true, "july": true,
+	"august": true, "september": true, "october": true, "november": true, "december": true,
+}
+
+// abbreviations end in a period without ending the sentence
+var abbreviations = map[string]bool{
+	"mr.": true, "mrs.": true, "ms.": true, "dr.": true, "prof.": true,
+	"e.g.": true, "i.e.": true, "vs.": true, "etc.": true, "st.": true,
+}
+
+// questionWords start sentences that are closed with a question mark
+var questionWords = map[string]bool{
+	"what": true, "why": true, "how": true, "who": true, "where": true, "when": true, "which": true,
+	"do": true, "does": true, "did": true, "is": true, "are": true, "can": true, "could": true,
+	"would": true, "should": true, "will": true,
+}
+
+// RestorePunctuation reflows a transcript into punctuated prose. Lines are
+// treated as fragments of the same paragraph and joined, ending a sentence
+// where the next fragment starts with a capital letter; blank lines keep
+// separating paragraphs. Sentence starts, "I" and day and month names are
+// capitalized, and every paragraph ends with terminal punctuation: a question
+// mark when its last sentence opens with a question word, a period otherwise.
+func RestorePunctuation(transcript string) string {
+	return RestorePunctuationLanguage(transcript, AutoLanguage)
+}
+
+// RestorePunctuationLanguage is like RestorePunctuation for text in the given
+// language, detecting it from the text for AutoLanguage or "". Chinese and
+// Japanese fragments are joined without spaces, are not capitalized, and end
+// with "。".
+func RestorePunctuationLanguage(transcript, language string) string {
+	if language == "" || language == AutoLanguage {
+		language = DetectLanguage(transcript)
+	}
+	restore := restoreParagraph
+	if !usesSpaces(language) {
+		restore = restoreCJKParagraph
+	}
+
+	var paragraphs []string
+	for _, block := range strings.Split(strings.ReplaceAll(transcript, "\r\n", "\n"), "\n\n") {
+		if paragraph := restore(block); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// restoreParagraph joins the lines of one paragraph into punctuated sentences
+func restoreParagraph(block string) string {
+	var fragments []string
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if n := len(fragments); n > 0 && !EndsSentence(fragments[n-1]) && startsUpper(line) {
+			fragments[n-1] = closeSentence(fragments[n-1])
+		}
+		fragments = append(fragments, line)
+	}
+	if len(fragments) == 0 {
+		return ""
+	}
+
+	words := strings.Fields(strings.Join(fragments, " "))
+	sentenceStart := true
+	for i, word := range words {
+		bare := strings.ToLower(strings.Trim(word, ".,!?;:\"'()"))
+		if sentenceStart || properNouns[bare] {
+			words[i] = capitalize(word)
+		}
+		sentenceStart = EndsSentence(word) && !abbreviations[strings.ToLower(word)]
+	}
+
+	paragraph := spaceBeforePunct.ReplaceAllString(strings.Join(words, " "), "$1")
+	if !EndsSentence(paragraph) {
+		paragraph = closeSentence(paragraph)
+	}
+	return paragraph
+}
+
+// restoreCJKParagraph joins the lines of a Chinese or Japanese paragraph and
+// closes it with an ideographic full stop
+func restoreCJKParagraph(block string) string {
+	paragraph := JoinText(strings.Split(block, "\n"))
+	if paragraph != "" && !EndsSentence(paragraph) {
+		paragraph = strings.TrimRight(paragraph, "、，") + "。"
+	}
+	return paragraph
+}
+
+// closeSentence ends text with a period, or a question mark when its last
+// sentence opens with a question word
+func closeSentence(text string) string {
+	text = strings.TrimRight(text, " ,;:-")
+	last := text
+	if i := strings.LastIndexAny(text, ".!?…"); i >= 0 {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		last = text[i+size:]
+	}
+	if fields := strings.Fields(last); len(fields) > 0 && questionWords[strings.ToLower(strings.Trim(fields[0], "\"'("))] {
+		return text + "?"
+	}
+	return text + "."
+}
+
+// EndsSentence reports whether s ends with terminal punctuation, allowing
+// for a closing quote or parenthesis
+func EndsSentence(s string) bool {
+	s = strings.TrimRight(s, "\"')」』")
+	for _, end := range []string{".", "!", "?", "…", "。", "！", "？"} {
+		if strings.HasSuffix(s, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// startsUpper reports whether the first letter of s is uppercase
+func startsUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return unicode.IsUpper(r)
+		}
+	}
+	return false
+}
+
+// capitalize uppercases the first letter of word, skipping leading quotes
+func capitalize(word string) string {
+	for i, r := range word {
+		if unicode.IsLetter(r) {
+			return word[:i] + string(unicode.ToUpper(r)) + word[i+utf8.RuneLen(r):]
+		}
+		if unicode.IsDigit(r) {
+			return word
+		}
+	}
+	return word
+}
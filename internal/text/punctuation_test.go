@@ -0,0 +1,55 @@
+package text
+
+import "testing"
+
+func TestRestorePunctuation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "sentence starts and I",
+			input: "so i think we should start. what do you think",
+			want:  "So I think we should start. What do you think?",
+		},
+		{
+			name:  "fragments joined into sentences",
+			input: "we met on monday\nThe plan was agreed\nand then i'm going to\nwrite it up",
+			want:  "We met on Monday. The plan was agreed and then I'm going to write it up.",
+		},
+		{
+			name:  "paragraphs kept",
+			input: "first part\n\nsecond part!",
+			want:  "First part.\n\nSecond part!",
+		},
+		{
+			name:  "abbreviations do not end sentences",
+			input: "ask dr. smith, e.g. about the report ,",
+			want:  "Ask dr. smith, e.g. about the report.",
+		},
+		{
+			name:  "questions",
+			input: "how does it work\nIt is simple",
+			want:  "How does it work? It is simple.",
+		},
+		{
+			name:  "space before punctuation",
+			input: "hello , world ?",
+			want:  "Hello, world?",
+		},
+		{
+			name:  "empty",
+			input: "  \n\n ",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RestorePunctuation(tt.input); got != tt.want {
+				t.Errorf("RestorePunctuation(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package text
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WordsPerMinute is the average reading speed used to estimate reading time
+const WordsPerMinute = 200
+
+// Stats holds simple statistics about a piece of extracted text
+type Stats struct {
+	Words       int
+	ReadingTime time.Duration
+}
+
+// TextStats counts the words in content and estimates its reading time.
+// Markdown markers such as "#", ">" and "---" are not counted as words.
+func TextStats(content string) Stats {
+	words := 0
+	for _, field := range strings.Fields(content) {
+		if strings.IndexFunc(field, isWordRune) >= 0 {
+			words++
+		}
+	}
+
+	minutes := float64(words) / WordsPerMinute
+	return Stats{
+		Words:       words,
+		ReadingTime: time.Duration(minutes * float64(time.Minute)),
+	}
+}
+
+// ReadingMinutes returns the reading time rounded up to whole minutes
+func (s Stats) ReadingMinutes() int {
+	return int(math.Ceil(s.ReadingTime.Minutes()))
+}
+
+// isWordRune reports whether r can be part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
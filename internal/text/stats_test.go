@@ -0,0 +1,35 @@
+package text
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextStats(t *testing.T) {
+	tests := []struct {
+		content string
+		words   int
+		minutes int
+	}{
+		{"", 0, 0},
+		{"# Title\n\n> Quoted **bold** text\n\n---\n", 4, 1},
+		{"one two three", 3, 1},
+		{strings.Repeat("word ", 200), 200, 1},
+		{strings.Repeat("word ", 201), 201, 2},
+	}
+
+	for _, test := range tests {
+		stats := TextStats(test.content)
+		if stats.Words != test.words {
+			t.Errorf("TextStats(%q).Words = %d, expected %d", test.content, stats.Words, test.words)
+		}
+		if stats.ReadingMinutes() != test.minutes {
+			t.Errorf("TextStats(%q).ReadingMinutes() = %d, expected %d", test.content, stats.ReadingMinutes(), test.minutes)
+		}
+	}
+
+	if got := TextStats(strings.Repeat("word ", 400)).ReadingTime; got != 2*time.Minute {
+		t.Errorf("Expected 2 minutes for 400 words, got %v", got)
+	}
+}
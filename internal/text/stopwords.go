@@ -0,0 +1,116 @@
+package text
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// stopwordLists are the built-in stopwords per language code
+var stopwordLists = map[string]string{
+	"en": `a about above after again against all also am an and any are as at be because been before being
+below between both but by can could did do does doing down during each even few for from further get got
+had has have having he her here hers herself him himself his how however i if in into is it its itself
+just like many may me might more most much must my myself no nor not now of off on once one only or other
+our ours ourselves out over own really same say said says she should so some such than that the their
+theirs them themselves then there these they this those through to too under until up upon us very was
+we well were what when where which while who whom why will with within without would yes yet you your
+yours yourself yourselves i'm i've i'll i'd you're you've you'll he's she's it's we're we've they're
+they've don't doesn't didn't isn't aren't wasn't weren't won't wouldn't can't couldn't shouldn't that's
+there's let's going gonna okay oh um uh yeah`,
+
+	"de": `aber alle allem allen aller alles als also am an ander andere anderem anderen anderer anderes
+auch auf aus bei bin bis bist da damit dann das dass dein deine dem den denn der des dich die dies diese
+diesem diesen dieser dieses dir doch dort du durch ein eine einem einen einer eines er es etwas euch
+euer für gegen hab habe haben hat hatte hier hin hinter ich ihr ihre im in indem ins ist ja jede jedem
+jeden jeder jedes jetzt kann kein keine können man mein meine mich mir mit muss nach nicht nichts noch
+nun nur ob oder ohne sehr sein seine sich sie sind so solche soll sondern sonst über um und uns unser
+unter viel vom von vor war waren warum was weil welche wenn wer werden wie wieder will wir wird wo zu
+zum zur zwar zwischen`,
+
+	"es": `a al algo algunas algunos ante antes como con contra cual cuando de del desde donde durante
+e el ella ellas ellos en entre era eran es esa esas ese eso esos esta estaba estado estar estas este
+esto estos fue fueron ha había han hasta hay la las le les lo los más me mi mis mucho muy nada ni no
+nos nosotros o os otra otro para pero poco por porque que quien se sea ser si sin sobre son su sus
+también tanto te tiene tienen todo todos tu tus un una uno unos y ya yo`,
+
+	"fr": `à ai au aux avec avait avez avoir c ce cela ces cet cette comme dans de des donc du elle elles
+en est et été être eu il ils je la le les leur leurs lui ma mais me même mes moi mon ne nos notre nous
+on ont ou où par pas pour qu que qui sa se ses si son sont sur ta te tes toi ton tous tout très tu un
+une vos votre vous y`,
+
+	// Hiragana already separates Japanese words, so these are the kanji and
+	// katakana words too common to describe content
+	"ja": `事 物 方 為 時 中 上 下 前 後 今 私 僕 彼 彼女 我々 自分 何 人 年 月 日 等 場合 部分 以上 以下
+場所 必要 可能 問題 関係 説明 今回 今日 本当 全部 一番 一つ 二つ 最初 最後 感じ 意味 他 皆 全て`,
+
+	// Single characters here also split runs of Han characters into words
+	"zh": `的 了 是 在 我 有 和 就 不 人 都 一 也 很 到 说 要 去 你 会 着 看 好 这 那 他 她 它 们 个
+与 及 或 而 被 把 给 让 对 从 向 但 因 为 所 以 如 果 之 其 此 由 于 等 中 上 下 里 吗 呢 吧 啊 没有 自己
+我们 你们 他们 这个 那个 什么 怎么 因为 所以 如果 但是 可以 已经 还是 就是 一个 没 还 又 再 才 只`,
+}
+
+// StopwordLanguages returns the language codes with built-in stopword lists
+func StopwordLanguages() []string {
+	languages := make([]string, 0, len(stopwordLists))
+	for language := range stopwordLists {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Stopwords returns the built-in stopword set for a language code
+func Stopwords(language string) (map[string]bool, error) {
+	list, ok := stopwordLists[strings.ToLower(strings.TrimSpace(language))]
+	if !ok {
+		return nil, fmt.Errorf("no stopword list for language %q (available: %s)", language, strings.Join(StopwordLanguages(), ", "))
+	}
+	return stopwordSet(strings.Fields(list)), nil
+}
+
+// StopwordsFor returns the built-in stopwords for language. AutoLanguage or
+// "" detects the language from text, falling back to English when there is
+// no list for it.
+func StopwordsFor(text, language string) (map[string]bool, error) {
+	if language == "" || language == AutoLanguage {
+		language = DetectLanguage(text)
+		if _, ok := stopwordLists[language]; !ok {
+			language = "en"
+		}
+	}
+	return Stopwords(language)
+}
+
+// LoadStopwords reads a stopword file with one word per line. Blank lines and
+// lines starting with "#" are ignored.
+func LoadStopwords(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stopword file: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stopword file: %w", err)
+	}
+	return stopwordSet(words), nil
+}
+
+func stopwordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[strings.ToLower(word)] = true
+	}
+	return set
+}
@@ -0,0 +1,81 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxTitleLength is the longest derived title, in characters
+const MaxTitleLength = 60
+
+// DeriveTitle builds a title for content that has none: the first markdown
+// heading, or else the first sentence of the first paragraph. Markdown markup
+// is removed and long titles are cut at a word boundary. It returns "" when
+// the content has no text.
+func DeriveTitle(content string) string {
+	var firstText string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || trimmed == "" || isRule(trimmed) {
+			continue
+		}
+		if level := HeadingLevel(trimmed); level > 0 {
+			if title := cleanTitle(trimmed[level:]); title != "" {
+				return truncateTitle(title)
+			}
+			continue
+		}
+		if firstText == "" {
+			firstText = trimmed
+		}
+	}
+
+	return truncateTitle(cleanTitle(firstSentence(firstText)))
+}
+
+// isRule reports whether a line is a markdown thematic break
+func isRule(line string) bool {
+	return strings.Trim(line, "-*_ ") == "" && len(strings.TrimSpace(line)) >= 3
+}
+
+// firstSentence returns the first sentence of text without its terminator
+func firstSentence(text string) string {
+	sentences := Sentences(text)
+	if len(sentences) == 0 {
+		return ""
+	}
+	return strings.TrimRight(sentences[0], ".!?。！？")
+}
+
+// cleanTitle strips markdown markup and collapses whitespace
+func cleanTitle(title string) string {
+	title = strings.Map(func(r rune) rune {
+		switch r {
+		case '*', '_', '`', '#', '[', ']', '>':
+			return -1
+		}
+		return r
+	}, title)
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// truncateTitle cuts a title to MaxTitleLength characters, at the last word
+// boundary when there is one, and trims trailing punctuation
+func truncateTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) > MaxTitleLength {
+		cut := string(runes[:MaxTitleLength])
+		if i := strings.LastIndex(cut, " "); i > 0 {
+			cut = cut[:i]
+		}
+		title = cut
+	}
+	return strings.TrimRightFunc(title, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';' || r == ':' || r == '-'
+	})
+}
@@ -0,0 +1,26 @@
+package text
+
+import "testing"
+
+func TestDeriveTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"heading", "Intro text.\n\n## Getting **Started**\n\nBody", "Getting Started"},
+		{"heading in code is ignored", "```\n# not a title\n```\nReal text here. More.", "Real text here"},
+		{"first sentence", "---\n\nWelcome to the *annual* meeting. Today we discuss budgets.", "Welcome to the annual meeting"},
+		{"decimal is not a sentence end", "Version 2.5 ships today", "Version 2.5 ships today"},
+		{"truncated at word boundary", "This is a very long opening sentence that keeps going well past the limit for titles", "This is a very long opening sentence that keeps going well"},
+		{"empty", "\n\n---\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeriveTitle(tt.content); got != tt.want {
+				t.Errorf("DeriveTitle = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
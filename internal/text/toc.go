@@ -0,0 +1,120 @@
+package text
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// slugPattern matches characters dropped from heading anchors
+var slugPattern = regexp.MustCompile(`[^\p{L}\p{N}\s_-]`)
+
+// Slugger builds GitHub-style heading anchors, numbering repeated headings
+// ("intro", "intro-1", ...) the way GitHub does within one document
+type Slugger struct {
+	seen map[string]int
+}
+
+// Slug returns the anchor for heading, unique among the slugs returned so far
+func (s *Slugger) Slug(heading string) string {
+	if s.seen == nil {
+		s.seen = make(map[string]int)
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(heading))
+	slug = slugPattern.ReplaceAllString(slug, "")
+	slug = strings.ReplaceAll(slug, " ", "-")
+
+	n := s.seen[slug]
+	s.seen[slug] = n + 1
+	if n > 0 {
+		return fmt.Sprintf("%s-%d", slug, n)
+	}
+	return slug
+}
+
+// HeadingLevel returns the level of an ATX heading line such as "## Title",
+// or 0 when the line is not a heading
+func HeadingLevel(line string) int {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 || !strings.HasPrefix(line[level:], " ") {
+		return 0
+	}
+	return level
+}
+
+// ShiftHeadings demotes every heading of a markdown document by offset
+// levels, so "# Title" becomes "### Title" for an offset of 2. Levels are
+// clamped at 6, the deepest markdown heading. Headings inside fenced code
+// blocks are left alone.
+func ShiftHeadings(markdown string, offset int) string {
+	if offset <= 0 {
+		return markdown
+	}
+
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		level := HeadingLevel(line)
+		if inFence || level == 0 {
+			continue
+		}
+		lines[i] = strings.Repeat("#", min(level+offset, 6)) + line[level:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GenerateTOC returns a linked table of contents for the "#" and "##"
+// headings of a markdown document, or "" when it has none. Headings inside
+// fenced code blocks are ignored.
+func GenerateTOC(markdown string) string {
+	type heading struct {
+		level int
+		text  string
+	}
+
+	var headings []heading
+	minLevel := 2
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		level := HeadingLevel(line)
+		if inFence || level == 0 || level > 2 {
+			continue
+		}
+		headings = append(headings, heading{level, strings.TrimSpace(line[level:])})
+		minLevel = min(minLevel, level)
+	}
+
+	if len(headings) == 0 {
+		return ""
+	}
+
+	// Indent relative to the shallowest heading so lists never start nested
+	var b strings.Builder
+	var slugger Slugger
+	b.WriteString("## Contents\n\n")
+	for _, h := range headings {
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", strings.Repeat("  ", h.level-minLevel), h.text, slugger.Slug(h.text))
+	}
+	return b.String()
+}
+
+// InsertTOC adds the table of contents for markdown at its top, after a
+// leading "# " title line if there is one
+func InsertTOC(markdown string) string {
+	toc := GenerateTOC(markdown)
+	if toc == "" {
+		return markdown
+	}
+
+	if first, rest, ok := strings.Cut(markdown, "\n"); ok && HeadingLevel(first) == 1 {
+		return first + "\n\n" + toc + "\n" + strings.TrimLeft(rest, "\n")
+	}
+	return toc + "\n" + markdown
+}
@@ -0,0 +1,98 @@
+package text
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugger(t *testing.T) {
+	var s Slugger
+	tests := []struct {
+		heading  string
+		expected string
+	}{
+		{"Getting Started", "getting-started"},
+		{"What's New?", "whats-new"},
+		{"Getting Started", "getting-started-1"},
+		{"Getting Started", "getting-started-2"},
+		{"snake_case & more", "snake_case--more"},
+		{"Über Café", "über-café"},
+	}
+
+	for _, test := range tests {
+		if result := s.Slug(test.heading); result != test.expected {
+			t.Errorf("Slug(%q) = %q, expected %q", test.heading, result, test.expected)
+		}
+	}
+}
+
+func TestHeadingLevel(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected int
+	}{
+		{"# Title", 1},
+		{"### Deep", 3},
+		{"#hashtag", 0},
+		{"####### Too deep", 0},
+		{"plain", 0},
+	}
+
+	for _, test := range tests {
+		if result := HeadingLevel(test.line); result != test.expected {
+			t.Errorf("HeadingLevel(%q) = %d, expected %d", test.line, result, test.expected)
+		}
+	}
+}
+
+func TestShiftHeadings(t *testing.T) {
+	markdown := "# Title\n\ntext with # hash\n\n## Part\n\n##### Deep\n\n###### Deepest\n\n```\n# comment\n```\n#hashtag"
+
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, markdown},
+		{-1, markdown},
+		{2, "### Title\n\ntext with # hash\n\n#### Part\n\n###### Deep\n\n###### Deepest\n\n```\n# comment\n```\n#hashtag"},
+		{9, "###### Title\n\ntext with # hash\n\n###### Part\n\n###### Deep\n\n###### Deepest\n\n```\n# comment\n```\n#hashtag"},
+	}
+
+	for _, test := range tests {
+		if result := ShiftHeadings(markdown, test.offset); result != test.expected {
+			t.Errorf("ShiftHeadings(offset %d) = %q, expected %q", test.offset, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateTOC(t *testing.T) {
+	markdown := "# Intro\n\ntext\n\n## Setup\n\n### Details\n\n```\n# not a heading\n```\n\n## Setup\n"
+
+	expected := "## Contents\n\n- [Intro](#intro)\n  - [Setup](#setup)\n  - [Setup](#setup-1)\n"
+	if toc := GenerateTOC(markdown); toc != expected {
+		t.Errorf("Unexpected TOC:\n%s\nexpected:\n%s", toc, expected)
+	}
+
+	if toc := GenerateTOC("no headings here"); toc != "" {
+		t.Errorf("Expected empty TOC, got %q", toc)
+	}
+}
+
+func TestInsertTOC(t *testing.T) {
+	withTitle := InsertTOC("# Title\n\n## Part\n\nbody")
+	if !strings.HasPrefix(withTitle, "# Title\n\n## Contents\n") {
+		t.Errorf("Expected TOC after the title line, got:\n%s", withTitle)
+	}
+	if !strings.HasSuffix(withTitle, "## Part\n\nbody") {
+		t.Errorf("Expected body to follow the TOC, got:\n%s", withTitle)
+	}
+
+	withoutTitle := InsertTOC("## Part\n\nbody")
+	if !strings.HasPrefix(withoutTitle, "## Contents\n\n- [Part](#part)\n\n## Part") {
+		t.Errorf("Expected TOC at the top, got:\n%s", withoutTitle)
+	}
+
+	if plain := InsertTOC("body"); plain != "body" {
+		t.Errorf("Expected document without headings unchanged, got %q", plain)
+	}
+}